@@ -21,12 +21,44 @@ type Config struct {
 
 	DebugMode bool
 	LogFormat string
+
+	RiskModels []string
+
+	SchoolTimeZone string
+
+	GraphQLMaxBatchSize int
+
+	StorageDriver string
+	StoragePath   string
+	MaxPhotoSize  int64
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+
+	ReportPath      string
+	ReportWorkers   int
+	ReportQueueSize int
+	ReportExpireIn  time.Duration
+	ReportVerifyURL string
 }
 
 func LoadConfig(path string) *Config {
 	config := viper.New()
 	config.SetConfigName("Config")
 	config.AddConfigPath(path)
+	config.SetDefault("school.time-zone", "UTC")
+	config.SetDefault("graphql.max-batch-size", 10)
+	config.SetDefault("storage.driver", "local")
+	config.SetDefault("storage.path", "./photos")
+	config.SetDefault("storage.max-photo-size", 10<<20)
+	config.SetDefault("report.path", "./reports")
+	config.SetDefault("report.workers", 2)
+	config.SetDefault("report.queue-size", 16)
+	config.SetDefault("report.expire-in", "24h")
+	config.SetDefault("report.verify-url", "http://localhost:3001/verify/")
 	err := config.ReadInConfig()
 	if err != nil {
 		log.Fatalf("Fatal error context file: %s \n", err)
@@ -46,5 +78,27 @@ func LoadConfig(path string) *Config {
 
 		DebugMode: config.Get("log.debug-mode").(bool),
 		LogFormat: config.Get("log.log-format").(string),
+
+		RiskModels: config.GetStringSlice("risk.models"),
+
+		SchoolTimeZone: config.GetString("school.time-zone"),
+
+		GraphQLMaxBatchSize: config.GetInt("graphql.max-batch-size"),
+
+		StorageDriver: config.GetString("storage.driver"),
+		StoragePath:   config.GetString("storage.path"),
+		MaxPhotoSize:  config.GetInt64("storage.max-photo-size"),
+
+		S3Endpoint:  config.GetString("storage.s3.endpoint"),
+		S3Region:    config.GetString("storage.s3.region"),
+		S3Bucket:    config.GetString("storage.s3.bucket"),
+		S3AccessKey: config.GetString("storage.s3.access-key"),
+		S3SecretKey: config.GetString("storage.s3.secret-key"),
+
+		ReportPath:      config.GetString("report.path"),
+		ReportWorkers:   config.GetInt("report.workers"),
+		ReportQueueSize: config.GetInt("report.queue-size"),
+		ReportExpireIn:  config.GetDuration("report.expire-in"),
+		ReportVerifyURL: config.GetString("report.verify-url"),
 	}
 }
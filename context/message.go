@@ -1,8 +1,20 @@
 package context
 
 const (
-	PostMethodSupported = "only post method is allowed"
-	CredentialsError    = "credentials error"
-	TokenError          = "token error"
-	UnauthorizedAccess  = "unauthorized access"
+	PostMethodSupported      = "only post method is allowed"
+	GetOrPostMethodSupported = "only get and post methods are allowed"
+	CredentialsError         = "credentials error"
+	TokenError               = "token error"
+	UnauthorizedAccess       = "unauthorized access"
+	EmptyRequestBody         = "request body is empty"
+	MalformedRequestBody     = "request body is not a valid graphql request"
+	MalformedVariables       = "variables are not a valid json object"
+	MissingQuery             = "query is required"
+	EmptyBatch               = "batch must contain at least one operation"
+	BatchTooLarge            = "batch must contain at most %d operations"
+	QueryOverGetOnly         = "only query operations can be sent with the get method"
+	SubscriptionOnly         = "only subscription operations can be started"
+	UnknownMessageType       = "unknown message type"
+	RepeatedConnectionInit   = "connection is already initialized"
+	ConnectionNotInitialized = "connection is not initialized"
 )
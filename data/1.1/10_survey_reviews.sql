@@ -0,0 +1,69 @@
+-- IDCRA API Migration File Survey Reviews
+-- Contents:
+-- - Supervisor Role
+-- - Survey Status
+-- - Survey Drafts
+-- - Survey Reviews
+-- ----------------------------------------------------------------------------
+
+-- Supervisor Role
+-- Supervisors review the surveys submitted by surveyors.
+INSERT INTO `roles` VALUES
+('3b7e9c41-6d2a-4f85-b0c3-9a1e5d7f2c68', 'SUPERVISOR');
+-- ----------------------------------------------------------------------------
+
+-- Survey Status
+-- A survey is saved as a DRAFT while the surveyor is still recording it, is
+-- SUBMITTED for review once complete, and is then APPROVED or REJECTED by a
+-- supervisor. A rejected survey can be reopened as a draft. Surveys recorded
+-- before the review workflow existed were final and are approved.
+ALTER TABLE `surveys`
+  ADD COLUMN `status` ENUM('DRAFT', 'SUBMITTED', 'APPROVED', 'REJECTED') NOT NULL DEFAULT 'SUBMITTED' AFTER `subjective_score`,
+  ADD COLUMN `submitted_at` TIMESTAMP NULL AFTER `status`,
+  ADD COLUMN `reviewed_by` CHAR(36) NULL AFTER `submitted_at`,
+  ADD COLUMN `reviewed_at` TIMESTAMP NULL AFTER `reviewed_by`,
+  ADD COLUMN `review_comment` VARCHAR(1000) NULL AFTER `reviewed_at`,
+  ADD INDEX `surveys_idx_status` (`status`, `submitted_at`),
+  ADD CONSTRAINT `fk_surveys_reviewers` FOREIGN KEY (`reviewed_by`)
+    REFERENCES `users`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION;
+
+UPDATE `surveys` SET `status` = 'APPROVED', `submitted_at` = `created_at`, `updated_at` = `updated_at`;
+-- ----------------------------------------------------------------------------
+
+-- Survey Drafts Table
+-- The input of a draft survey as last saved by the surveyor, in JSON. Only the
+-- student, surveyor and date of a draft are kept on the survey itself; the
+-- rest is recorded when the draft is submitted.
+CREATE TABLE IF NOT EXISTS `survey_drafts` (
+  `survey_id` CHAR(36) NOT NULL,
+  `input` MEDIUMTEXT NOT NULL,
+  `updated_at` TIMESTAMP NOT NULL DEFAULT NOW() ON UPDATE NOW(),
+  PRIMARY KEY (`survey_id`),
+  CONSTRAINT `fk_survey_drafts_surveys` FOREIGN KEY (`survey_id`)
+    REFERENCES `surveys`(`id`)
+    ON DELETE CASCADE ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- Survey Reviews Table
+-- Every approval and rejection of a survey, oldest first.
+CREATE TABLE IF NOT EXISTS `survey_reviews` (
+  `id` CHAR(36) NOT NULL,
+  `survey_id` CHAR(36) NOT NULL,
+  `status` ENUM('APPROVED', 'REJECTED') NOT NULL,
+  `reviewer_id` CHAR(36) NOT NULL,
+  `comment` VARCHAR(1000) NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (`id`),
+  INDEX `survey_reviews_idx_1` (`survey_id`, `created_at`),
+  CONSTRAINT `fk_survey_reviews_surveys` FOREIGN KEY (`survey_id`)
+    REFERENCES `surveys`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT `fk_survey_reviews_users` FOREIGN KEY (`reviewer_id`)
+    REFERENCES `users`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
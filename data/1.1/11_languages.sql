@@ -0,0 +1,11 @@
+-- IDCRA API Migration File Languages
+-- Contents:
+-- - Preferred Language
+-- ----------------------------------------------------------------------------
+
+-- Preferred Language
+-- The language reports are printed in for the user when no language is asked
+-- for, such as "id" or "en". Users without one get the default language.
+ALTER TABLE `users`
+  ADD COLUMN `preferred_language` VARCHAR(16) NULL AFTER `ip_address`;
+-- ----------------------------------------------------------------------------
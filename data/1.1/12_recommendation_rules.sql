@@ -0,0 +1,208 @@
+-- IDCRA API Migration File Recommendation Rules
+-- Contents:
+-- - Recommendation Rule Sets
+-- - Recommendation Rules
+-- - Recommendation Rule Texts
+-- - Recommendation rules data
+-- ----------------------------------------------------------------------------
+
+-- Recommendation Rule Sets Table
+-- A rule set is never changed once published; changes are published as a new
+-- version. Reports are printed with the latest version.
+CREATE TABLE IF NOT EXISTS `recommendation_rule_sets` (
+  `id` CHAR(36) NOT NULL,
+  `version` INT NOT NULL,
+  `note` VARCHAR(255),
+  `created_by` CHAR(36),
+  `created_at` TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `recommendation_rule_sets_idx_1` (`version`),
+  CONSTRAINT `fk_recommendation_rule_sets_users` FOREIGN KEY (`created_by`)
+    REFERENCES `users`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- Recommendation Rules Table
+-- A rule adds its text to the target section of the reports of surveys in its
+-- risk category. The age band, answer and diagnosis, when set, narrow the rule
+-- down to students of that age at the survey date, surveys with that answer to
+-- the question and surveys with a case of that diagnosis.
+CREATE TABLE IF NOT EXISTS `recommendation_rules` (
+  `id` CHAR(36) NOT NULL,
+  `rule_set_id` CHAR(36) NOT NULL,
+  `position` INT NOT NULL,
+  `risk_category` ENUM('low', 'medium', 'high') NOT NULL,
+  `target` VARCHAR(45) NOT NULL,
+  `min_age` INT,
+  `max_age` INT,
+  `question_code` VARCHAR(45),
+  `answer_value` VARCHAR(45),
+  `diagnosis_and_action_id` CHAR(36),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `recommendation_rules_idx_1` (`rule_set_id`, `position`),
+  CONSTRAINT `fk_recommendation_rules_rule_sets` FOREIGN KEY (`rule_set_id`)
+    REFERENCES `recommendation_rule_sets`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT `fk_recommendation_rules_diagnosis_and_actions` FOREIGN KEY (`diagnosis_and_action_id`)
+    REFERENCES `diagnosis_and_actions`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- Recommendation Rule Texts Table
+CREATE TABLE IF NOT EXISTS `recommendation_rule_texts` (
+  `rule_id` CHAR(36) NOT NULL,
+  `locale` VARCHAR(10) NOT NULL,
+  `text` VARCHAR(1000) NOT NULL,
+  PRIMARY KEY (`rule_id`, `locale`),
+  CONSTRAINT `fk_recommendation_rule_texts_rules` FOREIGN KEY (`rule_id`)
+    REFERENCES `recommendation_rules`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- Recommendation Rules Data
+-- The recommendations reports were printed with before they were configurable,
+-- with the ART suggestion of high risk surveys restored.
+INSERT INTO `recommendation_rule_sets` (`id`, `version`, `note`, `created_at`) VALUES
+('8d4b2f1e-6a3c-4e57-b9d2-3f1a7c5e0b21', 1, 'Initial recommendations', NOW());
+
+INSERT INTO `recommendation_rules` (`id`, `rule_set_id`, `position`, `risk_category`, `target`)
+SELECT UUID(), '8d4b2f1e-6a3c-4e57-b9d2-3f1a7c5e0b21', r.`position`, r.`risk_category`, r.`target`
+FROM (
+  SELECT 1 AS `position`, 'low' AS `risk_category`, 'PARENT_REMINDER' AS `target`
+  UNION ALL SELECT 2, 'low', 'PARENT_GUIDANCE'
+  UNION ALL SELECT 3, 'low', 'PARENT_GUIDANCE'
+  UNION ALL SELECT 4, 'low', 'PARENT_SUPERVISION'
+  UNION ALL SELECT 5, 'low', 'TEACHER_REMINDER'
+  UNION ALL SELECT 6, 'low', 'TEACHER_GUIDANCE'
+  UNION ALL SELECT 7, 'low', 'TEACHER_GUIDANCE'
+  UNION ALL SELECT 8, 'low', 'OPERATOR_RECURRING'
+  UNION ALL SELECT 9, 'low', 'OPERATOR_FLUORIDE'
+  UNION ALL SELECT 10, 'low', 'OPERATOR_DIET'
+  UNION ALL SELECT 11, 'low', 'OPERATOR_SEALANT'
+  UNION ALL SELECT 12, 'low', 'OPERATOR_ART'
+  UNION ALL SELECT 13, 'medium', 'PARENT_REMINDER'
+  UNION ALL SELECT 14, 'medium', 'PARENT_GUIDANCE'
+  UNION ALL SELECT 15, 'medium', 'PARENT_GUIDANCE'
+  UNION ALL SELECT 16, 'medium', 'PARENT_GUIDANCE'
+  UNION ALL SELECT 17, 'medium', 'PARENT_SUPERVISION'
+  UNION ALL SELECT 18, 'medium', 'TEACHER_REMINDER'
+  UNION ALL SELECT 19, 'medium', 'TEACHER_GUIDANCE'
+  UNION ALL SELECT 20, 'medium', 'TEACHER_GUIDANCE'
+  UNION ALL SELECT 21, 'medium', 'TEACHER_GUIDANCE'
+  UNION ALL SELECT 22, 'medium', 'OPERATOR_RECURRING'
+  UNION ALL SELECT 23, 'medium', 'OPERATOR_FLUORIDE'
+  UNION ALL SELECT 24, 'medium', 'OPERATOR_DIET'
+  UNION ALL SELECT 25, 'medium', 'OPERATOR_SEALANT'
+  UNION ALL SELECT 26, 'medium', 'OPERATOR_ART'
+  UNION ALL SELECT 27, 'high', 'PARENT_REMINDER'
+  UNION ALL SELECT 28, 'high', 'PARENT_GUIDANCE'
+  UNION ALL SELECT 29, 'high', 'PARENT_GUIDANCE'
+  UNION ALL SELECT 30, 'high', 'PARENT_GUIDANCE'
+  UNION ALL SELECT 31, 'high', 'PARENT_SUPERVISION'
+  UNION ALL SELECT 32, 'high', 'PARENT_SUPERVISION'
+  UNION ALL SELECT 33, 'high', 'TEACHER_REMINDER'
+  UNION ALL SELECT 34, 'high', 'TEACHER_GUIDANCE'
+  UNION ALL SELECT 35, 'high', 'TEACHER_GUIDANCE'
+  UNION ALL SELECT 36, 'high', 'TEACHER_GUIDANCE'
+  UNION ALL SELECT 37, 'high', 'OPERATOR_RECURRING'
+  UNION ALL SELECT 38, 'high', 'OPERATOR_FLUORIDE'
+  UNION ALL SELECT 39, 'high', 'OPERATOR_DIET'
+  UNION ALL SELECT 40, 'high', 'OPERATOR_SEALANT'
+  UNION ALL SELECT 41, 'high', 'OPERATOR_ART'
+) r;
+
+INSERT INTO `recommendation_rule_texts` (`rule_id`, `locale`, `text`)
+SELECT r.`id`, t.`locale`, t.`text`
+FROM `recommendation_rules` r
+JOIN (
+  SELECT 1 AS `position`, 'id' AS `locale`, 'Orang tua mengingatkan agar kontrol ke dokter gigi setiap 6 bulan sekali' AS `text`
+  UNION ALL SELECT 1, 'en', 'Parents remind the child to see a dentist every 6 months'
+  UNION ALL SELECT 2, 'id', 'Orang tua mengajarkan cara menyikat gigi yang benar'
+  UNION ALL SELECT 2, 'en', 'Parents teach the child how to brush their teeth properly'
+  UNION ALL SELECT 3, 'id', 'Orang tua mengingatkan agar menyikat gigi 2x sehari dengan pasta gigi ber fluoride'
+  UNION ALL SELECT 3, 'en', 'Parents remind the child to brush twice a day with fluoride toothpaste'
+  UNION ALL SELECT 4, 'id', 'Orang tua memberikan pengawasan terhadap makanan manis dan lengket yang dikonsumsi sehari - hari'
+  UNION ALL SELECT 4, 'en', 'Parents supervise the sweet and sticky food the child eats every day'
+  UNION ALL SELECT 5, 'id', 'Guru mengingatkan agar kontrol ke dokter gigi setiap 6 bulan sekali'
+  UNION ALL SELECT 5, 'en', 'Teachers remind the child to see a dentist every 6 months'
+  UNION ALL SELECT 6, 'id', 'Guru mengajarkan cara menyikat gigi yang benar'
+  UNION ALL SELECT 6, 'en', 'Teachers teach the child how to brush their teeth properly'
+  UNION ALL SELECT 7, 'id', 'Guru mengingatkan agar menyikat gigi 2x sehari dengan pasta gigi ber fluoride'
+  UNION ALL SELECT 7, 'en', 'Teachers remind the child to brush twice a day with fluoride toothpaste'
+  UNION ALL SELECT 8, 'id', 'setiap 6-12 bulan'
+  UNION ALL SELECT 8, 'en', 'every 6-12 months'
+  UNION ALL SELECT 9, 'id', 'pasta gigi 2x sehari'
+  UNION ALL SELECT 9, 'en', 'toothpaste twice a day'
+  UNION ALL SELECT 10, 'id', 'pemeliharaan asupan diet'
+  UNION ALL SELECT 10, 'en', 'maintain the diet'
+  UNION ALL SELECT 11, 'id', 'fissure sealant dilakukan jika diperlukan'
+  UNION ALL SELECT 11, 'en', 'fissure sealant when needed'
+  UNION ALL SELECT 12, 'id', 'pengawasan karies baru'
+  UNION ALL SELECT 12, 'en', 'monitor new caries'
+  UNION ALL SELECT 13, 'id', 'Orang tua mengingatkan agar kontrol ke dokter gigi setiap 4-6 bulan sekali'
+  UNION ALL SELECT 13, 'en', 'Parents remind the child to see a dentist every 4-6 months'
+  UNION ALL SELECT 14, 'id', 'Orang tua mengajarkan cara menyikat gigi yang benar'
+  UNION ALL SELECT 14, 'en', 'Parents teach the child how to brush their teeth properly'
+  UNION ALL SELECT 15, 'id', 'Orang tua mengingatkan agar menyikat gigi 2x sehari dengan pasta gigi ber fluoride'
+  UNION ALL SELECT 15, 'en', 'Parents remind the child to brush twice a day with fluoride toothpaste'
+  UNION ALL SELECT 16, 'id', 'Orang tua mengingatkan agar dilakukan perawatan topical aplikasi fluoride'
+  UNION ALL SELECT 16, 'en', 'Parents remind the child to have topical fluoride applied'
+  UNION ALL SELECT 17, 'id', 'Orang tua melakukan diet makanan manis dan lengket yang dikonsumsi sehari- hari'
+  UNION ALL SELECT 17, 'en', 'Parents limit the sweet and sticky food the child eats every day'
+  UNION ALL SELECT 18, 'id', 'Guru mengingatkan agar kontrol ke dokter gigi setiap 4-6 bulan sekali'
+  UNION ALL SELECT 18, 'en', 'Teachers remind the child to see a dentist every 4-6 months'
+  UNION ALL SELECT 19, 'id', 'Guru mengajarkan cara menyikat gigi yang benar'
+  UNION ALL SELECT 19, 'en', 'Teachers teach the child how to brush their teeth properly'
+  UNION ALL SELECT 20, 'id', 'Guru mengingatkan agar menyikat gigi 2x sehari dengan pasta gigi ber fluoride'
+  UNION ALL SELECT 20, 'en', 'Teachers remind the child to brush twice a day with fluoride toothpaste'
+  UNION ALL SELECT 21, 'id', 'Guru mengingatkan agar dilakukan perawatan topical aplikasi fluoride'
+  UNION ALL SELECT 21, 'en', 'Teachers remind the child to have topical fluoride applied'
+  UNION ALL SELECT 22, 'id', 'setiap 4-6 bulan'
+  UNION ALL SELECT 22, 'en', 'every 4-6 months'
+  UNION ALL SELECT 23, 'id', 'pasta gigi 2x sehari + Topikal aplikasi'
+  UNION ALL SELECT 23, 'en', 'toothpaste twice a day + topical application'
+  UNION ALL SELECT 24, 'id', 'diet dengan pengawasan'
+  UNION ALL SELECT 24, 'en', 'supervised diet'
+  UNION ALL SELECT 25, 'id', 'fissure sealant dilakukan jika diperlukan'
+  UNION ALL SELECT 25, 'en', 'fissure sealant when needed'
+  UNION ALL SELECT 26, 'id', 'pengawasan karies baru + restorasi dari kavitas baru'
+  UNION ALL SELECT 26, 'en', 'monitor new caries + restore new cavities'
+  UNION ALL SELECT 27, 'id', 'Orang tua mengingatkan agar kontrol ke dokter gigi setiap 3-4 bulan sekali'
+  UNION ALL SELECT 27, 'en', 'Parents remind the child to see a dentist every 3-4 months'
+  UNION ALL SELECT 28, 'id', 'Orang tua mengajarkan cara menyikat gigi yang benar'
+  UNION ALL SELECT 28, 'en', 'Parents teach the child how to brush their teeth properly'
+  UNION ALL SELECT 29, 'id', 'Orang tua mengingatkan agar menyikat gigi 2x sehari dengan pasta gigi ber fluoride'
+  UNION ALL SELECT 29, 'en', 'Parents remind the child to brush twice a day with fluoride toothpaste'
+  UNION ALL SELECT 30, 'id', 'Orang tua mengingatkan agar dilakukan perawatan topical aplikasi fluoride'
+  UNION ALL SELECT 30, 'en', 'Parents remind the child to have topical fluoride applied'
+  UNION ALL SELECT 31, 'id', 'Orang tua melakukan diet makanan manis dan lengket yang dikonsumsi sehari- hari'
+  UNION ALL SELECT 31, 'en', 'Parents limit the sweet and sticky food the child eats every day'
+  UNION ALL SELECT 32, 'id', 'Orang tua mengganti konsumsi permen yang manis dengan permen xylitol'
+  UNION ALL SELECT 32, 'en', 'Parents replace sweet candy with xylitol candy'
+  UNION ALL SELECT 33, 'id', 'Guru mengingatkan agar kontrol ke dokter gigi setiap 3-4 bulan sekali'
+  UNION ALL SELECT 33, 'en', 'Teachers remind the child to see a dentist every 3-4 months'
+  UNION ALL SELECT 34, 'id', 'Guru mengajarkan cara menyikat gigi yang benar'
+  UNION ALL SELECT 34, 'en', 'Teachers teach the child how to brush their teeth properly'
+  UNION ALL SELECT 35, 'id', 'Guru mengingatkan agar menyikat gigi 2x sehari dengan pasta gigi ber fluoride'
+  UNION ALL SELECT 35, 'en', 'Teachers remind the child to brush twice a day with fluoride toothpaste'
+  UNION ALL SELECT 36, 'id', 'Guru mengingatkan agar dilakukan perawatan topical aplikasi fluoride'
+  UNION ALL SELECT 36, 'en', 'Teachers remind the child to have topical fluoride applied'
+  UNION ALL SELECT 37, 'id', 'setiap 3-4 bulan'
+  UNION ALL SELECT 37, 'en', 'every 3-4 months'
+  UNION ALL SELECT 38, 'id', 'topikal aplikasi + pasta gigi 2x sehari'
+  UNION ALL SELECT 38, 'en', 'topical application + toothpaste twice a day'
+  UNION ALL SELECT 39, 'id', 'diet dengan pengawasan + xylitol'
+  UNION ALL SELECT 39, 'en', 'supervised diet + xylitol'
+  UNION ALL SELECT 40, 'id', 'direkomendasikan fissure sealant'
+  UNION ALL SELECT 40, 'en', 'fissure sealant recommended'
+  UNION ALL SELECT 41, 'id', 'pengawasan karies baru + restorasi dari kavitas baru'
+  UNION ALL SELECT 41, 'en', 'monitor new caries + restore new cavities'
+) t ON t.`position` = r.`position`
+WHERE r.`rule_set_id` = '8d4b2f1e-6a3c-4e57-b9d2-3f1a7c5e0b21';
+-- ----------------------------------------------------------------------------
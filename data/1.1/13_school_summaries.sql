@@ -0,0 +1,11 @@
+-- IDCRA API Migration File School Summaries
+-- Contents:
+-- - Student Sex
+-- ----------------------------------------------------------------------------
+
+-- Student Sex
+-- School summaries break tooth indices down by sex. Students recorded before
+-- the sex was asked for are counted as unknown.
+ALTER TABLE `students`
+  ADD COLUMN `sex` ENUM('MALE', 'FEMALE') NULL AFTER `date_of_birth`;
+-- ----------------------------------------------------------------------------
@@ -0,0 +1,58 @@
+-- IDCRA API Migration File Report Templates
+-- Contents:
+-- - Report Templates
+-- - Organizations
+-- - School Organizations and Report Templates
+-- ----------------------------------------------------------------------------
+
+-- Report Templates Table
+-- A template brands the survey reports of the schools it is attached to with
+-- a logo, colours and header and footer texts. Logos are kept in the photo
+-- storage under their key. Colours are #rrggbb.
+CREATE TABLE IF NOT EXISTS `report_templates` (
+  `id` CHAR(36) NOT NULL,
+  `name` VARCHAR(255) NOT NULL,
+  `title` VARCHAR(255),
+  `header_text` VARCHAR(1000),
+  `footer_text` VARCHAR(1000),
+  `primary_color` CHAR(7) NOT NULL DEFAULT '#000000',
+  `accent_color` CHAR(7) NOT NULL DEFAULT '#000000',
+  `logo_key` VARCHAR(255),
+  `logo_content_type` VARCHAR(45),
+  `created_at` TIMESTAMP NOT NULL DEFAULT NOW(),
+  `updated_at` TIMESTAMP NOT NULL DEFAULT NOW() ON UPDATE NOW(),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `report_templates_idx_1` (`name`)
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- Organizations Table
+-- Partner organizations run surveys at several schools. Their report template
+-- brands the reports of their schools that have no template of their own.
+CREATE TABLE IF NOT EXISTS `organizations` (
+  `id` CHAR(36) NOT NULL,
+  `name` VARCHAR(255) NOT NULL,
+  `report_template_id` CHAR(36),
+  `created_at` TIMESTAMP NOT NULL DEFAULT NOW(),
+  `updated_at` TIMESTAMP NOT NULL DEFAULT NOW() ON UPDATE NOW(),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `organizations_idx_1` (`name`),
+  CONSTRAINT `fk_organizations_report_templates` FOREIGN KEY (`report_template_id`)
+    REFERENCES `report_templates`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- School Organizations and Report Templates
+ALTER TABLE `schools`
+  ADD COLUMN `organization_id` CHAR(36) NULL AFTER `name`,
+  ADD COLUMN `report_template_id` CHAR(36) NULL AFTER `organization_id`,
+  ADD CONSTRAINT `fk_schools_organizations` FOREIGN KEY (`organization_id`)
+    REFERENCES `organizations`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION,
+  ADD CONSTRAINT `fk_schools_report_templates` FOREIGN KEY (`report_template_id`)
+    REFERENCES `report_templates`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION;
+-- ----------------------------------------------------------------------------
@@ -0,0 +1,25 @@
+-- IDCRA API Migration File Report Verifications
+-- Contents:
+-- - Report Verifications
+-- ----------------------------------------------------------------------------
+
+-- Report Verifications Table
+-- Every printed survey report carries a verification code, printed as text and
+-- as a QR code, under which the SHA-256 hash of the PDF is kept so the report
+-- can be checked for authenticity. The survey status is the status at the time
+-- the report was printed.
+CREATE TABLE IF NOT EXISTS `report_verifications` (
+  `code` CHAR(10) NOT NULL,
+  `survey_id` CHAR(36) NOT NULL,
+  `survey_status` VARCHAR(45) NOT NULL,
+  `language` VARCHAR(10) NOT NULL,
+  `sha256` CHAR(64) NOT NULL,
+  `generated_at` TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (`code`),
+  INDEX `report_verifications_idx_1` (`survey_id`, `generated_at`),
+  CONSTRAINT `fk_report_verifications_surveys` FOREIGN KEY (`survey_id`)
+    REFERENCES `surveys`(`id`)
+    ON DELETE CASCADE ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
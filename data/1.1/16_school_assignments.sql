@@ -0,0 +1,24 @@
+-- IDCRA API Migration File School Assignments
+-- Contents:
+-- - User <--> Schools Relationship
+-- ----------------------------------------------------------------------------
+
+-- User <--> Schools relationship
+-- Surveyors and teachers work with the schools they are assigned to, and only
+-- see the changes and events of those schools. Admins and supervisors work
+-- with every school.
+CREATE TABLE IF NOT EXISTS `rel_users_schools` (
+  `user_id` CHAR(36) NOT NULL,
+  `school_id` CHAR(36) NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (`user_id`, `school_id`),
+  INDEX `rel_users_schools_idx_1` (`school_id`),
+  CONSTRAINT `fk_rel_users_schools_users` FOREIGN KEY (`user_id`)
+    REFERENCES `users`(`id`)
+    ON DELETE CASCADE ON UPDATE NO ACTION,
+  CONSTRAINT `fk_rel_users_schools_schools` FOREIGN KEY (`school_id`)
+    REFERENCES `schools`(`id`)
+    ON DELETE CASCADE ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
@@ -0,0 +1,22 @@
+-- IDCRA API Migration File Survey Submission Scopes
+-- Contents:
+-- - Survey Submissions Surveyor and Payload Hash
+-- ----------------------------------------------------------------------------
+
+-- Survey Submissions Surveyor and Payload Hash
+-- Idempotency keys are generated by each client, so they are only unique per
+-- surveyor. The payload hash tells a retried upload from a different survey
+-- sent with a reused key; it is NULL for submissions stored before it.
+ALTER TABLE `survey_submissions`
+  ADD COLUMN `surveyor_id` CHAR(36) NULL FIRST,
+  ADD COLUMN `payload_hash` CHAR(64) NULL AFTER `survey_id`;
+
+UPDATE `survey_submissions` ss
+  JOIN `surveys` s ON s.`id` = ss.`survey_id`
+  SET ss.`surveyor_id` = s.`surveyor_id`;
+
+ALTER TABLE `survey_submissions`
+  MODIFY COLUMN `surveyor_id` CHAR(36) NOT NULL,
+  DROP PRIMARY KEY,
+  ADD PRIMARY KEY (`surveyor_id`, `idempotency_key`);
+-- ----------------------------------------------------------------------------
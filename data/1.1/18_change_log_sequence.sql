@@ -0,0 +1,36 @@
+-- IDCRA API Migration File Change Log Sequence
+-- Contents:
+-- - Change log sequence
+-- - Change log sequence trigger
+-- ----------------------------------------------------------------------------
+
+-- Change Log Sequence Table
+-- Auto increment values are allocated when a row is written, not when its
+-- transaction commits, so a change could become visible after one with a
+-- higher number and be skipped by a resumed feed. Sequence numbers are taken
+-- from this single row instead: its lock is held until the transaction
+-- recording the change commits, so numbers become visible in order.
+CREATE TABLE IF NOT EXISTS `change_log_sequence` (
+  `id` TINYINT UNSIGNED NOT NULL,
+  `seq` BIGINT UNSIGNED NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+
+INSERT INTO `change_log_sequence` (`id`, `seq`)
+SELECT 1, COALESCE(MAX(`seq`), 0) FROM `change_log`;
+
+ALTER TABLE `change_log`
+  MODIFY COLUMN `seq` BIGINT UNSIGNED NOT NULL;
+-- ----------------------------------------------------------------------------
+
+-- Change Log Sequence Trigger
+DELIMITER $$
+CREATE TRIGGER `change_log_before_insert` BEFORE INSERT ON `change_log`
+  FOR EACH ROW
+  BEGIN
+    UPDATE `change_log_sequence` SET `seq` = LAST_INSERT_ID(`seq` + 1) WHERE `id` = 1;
+    SET NEW.seq = LAST_INSERT_ID();
+  END$$
+DELIMITER ;
+-- ----------------------------------------------------------------------------
@@ -0,0 +1,16 @@
+-- IDCRA API Migration File Questionnaire Placeholder Texts
+-- Contents:
+-- - Legacy questionnaire placeholder texts
+-- ----------------------------------------------------------------------------
+
+-- Legacy Questionnaire Placeholder Texts
+-- Databases migrated before the legacy questionnaire stopped seeding question
+-- texts hold placeholders like "Section 1 question 1". They are removed so
+-- clients fall back to their own wording until the real texts are added.
+DELETE t FROM `questionnaire_texts` t
+JOIN `questionnaire_questions` q ON q.`id` = t.`owner_id`
+JOIN `questionnaire_sections` sec ON sec.`id` = q.`section_id`
+WHERE sec.`questionnaire_id` = '5f0f6a52-3c55-4c56-9d8e-2b7c1f0c9a11'
+  AND (t.`text` = CONCAT('Section ', sec.`position`, ' question ', q.`position`)
+    OR t.`text` = CONCAT('Bagian ', sec.`position`, ' pertanyaan ', q.`position`));
+-- ----------------------------------------------------------------------------
@@ -0,0 +1,18 @@
+-- IDCRA API Migration File Survey Submissions
+-- Contents:
+-- - Survey Submissions
+-- ----------------------------------------------------------------------------
+
+-- Survey Submissions Table
+CREATE TABLE IF NOT EXISTS `survey_submissions` (
+  `idempotency_key` VARCHAR(255) NOT NULL,
+  `survey_id` CHAR(36) NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (`idempotency_key`),
+  INDEX `survey_submissions_idx_1` (`survey_id`),
+  CONSTRAINT `fk_survey_submissions_surveys` FOREIGN KEY (`survey_id`)
+    REFERENCES `surveys`(`id`)
+    ON DELETE CASCADE ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
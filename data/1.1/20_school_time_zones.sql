@@ -0,0 +1,11 @@
+-- IDCRA API Migration File School Time Zones
+-- Contents:
+-- - Schools time zone
+-- ----------------------------------------------------------------------------
+
+-- Schools Time Zone
+-- The IANA time zone of a school, deciding the day recalls of its students
+-- become overdue. Schools without one use the configured default time zone.
+ALTER TABLE `schools`
+  ADD COLUMN `time_zone` VARCHAR(64) NULL AFTER `report_template_id`;
+-- ----------------------------------------------------------------------------
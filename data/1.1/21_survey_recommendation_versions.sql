@@ -0,0 +1,12 @@
+-- IDCRA API Migration File Survey Recommendation Versions
+-- Contents:
+-- - Surveys recommendation version
+-- ----------------------------------------------------------------------------
+
+-- Surveys Recommendation Version
+-- The version of the recommendation rules that were published when a survey
+-- was approved. Reports of the survey are printed with that version, so they
+-- keep their recommendations when newer rules are published.
+ALTER TABLE `surveys`
+  ADD COLUMN `recommendation_version` INT NULL AFTER `review_comment`;
+-- ----------------------------------------------------------------------------
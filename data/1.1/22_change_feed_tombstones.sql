@@ -0,0 +1,54 @@
+-- IDCRA API Migration File Change Feed Tombstones
+-- Contents:
+-- - Students moving to another school
+-- - Tombstones of dependent records
+-- ----------------------------------------------------------------------------
+
+-- Students Moving To Another School
+-- Clients only follow the schools they work with, so a student moved to
+-- another school is removed from the school it left.
+DROP TRIGGER IF EXISTS `students_after_update`;
+
+DELIMITER $$
+CREATE TRIGGER `students_after_update` AFTER UPDATE ON `students`
+  FOR EACH ROW
+  BEGIN
+    IF NOT (OLD.school_id <=> NEW.school_id) THEN
+      INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+      VALUES ('STUDENT', OLD.id, 'DELETED', OLD.school_id);
+    END IF;
+    INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+    VALUES ('STUDENT', NEW.id, 'UPDATED', NEW.school_id);
+  END$$
+DELIMITER ;
+-- ----------------------------------------------------------------------------
+
+-- Tombstones Of Dependent Records
+-- MySQL does not fire triggers for rows removed by a cascading foreign key, so
+-- the records of a deleted survey or student are logged as deleted before the
+-- parent goes. Records deleted on their own beforehand are already logged by
+-- their own triggers and are no longer found here.
+DELIMITER $$
+CREATE TRIGGER `surveys_before_delete` BEFORE DELETE ON `surveys`
+  FOR EACH ROW
+  BEGIN
+    INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+    SELECT 'CASE', c.`id`, 'DELETED', stu.`school_id`
+    FROM `cases` c JOIN `students` stu ON stu.`id` = OLD.student_id
+    WHERE c.`survey_id` = OLD.id;
+  END$$
+
+CREATE TRIGGER `students_before_delete` BEFORE DELETE ON `students`
+  FOR EACH ROW
+  BEGIN
+    INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+    SELECT 'CASE', c.`id`, 'DELETED', OLD.school_id
+    FROM `cases` c JOIN `surveys` sur ON sur.`id` = c.`survey_id`
+    WHERE sur.`student_id` = OLD.id;
+    INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+    SELECT 'SURVEY', sur.`id`, 'DELETED', OLD.school_id
+    FROM `surveys` sur
+    WHERE sur.`student_id` = OLD.id;
+  END$$
+DELIMITER ;
+-- ----------------------------------------------------------------------------
@@ -0,0 +1,147 @@
+-- IDCRA API Migration File Change Feed
+-- Contents:
+-- - Updated at tracking of the core tables
+-- - Change log
+-- - Change log data
+-- - Change log triggers
+-- ----------------------------------------------------------------------------
+
+-- Schools Updated At
+ALTER TABLE `schools`
+  ADD COLUMN `updated_at` TIMESTAMP NOT NULL DEFAULT NOW() ON UPDATE NOW() AFTER `created_at`,
+  ADD INDEX `schools_updated_at_idx` (`updated_at`);
+-- ----------------------------------------------------------------------------
+
+-- Students Updated At
+ALTER TABLE `students`
+  ADD COLUMN `updated_at` TIMESTAMP NOT NULL DEFAULT NOW() ON UPDATE NOW() AFTER `created_at`,
+  ADD INDEX `students_updated_at_idx` (`updated_at`);
+-- ----------------------------------------------------------------------------
+
+-- DiagnosisAndActions Updated At
+ALTER TABLE `diagnosis_and_actions`
+  ADD COLUMN `updated_at` TIMESTAMP NOT NULL DEFAULT NOW() ON UPDATE NOW() AFTER `created_at`,
+  ADD INDEX `diagnosis_and_actions_updated_at_idx` (`updated_at`);
+-- ----------------------------------------------------------------------------
+
+-- Surveys Updated At
+ALTER TABLE `surveys`
+  ADD COLUMN `updated_at` TIMESTAMP NOT NULL DEFAULT NOW() ON UPDATE NOW() AFTER `created_at`,
+  ADD INDEX `surveys_updated_at_idx` (`updated_at`);
+-- ----------------------------------------------------------------------------
+
+-- Cases Updated At
+ALTER TABLE `cases`
+  ADD COLUMN `updated_at` TIMESTAMP NOT NULL DEFAULT NOW() ON UPDATE NOW() AFTER `created_at`,
+  ADD INDEX `cases_updated_at_idx` (`updated_at`);
+-- ----------------------------------------------------------------------------
+
+-- Change Log Table
+-- Every insert, update and delete of the core tables is appended here by the
+-- triggers below. Deletes are kept as tombstones so that offline clients can
+-- remove their local copies.
+CREATE TABLE IF NOT EXISTS `change_log` (
+  `seq` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `entity_type` VARCHAR(45) NOT NULL,
+  `entity_id` CHAR(36) NOT NULL,
+  `operation` ENUM('CREATED', 'UPDATED', 'DELETED') NOT NULL,
+  `school_id` CHAR(36),
+  `changed_at` TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (`seq`),
+  INDEX `change_log_idx_1` (`entity_type`, `seq`),
+  INDEX `change_log_idx_2` (`school_id`, `seq`),
+  INDEX `change_log_idx_3` (`changed_at`)
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- Change Log Data
+-- Records already stored before the change log existed are logged as created.
+INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`, `changed_at`)
+SELECT 'DIAGNOSIS_AND_ACTION', `id`, 'CREATED', NULL, `created_at` FROM `diagnosis_and_actions` ORDER BY `created_at`;
+INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`, `changed_at`)
+SELECT 'SCHOOL', `id`, 'CREATED', `id`, `created_at` FROM `schools` ORDER BY `created_at`;
+INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`, `changed_at`)
+SELECT 'STUDENT', `id`, 'CREATED', `school_id`, `created_at` FROM `students` ORDER BY `created_at`;
+INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`, `changed_at`)
+SELECT 'SURVEY', sur.`id`, 'CREATED', stu.`school_id`, sur.`created_at`
+FROM `surveys` sur JOIN `students` stu ON stu.`id` = sur.`student_id` ORDER BY sur.`created_at`;
+INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`, `changed_at`)
+SELECT 'CASE', c.`id`, 'CREATED', stu.`school_id`, c.`created_at`
+FROM `cases` c JOIN `surveys` sur ON sur.`id` = c.`survey_id` JOIN `students` stu ON stu.`id` = sur.`student_id` ORDER BY c.`created_at`;
+-- ----------------------------------------------------------------------------
+
+-- Schools Change Log Triggers
+CREATE TRIGGER `schools_after_insert` AFTER INSERT ON `schools`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('SCHOOL', NEW.id, 'CREATED', NEW.id);
+
+CREATE TRIGGER `schools_after_update` AFTER UPDATE ON `schools`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('SCHOOL', NEW.id, 'UPDATED', NEW.id);
+
+CREATE TRIGGER `schools_after_delete` AFTER DELETE ON `schools`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('SCHOOL', OLD.id, 'DELETED', OLD.id);
+
+-- ----------------------------------------------------------------------------
+
+-- Students Change Log Triggers
+CREATE TRIGGER `students_after_insert` AFTER INSERT ON `students`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('STUDENT', NEW.id, 'CREATED', NEW.school_id);
+
+CREATE TRIGGER `students_after_update` AFTER UPDATE ON `students`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('STUDENT', NEW.id, 'UPDATED', NEW.school_id);
+
+CREATE TRIGGER `students_after_delete` AFTER DELETE ON `students`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('STUDENT', OLD.id, 'DELETED', OLD.school_id);
+
+-- ----------------------------------------------------------------------------
+
+-- DiagnosisAndActions Change Log Triggers
+CREATE TRIGGER `diagnosis_and_actions_after_insert` AFTER INSERT ON `diagnosis_and_actions`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('DIAGNOSIS_AND_ACTION', NEW.id, 'CREATED', NULL);
+
+CREATE TRIGGER `diagnosis_and_actions_after_update` AFTER UPDATE ON `diagnosis_and_actions`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('DIAGNOSIS_AND_ACTION', NEW.id, 'UPDATED', NULL);
+
+CREATE TRIGGER `diagnosis_and_actions_after_delete` AFTER DELETE ON `diagnosis_and_actions`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('DIAGNOSIS_AND_ACTION', OLD.id, 'DELETED', NULL);
+
+-- ----------------------------------------------------------------------------
+
+-- Surveys Change Log Triggers
+CREATE TRIGGER `surveys_after_insert` AFTER INSERT ON `surveys`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('SURVEY', NEW.id, 'CREATED', (SELECT `school_id` FROM `students` WHERE `id` = NEW.student_id));
+
+CREATE TRIGGER `surveys_after_update` AFTER UPDATE ON `surveys`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('SURVEY', NEW.id, 'UPDATED', (SELECT `school_id` FROM `students` WHERE `id` = NEW.student_id));
+
+CREATE TRIGGER `surveys_after_delete` AFTER DELETE ON `surveys`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('SURVEY', OLD.id, 'DELETED', (SELECT `school_id` FROM `students` WHERE `id` = OLD.student_id));
+
+-- ----------------------------------------------------------------------------
+
+-- Cases Change Log Triggers
+CREATE TRIGGER `cases_after_insert` AFTER INSERT ON `cases`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('CASE', NEW.id, 'CREATED', (SELECT stu.`school_id` FROM `surveys` sur JOIN `students` stu ON stu.`id` = sur.`student_id` WHERE sur.`id` = NEW.survey_id));
+
+CREATE TRIGGER `cases_after_update` AFTER UPDATE ON `cases`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('CASE', NEW.id, 'UPDATED', (SELECT stu.`school_id` FROM `surveys` sur JOIN `students` stu ON stu.`id` = sur.`student_id` WHERE sur.`id` = NEW.survey_id));
+
+CREATE TRIGGER `cases_after_delete` AFTER DELETE ON `cases`
+  FOR EACH ROW INSERT INTO `change_log` (`entity_type`, `entity_id`, `operation`, `school_id`)
+  VALUES ('CASE', OLD.id, 'DELETED', (SELECT stu.`school_id` FROM `surveys` sur JOIN `students` stu ON stu.`id` = sur.`student_id` WHERE sur.`id` = OLD.survey_id));
+
+-- ----------------------------------------------------------------------------
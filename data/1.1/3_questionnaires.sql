@@ -0,0 +1,225 @@
+-- IDCRA API Migration File Questionnaires
+-- Contents:
+-- - Questionnaires
+-- - Questionnaire Sections
+-- - Questionnaire Questions
+-- - Questionnaire Answer Options
+-- - Questionnaire Texts
+-- - Legacy questionnaire data
+-- - Survey Answers
+-- - Survey answers data
+-- ----------------------------------------------------------------------------
+
+-- Questionnaires Table
+-- A questionnaire is never changed once surveys have been recorded against it;
+-- changes are published as a new version with the same code. When score_min
+-- and score_max are not set, subjective scores are normalized against the
+-- lowest and highest possible weighted scores.
+CREATE TABLE IF NOT EXISTS `questionnaires` (
+  `id` CHAR(36) NOT NULL,
+  `code` VARCHAR(45) NOT NULL,
+  `version` INT NOT NULL,
+  `score_min` DECIMAL(12,4),
+  `score_max` DECIMAL(12,4),
+  `created_at` TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `questionnaires_idx_1` (`code`, `version`)
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- Questionnaire Sections Table
+CREATE TABLE IF NOT EXISTS `questionnaire_sections` (
+  `id` CHAR(36) NOT NULL,
+  `questionnaire_id` CHAR(36) NOT NULL,
+  `code` VARCHAR(45) NOT NULL,
+  `position` INT NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `questionnaire_sections_idx_1` (`questionnaire_id`, `code`),
+  CONSTRAINT `fk_questionnaire_sections_questionnaires` FOREIGN KEY (`questionnaire_id`)
+    REFERENCES `questionnaires`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- Questionnaire Questions Table
+CREATE TABLE IF NOT EXISTS `questionnaire_questions` (
+  `id` CHAR(36) NOT NULL,
+  `section_id` CHAR(36) NOT NULL,
+  `code` VARCHAR(45) NOT NULL,
+  `position` INT NOT NULL,
+  `required` TINYINT(1) NOT NULL DEFAULT 1,
+  `weight` DECIMAL(12,4) NOT NULL DEFAULT 1,
+  PRIMARY KEY (`id`),
+  INDEX `questionnaire_questions_idx_1` (`section_id`, `position`),
+  CONSTRAINT `fk_questionnaire_questions_sections` FOREIGN KEY (`section_id`)
+    REFERENCES `questionnaire_sections`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- Questionnaire Answer Options Table
+CREATE TABLE IF NOT EXISTS `questionnaire_answer_options` (
+  `id` CHAR(36) NOT NULL,
+  `question_id` CHAR(36) NOT NULL,
+  `value` VARCHAR(45) NOT NULL,
+  `position` INT NOT NULL,
+  `score` DECIMAL(12,4) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `questionnaire_answer_options_idx_1` (`question_id`, `value`),
+  CONSTRAINT `fk_questionnaire_answer_options_questions` FOREIGN KEY (`question_id`)
+    REFERENCES `questionnaire_questions`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- Questionnaire Texts Table
+-- Localized titles of sections, texts of questions and labels of answer
+-- options, keyed by the id of the section, question or answer option.
+CREATE TABLE IF NOT EXISTS `questionnaire_texts` (
+  `owner_id` CHAR(36) NOT NULL,
+  `locale` VARCHAR(10) NOT NULL,
+  `text` VARCHAR(1000) NOT NULL,
+  PRIMARY KEY (`owner_id`, `locale`)
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- Legacy Questionnaire Data
+-- The 16 risk questions surveys were recorded with before questionnaires were
+-- configurable. The score bounds keep the subjective scores of those surveys.
+-- The wording of the questions was kept by the clients, not the API, so no
+-- question texts are seeded; they are added to questionnaire_texts once the
+-- wording of the printed forms is confirmed.
+INSERT INTO `questionnaires` (`id`, `code`, `version`, `score_min`, `score_max`, `created_at`) VALUES
+('5f0f6a52-3c55-4c56-9d8e-2b7c1f0c9a11', 'caries-risk', 1, 16, 37, NOW());
+
+INSERT INTO `questionnaire_sections` (`id`, `questionnaire_id`, `code`, `position`) VALUES
+(UUID(), '5f0f6a52-3c55-4c56-9d8e-2b7c1f0c9a11', 's1', 1),
+(UUID(), '5f0f6a52-3c55-4c56-9d8e-2b7c1f0c9a11', 's2', 2);
+
+INSERT INTO `questionnaire_questions` (`id`, `section_id`, `code`, `position`, `required`, `weight`)
+SELECT UUID(), sec.`id`, q.`code`, q.`position`, 1, 1
+FROM `questionnaire_sections` sec
+JOIN (
+  SELECT 's1' AS `section_code`, 's1q1' AS `code`, 1 AS `position`
+  UNION ALL SELECT 's1', 's1q2', 2
+  UNION ALL SELECT 's1', 's1q3', 3
+  UNION ALL SELECT 's1', 's1q4', 4
+  UNION ALL SELECT 's1', 's1q5', 5
+  UNION ALL SELECT 's1', 's1q6', 6
+  UNION ALL SELECT 's1', 's1q7', 7
+  UNION ALL SELECT 's2', 's2q1', 1
+  UNION ALL SELECT 's2', 's2q2', 2
+  UNION ALL SELECT 's2', 's2q3', 3
+  UNION ALL SELECT 's2', 's2q4', 4
+  UNION ALL SELECT 's2', 's2q5', 5
+  UNION ALL SELECT 's2', 's2q6', 6
+  UNION ALL SELECT 's2', 's2q7', 7
+  UNION ALL SELECT 's2', 's2q8', 8
+  UNION ALL SELECT 's2', 's2q9', 9
+) q ON q.`section_code` = sec.`code`
+WHERE sec.`questionnaire_id` = '5f0f6a52-3c55-4c56-9d8e-2b7c1f0c9a11';
+
+INSERT INTO `questionnaire_answer_options` (`id`, `question_id`, `value`, `position`, `score`)
+SELECT UUID(), q.`id`, o.`value`, o.`position`, o.`score`
+FROM `questionnaire_questions` q
+JOIN `questionnaire_sections` sec ON sec.`id` = q.`section_id`
+JOIN (
+  SELECT 'Low' AS `value`, 1 AS `position`, 1 AS `score`
+  UNION ALL SELECT 'Medium', 2, 2
+  UNION ALL SELECT 'High', 3, 3
+) o
+WHERE sec.`questionnaire_id` = '5f0f6a52-3c55-4c56-9d8e-2b7c1f0c9a11';
+
+INSERT INTO `questionnaire_texts` (`owner_id`, `locale`, `text`)
+SELECT sec.`id`, 'en', CONCAT('Section ', sec.`position`) FROM `questionnaire_sections` sec
+WHERE sec.`questionnaire_id` = '5f0f6a52-3c55-4c56-9d8e-2b7c1f0c9a11'
+UNION ALL
+SELECT sec.`id`, 'id', CONCAT('Bagian ', sec.`position`) FROM `questionnaire_sections` sec
+WHERE sec.`questionnaire_id` = '5f0f6a52-3c55-4c56-9d8e-2b7c1f0c9a11'
+UNION ALL
+SELECT o.`id`, 'en', o.`value` FROM `questionnaire_answer_options` o
+JOIN `questionnaire_questions` q ON q.`id` = o.`question_id`
+JOIN `questionnaire_sections` sec ON sec.`id` = q.`section_id`
+WHERE sec.`questionnaire_id` = '5f0f6a52-3c55-4c56-9d8e-2b7c1f0c9a11'
+UNION ALL
+SELECT o.`id`, 'id', CASE o.`value` WHEN 'Low' THEN 'Rendah' WHEN 'Medium' THEN 'Sedang' ELSE 'Tinggi' END FROM `questionnaire_answer_options` o
+JOIN `questionnaire_questions` q ON q.`id` = o.`question_id`
+JOIN `questionnaire_sections` sec ON sec.`id` = q.`section_id`
+WHERE sec.`questionnaire_id` = '5f0f6a52-3c55-4c56-9d8e-2b7c1f0c9a11';
+-- ----------------------------------------------------------------------------
+
+-- Survey Answers Table
+CREATE TABLE IF NOT EXISTS `survey_answers` (
+  `survey_id` CHAR(36) NOT NULL,
+  `question_id` CHAR(36) NOT NULL,
+  `value` VARCHAR(45) NOT NULL,
+  `score` DECIMAL(12,4) NOT NULL,
+  PRIMARY KEY (`survey_id`, `question_id`),
+  CONSTRAINT `fk_survey_answers_surveys` FOREIGN KEY (`survey_id`)
+    REFERENCES `surveys`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT `fk_survey_answers_questions` FOREIGN KEY (`question_id`)
+    REFERENCES `questionnaire_questions`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- Survey Answers Data
+-- Surveys recorded before questionnaires were configurable answered the legacy
+-- questionnaire. Their answers are moved into survey_answers and the legacy
+-- answer columns are no longer written.
+ALTER TABLE `surveys`
+  ADD COLUMN `questionnaire_id` CHAR(36) AFTER `date`,
+  ADD CONSTRAINT `fk_surveys_questionnaires` FOREIGN KEY (`questionnaire_id`)
+    REFERENCES `questionnaires`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION,
+  MODIFY COLUMN `s1q1` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s1q2` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s1q3` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s1q4` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s1q5` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s1q6` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s1q7` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s2q1` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s2q2` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s2q3` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s2q4` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s2q5` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s2q6` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s2q7` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s2q8` ENUM('Low', 'Medium', 'High'),
+  MODIFY COLUMN `s2q9` ENUM('Low', 'Medium', 'High');
+
+UPDATE `surveys` SET `questionnaire_id` = '5f0f6a52-3c55-4c56-9d8e-2b7c1f0c9a11';
+
+INSERT INTO `survey_answers` (`survey_id`, `question_id`, `value`, `score`)
+SELECT s.`id`, q.`id`, o.`value`, q.`weight` * o.`score`
+FROM `surveys` s
+JOIN `questionnaire_sections` sec ON sec.`questionnaire_id` = s.`questionnaire_id`
+JOIN `questionnaire_questions` q ON q.`section_id` = sec.`id`
+JOIN `questionnaire_answer_options` o ON o.`question_id` = q.`id`
+  AND o.`value` = CASE q.`code`
+    WHEN 's1q1' THEN s.`s1q1`
+    WHEN 's1q2' THEN s.`s1q2`
+    WHEN 's1q3' THEN s.`s1q3`
+    WHEN 's1q4' THEN s.`s1q4`
+    WHEN 's1q5' THEN s.`s1q5`
+    WHEN 's1q6' THEN s.`s1q6`
+    WHEN 's1q7' THEN s.`s1q7`
+    WHEN 's2q1' THEN s.`s2q1`
+    WHEN 's2q2' THEN s.`s2q2`
+    WHEN 's2q3' THEN s.`s2q3`
+    WHEN 's2q4' THEN s.`s2q4`
+    WHEN 's2q5' THEN s.`s2q5`
+    WHEN 's2q6' THEN s.`s2q6`
+    WHEN 's2q7' THEN s.`s2q7`
+    WHEN 's2q8' THEN s.`s2q8`
+    WHEN 's2q9' THEN s.`s2q9`
+  END;
+-- ----------------------------------------------------------------------------
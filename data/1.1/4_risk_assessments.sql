@@ -0,0 +1,24 @@
+-- IDCRA API Migration File Risk Assessments
+-- Contents:
+-- - Survey Risk Assessments
+-- ----------------------------------------------------------------------------
+
+-- Survey Risk Assessments Table
+-- One assessment per survey and risk model. Assessments of surveys recorded
+-- before this table existed are filled in by running the server once with
+-- the -recompute-risk flag.
+CREATE TABLE IF NOT EXISTS `survey_risk_assessments` (
+  `survey_id` CHAR(36) NOT NULL,
+  `model` VARCHAR(45) NOT NULL,
+  `score` DECIMAL(12,4) NOT NULL,
+  `category` VARCHAR(20) NOT NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT NOW(),
+  `updated_at` TIMESTAMP NOT NULL DEFAULT NOW() ON UPDATE NOW(),
+  PRIMARY KEY (`survey_id`, `model`),
+  INDEX `survey_risk_assessments_idx_1` (`model`, `category`),
+  CONSTRAINT `fk_survey_risk_assessments_surveys` FOREIGN KEY (`survey_id`)
+    REFERENCES `surveys`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
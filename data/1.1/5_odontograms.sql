@@ -0,0 +1,34 @@
+-- IDCRA API Migration File Odontograms
+-- Contents:
+-- - Survey Teeth
+-- - Survey Tooth Surfaces
+-- ----------------------------------------------------------------------------
+
+-- Survey Teeth Table
+-- The charted status of a tooth, identified by its FDI code. Teeth that are
+-- not charted are taken to be sound.
+CREATE TABLE IF NOT EXISTS `survey_teeth` (
+  `survey_id` CHAR(36) NOT NULL,
+  `tooth_number` INT NOT NULL,
+  `status` VARCHAR(30) NOT NULL,
+  PRIMARY KEY (`survey_id`, `tooth_number`),
+  CONSTRAINT `fk_survey_teeth_surveys` FOREIGN KEY (`survey_id`)
+    REFERENCES `surveys`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
+
+-- Survey Tooth Surfaces Table
+CREATE TABLE IF NOT EXISTS `survey_tooth_surfaces` (
+  `survey_id` CHAR(36) NOT NULL,
+  `tooth_number` INT NOT NULL,
+  `surface` VARCHAR(20) NOT NULL,
+  `condition` VARCHAR(20) NOT NULL,
+  PRIMARY KEY (`survey_id`, `tooth_number`, `surface`),
+  CONSTRAINT `fk_survey_tooth_surfaces_survey_teeth` FOREIGN KEY (`survey_id`, `tooth_number`)
+    REFERENCES `survey_teeth`(`survey_id`, `tooth_number`)
+    ON DELETE CASCADE ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
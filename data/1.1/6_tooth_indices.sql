@@ -0,0 +1,75 @@
+-- IDCRA API Migration File Tooth Indices
+-- Contents:
+-- - Diagnosis and Action Index Components
+-- - Survey Computed Tooth Indices
+-- ----------------------------------------------------------------------------
+
+-- Diagnosis and Action Index Components
+-- The component of the DMF-T and def-t indices a diagnosis counts towards:
+-- decayed, missing (def-t: extracted or indicated for extraction) or filled.
+-- Diagnoses without a component, such as caries free, do not count.
+ALTER TABLE `diagnosis_and_actions`
+  ADD COLUMN `index_component` ENUM('DECAYED', 'MISSING', 'FILLED') NULL AFTER `unit_cost`;
+
+UPDATE `diagnosis_and_actions` SET `index_component` = 'DECAYED' WHERE `id` = '469d07e4-d71a-4dcb-9932-c805aefff48f';
+
+INSERT INTO `diagnosis_and_actions` (`id`, `diagnosis`, `action`, `unit_cost`, `index_component`, `created_at`) VALUES
+('7c1e2d4a-0b8f-4a56-9e3d-61f2a7c5b901', 'Karies Dentin', 'Rujuk', 0, 'DECAYED', NOW()),
+('a93f5b10-2c6e-4d7a-8f41-0e5b9c3d7a22', 'Indikasi Pencabutan', 'Rujuk', 0, 'MISSING', NOW()),
+('d2b8e6f4-5a1c-4e93-b7d0-3c9a8f1e6b43', 'Gigi Hilang Karena Karies', 'Tidak Ada', 0, 'MISSING', NOW()),
+('f0c4a7e2-9d3b-4b18-a6e5-8d2f1c7b5e64', 'Tumpatan', 'Tidak Ada', 0, 'FILLED', NOW());
+-- ----------------------------------------------------------------------------
+
+-- Survey Computed Tooth Indices
+-- The def-t (lower) and DMF-T (upper) counts derived from the cases of a
+-- survey, and whether they differ from the counts entered by the surveyor.
+ALTER TABLE `surveys`
+  ADD COLUMN `computed_lower_d` INT NOT NULL DEFAULT 0 AFTER `upper_f`,
+  ADD COLUMN `computed_lower_e` INT NOT NULL DEFAULT 0 AFTER `computed_lower_d`,
+  ADD COLUMN `computed_lower_f` INT NOT NULL DEFAULT 0 AFTER `computed_lower_e`,
+  ADD COLUMN `computed_upper_d` INT NOT NULL DEFAULT 0 AFTER `computed_lower_f`,
+  ADD COLUMN `computed_upper_m` INT NOT NULL DEFAULT 0 AFTER `computed_upper_d`,
+  ADD COLUMN `computed_upper_f` INT NOT NULL DEFAULT 0 AFTER `computed_upper_m`,
+  ADD COLUMN `tooth_index_mismatch` BOOLEAN NOT NULL DEFAULT FALSE AFTER `computed_upper_f`,
+  ADD INDEX `surveys_idx_tooth_index_mismatch` (`tooth_index_mismatch`);
+
+-- Every tooth counts once, as decayed before missing before filled.
+UPDATE `surveys` s
+  JOIN (
+    SELECT
+      t.survey_id,
+      SUM(t.tooth_number > 50 AND t.component = 'DECAYED') lower_d,
+      SUM(t.tooth_number > 50 AND t.component = 'MISSING') lower_e,
+      SUM(t.tooth_number > 50 AND t.component = 'FILLED') lower_f,
+      SUM(t.tooth_number < 50 AND t.component = 'DECAYED') upper_d,
+      SUM(t.tooth_number < 50 AND t.component = 'MISSING') upper_m,
+      SUM(t.tooth_number < 50 AND t.component = 'FILLED') upper_f
+    FROM (
+      SELECT
+        c.survey_id,
+        c.tooth_number,
+        CASE
+          WHEN SUM(d.index_component = 'DECAYED') > 0 THEN 'DECAYED'
+          WHEN SUM(d.index_component = 'MISSING') > 0 THEN 'MISSING'
+          WHEN SUM(d.index_component = 'FILLED') > 0 THEN 'FILLED'
+        END component
+      FROM `cases` c
+      JOIN `diagnosis_and_actions` d ON d.id = c.diagnosis_and_action_id
+      GROUP BY c.survey_id, c.tooth_number
+    ) t
+    GROUP BY t.survey_id
+  ) computed ON computed.survey_id = s.id
+SET
+  s.computed_lower_d = computed.lower_d,
+  s.computed_lower_e = computed.lower_e,
+  s.computed_lower_f = computed.lower_f,
+  s.computed_upper_d = computed.upper_d,
+  s.computed_upper_m = computed.upper_m,
+  s.computed_upper_f = computed.upper_f;
+
+UPDATE `surveys`
+SET `tooth_index_mismatch` = (
+  `lower_d` <> `computed_lower_d` OR `lower_e` <> `computed_lower_e` OR `lower_f` <> `computed_lower_f` OR
+  `upper_d` <> `computed_upper_d` OR `upper_m` <> `computed_upper_m` OR `upper_f` <> `computed_upper_f`
+);
+-- ----------------------------------------------------------------------------
@@ -0,0 +1,54 @@
+-- IDCRA API Migration File Recalls
+-- Contents:
+-- - Recalls
+-- ----------------------------------------------------------------------------
+
+-- Recalls Table
+-- The next visit due for a student after a survey, 3, 4 or 6 months after it
+-- for high, medium and low caries risk. A recall is open while it is DUE or
+-- SCHEDULED and becomes DONE when the student is surveyed again.
+CREATE TABLE IF NOT EXISTS `recalls` (
+  `id` CHAR(36) NOT NULL,
+  `student_id` CHAR(36) NOT NULL,
+  `survey_id` CHAR(36) NOT NULL,
+  `survey_date` DATE NOT NULL,
+  `risk_category` VARCHAR(20) NOT NULL,
+  `due_date` DATE NOT NULL,
+  `status` ENUM('DUE', 'SCHEDULED', 'DONE', 'SKIPPED') NOT NULL DEFAULT 'DUE',
+  `scheduled_date` DATE NULL,
+  `note` VARCHAR(255) NULL,
+  `completed_survey_id` CHAR(36) NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT NOW(),
+  `updated_at` TIMESTAMP NOT NULL DEFAULT NOW() ON UPDATE NOW(),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `recalls_idx_1` (`survey_id`),
+  INDEX `recalls_idx_2` (`student_id`, `status`),
+  INDEX `recalls_idx_3` (`status`, `due_date`),
+  CONSTRAINT `fk_recalls_students` FOREIGN KEY (`student_id`)
+    REFERENCES `students`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT `fk_recalls_surveys` FOREIGN KEY (`survey_id`)
+    REFERENCES `surveys`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+
+-- Schedule the recall of the latest survey of every student from its
+-- subjective score, as the IDCRA risk model does.
+INSERT INTO `recalls` (`id`, `student_id`, `survey_id`, `survey_date`, `risk_category`, `due_date`, `status`, `created_at`)
+SELECT
+  UUID(),
+  s.student_id,
+  s.id,
+  s.date,
+  CASE WHEN s.subjective_score > 66 THEN 'high' WHEN s.subjective_score > 33 THEN 'medium' ELSE 'low' END,
+  DATE_ADD(s.date, INTERVAL CASE WHEN s.subjective_score > 66 THEN 3 WHEN s.subjective_score > 33 THEN 4 ELSE 6 END MONTH),
+  'DUE',
+  NOW()
+FROM `surveys` s
+WHERE NOT EXISTS (
+  SELECT 1 FROM `surveys` later
+  WHERE later.student_id = s.student_id
+    AND (later.date > s.date OR (later.date = s.date AND later.created_at > s.created_at))
+);
+-- ----------------------------------------------------------------------------
@@ -0,0 +1,45 @@
+-- IDCRA API Migration File Treatments
+-- Contents:
+-- - Case Treatment Status
+-- - Case Treatments
+-- ----------------------------------------------------------------------------
+
+-- Case Treatment Status
+-- The state of the action of the diagnosis of a case. Every case starts out
+-- PLANNED, becomes PERFORMED once an operator carries out the action, FAILED
+-- when the treatment is found to have failed, and REDONE when it is carried
+-- out again. The operator, date and notes are those of the latest change.
+ALTER TABLE `cases`
+  ADD COLUMN `treatment_status` ENUM('PLANNED', 'PERFORMED', 'FAILED', 'REDONE') NOT NULL DEFAULT 'PLANNED' AFTER `diagnosis_and_action_id`,
+  ADD COLUMN `performed_by` CHAR(36) NULL AFTER `treatment_status`,
+  ADD COLUMN `performed_date` DATE NULL AFTER `performed_by`,
+  ADD COLUMN `treatment_notes` VARCHAR(255) NULL AFTER `performed_date`,
+  ADD INDEX `cases_idx_treatment_status` (`treatment_status`),
+  ADD CONSTRAINT `fk_cases_users` FOREIGN KEY (`performed_by`)
+    REFERENCES `users`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION;
+-- ----------------------------------------------------------------------------
+
+-- Case Treatments Table
+-- Every change of the treatment status of a case, oldest first. The cost
+-- report counts every PERFORMED and REDONE row as one performed action.
+CREATE TABLE IF NOT EXISTS `case_treatments` (
+  `id` CHAR(36) NOT NULL,
+  `case_id` CHAR(36) NOT NULL,
+  `status` ENUM('PLANNED', 'PERFORMED', 'FAILED', 'REDONE') NOT NULL,
+  `performed_by` CHAR(36) NULL,
+  `performed_date` DATE NULL,
+  `notes` VARCHAR(255) NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (`id`),
+  INDEX `case_treatments_idx_1` (`case_id`, `created_at`),
+  INDEX `case_treatments_idx_2` (`status`, `performed_date`),
+  CONSTRAINT `fk_case_treatments_cases` FOREIGN KEY (`case_id`)
+    REFERENCES `cases`(`id`)
+    ON DELETE CASCADE ON UPDATE NO ACTION,
+  CONSTRAINT `fk_case_treatments_users` FOREIGN KEY (`performed_by`)
+    REFERENCES `users`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
@@ -0,0 +1,37 @@
+-- IDCRA API Migration File Photos
+-- Contents:
+-- - Survey Photos
+-- ----------------------------------------------------------------------------
+
+-- Survey Photos Table
+-- Clinical photos attached to a survey, or to one of its cases. The photo and
+-- its JPEG thumbnail are kept in the configured storage under their keys.
+CREATE TABLE IF NOT EXISTS `survey_photos` (
+  `id` CHAR(36) NOT NULL,
+  `survey_id` CHAR(36) NOT NULL,
+  `case_id` CHAR(36) NULL,
+  `content_type` VARCHAR(50) NOT NULL,
+  `size` BIGINT NOT NULL,
+  `width` INT NOT NULL,
+  `height` INT NOT NULL,
+  `storage_key` VARCHAR(255) NOT NULL,
+  `thumbnail_key` VARCHAR(255) NOT NULL,
+  `caption` VARCHAR(255) NULL,
+  `include_in_report` BOOLEAN NOT NULL DEFAULT FALSE,
+  `uploaded_by` CHAR(36) NULL,
+  `created_at` TIMESTAMP NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (`id`),
+  INDEX `survey_photos_idx_1` (`survey_id`, `created_at`),
+  INDEX `survey_photos_idx_2` (`case_id`),
+  CONSTRAINT `fk_survey_photos_surveys` FOREIGN KEY (`survey_id`)
+    REFERENCES `surveys`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION,
+  CONSTRAINT `fk_survey_photos_cases` FOREIGN KEY (`case_id`)
+    REFERENCES `cases`(`id`)
+    ON DELETE SET NULL ON UPDATE NO ACTION,
+  CONSTRAINT `fk_survey_photos_users` FOREIGN KEY (`uploaded_by`)
+    REFERENCES `users`(`id`)
+    ON DELETE NO ACTION ON UPDATE NO ACTION
+) ENGINE=InnoDB
+  DEFAULT CHARSET=utf8;
+-- ----------------------------------------------------------------------------
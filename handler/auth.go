@@ -13,6 +13,7 @@ import (
 	gcontext "github.com/kerti/idcra-api/context"
 	"github.com/kerti/idcra-api/model"
 	"github.com/kerti/idcra-api/service"
+	"github.com/op/go-logging"
 	"golang.org/x/net/context"
 )
 
@@ -23,12 +24,10 @@ func Authenticate(h http.Handler) http.Handler {
 			userId       string
 		)
 		ctx := r.Context()
-		token, err := validateBearerAuthHeader(ctx, r)
+		tokenString, err := bearerToken(r)
 		if err == nil {
-			isAuthorized = true
-			if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-				userIdByte, _ := base64.StdEncoding.DecodeString(claims["id"].(string))
-				userId = string(userIdByte[:])
+			if userId, err = userIDFromToken(ctx, tokenString); err == nil {
+				isAuthorized = true
 			} else {
 				log.Println(err)
 			}
@@ -124,12 +123,41 @@ func Login() http.Handler {
 
 func writeResponse(w http.ResponseWriter, response interface{}, code int) {
 	jsonResponse, _ := json.Marshal(response)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(code)
 	if _, err := w.Write(jsonResponse); err != nil {
 		log.Println(err)
 	}
 }
 
+// writeError writes a catalog error with the matching HTTP status code. Details
+// of internal errors are logged and not sent to the client.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := model.AsError(err)
+
+	code := http.StatusInternalServerError
+	switch appErr.Code {
+	case model.ErrorCodeNotFound:
+		code = http.StatusNotFound
+	case model.ErrorCodeValidationFailed:
+		code = http.StatusBadRequest
+	case model.ErrorCodeUnauthenticated:
+		code = http.StatusUnauthorized
+	case model.ErrorCodeForbidden:
+		code = http.StatusForbidden
+	case model.ErrorCodeConflict:
+		code = http.StatusConflict
+	case model.ErrorCodeInternal:
+		r.Context().Value("log").(*logging.Logger).Errorf("Internal error at %s : %v", r.URL.Path, appErr.Cause)
+	}
+
+	response := &model.Response{
+		Code:  code,
+		Error: appErr.Message,
+	}
+	writeResponse(w, response, response.Code)
+}
+
 func validateBasicAuthHeader(r *http.Request) (*model.UserCredentials, error) {
 	auth := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
 	if len(auth) != 2 || auth[0] != "Basic" {
@@ -147,18 +175,34 @@ func validateBasicAuthHeader(r *http.Request) (*model.UserCredentials, error) {
 	return &userCredentials, nil
 }
 
-func validateBearerAuthHeader(ctx context.Context, r *http.Request) (*jwt.Token, error) {
-	var tokenString string
-	keys, ok := r.URL.Query()["at"]
-	if !ok || len(keys) < 1 {
-		auth := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
-		if len(auth) != 2 || auth[0] != "Bearer" {
-			return nil, errors.New(gcontext.CredentialsError)
-		}
-		tokenString = auth[1]
-	} else {
-		tokenString = keys[0]
+// bearerToken returns the token of a request, from the "at" query parameter
+// or the bearer authorization header
+func bearerToken(r *http.Request) (string, error) {
+	if keys, ok := r.URL.Query()["at"]; ok && len(keys) > 0 {
+		return keys[0], nil
 	}
+	auth := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(auth) != 2 || auth[0] != "Bearer" {
+		return "", errors.New(gcontext.CredentialsError)
+	}
+	return auth[1], nil
+}
+
+// userIDFromToken validates a token and returns the ID of the user it was
+// issued to
+func userIDFromToken(ctx context.Context, tokenString string) (string, error) {
 	token, err := ctx.Value("authService").(*service.AuthService).ValidateJWT(&tokenString)
-	return token, err
+	if err != nil {
+		return "", err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return "", errors.New(gcontext.TokenError)
+	}
+	id, _ := claims["id"].(string)
+	userID, err := base64.StdEncoding.DecodeString(id)
+	if err != nil || len(userID) == 0 {
+		return "", errors.New(gcontext.TokenError)
+	}
+	return string(userID), nil
 }
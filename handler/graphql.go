@@ -3,52 +3,338 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/graph-gophers/graphql-go"
+	qerrors "github.com/graph-gophers/graphql-go/errors"
+	gcontext "github.com/kerti/idcra-api/context"
 	"github.com/kerti/idcra-api/loader"
+	"github.com/kerti/idcra-api/model"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
 )
 
 type GraphQL struct {
 	Schema  *graphql.Schema
 	Loaders loader.LoaderCollection
+	// MaxBatchSize is the largest number of operations accepted in a batch
+	MaxBatchSize int
+}
+
+type graphqlParams struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type graphqlError struct {
+	Message    string                 `json:"message"`
+	Locations  []qerrors.Location     `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []*graphqlError `json:"errors,omitempty"`
 }
 
 func (h *GraphQL) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	var params struct {
-		Query         string                 `json:"query"`
-		OperationName string                 `json:"operationName"`
-		Variables     map[string]interface{} `json:"variables"`
+	var (
+		batch   []graphqlParams
+		isBatch bool
+		err     error
+	)
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.WriteHeader(http.StatusOK)
+		return
+	case http.MethodGet:
+		params, err := paramsFromURL(r)
+		if err != nil {
+			writeBadRequest(w, err)
+			return
+		}
+		if operationType(params.Query, params.OperationName) != "query" {
+			writeBadRequest(w, errors.New(gcontext.QueryOverGetOnly))
+			return
+		}
+		batch = []graphqlParams{*params}
+	case http.MethodPost:
+		batch, isBatch, err = paramsFromBody(r)
+		if err != nil {
+			writeBadRequest(w, err)
+			return
+		}
+	default:
+		response := &model.Response{
+			Code:  http.StatusMethodNotAllowed,
+			Error: gcontext.GetOrPostMethodSupported,
+		}
+		writeResponse(w, response, response.Code)
+		return
+	}
+
+	if h.MaxBatchSize > 0 && len(batch) > h.MaxBatchSize {
+		writeBadRequest(w, fmt.Errorf(gcontext.BatchTooLarge, h.MaxBatchSize))
+		return
+	}
+
+	for _, params := range batch {
+		if strings.TrimSpace(params.Query) == "" {
+			writeBadRequest(w, errors.New(gcontext.MissingQuery))
+			return
+		}
 	}
+
+	// All operations of a batch share one set of loaders so that the dataloaders
+	// can batch and cache across operations.
+	ctx := h.Loaders.Attach(r.Context())
+	responses := h.execBatch(ctx, batch)
+
 	var responseJSON []byte
+	if isBatch {
+		responseJSON, err = json.Marshal(responses)
+	} else {
+		responseJSON, err = json.Marshal(responses[0])
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if _, err := w.Write(responseJSON); err != nil {
+		log.Println(err)
+	}
+}
+
+// execBatch runs the operations of a batch one after another in the order
+// they were sent, so mutations see the changes of the operations before them.
+func (h *GraphQL) execBatch(ctx context.Context, batch []graphqlParams) []*graphqlResponse {
+	responses := make([]*graphqlResponse, len(batch))
+	for i, params := range batch {
+		responses[i] = formatResponse(ctx, h.Schema.Exec(ctx, params.Query, params.OperationName, params.Variables))
+	}
+	return responses
+}
+
+// formatResponse exposes the code and field details of catalog errors as
+// GraphQL error extensions. Resolver errors outside the catalog and panics are
+// logged and reported as internal errors without their details.
+func formatResponse(ctx context.Context, response *graphql.Response) *graphqlResponse {
+	formatted := &graphqlResponse{Data: response.Data}
+
+	for _, queryErr := range response.Errors {
+		formattedErr := &graphqlError{
+			Message:   queryErr.Message,
+			Locations: queryErr.Locations,
+			Path:      queryErr.Path,
+		}
+
+		var appErr *model.Error
+		if queryErr.ResolverError != nil {
+			appErr = model.AsError(queryErr.ResolverError)
+		} else if strings.HasPrefix(queryErr.Message, "graphql: panic occurred") {
+			appErr = model.NewInternalError(queryErr)
+		}
+
+		if appErr != nil {
+			if appErr.Code == model.ErrorCodeInternal {
+				ctx.Value("log").(*logging.Logger).Errorf("Internal error at %v : %v", queryErr.Path, appErr.Cause)
+			}
+			formattedErr.Message = appErr.Message
+			formattedErr.Extensions = appErr.Extensions()
+		}
 
-	// check for empty body
+		formatted.Errors = append(formatted.Errors, formattedErr)
+	}
+
+	return formatted
+}
+
+func paramsFromURL(r *http.Request) (*graphqlParams, error) {
+	values := r.URL.Query()
+	params := &graphqlParams{
+		Query:         values.Get("query"),
+		OperationName: values.Get("operationName"),
+	}
+
+	if variables := values.Get("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &params.Variables); err != nil {
+			return nil, errors.New(gcontext.MalformedVariables)
+		}
+	}
+
+	return params, nil
+}
+
+func paramsFromBody(r *http.Request) (batch []graphqlParams, isBatch bool, err error) {
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		return nil, false, err
 	}
 
-	r.Body = ioutil.NopCloser(bytes.NewBuffer(body))
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, false, errors.New(gcontext.EmptyRequestBody)
+	}
 
-	if len(body) > 0 {
-		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/graphql") {
+		return []graphqlParams{{Query: string(body)}}, false, nil
+	}
+
+	if body[0] == '[' {
+		if err = json.Unmarshal(body, &batch); err != nil {
+			return nil, false, errors.New(gcontext.MalformedRequestBody)
+		}
+		if len(batch) == 0 {
+			return nil, false, errors.New(gcontext.EmptyBatch)
 		}
-		ctx := h.Loaders.Attach(r.Context())
+		return batch, true, nil
+	}
 
-		response := h.Schema.Exec(ctx, params.Query, params.OperationName, params.Variables)
-		responseJSON, err = json.Marshal(response)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+	var params graphqlParams
+	if err = json.Unmarshal(body, &params); err != nil {
+		return nil, false, errors.New(gcontext.MalformedRequestBody)
+	}
+
+	return []graphqlParams{params}, false, nil
+}
+
+func writeBadRequest(w http.ResponseWriter, err error) {
+	response := &model.Response{
+		Code:  http.StatusBadRequest,
+		Error: err.Error(),
+	}
+	writeResponse(w, response, response.Code)
+}
+
+// operationType returns the type ("query", "mutation" or "subscription") of the
+// operation that would be executed for the given document and operation name.
+func operationType(query string, operationName string) string {
+	kind, _ := findOperation(query, operationName)
+	return kind
+}
+
+// findOperation locates the keyword of the operation that would be executed
+// for the given document and operation name, returning its type and byte
+// offset. The shorthand query has no keyword and is reported at offset -1.
+// It only looks at top level definitions, so it does not need a full parser.
+func findOperation(query string, operationName string) (kind string, pos int) {
+	var (
+		depth   int
+		parens  int
+		keyword string
+	)
+
+	tokens := tokenize(query)
+	for i, t := range tokens {
+		switch t.text {
+		case "(":
+			parens++
+		case ")":
+			parens--
+		case "{":
+			if depth == 0 && parens == 0 {
+				// a selection set without a keyword is the query shorthand
+				if keyword == "" && operationName == "" {
+					return "query", -1
+				}
+				keyword = ""
+			}
+			depth++
+		case "}":
+			depth--
+		case "query", "mutation", "subscription", "fragment":
+			// skip nested selections and the name of a fragment
+			if depth != 0 || parens != 0 || i > 0 && tokens[i-1].text == "fragment" {
+				continue
+			}
+			keyword = t.text
+			name := ""
+			if i+1 < len(tokens) && isName(tokens[i+1].text) {
+				name = tokens[i+1].text
+			}
+			if t.text != "fragment" && (operationName == "" || operationName == name) {
+				return t.text, t.pos
+			}
 		}
 	}
 
-	if _, err := w.Write(responseJSON); err != nil {
-		log.Println(err)
+	return "", -1
+}
+
+type token struct {
+	text string
+	pos  int
+}
+
+// tokenize splits a GraphQL document into names and punctuators, dropping
+// comments, string literals and block strings.
+func tokenize(query string) []token {
+	var (
+		tokens []token
+		start  = -1
+	)
+
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, token{text: query[start:end], pos: start})
+			start = -1
+		}
+	}
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '#':
+			flush(i)
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], `"""`):
+			flush(i)
+			// block strings end at the next """ that is not escaped as \"""
+			for i += 3; i < len(query) && !strings.HasPrefix(query[i:], `"""`); i++ {
+				if strings.HasPrefix(query[i:], `\"""`) {
+					i += 3
+				}
+			}
+			i += 2
+		case c == '"':
+			flush(i)
+			for i++; i < len(query) && query[i] != '"'; i++ {
+				if query[i] == '\\' {
+					i++
+				}
+			}
+		case c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			if start < 0 {
+				start = i
+			}
+		case c == '{' || c == '}' || c == '(' || c == ')':
+			flush(i)
+			tokens = append(tokens, token{text: string(c), pos: i})
+		default:
+			flush(i)
+		}
+	}
+	flush(len(query))
+
+	return tokens
+}
+
+func isName(token string) bool {
+	switch token {
+	case "{", "}", "(", ")":
+		return false
 	}
+	return true
 }
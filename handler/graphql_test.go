@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOperationType(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		operationName string
+		want          string
+	}{
+		{"Shorthand", `{ schools { totalCount } }`, "", "query"},
+		{"Mutation", `mutation { createSchool(name: "a") { id } }`, "", "mutation"},
+		{"NamedOperation", `query A { a } mutation B { b }`, "B", "mutation"},
+		{"UnknownOperation", `query A { a }`, "B", ""},
+		{"Comment", "# mutation { b }\nquery { a }", "", "query"},
+		{"KeywordInString", `query { a(note: "mutation { b }") }`, "", "query"},
+		{"EscapedQuote", `query { a(note: "say \"mutation\"") }`, "", "query"},
+		{"BlockString", `query { a(note: """mutation { b } "quoted" """) }`, "", "query"},
+		{"EscapedBlockString", `query { a(note: """ \""" mutation { b } """) } mutation M { c }`, "M", "mutation"},
+		{"MultilineBlockString", "mutation {\n  a(note: \"\"\"\n  query { b }\n  \"\"\")\n}", "", "mutation"},
+		{"FragmentFirst", `fragment F on School { id } mutation { createSchool(name: "a") { ...F } }`, "", "mutation"},
+		{"FragmentNamedLikeOperation", `fragment mutation on School { id } query { school(id: "a") { ...mutation } }`, "", "query"},
+		{"VariablesWithDefaults", `query Q($first: Int = 1) { schools(first: $first) { totalCount } }`, "Q", "query"},
+		{"Subscription", `subscription { reportReady(jobID: "a") { id } }`, "", "subscription"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, operationType(test.query, test.operationName))
+		})
+	}
+}
+
+func TestFindOperationPosition(t *testing.T) {
+	query := `# comment
+subscription S { reportReady(jobID: "a") { id } }`
+	kind, pos := findOperation(query, "S")
+	assert.Equal(t, "subscription", kind)
+	assert.Equal(t, "subscription", query[pos:pos+len(kind)])
+
+	_, pos = findOperation(`{ a }`, "")
+	assert.Equal(t, -1, pos)
+}
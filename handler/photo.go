@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+)
+
+// photoFormMemory is the part of a multipart upload kept in memory, the rest
+// is buffered in temporary files
+const photoFormMemory = 1 << 20
+
+// UploadPhoto attaches a photo sent as the "photo" part of a multipart form to
+// the survey in the "surveyId" field, and to the case in the optional "caseId"
+// field. It responds with the ID of the photo, which clients query through
+// GraphQL like the rest of the survey.
+func UploadPhoto() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if r.Method != http.MethodPost {
+			response := &model.Response{
+				Code:  http.StatusMethodNotAllowed,
+				Error: gcontext.PostMethodSupported,
+			}
+			writeResponse(w, response, response.Code)
+			return
+		}
+
+		if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+			writeError(w, r, model.NewUnauthenticatedError(gcontext.CredentialsError))
+			return
+		}
+
+		photoService := ctx.Value("photoService").(*service.PhotoService)
+		maxSize := photoService.MaxSize()
+
+		// leave room for the other fields of the form so an oversized photo
+		// fails validation instead of parsing
+		r.Body = http.MaxBytesReader(w, r.Body, maxSize+photoFormMemory)
+		if err := r.ParseMultipartForm(photoFormMemory); err != nil {
+			writeError(w, r, model.NewFieldError("photo", "invalid multipart form or photo too large"))
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		surveyID := r.FormValue("surveyId")
+		if surveyID != "" {
+			if err := authorizeSurvey(r, surveyID); err != nil {
+				writeError(w, r, err)
+				return
+			}
+		}
+
+		file, _, err := r.FormFile("photo")
+		if err != nil {
+			writeError(w, r, model.NewFieldError("photo", "photo is required"))
+			return
+		}
+		defer file.Close()
+
+		data, err := ioutil.ReadAll(io.LimitReader(file, maxSize+1))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		upload := &model.PhotoUpload{
+			SurveyID:   surveyID,
+			UploadedBy: ctx.Value("user_id").(*string),
+		}
+		if caseID := r.FormValue("caseId"); caseID != "" {
+			upload.CaseID = &caseID
+		}
+		if caption := r.FormValue("caption"); caption != "" {
+			upload.Caption = &caption
+		}
+		if includeInReport := r.FormValue("includeInReport"); includeInReport != "" {
+			upload.IncludeInReport, err = strconv.ParseBool(includeInReport)
+			if err != nil {
+				writeError(w, r, model.NewFieldError("includeInReport", "invalid value for includeInReport, expecting true or false"))
+				return
+			}
+		}
+
+		photo, err := photoService.Upload(upload, data)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		response := &model.ResponseSuccess{
+			Code:    http.StatusCreated,
+			Message: photo.ID,
+		}
+		writeResponse(w, response, response.Code)
+	})
+}
+
+// Photo serves the photo at /photos/{id}, and its thumbnail at
+// /photos/{id}/thumbnail.
+func Photo() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+			writeError(w, r, model.NewUnauthenticatedError(gcontext.CredentialsError))
+			return
+		}
+
+		path := strings.TrimPrefix(r.URL.Path, "/photos/")
+		id := strings.TrimSuffix(path, "/thumbnail")
+		thumbnail := id != path
+
+		photoService := ctx.Value("photoService").(*service.PhotoService)
+		photo, err := photoService.FindByID(id)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if err := authorizeSurvey(r, photo.SurveyID); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		data, err := photoService.Open(photo, thumbnail)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		contentType := photo.ContentType
+		if thumbnail {
+			contentType = model.PhotoContentTypeJPEG
+		}
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Cache-Control", "private, max-age=86400")
+		w.Write(data)
+	})
+}
+
+// authorizeSurvey makes sure the signed in user may see the clinical data of
+// a survey
+func authorizeSurvey(r *http.Request, surveyID string) error {
+	ctx := r.Context()
+	userID := ctx.Value("user_id").(*string)
+	roles, err := ctx.Value("roleService").(*service.RoleService).FindByUserId(userID)
+	if err != nil {
+		return err
+	}
+	return ctx.Value("surveyService").(*service.SurveyService).CheckAccess(*userID, roles, surveyID)
+}
@@ -2,15 +2,18 @@ package handler
 
 import (
 	"bytes"
-	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
+	"time"
 
+	gcontext "github.com/kerti/idcra-api/context"
 	"github.com/kerti/idcra-api/model"
 	"github.com/kerti/idcra-api/service"
+	logging "github.com/op/go-logging"
 	uuid "github.com/satori/go.uuid"
 )
 
@@ -32,13 +35,27 @@ func SurveyReport() http.Handler {
 			return
 		}
 
-		reportData, err := ctx.Value("reportService").(*service.ReportService).GenerateSurveyPDF(id)
-		if err != nil {
-			response := &model.Response{
-				Code:  http.StatusInternalServerError,
-				Error: err.Error(),
+		includePhotos, _ := strconv.ParseBool(r.URL.Query().Get("photos"))
+		preview, _ := strconv.ParseBool(r.URL.Query().Get("preview"))
+		if preview {
+			if err := authorizeSurveyPreview(r, id.String()); err != nil {
+				writeError(w, r, err)
+				return
 			}
-			writeResponse(w, response, response.Code)
+		}
+		language, err := ctx.Value("userService").(*service.UserService).ReportLanguage(ctx.Value("user_id").(*string), r.URL.Query().Get("lang"))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		reportData, err := ctx.Value("reportService").(*service.ReportService).GenerateSurveyPDF(id, service.SurveyPDFOptions{
+			IncludePhotos: includePhotos,
+			Preview:       preview,
+			Language:      language,
+		})
+		if err != nil {
+			writeError(w, r, err)
 			return
 		}
 
@@ -47,104 +64,338 @@ func SurveyReport() http.Handler {
 	})
 }
 
+// authorizeSurveyPreview makes sure the signed in user can print a survey that
+// is not approved yet. Approved surveys are printed as usual.
+func authorizeSurveyPreview(r *http.Request, surveyID string) error {
+	ctx := r.Context()
+	survey, err := ctx.Value("surveyService").(*service.SurveyService).FindByID(surveyID)
+	if err != nil {
+		return err
+	}
+	if survey.IsApproved() {
+		return nil
+	}
+
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+	userID := ctx.Value("user_id").(*string)
+	roles, err := ctx.Value("roleService").(*service.RoleService).FindByUserId(userID)
+	if err != nil {
+		return err
+	}
+	return survey.CanPreview(*userID, roles)
+}
+
+// authorizeSchool makes sure the signed in user works with a school, as the
+// change feed does
+func authorizeSchool(r *http.Request, schoolID string) error {
+	ctx := r.Context()
+	access, err := ctx.Value("schoolService").(*service.SchoolService).FindAccess(*ctx.Value("user_id").(*string))
+	if err != nil {
+		return err
+	}
+	return access.Check([]string{schoolID})
+}
+
+// SchoolReport queues the report of every student of a school and responds
+// with the ID of the report job. Clients follow the job with the reportJob
+// query or the reportReady subscription, and download the archive once done.
 func SchoolReport() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// w.Header().Set("Content-type", "application/octet-stream")
 		w.Header().Set("Content-type", "application/json")
 
 		ctx := r.Context()
-		schoolId := strings.TrimPrefix(r.URL.Path, "/reports/school/")
+		if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+			writeError(w, r, model.NewUnauthenticatedError(gcontext.CredentialsError))
+			return
+		}
+		schoolID := strings.TrimPrefix(r.URL.Path, "/reports/school/")
+		if err := authorizeSchool(r, schoolID); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		school, err := ctx.Value("schoolService").(*service.SchoolService).FindByID(schoolID)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
 
-		os.RemoveAll("./reports/")
-		os.MkdirAll("./reports/", os.ModePerm)
+		userID := ctx.Value("user_id").(*string)
+		language, err := ctx.Value("userService").(*service.UserService).ReportLanguage(userID, r.URL.Query().Get("lang"))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
 
-		os.RemoveAll("./tmp/")
-		os.MkdirAll("./tmp/", os.ModePerm)
+		// Clients may pass their own job ID to subscribe to reportReady before
+		// the report is requested.
+		var jobID *string
+		if id := r.URL.Query().Get("jobId"); id != "" {
+			jobID = &id
+		}
 
-		err := ctx.Value("reportService").(*service.ReportService).GenerateSchoolReport(schoolId)
+		job, err := ctx.Value("reportJobService").(*service.ReportJobService).Enqueue(school.ID, language, jobID, userID)
 		if err != nil {
-			response := &model.Response{
-				Code:  http.StatusInternalServerError,
-				Error: err.Error(),
-			}
-			writeResponse(w, response, response.Code)
+			writeError(w, r, err)
 			return
 		}
 
-		school, err := ctx.Value("schoolService").(*service.SchoolService).FindByID(schoolId)
+		response := &model.ResponseSuccess{
+			Code:    http.StatusAccepted,
+			Message: job.ID,
+		}
+
+		writeResponse(w, response, response.Code)
+	})
+}
+
+// SchoolSummaryReport prints the summary of the approved surveys of a school at
+// /reports/summary/school/{id}?startDate=yyyy-mm-dd&endDate=yyyy-mm-dd, the
+// end date not being part of the period.
+func SchoolSummaryReport() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+			writeError(w, r, model.NewUnauthenticatedError(gcontext.CredentialsError))
+			return
+		}
+		schoolID := strings.TrimPrefix(r.URL.Path, "/reports/summary/school/")
+		if err := authorizeSchool(r, schoolID); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		language, err := ctx.Value("userService").(*service.UserService).ReportLanguage(ctx.Value("user_id").(*string), r.URL.Query().Get("lang"))
 		if err != nil {
-			response := &model.Response{
-				Code:  http.StatusInternalServerError,
-				Error: err.Error(),
-			}
-			writeResponse(w, response, response.Code)
+			writeError(w, r, err)
 			return
 		}
 
-		err = os.Rename("./schoolreports.zip", fmt.Sprintf("./reports/%s.zip", school.Name))
+		reportData, err := ctx.Value("reportService").(*service.ReportService).GenerateSchoolSummaryPDF(schoolID, r.URL.Query().Get("startDate"), r.URL.Query().Get("endDate"), language)
 		if err != nil {
-			response := &model.Response{
-				Code:  http.StatusInternalServerError,
-				Error: err.Error(),
-			}
-			writeResponse(w, response, response.Code)
+			writeError(w, r, err)
 			return
 		}
 
-		os.RemoveAll("./tmp/")
-		os.MkdirAll("./tmp/", os.ModePerm)
+		w.Header().Set("Content-type", "application/pdf")
+		io.Copy(w, &reportData)
+	})
+}
 
-		response := &model.Response{
-			Code: http.StatusOK,
+// StreamSchoolReport sends the report archive of a school at
+// /reports/stream/school/{id}, printing every report straight into the
+// response. Errors found once the archive is being sent can only cut it short,
+// so they are logged.
+func StreamSchoolReport() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+			writeError(w, r, model.NewUnauthenticatedError(gcontext.CredentialsError))
+			return
+		}
+		schoolID := strings.TrimPrefix(r.URL.Path, "/reports/stream/school/")
+		if err := authorizeSchool(r, schoolID); err != nil {
+			writeError(w, r, err)
+			return
+		}
+		language, err := ctx.Value("userService").(*service.UserService).ReportLanguage(ctx.Value("user_id").(*string), r.URL.Query().Get("lang"))
+		if err != nil {
+			writeError(w, r, err)
+			return
 		}
 
-		writeResponse(w, response, response.Code)
-		return
+		reportService := ctx.Value("reportService").(*service.ReportService)
+		fileName, reports, err := reportService.FindSchoolReports(schoolID)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-type", "application/zip")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+		w.WriteHeader(http.StatusOK)
+
+		err = reportService.WriteSchoolReport(schoolID, reports, language, w, func(completed, total int) {})
+		if err != nil {
+			ctx.Value("log").(*logging.Logger).Errorf("Error in streaming report of school %s : %v", schoolID, err)
+		}
 	})
 }
 
-func DownloadZip() ([]byte, error) {
-	r, w := io.Pipe()
+// ExportSurveys sends the surveys the user may see as CSV or XLSX at
+// /exports/surveys?format=CSV|XLSX&schoolId=&startDate=yyyy-mm-dd&endDate=yyyy-mm-dd&surveyorId=,
+// every filter being optional. The file is written as the surveys are read, so
+// errors found once it is being sent can only cut it short and are logged.
+func ExportSurveys() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+			writeError(w, r, model.NewUnauthenticatedError(gcontext.CredentialsError))
+			return
+		}
 
-	defer r.Close()
-	defer w.Close()
+		query := r.URL.Query()
+		optional := func(name string) *string {
+			if value := query.Get(name); value != "" {
+				return &value
+			}
+			return nil
+		}
+		filter := model.SurveyExportFilter{
+			Format:     strings.ToUpper(query.Get("format")),
+			SchoolID:   optional("schoolId"),
+			StartDate:  optional("startDate"),
+			EndDate:    optional("endDate"),
+			SurveyorID: optional("surveyorId"),
+		}
+		if err := filter.Validate(); err != nil {
+			writeError(w, r, err)
+			return
+		}
 
-	zip, err := os.Stat("./schoolreports.zip")
-	if err != nil {
-		return nil, err
-	}
+		exportService := ctx.Value("exportService").(*service.ExportService)
+		scope, err := exportService.DataScope(ctx.Value("user_id").(*string))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
 
-	go func() {
+		fileName := filter.FileName(time.Now())
+		w.Header().Set("Content-type", model.ContentTypeOf(fileName))
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+		w.WriteHeader(http.StatusOK)
 
-		f, err := os.Open(zip.Name())
+		err = exportService.WriteSurveys(filter, scope, w, func(completed, total int) {})
 		if err != nil {
+			ctx.Value("log").(*logging.Logger).Errorf("Error in exporting surveys : %v", err)
+		}
+	})
+}
+
+// ReportJobDownload serves the archive of a finished report job at
+// /reports/jobs/{id}/download.
+func ReportJobDownload() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+			writeError(w, r, model.NewUnauthenticatedError(gcontext.CredentialsError))
 			return
+		}
 
+		path := strings.TrimPrefix(r.URL.Path, "/reports/jobs/")
+		if !strings.HasSuffix(path, "/download") {
+			writeError(w, r, model.NewNotFoundError("report job", path))
+			return
 		}
+		id := strings.TrimSuffix(path, "/download")
 
-		buf := make([]byte, 1024)
-		for {
-			chunk, err := f.Read(buf)
-			if err != nil && err != io.EOF {
-				panic(err)
-			}
-			if chunk == 0 {
-				break
-			}
+		job, archive, err := ctx.Value("reportJobService").(*service.ReportJobService).Open(id)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		defer archive.Close()
+		if !job.CanDownload(*ctx.Value("user_id").(*string)) {
+			writeError(w, r, model.NewNotFoundError("report job", id))
+			return
+		}
 
-			if _, err := w.Write(buf[:chunk]); err != nil {
-				return
-			}
+		w.Header().Set("Content-type", job.ContentType())
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": job.FileName}))
+		http.ServeContent(w, r, job.FileName, *job.FinishedAt, archive)
+	})
+}
 
+// ReportTemplatePreview prints the report of a made-up survey with a report
+// template at /reports/templates/{id}/preview, for admins to check the
+// template before attaching it to schools.
+func ReportTemplatePreview() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+			writeError(w, r, model.NewUnauthenticatedError(gcontext.CredentialsError))
+			return
 		}
+		userID := ctx.Value("user_id").(*string)
 
-		w.Close()
-	}()
+		roles, err := ctx.Value("roleService").(*service.RoleService).FindByUserId(userID)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if !model.HasRole(roles, model.RoleAdmin) {
+			writeError(w, r, model.NewForbiddenError("only admins can preview report templates"))
+			return
+		}
 
-	body, err := ioutil.ReadAll(r)
-	if err != nil {
-		return nil, err
-	}
-	return body, nil
+		path := strings.TrimPrefix(r.URL.Path, "/reports/templates/")
+		if !strings.HasSuffix(path, "/preview") {
+			writeError(w, r, model.NewNotFoundError("report template", path))
+			return
+		}
+		id := strings.TrimSuffix(path, "/preview")
+
+		language, err := ctx.Value("userService").(*service.UserService).ReportLanguage(userID, r.URL.Query().Get("lang"))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
 
+		reportData, err := ctx.Value("reportService").(*service.ReportService).GenerateTemplatePreviewPDF(id, language)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-type", "application/pdf")
+		io.Copy(w, &reportData)
+	})
+}
+
+// maxVerifiedReportSize is the largest PDF accepted to compare with a printed
+// report, in bytes
+const maxVerifiedReportSize = 32 << 20
+
+// VerifyReport tells whether a report printed with a verification code is
+// genuine at /verify/{code}. It is public, so clinics handed a printed report
+// can check it without an account, and only tells non-identifying details of
+// the report. A PDF posted as the request body is compared with the printed
+// report.
+func VerifyReport() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			response := &model.Response{
+				Code:  http.StatusMethodNotAllowed,
+				Error: gcontext.GetOrPostMethodSupported,
+			}
+			writeResponse(w, response, response.Code)
+			return
+		}
+
+		code := strings.TrimPrefix(r.URL.Path, "/verify/")
+		verification, err := ctx.Value("reportVerificationService").(*service.ReportVerificationService).FindByCode(code)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		var pdf []byte
+		if r.Method == http.MethodPost {
+			pdf, err = ioutil.ReadAll(io.LimitReader(r.Body, maxVerifiedReportSize+1))
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+			if len(pdf) > maxVerifiedReportSize {
+				writeError(w, r, model.NewFieldError("report", "report is larger than 32 MB"))
+				return
+			}
+		}
+
+		result := model.NewReportVerificationResult(verification, pdf)
+		writeResponse(w, result, http.StatusOK)
+	})
 }
@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/loader"
+	"github.com/kerti/idcra-api/service"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+	"golang.org/x/net/websocket"
+)
+
+// Message types of the graphql-ws protocol used by subscriptions-transport-ws.
+const (
+	gqlConnectionInit      = "connection_init"
+	gqlConnectionAck       = "connection_ack"
+	gqlConnectionError     = "connection_error"
+	gqlConnectionKeepAlive = "ka"
+	gqlConnectionTerminate = "connection_terminate"
+	gqlStart               = "start"
+	gqlStop                = "stop"
+	gqlData                = "data"
+	gqlError               = "error"
+	gqlComplete            = "complete"
+
+	keepAliveInterval = 15 * time.Second
+)
+
+// connectionParams is the payload of connection_init. Browsers cannot set
+// headers on WebSocket requests, so clients send their token here instead.
+type connectionParams struct {
+	AuthToken     string `json:"authToken"`
+	Authorization string `json:"Authorization"`
+}
+
+type operationMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Subscription serves GraphQL subscriptions over WebSocket using the graphql-ws
+// protocol. Schema must be the subscription schema, whose query entry point is
+// the Subscription type.
+type Subscription struct {
+	Schema  *graphql.Schema
+	Loaders loader.LoaderCollection
+}
+
+func (h *Subscription) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	server := websocket.Server{
+		Handshake: func(config *websocket.Config, r *http.Request) error {
+			config.Protocol = []string{"graphql-ws"}
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			session := &subscriptionSession{
+				handler:    h,
+				ctx:        ctx,
+				ws:         ws,
+				operations: make(map[string]func()),
+				log:        ctx.Value("log").(*logging.Logger),
+			}
+			session.serve()
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+type subscriptionSession struct {
+	handler *Subscription
+	ctx     context.Context
+	ws      *websocket.Conn
+	log     *logging.Logger
+
+	writeMu     sync.Mutex
+	mu          sync.Mutex
+	operations  map[string]func()
+	initialized bool
+}
+
+func (s *subscriptionSession) serve() {
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		s.stopAll()
+		s.ws.Close()
+	}()
+
+	for {
+		var msg operationMessage
+		if err := websocket.JSON.Receive(s.ws, &msg); err != nil {
+			return
+		}
+
+		switch msg.Type {
+		case gqlConnectionInit:
+			if s.initialized {
+				s.send(operationMessage{Type: gqlConnectionError, Payload: errorPayload(gcontext.RepeatedConnectionInit)})
+				return
+			}
+			if err := s.authenticate(msg.Payload); err != nil {
+				s.send(operationMessage{Type: gqlConnectionError, Payload: errorPayload(gcontext.CredentialsError)})
+				return
+			}
+			s.initialized = true
+			s.send(operationMessage{Type: gqlConnectionAck})
+			s.send(operationMessage{Type: gqlConnectionKeepAlive})
+			go s.keepAlive(done)
+		case gqlStart:
+			if !s.initialized {
+				s.send(operationMessage{ID: msg.ID, Type: gqlError, Payload: errorPayload(gcontext.ConnectionNotInitialized)})
+				continue
+			}
+			s.start(msg)
+		case gqlStop:
+			s.stop(msg.ID)
+		case gqlConnectionTerminate:
+			return
+		default:
+			s.send(operationMessage{ID: msg.ID, Type: gqlError, Payload: errorPayload(gcontext.UnknownMessageType)})
+		}
+	}
+}
+
+// authenticate signs the session in with the token of the connection_init
+// payload, or else with the token the WebSocket request was made with
+func (s *subscriptionSession) authenticate(payload json.RawMessage) error {
+	var params connectionParams
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &params); err != nil {
+			return err
+		}
+	}
+
+	tokenString := params.AuthToken
+	if tokenString == "" {
+		tokenString = strings.TrimPrefix(params.Authorization, "Bearer ")
+	}
+	if tokenString == "" {
+		if isAuthorized := s.ctx.Value("is_authorized").(bool); !isAuthorized {
+			return errors.New(gcontext.CredentialsError)
+		}
+		return nil
+	}
+
+	userID, err := userIDFromToken(s.ctx, tokenString)
+	if err != nil {
+		return err
+	}
+	s.ctx = context.WithValue(s.ctx, "user_id", &userID)
+	s.ctx = context.WithValue(s.ctx, "is_authorized", true)
+	return nil
+}
+
+func (s *subscriptionSession) start(msg operationMessage) {
+	var params graphqlParams
+	if err := json.Unmarshal(msg.Payload, &params); err != nil {
+		s.send(operationMessage{ID: msg.ID, Type: gqlError, Payload: errorPayload(gcontext.MalformedRequestBody)})
+		return
+	}
+
+	kind, pos := findOperation(params.Query, params.OperationName)
+	if kind != "subscription" {
+		s.send(operationMessage{ID: msg.ID, Type: gqlError, Payload: errorPayload(gcontext.SubscriptionOnly)})
+		return
+	}
+
+	// The subscription schema exposes the Subscription type as its query
+	// entry point, so the operation is executed as a query.
+	query := params.Query[:pos] + "query" + params.Query[pos+len(kind):]
+	if errs := s.handler.Schema.Validate(query); len(errs) > 0 {
+		payload, _ := json.Marshal(errs)
+		s.send(operationMessage{ID: msg.ID, Type: gqlError, Payload: payload})
+		return
+	}
+
+	events, unsubscribe := s.ctx.Value("eventBus").(*service.EventBus).Subscribe()
+
+	s.mu.Lock()
+	if stop, ok := s.operations[msg.ID]; ok {
+		stop()
+	}
+	s.operations[msg.ID] = unsubscribe
+	s.mu.Unlock()
+
+	go func() {
+		for event := range events {
+			event := event
+			ctx := context.WithValue(s.handler.Loaders.Attach(s.ctx), "event", &event)
+			response := formatResponse(ctx, s.handler.Schema.Exec(ctx, query, params.OperationName, params.Variables))
+			if isEmptyResponse(response) {
+				continue
+			}
+
+			payload, err := json.Marshal(response)
+			if err != nil {
+				s.log.Errorf("Error in encoding subscription response : %v", err)
+				continue
+			}
+			s.send(operationMessage{ID: msg.ID, Type: gqlData, Payload: payload})
+		}
+		s.send(operationMessage{ID: msg.ID, Type: gqlComplete})
+	}()
+}
+
+func (s *subscriptionSession) stop(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if unsubscribe, ok := s.operations[id]; ok {
+		unsubscribe()
+		delete(s.operations, id)
+	}
+}
+
+func (s *subscriptionSession) stopAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, unsubscribe := range s.operations {
+		unsubscribe()
+		delete(s.operations, id)
+	}
+}
+
+func (s *subscriptionSession) keepAlive(done <-chan struct{}) {
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.send(operationMessage{Type: gqlConnectionKeepAlive})
+		case <-done:
+			return
+		}
+	}
+}
+
+func (s *subscriptionSession) send(msg operationMessage) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if err := websocket.JSON.Send(s.ws, msg); err != nil {
+		s.log.Debugf("Error in sending subscription message : %v", err)
+	}
+}
+
+// isEmptyResponse reports whether every subscription field resolved to null,
+// meaning the event did not match the subscription.
+func isEmptyResponse(response *graphqlResponse) bool {
+	if len(response.Errors) > 0 {
+		return false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(response.Data, &fields); err != nil {
+		return false
+	}
+
+	for _, value := range fields {
+		if string(value) != "null" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func errorPayload(message string) json.RawMessage {
+	payload, _ := json.Marshal(map[string]string{"message": message})
+	return payload
+}
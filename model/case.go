@@ -1,7 +1,6 @@
 package model
 
 import (
-	"fmt"
 	"time"
 
 	uuid "github.com/satori/go.uuid"
@@ -13,7 +12,14 @@ type Case struct {
 	SurveyID             string `db:"survey_id"`
 	DiagnosisAndActionID string `db:"diagnosis_and_action_id"`
 	ToothNumber          int32  `db:"tooth_number"`
-	CreatedAt            string `db:"created_at"`
+	// TreatmentStatus is PLANNED until the action of the diagnosis is
+	// performed; the operator, date and notes are those of the latest change
+	TreatmentStatus string  `db:"treatment_status"`
+	PerformedBy     *string `db:"performed_by"`
+	PerformedDate   *string `db:"performed_date"`
+	TreatmentNotes  *string `db:"treatment_notes"`
+	CreatedAt       string  `db:"created_at"`
+	UpdatedAt       string  `db:"updated_at"`
 }
 
 // CaseInput is the input for case entity
@@ -23,15 +29,19 @@ type CaseInput struct {
 }
 
 func (ci *CaseInput) Validate() error {
+	v := &validator{}
+
 	if ci.DiagnosisAndActionID == nil {
-		return fmt.Errorf("diagnosis and action ID is required")
+		v.add("diagnosisAndActionId", "diagnosis and action ID is required")
 	}
 
 	if ci.ToothNumber == nil {
-		return fmt.Errorf("tooth number is required")
+		v.add("toothNumber", "tooth number is required")
+	} else if !IsValidToothNumber(*ci.ToothNumber) {
+		v.addf("toothNumber", invalidToothNumberMessage, *ci.ToothNumber)
 	}
 
-	return nil
+	return v.err()
 }
 
 func NewCaseFromInput(input CaseInput, surveyID string) (c Case, err error) {
@@ -44,6 +54,7 @@ func NewCaseFromInput(input CaseInput, surveyID string) (c Case, err error) {
 		SurveyID:             surveyID,
 		DiagnosisAndActionID: *input.DiagnosisAndActionID,
 		ToothNumber:          *input.ToothNumber,
+		TreatmentStatus:      TreatmentStatusPlanned,
 		CreatedAt:            time.Now().Format("2006-01-02 15:04:05"),
 	}
 
@@ -51,6 +51,35 @@ func TestCaseInput(t *testing.T) {
 			assert.Equal(t, "tooth number is required", err.Error())
 		})
 
+		t.Run("InvalidToothNumber", func(t *testing.T) {
+			for _, n := range []int32{0, 10, 19, 49, 56, 86, 91} {
+				sut := getValidCaseInput()
+				sut.ToothNumber = &n
+
+				err := sut.Validate()
+
+				assert.NotNil(t, err, "tooth %d", n)
+			}
+		})
+
+		t.Run("ValidToothNumber", func(t *testing.T) {
+			for _, n := range []int32{11, 18, 48, 51, 55, 85} {
+				sut := getValidCaseInput()
+				sut.ToothNumber = &n
+
+				assert.Nil(t, sut.Validate(), "tooth %d", n)
+			}
+		})
+
+		t.Run("AllErrors", func(t *testing.T) {
+			sut := &CaseInput{}
+
+			err := sut.Validate()
+
+			assert.NotNil(t, err)
+			assert.Equal(t, 2, len(err.(*Error).Fields))
+		})
+
 	})
 
 }
@@ -0,0 +1,95 @@
+package model
+
+func init() {
+	RegisterCatalog(&MessageCatalog{
+		Language: LanguageEnglish,
+		Name:     "English",
+		Months: [12]string{
+			"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December",
+		},
+		Messages: map[string]string{
+			"report.title":                    "IDCRA Survey Report",
+			"report.preview":                  "PREVIEW - survey not yet approved (%s)",
+			"report.identity":                 "Identity",
+			"report.studentName":              "Student Name",
+			"report.schoolName":               "School Name",
+			"report.surveyDate":               "Survey Date",
+			"report.charts":                   "Survey Results",
+			"report.odontogram":               "Odontogram",
+			"report.history":                  "Examination History",
+			"report.history.previousVisit":    "Previous Examination",
+			"report.history.daysAgo":          "%d days ago",
+			"report.history.scoreChange":      "Score Change",
+			"report.history.indexChange":      "DMF-T / def-t Change",
+			"report.history.newCaries":        "New Caries",
+			"report.history.resolvedCaries":   "Resolved Caries",
+			"report.photos":                   "Clinical Photos",
+			"report.operator":                 "Operator's Suggestions",
+			"report.operator.recurring":       "RECURRING",
+			"report.operator.fluoride":        "FLUORIDE",
+			"report.operator.diet":            "DIET",
+			"report.operator.sealant":         "SEALANT",
+			"report.operator.art":             "ART",
+			"report.parent":                   "Parent's Suggestions",
+			"report.teacher":                  "Teacher's Suggestions",
+			"report.reminder":                 "Reminder",
+			"report.guidance":                 "Guidance",
+			"report.supervision":              "Supervision",
+			"report.recommendationVersion":    "Recommendations version %d",
+			"report.verification":             "Report Verification",
+			"report.verification.code":        "Verification code: %s",
+			"report.verification.url":         "Scan the QR code or visit %s to check this report",
+			"report.verification.generatedAt": "Printed on %s",
+
+			"summary.title":            "School Survey Summary",
+			"summary.period":           "Period",
+			"summary.participation":    "Participation",
+			"summary.surveyed":         "%d of %d students surveyed (%.1f%%)",
+			"summary.meanScore":        "Mean Subjective Score",
+			"summary.meanIndices":      "Mean DMF-T / def-t",
+			"summary.noSurveys":        "No approved surveys in this period",
+			"summary.riskDistribution": "Risk Category Distribution",
+			"summary.riskCategory":     "Risk Category",
+			"summary.toothIndices":     "DMF-T / def-t by Age and Sex",
+			"summary.ageBand":          "Age",
+			"summary.sex":              "Sex",
+			"summary.students":         "Students",
+			"summary.diagnoses":        "Most Common Diagnoses",
+			"summary.diagnosis":        "Diagnosis",
+			"summary.cases":            "Cases",
+			"summary.cost":             "Estimated Treatment Cost",
+			"summary.action":           "Action",
+			"summary.planned":          "Planned",
+			"summary.plannedCost":      "Cost",
+			"summary.performed":        "Performed",
+			"summary.performedCost":    "Performed Cost",
+			"summary.total":            "Total",
+
+			"chart.subjectiveScore":     "Subjective Score",
+			"chart.risk":                "Risk (%)",
+			"chart.odontogram.caries":   "Caries",
+			"chart.odontogram.filling":  "Filling",
+			"chart.odontogram.sealant":  "Sealant",
+			"chart.odontogram.fracture": "Fracture",
+			"chart.odontogram.missing":  "Missing / unerupted",
+
+			"status.DRAFT":     "draft",
+			"status.SUBMITTED": "awaiting approval",
+			"status.APPROVED":  "approved",
+			"status.REJECTED":  "rejected",
+
+			"risk.low":    "Low",
+			"risk.medium": "Medium",
+			"risk.high":   "High",
+
+			"sex.MALE":    "Male",
+			"sex.FEMALE":  "Female",
+			"sex.UNKNOWN": "Unknown",
+
+			"template.sample.notice":      "Sample - report template preview with made-up data",
+			"template.sample.studentName": "Budi Santoso",
+			"template.sample.schoolName":  "Sample Elementary School",
+		},
+	})
+}
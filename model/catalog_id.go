@@ -0,0 +1,95 @@
+package model
+
+func init() {
+	RegisterCatalog(&MessageCatalog{
+		Language: LanguageIndonesian,
+		Name:     "Bahasa Indonesia",
+		Months: [12]string{
+			"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+			"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+		},
+		Messages: map[string]string{
+			"report.title":                    "Laporan Survey IDCRA",
+			"report.preview":                  "PRATINJAU - survey belum disetujui (%s)",
+			"report.identity":                 "Identitas",
+			"report.studentName":              "Nama Siswa",
+			"report.schoolName":               "Nama Sekolah",
+			"report.surveyDate":               "Tanggal Survey",
+			"report.charts":                   "Grafik Hasil Survey",
+			"report.odontogram":               "Odontogram",
+			"report.history":                  "Riwayat Pemeriksaan",
+			"report.history.previousVisit":    "Pemeriksaan Sebelumnya",
+			"report.history.daysAgo":          "%d hari yang lalu",
+			"report.history.scoreChange":      "Perubahan Skor",
+			"report.history.indexChange":      "Perubahan DMF-T / def-t",
+			"report.history.newCaries":        "Karies Baru",
+			"report.history.resolvedCaries":   "Karies Teratasi",
+			"report.photos":                   "Foto Klinis",
+			"report.operator":                 "Saran untuk Operator",
+			"report.operator.recurring":       "KUNJUNGAN ULANG",
+			"report.operator.fluoride":        "FLUORIDE",
+			"report.operator.diet":            "DIET",
+			"report.operator.sealant":         "SEALANT",
+			"report.operator.art":             "ART",
+			"report.parent":                   "Saran untuk Orang Tua",
+			"report.teacher":                  "Saran untuk Guru",
+			"report.reminder":                 "Pengingat",
+			"report.guidance":                 "Bimbingan",
+			"report.supervision":              "Pengawasan",
+			"report.recommendationVersion":    "Rekomendasi versi %d",
+			"report.verification":             "Verifikasi Laporan",
+			"report.verification.code":        "Kode verifikasi: %s",
+			"report.verification.url":         "Pindai kode QR atau kunjungi %s untuk memeriksa laporan ini",
+			"report.verification.generatedAt": "Dicetak pada %s",
+
+			"summary.title":            "Ringkasan Survey Sekolah",
+			"summary.period":           "Periode",
+			"summary.participation":    "Partisipasi",
+			"summary.surveyed":         "%d dari %d siswa disurvey (%.1f%%)",
+			"summary.meanScore":        "Skor Subjektif Rata-rata",
+			"summary.meanIndices":      "Rata-rata DMF-T / def-t",
+			"summary.noSurveys":        "Belum ada survey yang disetujui pada periode ini",
+			"summary.riskDistribution": "Distribusi Kategori Risiko",
+			"summary.riskCategory":     "Kategori Risiko",
+			"summary.toothIndices":     "DMF-T / def-t menurut Usia dan Jenis Kelamin",
+			"summary.ageBand":          "Usia",
+			"summary.sex":              "Jenis Kelamin",
+			"summary.students":         "Siswa",
+			"summary.diagnoses":        "Diagnosis Terbanyak",
+			"summary.diagnosis":        "Diagnosis",
+			"summary.cases":            "Kasus",
+			"summary.cost":             "Estimasi Biaya Perawatan",
+			"summary.action":           "Tindakan",
+			"summary.planned":          "Direncanakan",
+			"summary.plannedCost":      "Biaya",
+			"summary.performed":        "Dilakukan",
+			"summary.performedCost":    "Biaya Dilakukan",
+			"summary.total":            "Total",
+
+			"chart.subjectiveScore":     "Skor Subjektif",
+			"chart.risk":                "Risiko (%)",
+			"chart.odontogram.caries":   "Karies",
+			"chart.odontogram.filling":  "Tambalan",
+			"chart.odontogram.sealant":  "Sealant",
+			"chart.odontogram.fracture": "Fraktur",
+			"chart.odontogram.missing":  "Hilang / belum erupsi",
+
+			"status.DRAFT":     "draf",
+			"status.SUBMITTED": "menunggu persetujuan",
+			"status.APPROVED":  "disetujui",
+			"status.REJECTED":  "ditolak",
+
+			"risk.low":    "Rendah",
+			"risk.medium": "Sedang",
+			"risk.high":   "Tinggi",
+
+			"sex.MALE":    "Laki-laki",
+			"sex.FEMALE":  "Perempuan",
+			"sex.UNKNOWN": "Tidak diketahui",
+
+			"template.sample.notice":      "CONTOH - pratinjau template laporan dengan data contoh",
+			"template.sample.studentName": "Budi Santoso",
+			"template.sample.schoolName":  "SD Contoh",
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ChangeEntitySchool             = "SCHOOL"
+	ChangeEntityStudent            = "STUDENT"
+	ChangeEntityDiagnosisAndAction = "DIAGNOSIS_AND_ACTION"
+	ChangeEntitySurvey             = "SURVEY"
+	ChangeEntityCase               = "CASE"
+
+	ChangeOperationCreated = "CREATED"
+	ChangeOperationUpdated = "UPDATED"
+	ChangeOperationDeleted = "DELETED"
+)
+
+// ChangeEntityTypes lists the entity types recorded in the change log
+var ChangeEntityTypes = []string{
+	ChangeEntitySchool,
+	ChangeEntityStudent,
+	ChangeEntityDiagnosisAndAction,
+	ChangeEntitySurvey,
+	ChangeEntityCase,
+}
+
+// Change is an entry of the change log. A change with the DELETED operation is
+// the tombstone of a removed entity.
+type Change struct {
+	Seq        uint64
+	EntityType string  `db:"entity_type"`
+	EntityID   string  `db:"entity_id"`
+	Operation  string  `db:"operation"`
+	SchoolID   *string `db:"school_id"`
+	ChangedAt  string  `db:"changed_at"`
+}
+
+// ValidateChangeEntityTypes makes sure every requested entity type is recorded
+// in the change log
+func ValidateChangeEntityTypes(entityTypes []string) error {
+	for _, entityType := range entityTypes {
+		if !isOneOf(entityType, ChangeEntityTypes) {
+			return NewFieldError("entityTypes", fmt.Sprintf("invalid entity type %s, expecting one of %s", entityType, strings.Join(ChangeEntityTypes, ", ")))
+		}
+	}
+	return nil
+}
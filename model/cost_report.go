@@ -1,7 +1,126 @@
 package model
 
-// CostReport is the cost report entity
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CostReport is the cost report entity. Cost is the cost of the planned
+// actions, while PerformedCost counts every time an action was performed,
+// including actions redone after a failed treatment.
 type CostReport struct {
-	Description string  `db:"description"`
-	Cost        float64 `db:"cost"`
+	Description    string  `db:"description"`
+	Cost           float64 `db:"cost"`
+	PerformedCost  float64 `db:"performed_cost"`
+	PlannedCount   int32   `db:"planned_count"`
+	PerformedCount int32   `db:"performed_count"`
+}
+
+// Cost dimensions are what cost analytics can be grouped by
+const (
+	CostDimensionSchool       = "SCHOOL"
+	CostDimensionMonth        = "MONTH"
+	CostDimensionDiagnosis    = "DIAGNOSIS"
+	CostDimensionAction       = "ACTION"
+	CostDimensionRiskCategory = "RISK_CATEGORY"
+)
+
+// CostDimensions lists the dimensions cost analytics can be grouped by
+var CostDimensions = []string{
+	CostDimensionSchool,
+	CostDimensionMonth,
+	CostDimensionDiagnosis,
+	CostDimensionAction,
+	CostDimensionRiskCategory,
+}
+
+// OutstandingTreatmentStatuses are the statuses of cases still to be treated:
+// planned cases and cases whose treatment failed
+var OutstandingTreatmentStatuses = []string{TreatmentStatusPlanned, TreatmentStatusFailed}
+
+// CostAnalyticsFilter chooses the cases costs are summed over and how they are
+// grouped. No schools means every school. Dates are yyyy-mm-dd, the end date
+// not being part of the period.
+type CostAnalyticsFilter struct {
+	SchoolIDs []string
+	StartDate string
+	EndDate   string
+	GroupBy   []string
+	// ProjectOutstanding adds the cost of treating every outstanding case
+	ProjectOutstanding bool
+}
+
+// Validate checks the period and the dimensions of the filter
+func (f *CostAnalyticsFilter) Validate() error {
+	v := &validator{}
+
+	start, err := time.Parse("2006-01-02", f.StartDate)
+	if err != nil {
+		v.add("startDate", "invalid date format, expecting yyyy-mm-dd")
+	}
+	end, err := time.Parse("2006-01-02", f.EndDate)
+	if err != nil {
+		v.add("endDate", "invalid date format, expecting yyyy-mm-dd")
+	}
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		v.add("endDate", "end date must be after the start date")
+	}
+
+	seen := make(map[string]bool)
+	for i, dimension := range f.GroupBy {
+		field := fmt.Sprintf("groupBy[%d]", i)
+		if !isOneOf(dimension, CostDimensions) {
+			v.addf(field, "invalid dimension %s, expecting one of %s", dimension, strings.Join(CostDimensions, ", "))
+		} else if seen[dimension] {
+			v.addf(field, "dimension %s is given more than once", dimension)
+		}
+		seen[dimension] = true
+	}
+
+	return v.err()
+}
+
+// CostGroup sums the costs of the cases sharing the values of the grouped
+// dimensions. Dimensions that are not grouped by are nil. Outstanding costs
+// are those of the cases still to be treated.
+type CostGroup struct {
+	SchoolID         *string `db:"school_id"`
+	SchoolName       *string `db:"school_name"`
+	Month            *string `db:"month"`
+	Diagnosis        *string `db:"diagnosis"`
+	Action           *string `db:"action"`
+	RiskCategory     *string `db:"risk_category"`
+	Cost             float64 `db:"cost"`
+	PerformedCost    float64 `db:"performed_cost"`
+	PlannedCount     int32   `db:"planned_count"`
+	PerformedCount   int32   `db:"performed_count"`
+	OutstandingCost  float64 `db:"outstanding_cost"`
+	OutstandingCount int32   `db:"outstanding_count"`
+}
+
+func (g *CostGroup) add(other *CostGroup) {
+	g.Cost += other.Cost
+	g.PerformedCost += other.PerformedCost
+	g.PlannedCount += other.PlannedCount
+	g.PerformedCount += other.PerformedCount
+	g.OutstandingCost += other.OutstandingCost
+	g.OutstandingCount += other.OutstandingCount
+}
+
+// CostAnalytics holds the costs of every group and their total
+type CostAnalytics struct {
+	Groups []*CostGroup
+	Total  *CostGroup
+	// ProjectOutstanding tells whether outstanding costs were asked for
+	ProjectOutstanding bool
+}
+
+// NewCostAnalytics sums up the costs of the groups
+func NewCostAnalytics(groups []*CostGroup, projectOutstanding bool) *CostAnalytics {
+	total := &CostGroup{}
+	for _, g := range groups {
+		total.add(g)
+	}
+	return &CostAnalytics{Groups: groups, Total: total, ProjectOutstanding: projectOutstanding}
 }
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostAnalyticsFilter(t *testing.T) {
+	t.Run("Valid", func(t *testing.T) {
+		filter := CostAnalyticsFilter{StartDate: "2026-01-01", EndDate: "2026-07-01", GroupBy: []string{CostDimensionSchool, CostDimensionMonth}}
+		assert.NoError(t, filter.Validate())
+
+		filter.GroupBy = nil
+		assert.NoError(t, filter.Validate())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		filter := CostAnalyticsFilter{
+			StartDate: "2026-07-01",
+			EndDate:   "2026-01-01",
+			GroupBy:   []string{CostDimensionAction, "SURVEYOR", CostDimensionAction},
+		}
+		fields := map[string]bool{}
+		for _, f := range AsError(filter.Validate()).Fields {
+			fields[f.Field] = true
+		}
+		assert.Equal(t, map[string]bool{"endDate": true, "groupBy[1]": true, "groupBy[2]": true}, fields)
+
+		filter = CostAnalyticsFilter{StartDate: "July", EndDate: "2026-01-01"}
+		assert.Equal(t, "startDate", AsError(filter.Validate()).Fields[0].Field)
+	})
+}
+
+func TestNewCostAnalytics(t *testing.T) {
+	filling, sealant := "Filling", "Sealant"
+	analytics := NewCostAnalytics([]*CostGroup{
+		{Action: &filling, Cost: 100, PerformedCost: 50, PlannedCount: 2, PerformedCount: 1, OutstandingCost: 50, OutstandingCount: 1},
+		{Action: &sealant, Cost: 30, PlannedCount: 3, OutstandingCost: 30, OutstandingCount: 3},
+	}, true)
+
+	assert.Len(t, analytics.Groups, 2)
+	assert.True(t, analytics.ProjectOutstanding)
+	assert.Equal(t, &CostGroup{Cost: 130, PerformedCost: 50, PlannedCount: 5, PerformedCount: 1, OutstandingCost: 80, OutstandingCount: 4}, analytics.Total)
+
+	empty := NewCostAnalytics(nil, false)
+	assert.Equal(t, &CostGroup{}, empty.Total)
+}
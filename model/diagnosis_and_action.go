@@ -6,5 +6,9 @@ type DiagnosisAndAction struct {
 	Diagnosis string
 	Action    string
 	UnitCost  float64 `db:"unit_cost"`
-	CreatedAt string  `db:"created_at"`
+	// IndexComponent is the component of the def-t and DMF-T indices the
+	// diagnosis counts towards, if any
+	IndexComponent *string `db:"index_component"`
+	CreatedAt      string  `db:"created_at"`
+	UpdatedAt      string  `db:"updated_at"`
 }
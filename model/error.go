@@ -0,0 +1,132 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ErrorCode classifies an error for API clients. It is exposed as the "code"
+// extension of GraphQL errors.
+type ErrorCode string
+
+const (
+	ErrorCodeNotFound         ErrorCode = "NOT_FOUND"
+	ErrorCodeValidationFailed ErrorCode = "VALIDATION_FAILED"
+	ErrorCodeUnauthenticated  ErrorCode = "UNAUTHENTICATED"
+	ErrorCodeForbidden        ErrorCode = "FORBIDDEN"
+	ErrorCodeConflict         ErrorCode = "CONFLICT"
+	ErrorCodeInternal         ErrorCode = "INTERNAL"
+)
+
+const internalErrorMessage = "internal server error"
+
+// FieldError describes a problem with a single input field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an error from the catalog above. The message is safe to show to
+// clients; the cause, if any, is only meant for the logs.
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Fields  []FieldError
+	Cause   error
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Extensions returns the GraphQL error extensions for the error.
+func (e *Error) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{
+		"code": e.Code,
+	}
+	if len(e.Fields) > 0 {
+		extensions["fields"] = e.Fields
+	}
+	return extensions
+}
+
+func NewNotFoundError(entity string, id string) *Error {
+	return &Error{
+		Code:    ErrorCodeNotFound,
+		Message: fmt.Sprintf("%s %s not found", entity, id),
+	}
+}
+
+// NewValidationError returns a validation error listing every field error.
+func NewValidationError(fields ...FieldError) *Error {
+	messages := make([]string, len(fields))
+	for i, f := range fields {
+		messages[i] = f.Message
+	}
+
+	return &Error{
+		Code:    ErrorCodeValidationFailed,
+		Message: strings.Join(messages, "; "),
+		Fields:  fields,
+	}
+}
+
+// NewFieldError returns a validation error for a single field.
+func NewFieldError(field string, message string) *Error {
+	return NewValidationError(FieldError{Field: field, Message: message})
+}
+
+func NewUnauthenticatedError(message string) *Error {
+	return &Error{
+		Code:    ErrorCodeUnauthenticated,
+		Message: message,
+	}
+}
+
+func NewForbiddenError(message string) *Error {
+	return &Error{
+		Code:    ErrorCodeForbidden,
+		Message: message,
+	}
+}
+
+func NewConflictError(message string) *Error {
+	return &Error{
+		Code:    ErrorCodeConflict,
+		Message: message,
+	}
+}
+
+// NewInternalError hides the cause behind a generic message.
+func NewInternalError(cause error) *Error {
+	return &Error{
+		Code:    ErrorCodeInternal,
+		Message: internalErrorMessage,
+		Cause:   cause,
+	}
+}
+
+// prefixFieldErrors prepends prefix to the field paths of a validation error,
+// so that errors of nested inputs point at the right element.
+func prefixFieldErrors(err error, prefix string) error {
+	e, ok := err.(*Error)
+	if !ok {
+		return err
+	}
+
+	prefixed := *e
+	prefixed.Fields = make([]FieldError, len(e.Fields))
+	for i, f := range e.Fields {
+		prefixed.Fields[i] = FieldError{Field: prefix + f.Field, Message: f.Message}
+	}
+	return &prefixed
+}
+
+// AsError converts any error into a catalog error, treating unknown errors as
+// internal ones.
+func AsError(err error) *Error {
+	if e, ok := err.(*Error); ok {
+		return e
+	}
+	return NewInternalError(err)
+}
@@ -0,0 +1,13 @@
+package model
+
+// SurveyCreatedEvent is published after a survey has been committed.
+type SurveyCreatedEvent struct {
+	SchoolID string
+	Survey   *Survey
+}
+
+// ReportReadyEvent is published when a report job finishes, successfully or
+// not, with the job as it finished.
+type ReportReadyEvent struct {
+	Job ReportJob
+}
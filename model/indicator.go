@@ -0,0 +1,314 @@
+package model
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Indicator dimensions are what epidemiological indicators can be grouped by
+const (
+	IndicatorDimensionSchool  = "SCHOOL"
+	IndicatorDimensionAgeBand = "AGE_BAND"
+	IndicatorDimensionSex     = "SEX"
+	IndicatorDimensionPeriod  = "PERIOD"
+)
+
+// IndicatorDimensions lists the dimensions indicators can be grouped by
+var IndicatorDimensions = []string{
+	IndicatorDimensionSchool,
+	IndicatorDimensionAgeBand,
+	IndicatorDimensionSex,
+	IndicatorDimensionPeriod,
+}
+
+// Indicator periods are the lengths of the periods indicators grouped by
+// period are computed over
+const (
+	IndicatorPeriodMonth   = "MONTH"
+	IndicatorPeriodQuarter = "QUARTER"
+	IndicatorPeriodYear    = "YEAR"
+)
+
+// IndicatorPeriods lists the lengths of the periods indicators can be grouped
+// by
+var IndicatorPeriods = []string{IndicatorPeriodMonth, IndicatorPeriodQuarter, IndicatorPeriodYear}
+
+// IndicatorConfidenceLevel is the confidence level of the intervals of the
+// indicators, and indicatorZ the matching quantile of the normal distribution
+const (
+	IndicatorConfidenceLevel = 0.95
+	indicatorZ               = 1.959964
+)
+
+// IndicatorFilter chooses the surveys indicators are computed over and how
+// they are grouped. No schools means every school. Dates are yyyy-mm-dd, the
+// end date not being part of the period. Period is the length of the periods
+// when grouping by period, a year when not given.
+type IndicatorFilter struct {
+	SchoolIDs []string
+	StartDate string
+	EndDate   string
+	GroupBy   []string
+	Period    string
+}
+
+// Validate checks the dates, the dimensions and the period of the filter
+func (f *IndicatorFilter) Validate() error {
+	v := &validator{}
+
+	start, err := time.Parse("2006-01-02", f.StartDate)
+	if err != nil {
+		v.add("startDate", "invalid date format, expecting yyyy-mm-dd")
+	}
+	end, err := time.Parse("2006-01-02", f.EndDate)
+	if err != nil {
+		v.add("endDate", "invalid date format, expecting yyyy-mm-dd")
+	}
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		v.add("endDate", "end date must be after the start date")
+	}
+
+	seen := make(map[string]bool)
+	for i, dimension := range f.GroupBy {
+		field := fmt.Sprintf("groupBy[%d]", i)
+		if !isOneOf(dimension, IndicatorDimensions) {
+			v.addf(field, "invalid dimension %s, expecting one of %s", dimension, strings.Join(IndicatorDimensions, ", "))
+		} else if seen[dimension] {
+			v.addf(field, "dimension %s is given more than once", dimension)
+		}
+		seen[dimension] = true
+	}
+
+	if f.Period != "" && !isOneOf(f.Period, IndicatorPeriods) {
+		v.addf("period", "invalid period %s, expecting one of %s", f.Period, strings.Join(IndicatorPeriods, ", "))
+	}
+
+	return v.err()
+}
+
+// IndicatorKey holds the values of the grouped dimensions of a group of
+// surveys. Dimensions that are not grouped by are nil.
+type IndicatorKey struct {
+	SchoolID   *string `db:"school_id"`
+	SchoolName *string `db:"school_name"`
+	AgeBand    *string `db:"age_band"`
+	Sex        *string `db:"sex"`
+	Period     *string `db:"period"`
+}
+
+func (k IndicatorKey) String() string {
+	values := []*string{k.SchoolID, k.AgeBand, k.Sex, k.Period}
+	parts := make([]string, len(values))
+	for i, value := range values {
+		if value != nil {
+			parts[i] = *value
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// IndicatorAggregate sums up the tooth indices and risk categories of a group
+// of surveys
+type IndicatorAggregate struct {
+	IndicatorKey
+	Surveyed    int32 `db:"surveyed"`
+	WithCaries  int32 `db:"with_caries"`
+	DMFT        int64 `db:"dmft"`
+	DMFTSquares int64 `db:"dmft_squares"`
+	DefT        int64 `db:"deft"`
+	DefTSquares int64 `db:"deft_squares"`
+	Decayed     int64 `db:"decayed"`
+	Missing     int64 `db:"missing"`
+	Filled      int64 `db:"filled"`
+	RiskLow     int32 `db:"risk_low"`
+	RiskMedium  int32 `db:"risk_medium"`
+	RiskHigh    int32 `db:"risk_high"`
+}
+
+func (a *IndicatorAggregate) add(other *IndicatorAggregate) {
+	a.Surveyed += other.Surveyed
+	a.WithCaries += other.WithCaries
+	a.DMFT += other.DMFT
+	a.DMFTSquares += other.DMFTSquares
+	a.DefT += other.DefT
+	a.DefTSquares += other.DefTSquares
+	a.Decayed += other.Decayed
+	a.Missing += other.Missing
+	a.Filled += other.Filled
+	a.RiskLow += other.RiskLow
+	a.RiskMedium += other.RiskMedium
+	a.RiskHigh += other.RiskHigh
+}
+
+// IndicatorDMFTCount is the number of surveys of a group with a DMF-T score
+type IndicatorDMFTCount struct {
+	IndicatorKey
+	DMFT  int32 `db:"dmft"`
+	Count int32 `db:"count"`
+}
+
+// Estimate is the value of an indicator with its confidence interval
+type Estimate struct {
+	Value float64
+	Lower float64
+	Upper float64
+}
+
+// RiskShare is the share of the surveys of a group in a risk category, in
+// percent
+type RiskShare struct {
+	Category   string
+	Count      int32
+	Percentage Estimate
+}
+
+// IndicatorGroup holds the epidemiological indicators of a group of surveys.
+// Caries prevalence is the percentage of students with caries experience in
+// either dentition. The Significant Caries Index is the mean DMF-T of the third
+// of the students with the highest DMF-T. The care index is the percentage of
+// DMF teeth that are filled, nil when there are none.
+type IndicatorGroup struct {
+	IndicatorKey
+	Surveyed               int32
+	CariesPrevalence       Estimate
+	MeanDMFT               Estimate
+	MeanDefT               Estimate
+	SignificantCariesIndex Estimate
+	CareIndex              *Estimate
+	RiskDistribution       []*RiskShare
+}
+
+// Indicators holds the indicators of every group and of all the groups
+// together
+type Indicators struct {
+	Groups []*IndicatorGroup
+	Total  *IndicatorGroup
+}
+
+// NewIndicators computes the indicators of the groups from their aggregates
+// and the distribution of their DMF-T scores
+func NewIndicators(aggregates []*IndicatorAggregate, counts []*IndicatorDMFTCount) *Indicators {
+	histograms := make(map[string]map[int32]int32)
+	totalHistogram := make(map[int32]int32)
+	for _, c := range counts {
+		key := c.IndicatorKey.String()
+		if histograms[key] == nil {
+			histograms[key] = make(map[int32]int32)
+		}
+		histograms[key][c.DMFT] += c.Count
+		totalHistogram[c.DMFT] += c.Count
+	}
+
+	indicators := &Indicators{Groups: make([]*IndicatorGroup, 0, len(aggregates))}
+	total := &IndicatorAggregate{}
+	for _, a := range aggregates {
+		indicators.Groups = append(indicators.Groups, newIndicatorGroup(a, histograms[a.IndicatorKey.String()]))
+		total.add(a)
+	}
+	indicators.Total = newIndicatorGroup(total, totalHistogram)
+
+	return indicators
+}
+
+func newIndicatorGroup(a *IndicatorAggregate, histogram map[int32]int32) *IndicatorGroup {
+	g := &IndicatorGroup{
+		IndicatorKey:           a.IndicatorKey,
+		Surveyed:               a.Surveyed,
+		CariesPrevalence:       percentageEstimate(int64(a.WithCaries), int64(a.Surveyed)),
+		MeanDMFT:               meanEstimate(float64(a.DMFT), float64(a.DMFTSquares), int64(a.Surveyed)),
+		MeanDefT:               meanEstimate(float64(a.DefT), float64(a.DefTSquares), int64(a.Surveyed)),
+		SignificantCariesIndex: significantCariesIndex(histogram),
+	}
+
+	if dmf := a.Decayed + a.Missing + a.Filled; dmf > 0 {
+		care := percentageEstimate(a.Filled, dmf)
+		g.CareIndex = &care
+	}
+
+	riskCounts := map[string]int32{
+		RiskCategoryLow:    a.RiskLow,
+		RiskCategoryMedium: a.RiskMedium,
+		RiskCategoryHigh:   a.RiskHigh,
+	}
+	for _, category := range RiskCategories {
+		g.RiskDistribution = append(g.RiskDistribution, &RiskShare{
+			Category:   category,
+			Count:      riskCounts[category],
+			Percentage: percentageEstimate(int64(riskCounts[category]), int64(a.Surveyed)),
+		})
+	}
+
+	return g
+}
+
+// percentageEstimate returns a proportion in percent with its Wilson score
+// interval, which stays within 0 and 100 for small groups and extreme shares
+func percentageEstimate(successes int64, n int64) Estimate {
+	if n == 0 {
+		return Estimate{}
+	}
+	p := float64(successes) / float64(n)
+	z2 := indicatorZ * indicatorZ
+	nf := float64(n)
+
+	center := (p + z2/(2*nf)) / (1 + z2/nf)
+	margin := indicatorZ / (1 + z2/nf) * math.Sqrt(p*(1-p)/nf+z2/(4*nf*nf))
+
+	return Estimate{
+		Value: p * 100,
+		Lower: math.Max(0, center-margin) * 100,
+		Upper: math.Min(1, center+margin) * 100,
+	}
+}
+
+// meanEstimate returns the mean of n values from their sum and the sum of
+// their squares, with its normal confidence interval. Means of counts never
+// fall below zero, so neither does the interval.
+func meanEstimate(sum float64, squares float64, n int64) Estimate {
+	if n == 0 {
+		return Estimate{}
+	}
+	nf := float64(n)
+	mean := sum / nf
+	if n == 1 {
+		return Estimate{Value: mean, Lower: mean, Upper: mean}
+	}
+
+	variance := math.Max(0, (squares-nf*mean*mean)/(nf-1))
+	margin := indicatorZ * math.Sqrt(variance/nf)
+	return Estimate{Value: mean, Lower: math.Max(0, mean-margin), Upper: mean + margin}
+}
+
+// significantCariesIndex returns the mean DMF-T of the third of the students
+// with the highest DMF-T, rounded up, from the number of students with every
+// DMF-T score
+func significantCariesIndex(histogram map[int32]int32) Estimate {
+	var n int64
+	scores := make([]int32, 0, len(histogram))
+	for score, count := range histogram {
+		n += int64(count)
+		scores = append(scores, score)
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i] > scores[j] })
+
+	remaining := (n + 2) / 3
+	third := remaining
+	var sum, squares float64
+	for _, score := range scores {
+		if remaining == 0 {
+			break
+		}
+		taken := int64(histogram[score])
+		if taken > remaining {
+			taken = remaining
+		}
+		sum += float64(score) * float64(taken)
+		squares += float64(score) * float64(score) * float64(taken)
+		remaining -= taken
+	}
+
+	return meanEstimate(sum, squares, third)
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndicatorFilter(t *testing.T) {
+	filter := IndicatorFilter{StartDate: "2026-01-01", EndDate: "2027-01-01", GroupBy: []string{IndicatorDimensionSchool, IndicatorDimensionPeriod}, Period: IndicatorPeriodQuarter}
+	assert.NoError(t, filter.Validate())
+
+	filter = IndicatorFilter{StartDate: "2026-01-01", EndDate: "2026-01-01", GroupBy: []string{"GRADE", IndicatorDimensionSex, IndicatorDimensionSex}, Period: "WEEK"}
+	fields := map[string]bool{}
+	for _, f := range AsError(filter.Validate()).Fields {
+		fields[f.Field] = true
+	}
+	assert.Equal(t, map[string]bool{"endDate": true, "groupBy[0]": true, "groupBy[2]": true, "period": true}, fields)
+}
+
+func TestPercentageEstimate(t *testing.T) {
+	e := percentageEstimate(50, 100)
+	assert.Equal(t, float64(50), e.Value)
+	assert.InDelta(t, 40.38, e.Lower, 0.01)
+	assert.InDelta(t, 59.62, e.Upper, 0.01)
+
+	none := percentageEstimate(0, 10)
+	assert.Equal(t, float64(0), none.Lower)
+	assert.InDelta(t, 27.75, none.Upper, 0.01)
+
+	assert.Equal(t, Estimate{}, percentageEstimate(0, 0))
+}
+
+func TestMeanEstimate(t *testing.T) {
+	// 1, 2, 3, 4, 5 have a mean of 3 and a standard deviation of sqrt(2.5)
+	e := meanEstimate(15, 55, 5)
+	margin := indicatorZ * math.Sqrt(2.5/5)
+	assert.Equal(t, float64(3), e.Value)
+	assert.InDelta(t, 3-margin, e.Lower, 1e-9)
+	assert.InDelta(t, 3+margin, e.Upper, 1e-9)
+
+	assert.Equal(t, Estimate{Value: 4, Lower: 4, Upper: 4}, meanEstimate(4, 16, 1))
+	assert.Equal(t, float64(0), meanEstimate(1, 1, 10).Lower)
+}
+
+func TestSignificantCariesIndex(t *testing.T) {
+	// ten students with DMF-T 0, 0, 0, 0, 1, 1, 2, 3, 5, 6; the highest third
+	// rounded up are the four with 6, 5, 3 and 2
+	sic := significantCariesIndex(map[int32]int32{0: 4, 1: 2, 2: 1, 3: 1, 5: 1, 6: 1})
+	assert.Equal(t, 4.0, sic.Value)
+
+	// ties are split when the third ends within them
+	sic = significantCariesIndex(map[int32]int32{0: 3, 4: 3})
+	assert.Equal(t, 4.0, sic.Value)
+
+	assert.Equal(t, Estimate{}, significantCariesIndex(nil))
+}
+
+func TestNewIndicators(t *testing.T) {
+	a, b := "school-a", "school-b"
+	indicators := NewIndicators([]*IndicatorAggregate{
+		{IndicatorKey: IndicatorKey{SchoolID: &a}, Surveyed: 2, WithCaries: 1, DMFT: 4, DMFTSquares: 16, Decayed: 3, Filled: 1, RiskLow: 1, RiskHigh: 1},
+		{IndicatorKey: IndicatorKey{SchoolID: &b}, Surveyed: 1, DefT: 0},
+	}, []*IndicatorDMFTCount{
+		{IndicatorKey: IndicatorKey{SchoolID: &a}, DMFT: 0, Count: 1},
+		{IndicatorKey: IndicatorKey{SchoolID: &a}, DMFT: 4, Count: 1},
+		{IndicatorKey: IndicatorKey{SchoolID: &b}, DMFT: 0, Count: 1},
+	})
+
+	assert.Len(t, indicators.Groups, 2)
+	first := indicators.Groups[0]
+	assert.Equal(t, &a, first.SchoolID)
+	assert.Equal(t, float64(50), first.CariesPrevalence.Value)
+	assert.Equal(t, float64(2), first.MeanDMFT.Value)
+	assert.Equal(t, float64(4), first.SignificantCariesIndex.Value)
+	assert.Equal(t, float64(25), first.CareIndex.Value)
+	assert.Equal(t, RiskCategoryHigh, first.RiskDistribution[2].Category)
+	assert.Equal(t, float64(50), first.RiskDistribution[2].Percentage.Value)
+
+	assert.Nil(t, indicators.Groups[1].CareIndex)
+
+	total := indicators.Total
+	assert.Nil(t, total.SchoolID)
+	assert.Equal(t, int32(3), total.Surveyed)
+	assert.InDelta(t, 4.0/3, total.MeanDMFT.Value, 1e-9)
+	assert.Equal(t, float64(4), total.SignificantCariesIndex.Value)
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	LanguageIndonesian = "id"
+	LanguageEnglish    = "en"
+
+	// DefaultLanguage is the language of reports for users without a
+	// preferred language, and of messages missing from other catalogs
+	DefaultLanguage = LanguageIndonesian
+)
+
+// MessageCatalog holds the text of the reports in a language, keyed by
+// message. Messages are format strings taking the arguments of the message.
+type MessageCatalog struct {
+	// Language is the code of the language, such as "id" or "jv"
+	Language string
+	// Name is the name of the language in the language itself
+	Name     string
+	Months   [12]string
+	Messages map[string]string
+}
+
+var (
+	catalogsMu sync.RWMutex
+	catalogs   = make(map[string]*MessageCatalog)
+)
+
+// RegisterCatalog makes the messages of a language available by its code. It
+// panics when a catalog of the same language is already registered.
+func RegisterCatalog(c *MessageCatalog) {
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+
+	if _, ok := catalogs[c.Language]; ok {
+		panic(fmt.Sprintf("catalog of language %s is already registered", c.Language))
+	}
+	catalogs[c.Language] = c
+}
+
+// FindCatalog returns the registered catalog of a language
+func FindCatalog(language string) (*MessageCatalog, bool) {
+	catalogsMu.RLock()
+	defer catalogsMu.RUnlock()
+
+	c, ok := catalogs[language]
+	return c, ok
+}
+
+// Languages returns the codes of the languages with a catalog in order
+func Languages() []string {
+	catalogsMu.RLock()
+	defer catalogsMu.RUnlock()
+
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// ValidateLanguage makes sure a language has a catalog
+func ValidateLanguage(field string, language string) error {
+	if _, ok := FindCatalog(language); !ok {
+		return NewFieldError(field, fmt.Sprintf("unsupported language %s, expecting one of %s", language, strings.Join(Languages(), ", ")))
+	}
+	return nil
+}
+
+// Localizer formats the messages of a report in a language, falling back to
+// the default language for messages the catalog does not have.
+type Localizer struct {
+	catalog  *MessageCatalog
+	fallback *MessageCatalog
+}
+
+// NewLocalizer returns a localizer of the language, or of the default language
+// when the language has no catalog
+func NewLocalizer(language string) *Localizer {
+	fallback, _ := FindCatalog(DefaultLanguage)
+	catalog, ok := FindCatalog(language)
+	if !ok {
+		catalog = fallback
+	}
+	return &Localizer{catalog: catalog, fallback: fallback}
+}
+
+// Language returns the code of the language messages are formatted in
+func (l *Localizer) Language() string {
+	return l.catalog.Language
+}
+
+// T formats a message with its arguments. Messages missing from every catalog
+// are returned as their key so they stand out in the report.
+func (l *Localizer) T(key string, args ...interface{}) string {
+	message, ok := l.catalog.Messages[key]
+	if !ok {
+		message, ok = l.fallback.Messages[key]
+	}
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// FormatDate formats a date as day, month name and year, such as
+// "02 Januari 2006"
+func (l *Localizer) FormatDate(t time.Time) string {
+	return fmt.Sprintf("%02d %s %d", t.Day(), l.catalog.Months[t.Month()-1], t.Year())
+}
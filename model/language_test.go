@@ -0,0 +1,64 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalizer(t *testing.T) {
+	date := time.Date(2026, time.August, 7, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Languages", func(t *testing.T) {
+		id := NewLocalizer(LanguageIndonesian)
+		en := NewLocalizer(LanguageEnglish)
+
+		assert.Equal(t, "07 Agustus 2026", id.FormatDate(date))
+		assert.Equal(t, "07 August 2026", en.FormatDate(date))
+		assert.NotEqual(t, id.T("report.title"), en.T("report.title"))
+	})
+
+	t.Run("FallsBackToDefaultLanguage", func(t *testing.T) {
+		l := NewLocalizer("xx")
+
+		assert.Equal(t, DefaultLanguage, l.Language())
+		assert.Equal(t, NewLocalizer(DefaultLanguage).T("report.title"), l.T("report.title"))
+	})
+
+	t.Run("MissingMessage", func(t *testing.T) {
+		assert.Equal(t, "report.unknown", NewLocalizer(LanguageEnglish).T("report.unknown"))
+	})
+
+	t.Run("Arguments", func(t *testing.T) {
+		assert.Equal(t, "3 days ago", NewLocalizer(LanguageEnglish).T("report.history.daysAgo", 3))
+	})
+}
+
+func TestCatalogsAreComplete(t *testing.T) {
+	fallback, ok := FindCatalog(DefaultLanguage)
+	assert.True(t, ok)
+
+	for _, language := range Languages() {
+		c, _ := FindCatalog(language)
+		for key := range fallback.Messages {
+			_, ok := c.Messages[key]
+			assert.True(t, ok, "message %s missing from catalog %s", key, language)
+		}
+		for _, month := range c.Months {
+			assert.NotEmpty(t, month, "month missing from catalog %s", language)
+		}
+	}
+}
+
+func TestValidateLanguage(t *testing.T) {
+	assert.NoError(t, ValidateLanguage("lang", LanguageEnglish))
+	assert.Error(t, ValidateLanguage("lang", "xx"))
+	assert.Error(t, ValidateLanguage("lang", ""))
+}
+
+func TestRegisterCatalogTwice(t *testing.T) {
+	assert.Panics(t, func() {
+		RegisterCatalog(&MessageCatalog{Language: LanguageEnglish})
+	})
+}
@@ -0,0 +1,234 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	DentitionPermanent = "PERMANENT"
+	DentitionPrimary   = "PRIMARY"
+
+	ToothStatusSound               = "SOUND"
+	ToothStatusDecayed             = "DECAYED"
+	ToothStatusFilled              = "FILLED"
+	ToothStatusMissing             = "MISSING"
+	ToothStatusExtractionIndicated = "EXTRACTION_INDICATED"
+	ToothStatusUnerupted           = "UNERUPTED"
+
+	SurfaceOcclusal = "OCCLUSAL"
+	SurfaceMesial   = "MESIAL"
+	SurfaceDistal   = "DISTAL"
+	SurfaceBuccal   = "BUCCAL"
+	SurfaceLingual  = "LINGUAL"
+
+	SurfaceConditionSound    = "SOUND"
+	SurfaceConditionCaries   = "CARIES"
+	SurfaceConditionFilling  = "FILLING"
+	SurfaceConditionSealant  = "SEALANT"
+	SurfaceConditionFracture = "FRACTURE"
+
+	// invalidToothNumberMessage is the validation message of a tooth number
+	// that is not an FDI code, formatted with the number
+	invalidToothNumberMessage = "invalid tooth number %d, expecting an FDI tooth code (11-48 permanent, 51-85 primary)"
+)
+
+// ToothStatuses lists the statuses a tooth can be charted with
+var ToothStatuses = []string{
+	ToothStatusSound,
+	ToothStatusDecayed,
+	ToothStatusFilled,
+	ToothStatusMissing,
+	ToothStatusExtractionIndicated,
+	ToothStatusUnerupted,
+}
+
+// ToothSurfaces lists the surfaces of a tooth
+var ToothSurfaces = []string{
+	SurfaceOcclusal,
+	SurfaceMesial,
+	SurfaceDistal,
+	SurfaceBuccal,
+	SurfaceLingual,
+}
+
+// SurfaceConditions lists the conditions a tooth surface can be charted with
+var SurfaceConditions = []string{
+	SurfaceConditionSound,
+	SurfaceConditionCaries,
+	SurfaceConditionFilling,
+	SurfaceConditionSealant,
+	SurfaceConditionFracture,
+}
+
+// Odontogram is the dental chart of a survey. Teeth that are not charted are
+// taken to be sound.
+type Odontogram struct {
+	SurveyID string
+	Teeth    []*Tooth
+}
+
+// Tooth is the charted status of a tooth, identified by its FDI code
+type Tooth struct {
+	SurveyID    string `db:"survey_id"`
+	ToothNumber int32  `db:"tooth_number"`
+	Status      string
+	Surfaces    []*ToothSurface
+}
+
+// ToothSurface is the charted condition of a surface of a tooth
+type ToothSurface struct {
+	SurveyID    string `db:"survey_id"`
+	ToothNumber int32  `db:"tooth_number"`
+	Surface     string
+	Condition   string
+}
+
+// ToothInput is the input for a charted tooth
+type ToothInput struct {
+	ToothNumber int32
+	Status      string
+	Surfaces    *[]*ToothSurfaceInput
+}
+
+// ToothSurfaceInput is the input for a charted tooth surface
+type ToothSurfaceInput struct {
+	Surface   string
+	Condition string
+}
+
+// Dentition returns whether the tooth belongs to the primary or the permanent
+// dentition.
+func (t *Tooth) Dentition() string {
+	if IsPrimaryTooth(t.ToothNumber) {
+		return DentitionPrimary
+	}
+	return DentitionPermanent
+}
+
+// Surface returns the condition of the given surface of the tooth
+func (t *Tooth) Surface(surface string) string {
+	for _, s := range t.Surfaces {
+		if s.Surface == surface {
+			return s.Condition
+		}
+	}
+	return SurfaceConditionSound
+}
+
+// Tooth returns the charted tooth with the given FDI code, or a sound tooth if
+// it is not charted.
+func (o *Odontogram) Tooth(toothNumber int32) *Tooth {
+	for _, tooth := range o.Teeth {
+		if tooth.ToothNumber == toothNumber {
+			return tooth
+		}
+	}
+	return &Tooth{SurveyID: o.SurveyID, ToothNumber: toothNumber, Status: ToothStatusSound}
+}
+
+// ChartRows returns the FDI codes of every tooth in the order they are drawn
+// on a dental chart, from the upper permanent row down to the lower permanent
+// row, each row running from the patient's right to left.
+func ChartRows() [][]int32 {
+	return [][]int32{
+		chartRow(1, 2, 8),
+		chartRow(5, 6, 5),
+		chartRow(8, 7, 5),
+		chartRow(4, 3, 8),
+	}
+}
+
+func chartRow(rightQuadrant int32, leftQuadrant int32, teeth int32) []int32 {
+	row := make([]int32, 0, teeth*2)
+	for i := teeth; i >= 1; i-- {
+		row = append(row, rightQuadrant*10+i)
+	}
+	for i := int32(1); i <= teeth; i++ {
+		row = append(row, leftQuadrant*10+i)
+	}
+	return row
+}
+
+// Validate makes sure every tooth is an FDI code charted once with known
+// statuses and surface conditions, and that absent teeth have no surfaces
+// charted.
+func (o *Odontogram) Validate() error {
+	v := &validator{}
+	charted := make(map[int32]int)
+
+	for i, tooth := range o.Teeth {
+		field := fmt.Sprintf("teeth[%d]", i)
+
+		if !IsValidToothNumber(tooth.ToothNumber) {
+			v.addf(field+".toothNumber", invalidToothNumberMessage, tooth.ToothNumber)
+		} else if j, ok := charted[tooth.ToothNumber]; ok {
+			v.addf(field+".toothNumber", "tooth %d is already charted in teeth[%d]", tooth.ToothNumber, j)
+		} else {
+			charted[tooth.ToothNumber] = i
+		}
+
+		if !isOneOf(tooth.Status, ToothStatuses) {
+			v.addf(field+".status", "invalid status %s, expecting one of %s", tooth.Status, strings.Join(ToothStatuses, ", "))
+		}
+
+		if len(tooth.Surfaces) > 0 && (tooth.Status == ToothStatusMissing || tooth.Status == ToothStatusUnerupted) {
+			v.addf(field+".surfaces", "surfaces cannot be charted on a tooth with status %s", tooth.Status)
+			continue
+		}
+
+		surfaces := make(map[string]bool)
+		for j, surface := range tooth.Surfaces {
+			surfaceField := fmt.Sprintf("%s.surfaces[%d]", field, j)
+
+			if !isOneOf(surface.Surface, ToothSurfaces) {
+				v.addf(surfaceField+".surface", "invalid surface %s, expecting one of %s", surface.Surface, strings.Join(ToothSurfaces, ", "))
+			} else if surfaces[surface.Surface] {
+				v.addf(surfaceField+".surface", "surface %s is charted more than once", surface.Surface)
+			}
+			surfaces[surface.Surface] = true
+
+			if !isOneOf(surface.Condition, SurfaceConditions) {
+				v.addf(surfaceField+".condition", "invalid condition %s, expecting one of %s", surface.Condition, strings.Join(SurfaceConditions, ", "))
+			}
+		}
+	}
+
+	return v.err()
+}
+
+// NewOdontogramFromInput charts the given teeth for a survey
+func NewOdontogramFromInput(surveyID string, input []*ToothInput) (Odontogram, error) {
+	o := Odontogram{
+		SurveyID: surveyID,
+		Teeth:    make([]*Tooth, len(input)),
+	}
+
+	for i, toothInput := range input {
+		tooth := &Tooth{
+			SurveyID:    surveyID,
+			ToothNumber: toothInput.ToothNumber,
+			Status:      toothInput.Status,
+			Surfaces:    make([]*ToothSurface, 0),
+		}
+
+		if toothInput.Surfaces != nil {
+			for _, surfaceInput := range *toothInput.Surfaces {
+				tooth.Surfaces = append(tooth.Surfaces, &ToothSurface{
+					SurveyID:    surveyID,
+					ToothNumber: toothInput.ToothNumber,
+					Surface:     surfaceInput.Surface,
+					Condition:   surfaceInput.Condition,
+				})
+			}
+		}
+
+		o.Teeth[i] = tooth
+	}
+
+	if err := o.Validate(); err != nil {
+		return Odontogram{}, err
+	}
+
+	return o, nil
+}
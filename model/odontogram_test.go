@@ -0,0 +1,139 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getValidToothInputs() []*ToothInput {
+	return []*ToothInput{
+		{
+			ToothNumber: 16,
+			Status:      ToothStatusDecayed,
+			Surfaces: &[]*ToothSurfaceInput{
+				{Surface: SurfaceOcclusal, Condition: SurfaceConditionCaries},
+				{Surface: SurfaceMesial, Condition: SurfaceConditionFilling},
+			},
+		},
+		{ToothNumber: 36, Status: ToothStatusMissing},
+		{ToothNumber: 55, Status: ToothStatusExtractionIndicated},
+	}
+}
+
+func TestOdontogram(t *testing.T) {
+
+	t.Run("FromInput", func(t *testing.T) {
+		sut, err := NewOdontogramFromInput(surveyID, getValidToothInputs())
+
+		assert.Nil(t, err)
+		assert.Len(t, sut.Teeth, 3)
+		assert.Equal(t, surveyID, sut.Teeth[0].Surfaces[1].SurveyID)
+		assert.Equal(t, int32(16), sut.Teeth[0].Surfaces[1].ToothNumber)
+		assert.Equal(t, SurfaceConditionFilling, sut.Teeth[0].Surface(SurfaceMesial))
+		assert.Equal(t, SurfaceConditionSound, sut.Teeth[0].Surface(SurfaceDistal))
+	})
+
+	t.Run("Dentition", func(t *testing.T) {
+		assert.Equal(t, DentitionPermanent, (&Tooth{ToothNumber: 16}).Dentition())
+		assert.Equal(t, DentitionPrimary, (&Tooth{ToothNumber: 55}).Dentition())
+	})
+
+	t.Run("UnchartedToothIsSound", func(t *testing.T) {
+		sut, _ := NewOdontogramFromInput(surveyID, getValidToothInputs())
+
+		tooth := sut.Tooth(21)
+
+		assert.Equal(t, int32(21), tooth.ToothNumber)
+		assert.Equal(t, ToothStatusSound, tooth.Status)
+		assert.Equal(t, ToothStatusMissing, sut.Tooth(36).Status)
+	})
+
+	t.Run("ChartRowsCoverEveryTooth", func(t *testing.T) {
+		teeth := make(map[int32]bool)
+		for _, row := range ChartRows() {
+			for _, toothNumber := range row {
+				assert.True(t, IsValidToothNumber(toothNumber))
+				teeth[toothNumber] = true
+			}
+		}
+
+		assert.Len(t, teeth, MaxPermanentTeeth+MaxPrimaryTeeth)
+		assert.Equal(t, []int32{18, 17, 16, 15, 14, 13, 12, 11, 21, 22, 23, 24, 25, 26, 27, 28}, ChartRows()[0])
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+
+		t.Run("InvalidToothNumber", func(t *testing.T) {
+			input := getValidToothInputs()
+			input[0].ToothNumber = 19
+
+			_, err := NewOdontogramFromInput(surveyID, input)
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "teeth[0].toothNumber", err.(*Error).Fields[0].Field)
+		})
+
+		t.Run("DuplicateTooth", func(t *testing.T) {
+			input := getValidToothInputs()
+			input[2].ToothNumber = 16
+
+			_, err := NewOdontogramFromInput(surveyID, input)
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "tooth 16 is already charted in teeth[0]", err.Error())
+		})
+
+		t.Run("InvalidStatus", func(t *testing.T) {
+			input := getValidToothInputs()
+			input[1].Status = "BROKEN"
+
+			_, err := NewOdontogramFromInput(surveyID, input)
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "teeth[1].status", err.(*Error).Fields[0].Field)
+		})
+
+		t.Run("SurfacesOnMissingTooth", func(t *testing.T) {
+			input := getValidToothInputs()
+			input[1].Surfaces = &[]*ToothSurfaceInput{{Surface: SurfaceOcclusal, Condition: SurfaceConditionCaries}}
+
+			_, err := NewOdontogramFromInput(surveyID, input)
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "surfaces cannot be charted on a tooth with status MISSING", err.Error())
+		})
+
+		t.Run("DuplicateSurface", func(t *testing.T) {
+			input := getValidToothInputs()
+			(*input[0].Surfaces)[1].Surface = SurfaceOcclusal
+
+			_, err := NewOdontogramFromInput(surveyID, input)
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "teeth[0].surfaces[1].surface", err.(*Error).Fields[0].Field)
+			assert.Equal(t, "surface OCCLUSAL is charted more than once", err.Error())
+		})
+
+		t.Run("InvalidCondition", func(t *testing.T) {
+			input := getValidToothInputs()
+			(*input[0].Surfaces)[0].Condition = "ROTTEN"
+
+			_, err := NewOdontogramFromInput(surveyID, input)
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "teeth[0].surfaces[0].condition", err.(*Error).Fields[0].Field)
+		})
+
+		t.Run("CollectsAllErrors", func(t *testing.T) {
+			input := getValidToothInputs()
+			input[0].ToothNumber = 99
+			input[1].Status = "BROKEN"
+
+			_, err := NewOdontogramFromInput(surveyID, input)
+
+			assert.NotNil(t, err)
+			assert.Len(t, err.(*Error).Fields, 2)
+		})
+	})
+}
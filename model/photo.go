@@ -0,0 +1,156 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	PhotoContentTypeJPEG = "image/jpeg"
+	PhotoContentTypePNG  = "image/png"
+
+	// DefaultMaxPhotoSize is the largest photo accepted when the config does
+	// not set one, in bytes
+	DefaultMaxPhotoSize = 10 << 20
+
+	// MaxPhotoPixels is the largest photo accepted in pixels. A small file can
+	// hold a huge image, so the dimensions are checked before it is decoded.
+	MaxPhotoPixels = 50 * 1000 * 1000
+)
+
+// PhotoContentTypes lists the content types of the photos that can be attached
+// to a survey
+var PhotoContentTypes = []string{
+	PhotoContentTypeJPEG,
+	PhotoContentTypePNG,
+}
+
+// photoExtensions is the file extension of the stored photo of every content
+// type
+var photoExtensions = map[string]string{
+	PhotoContentTypeJPEG: "jpg",
+	PhotoContentTypePNG:  "png",
+}
+
+// Photo is a clinical photo attached to a survey, or to one of its cases. The
+// photo and its thumbnail are kept in storage under their keys.
+type Photo struct {
+	ID              string
+	SurveyID        string  `db:"survey_id"`
+	CaseID          *string `db:"case_id"`
+	ContentType     string  `db:"content_type"`
+	Size            int64   `db:"size"`
+	Width           int32   `db:"width"`
+	Height          int32   `db:"height"`
+	StorageKey      string  `db:"storage_key"`
+	ThumbnailKey    string  `db:"thumbnail_key"`
+	Caption         *string `db:"caption"`
+	IncludeInReport bool    `db:"include_in_report"`
+	UploadedBy      *string `db:"uploaded_by"`
+	CreatedAt       string  `db:"created_at"`
+}
+
+// PhotoUpload is a photo sent by a surveyor, with the content type detected
+// from its content
+type PhotoUpload struct {
+	SurveyID        string
+	CaseID          *string
+	Caption         *string
+	IncludeInReport bool
+	UploadedBy      *string
+	ContentType     string
+	Size            int64
+}
+
+// PhotoUpdate is a change of the caption of a photo or of whether it is
+// included in the student report
+type PhotoUpdate struct {
+	ID              string
+	Caption         *string
+	IncludeInReport *bool
+}
+
+// Validate makes sure the photo is attached to a survey, is a JPEG or PNG
+// image and is not larger than maxSize bytes
+func (u *PhotoUpload) Validate(maxSize int64) error {
+	v := &validator{}
+
+	if u.SurveyID == "" {
+		v.add("surveyId", "survey ID is required")
+	}
+
+	if !isOneOf(u.ContentType, PhotoContentTypes) {
+		v.addf("contentType", "invalid content type %s, expecting one of %s", u.ContentType, strings.Join(PhotoContentTypes, ", "))
+	}
+
+	if u.Size == 0 {
+		v.add("photo", "photo is empty")
+	} else if u.Size > maxSize {
+		v.addf("photo", "photo is larger than %s", formatBytes(maxSize))
+	}
+
+	if u.Caption != nil && len(*u.Caption) > 255 {
+		v.add("caption", "caption cannot be longer than 255 characters")
+	}
+
+	return v.err()
+}
+
+// Validate makes sure the caption fits
+func (u *PhotoUpdate) Validate() error {
+	v := &validator{}
+
+	if u.Caption != nil && len(*u.Caption) > 255 {
+		v.add("caption", "caption cannot be longer than 255 characters")
+	}
+
+	return v.err()
+}
+
+// NewPhotoFromUpload validates the upload and names the storage keys of the
+// photo and its thumbnail after the survey, so the photos of a survey are kept
+// together.
+func NewPhotoFromUpload(u *PhotoUpload, maxSize int64) (*Photo, error) {
+	if err := u.Validate(maxSize); err != nil {
+		return nil, err
+	}
+
+	id := uuid.NewV4().String()
+
+	return &Photo{
+		ID:              id,
+		SurveyID:        u.SurveyID,
+		CaseID:          u.CaseID,
+		ContentType:     u.ContentType,
+		Size:            u.Size,
+		StorageKey:      fmt.Sprintf("surveys/%s/%s.%s", u.SurveyID, id, photoExtensions[u.ContentType]),
+		ThumbnailKey:    fmt.Sprintf("surveys/%s/%s_thumbnail.jpg", u.SurveyID, id),
+		Caption:         u.Caption,
+		IncludeInReport: u.IncludeInReport,
+		UploadedBy:      u.UploadedBy,
+	}, nil
+}
+
+// ValidateImageDimensions makes sure an image is not larger than maxPixels, so
+// it can be decoded without exhausting memory
+func ValidateImageDimensions(field string, width int, height int, maxPixels int64) error {
+	if width <= 0 || height <= 0 {
+		return NewFieldError(field, fmt.Sprintf("%s has no pixels", field))
+	}
+	if int64(width)*int64(height) > maxPixels {
+		return NewFieldError(field, fmt.Sprintf("%s is %dx%d pixels, larger than the %d megapixels accepted", field, width, height, maxPixels/1000000))
+	}
+	return nil
+}
+
+func formatBytes(size int64) string {
+	if size >= 1<<20 && size%(1<<20) == 0 {
+		return fmt.Sprintf("%d MB", size>>20)
+	}
+	if size >= 1<<10 && size%(1<<10) == 0 {
+		return fmt.Sprintf("%d KB", size>>10)
+	}
+	return fmt.Sprintf("%d bytes", size)
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhoto(t *testing.T) {
+
+	t.Run("NewPhotoFromUpload", func(t *testing.T) {
+		caseID := "case"
+		photo, err := NewPhotoFromUpload(&PhotoUpload{SurveyID: "survey", CaseID: &caseID, ContentType: PhotoContentTypePNG, Size: 1024}, DefaultMaxPhotoSize)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, photo.ID)
+		assert.Equal(t, &caseID, photo.CaseID)
+		assert.Equal(t, "surveys/survey/"+photo.ID+".png", photo.StorageKey)
+		assert.Equal(t, "surveys/survey/"+photo.ID+"_thumbnail.jpg", photo.ThumbnailKey)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+
+		t.Run("UnsupportedContentType", func(t *testing.T) {
+			err := (&PhotoUpload{SurveyID: "survey", ContentType: "application/pdf", Size: 1024}).Validate(DefaultMaxPhotoSize)
+			assert.NotNil(t, err)
+			assert.Equal(t, "contentType", err.(*Error).Fields[0].Field)
+		})
+
+		t.Run("TooLarge", func(t *testing.T) {
+			err := (&PhotoUpload{SurveyID: "survey", ContentType: PhotoContentTypeJPEG, Size: 2<<20 + 1}).Validate(2 << 20)
+			assert.NotNil(t, err)
+			assert.Equal(t, "photo", err.(*Error).Fields[0].Field)
+			assert.Equal(t, "photo is larger than 2 MB", err.(*Error).Fields[0].Message)
+		})
+
+		t.Run("MissingSurveyAndEmptyPhoto", func(t *testing.T) {
+			err := (&PhotoUpload{ContentType: PhotoContentTypeJPEG}).Validate(DefaultMaxPhotoSize)
+			assert.NotNil(t, err)
+			assert.Len(t, err.(*Error).Fields, 2)
+		})
+
+		t.Run("LongCaption", func(t *testing.T) {
+			caption := strings.Repeat("a", 256)
+			assert.NotNil(t, (&PhotoUpdate{ID: "photo", Caption: &caption}).Validate())
+		})
+	})
+	t.Run("ImageDimensions", func(t *testing.T) {
+		assert.Nil(t, ValidateImageDimensions("photo", 4000, 3000, MaxPhotoPixels))
+
+		err := ValidateImageDimensions("photo", 60000, 60000, MaxPhotoPixels)
+		assert.NotNil(t, err)
+		assert.Equal(t, "photo", err.(*Error).Fields[0].Field)
+
+		assert.NotNil(t, ValidateImageDimensions("photo", 0, 100, MaxPhotoPixels))
+	})
+}
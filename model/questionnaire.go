@@ -0,0 +1,213 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// LegacyQuestionnaireID is the questionnaire with the 16 risk questions
+	// surveys were recorded with before questionnaires were configurable.
+	LegacyQuestionnaireID = "5f0f6a52-3c55-4c56-9d8e-2b7c1f0c9a11"
+
+	// DefaultLocale is used for questionnaire texts when no locale is requested
+	// or the text is not available in the requested locale.
+	DefaultLocale = "id"
+)
+
+// LocalizedText maps locales to the text in that locale
+type LocalizedText map[string]string
+
+// Get returns the text in the given locale, falling back to the default locale
+func (t LocalizedText) Get(locale *string) *string {
+	if locale != nil {
+		if text, ok := t[*locale]; ok {
+			return &text
+		}
+	}
+	if text, ok := t[DefaultLocale]; ok {
+		return &text
+	}
+	return nil
+}
+
+// Questionnaire is a versioned survey template
+type Questionnaire struct {
+	ID        string
+	Code      string
+	Version   int32
+	ScoreMin  *float64 `db:"score_min"`
+	ScoreMax  *float64 `db:"score_max"`
+	CreatedAt string   `db:"created_at"`
+	Sections  []*QuestionnaireSection
+}
+
+// QuestionnaireSection is a group of questions of a questionnaire
+type QuestionnaireSection struct {
+	ID              string
+	QuestionnaireID string `db:"questionnaire_id"`
+	Code            string
+	Position        int32
+	Title           LocalizedText `db:"-"`
+	Questions       []*Question
+}
+
+// Question is a question of a questionnaire
+type Question struct {
+	ID        string
+	SectionID string `db:"section_id"`
+	Code      string
+	Position  int32
+	Required  bool
+	Weight    float64
+	Text      LocalizedText `db:"-"`
+	Options   []*AnswerOption
+}
+
+// AnswerOption is an allowed answer of a question
+type AnswerOption struct {
+	ID         string
+	QuestionID string `db:"question_id"`
+	Value      string
+	Position   int32
+	Score      float64
+	Label      LocalizedText `db:"-"`
+}
+
+// Question returns the question with the given code, or nil if there is none
+func (q *Questionnaire) Question(code string) *Question {
+	for _, section := range q.Sections {
+		for _, question := range section.Questions {
+			if question.Code == code {
+				return question
+			}
+		}
+	}
+	return nil
+}
+
+// Option returns the answer option with the given value, or nil if there is none
+func (q *Question) Option(value string) *AnswerOption {
+	for _, option := range q.Options {
+		if option.Value == value {
+			return option
+		}
+	}
+	return nil
+}
+
+// Apply validates the answers of the survey against the questionnaire, scores
+// every answer and calculates the subjective score of the survey.
+func (q *Questionnaire) Apply(s *Survey) error {
+	var (
+		fields   []FieldError
+		answered = make(map[string]bool)
+		score    float64
+	)
+
+	for i, answer := range s.Answers {
+		question := q.Question(answer.QuestionCode)
+		if question == nil {
+			fields = append(fields, FieldError{
+				Field:   fmt.Sprintf("answers[%d].questionCode", i),
+				Message: fmt.Sprintf("unknown question %s", answer.QuestionCode),
+			})
+			continue
+		}
+
+		if answered[question.Code] {
+			fields = append(fields, FieldError{
+				Field:   fmt.Sprintf("answers[%d].questionCode", i),
+				Message: fmt.Sprintf("question %s is answered more than once", question.Code),
+			})
+			continue
+		}
+		answered[question.Code] = true
+
+		option := question.Option(answer.Value)
+		if option == nil {
+			fields = append(fields, FieldError{
+				Field:   fmt.Sprintf("answers[%d].value", i),
+				Message: fmt.Sprintf("invalid answer %s to question %s, expecting one of %s", answer.Value, question.Code, strings.Join(question.optionValues(), ", ")),
+			})
+			continue
+		}
+
+		answer.SurveyID = s.ID
+		answer.QuestionID = question.ID
+		answer.Score = question.Weight * option.Score
+		score += answer.Score
+	}
+
+	for _, section := range q.Sections {
+		for _, question := range section.Questions {
+			if question.Required && !answered[question.Code] {
+				fields = append(fields, FieldError{
+					Field:   "answers",
+					Message: fmt.Sprintf("question %s is required", question.Code),
+				})
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		return NewValidationError(fields...)
+	}
+
+	s.QuestionnaireID = &q.ID
+	s.SubjectiveScore = q.normalizeScore(score)
+
+	return nil
+}
+
+// normalizeScore scales a weighted score to 0..100 between the score bounds
+// of the questionnaire.
+func (q *Questionnaire) normalizeScore(score float64) int32 {
+	min, max := q.scoreBounds()
+	if max <= min {
+		return 0
+	}
+	return int32((score - min) * 100 / (max - min))
+}
+
+func (q *Questionnaire) scoreBounds() (min float64, max float64) {
+	if q.ScoreMin != nil && q.ScoreMax != nil {
+		return *q.ScoreMin, *q.ScoreMax
+	}
+
+	for _, section := range q.Sections {
+		for _, question := range section.Questions {
+			if len(question.Options) == 0 {
+				continue
+			}
+
+			lowest, highest := question.Options[0].Score, question.Options[0].Score
+			for _, option := range question.Options[1:] {
+				if option.Score < lowest {
+					lowest = option.Score
+				}
+				if option.Score > highest {
+					highest = option.Score
+				}
+			}
+
+			// an optional question left unanswered scores nothing
+			if !question.Required && lowest > 0 {
+				lowest = 0
+			}
+
+			min += question.Weight * lowest
+			max += question.Weight * highest
+		}
+	}
+
+	return min, max
+}
+
+func (q *Question) optionValues() []string {
+	values := make([]string, len(q.Options))
+	for i, option := range q.Options {
+		values[i] = option.Value
+	}
+	return values
+}
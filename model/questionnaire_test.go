@@ -0,0 +1,112 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getLegacyQuestionnaire() *Questionnaire {
+	scoreMin, scoreMax := float64(16), float64(37)
+	q := &Questionnaire{
+		ID:       LegacyQuestionnaireID,
+		Code:     "caries-risk",
+		Version:  1,
+		ScoreMin: &scoreMin,
+		ScoreMax: &scoreMax,
+	}
+
+	for sectionNumber, questionCount := range []int{7, 9} {
+		section := &QuestionnaireSection{Code: fmt.Sprintf("s%d", sectionNumber+1)}
+		for i := 1; i <= questionCount; i++ {
+			code := fmt.Sprintf("s%dq%d", sectionNumber+1, i)
+			section.Questions = append(section.Questions, &Question{
+				ID:       code + "_id",
+				Code:     code,
+				Required: true,
+				Weight:   1,
+				Options: []*AnswerOption{
+					{Value: "Low", Score: 1},
+					{Value: "Medium", Score: 2},
+					{Value: "High", Score: 3},
+				},
+			})
+		}
+		q.Sections = append(q.Sections, section)
+	}
+
+	return q
+}
+
+func getLegacyAnswers(value string) []*SurveyAnswer {
+	answers := make([]*SurveyAnswer, 0)
+	for _, code := range legacyQuestionCodes {
+		answers = append(answers, &SurveyAnswer{QuestionCode: code, Value: value})
+	}
+	return answers
+}
+
+func TestQuestionnaireApply(t *testing.T) {
+
+	t.Run("LegacyScore", func(t *testing.T) {
+		survey := &Survey{ID: "fake_survey_id", Answers: getLegacyAnswers("High")}
+		err := getLegacyQuestionnaire().Apply(survey)
+
+		assert.Nil(t, err)
+		assert.Equal(t, int32((48-16)*100/(37-16)), survey.SubjectiveScore)
+		assert.Equal(t, LegacyQuestionnaireID, *survey.QuestionnaireID)
+		assert.Equal(t, "s1q1_id", survey.Answers[0].QuestionID)
+		assert.Equal(t, "fake_survey_id", survey.Answers[0].SurveyID)
+		assert.Equal(t, float64(3), survey.Answers[0].Score)
+	})
+
+	t.Run("ComputedScoreBounds", func(t *testing.T) {
+		q := getLegacyQuestionnaire()
+		q.ScoreMin, q.ScoreMax = nil, nil
+		q.Sections[0].Questions[0].Weight = 2
+
+		survey := &Survey{Answers: getLegacyAnswers("Medium")}
+		err := q.Apply(survey)
+
+		assert.Nil(t, err)
+		assert.Equal(t, int32(50), survey.SubjectiveScore)
+	})
+
+	t.Run("UnknownQuestion", func(t *testing.T) {
+		survey := &Survey{Answers: append(getLegacyAnswers("Low"), &SurveyAnswer{QuestionCode: "s3q1", Value: "Low"})}
+		err := getLegacyQuestionnaire().Apply(survey)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "answers[16].questionCode", err.(*Error).Fields[0].Field)
+	})
+
+	t.Run("InvalidAnswer", func(t *testing.T) {
+		answers := getLegacyAnswers("Low")
+		answers[2].Value = "Very High"
+		err := getLegacyQuestionnaire().Apply(&Survey{Answers: answers})
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "answers[2].value", err.(*Error).Fields[0].Field)
+		assert.Equal(t, "invalid answer Very High to question s1q3, expecting one of Low, Medium, High", err.Error())
+	})
+
+	t.Run("MissingRequiredAnswer", func(t *testing.T) {
+		answers := getLegacyAnswers("Low")
+		err := getLegacyQuestionnaire().Apply(&Survey{Answers: answers[1:]})
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "answers", err.(*Error).Fields[0].Field)
+		assert.Equal(t, "question s1q1 is required", err.Error())
+	})
+}
+
+func TestLocalizedText(t *testing.T) {
+	text := LocalizedText{"id": "Rendah", "en": "Low"}
+	en, fr := "en", "fr"
+
+	assert.Equal(t, "Low", *text.Get(&en))
+	assert.Equal(t, "Rendah", *text.Get(&fr))
+	assert.Equal(t, "Rendah", *text.Get(nil))
+	assert.Nil(t, LocalizedText{}.Get(nil))
+}
@@ -0,0 +1,134 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	RecallStatusDue       = "DUE"
+	RecallStatusScheduled = "SCHEDULED"
+	RecallStatusDone      = "DONE"
+	RecallStatusSkipped   = "SKIPPED"
+)
+
+// RecallStatuses lists the statuses of a recall
+var RecallStatuses = []string{
+	RecallStatusDue,
+	RecallStatusScheduled,
+	RecallStatusDone,
+	RecallStatusSkipped,
+}
+
+// recallMonths is the number of months until the next visit for every risk
+// category, the shortest interval the survey report suggests to operators.
+var recallMonths = map[string]int{
+	RiskCategoryLow:    6,
+	RiskCategoryMedium: 4,
+	RiskCategoryHigh:   3,
+}
+
+// Recall is the next visit due for a student after a survey. A recall stays
+// open while it is due or scheduled, and is done once the student is surveyed
+// again.
+type Recall struct {
+	ID                string
+	StudentID         string  `db:"student_id"`
+	SurveyID          string  `db:"survey_id"`
+	SurveyDate        string  `db:"survey_date"`
+	RiskCategory      string  `db:"risk_category"`
+	DueDate           string  `db:"due_date"`
+	Status            string  `db:"status"`
+	ScheduledDate     *string `db:"scheduled_date"`
+	Note              *string `db:"note"`
+	CompletedSurveyID *string `db:"completed_survey_id"`
+	CreatedAt         string  `db:"created_at"`
+	UpdatedAt         string  `db:"updated_at"`
+	// SchoolID and TimeZone are the school of the student and its time zone,
+	// loaded with the recall
+	SchoolID string `db:"school_id"`
+	TimeZone string `db:"time_zone"`
+}
+
+// RecallUpdate is a change of the status of a recall by a coordinator
+type RecallUpdate struct {
+	ID            string
+	Status        string
+	ScheduledDate *string
+	Note          *string
+}
+
+// NewRecall schedules the next visit of the student of a survey from its IDCRA
+// risk category, the category recallMonths is set out for. Surveys without a
+// stored IDCRA assessment are assessed from their subjective score.
+func NewRecall(s *Survey) (*Recall, error) {
+	date, err := time.Parse("2006-01-02", s.Date)
+	if err != nil {
+		return nil, NewFieldError("date", "invalid date format, expecting yyyy-mm-dd")
+	}
+
+	category := s.RiskCategory(RiskModelIDCRA)
+
+	return &Recall{
+		ID:           uuid.NewV4().String(),
+		StudentID:    s.StudentID,
+		SurveyID:     s.ID,
+		SurveyDate:   s.Date,
+		RiskCategory: category,
+		DueDate:      date.AddDate(0, recallMonths[category], 0).Format("2006-01-02"),
+		Status:       RecallStatusDue,
+	}, nil
+}
+
+// IsOverdue reports whether an open recall is past its due date on the date
+// it is now at the school of the student. Recalls without a known time zone
+// are compared in UTC.
+func (r *Recall) IsOverdue(now time.Time) bool {
+	if r.Status != RecallStatusDue && r.Status != RecallStatusScheduled {
+		return false
+	}
+	dueDate, err := parseStoredDate(r.DueDate)
+	if err != nil {
+		return false
+	}
+
+	location, err := time.LoadLocation(r.TimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+	local := now.In(location)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	return dueDate.Before(today)
+}
+
+// Validate makes sure the status is known and a scheduled recall has a valid
+// scheduled date
+func (u *RecallUpdate) Validate() error {
+	v := &validator{}
+
+	if !isOneOf(u.Status, RecallStatuses) {
+		v.addf("status", "invalid status %s, expecting one of %s", u.Status, strings.Join(RecallStatuses, ", "))
+	}
+
+	if u.ScheduledDate != nil {
+		if _, err := time.Parse("2006-01-02", *u.ScheduledDate); err != nil {
+			v.add("scheduledDate", "invalid date format, expecting yyyy-mm-dd")
+		}
+	} else if u.Status == RecallStatusScheduled {
+		v.add("scheduledDate", fmt.Sprintf("scheduled date is required for status %s", RecallStatusScheduled))
+	}
+
+	return v.err()
+}
+
+// parseStoredDate parses a date that was stored as a date or read back from
+// the database as a timestamp.
+func parseStoredDate(date string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, date); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", date)
+}
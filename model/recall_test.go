@@ -0,0 +1,88 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecall(t *testing.T) {
+
+	t.Run("DueDateFromRiskCategory", func(t *testing.T) {
+		s := &Survey{ID: "survey", StudentID: "student", Date: "2026-01-31", SubjectiveScore: 10}
+
+		recall, err := NewRecall(s)
+		assert.Nil(t, err)
+		assert.Equal(t, RiskCategoryLow, recall.RiskCategory)
+		assert.Equal(t, "2026-07-31", recall.DueDate)
+		assert.Equal(t, RecallStatusDue, recall.Status)
+
+		s.RiskAssessments = []*RiskAssessment{{Model: RiskModelCAMBRA, Category: RiskCategoryMedium}}
+		recall, err = NewRecall(s)
+		assert.Nil(t, err)
+		assert.Equal(t, RiskCategoryLow, recall.RiskCategory)
+
+		s.RiskAssessments = append(s.RiskAssessments, &RiskAssessment{Model: RiskModelIDCRA, Category: RiskCategoryHigh})
+		recall, err = NewRecall(s)
+		assert.Nil(t, err)
+		assert.Equal(t, RiskCategoryHigh, recall.RiskCategory)
+		assert.Equal(t, "2026-05-01", recall.DueDate)
+	})
+
+	t.Run("Overdue", func(t *testing.T) {
+		today := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+		assert.True(t, (&Recall{Status: RecallStatusDue, DueDate: "2026-02-28T00:00:00Z"}).IsOverdue(today))
+		assert.True(t, (&Recall{Status: RecallStatusScheduled, DueDate: "2026-02-28"}).IsOverdue(today))
+		assert.False(t, (&Recall{Status: RecallStatusDue, DueDate: "2026-03-01"}).IsOverdue(today))
+		assert.False(t, (&Recall{Status: RecallStatusSkipped, DueDate: "2026-02-28"}).IsOverdue(today))
+	})
+
+	t.Run("OverdueInSchoolTimeZone", func(t *testing.T) {
+		// already March 1st in Jakarta, still February 28th in UTC
+		now := time.Date(2026, 2, 28, 20, 0, 0, 0, time.UTC)
+
+		assert.True(t, (&Recall{Status: RecallStatusDue, DueDate: "2026-02-28", TimeZone: "Asia/Jakarta"}).IsOverdue(now))
+		assert.False(t, (&Recall{Status: RecallStatusDue, DueDate: "2026-02-28", TimeZone: "UTC"}).IsOverdue(now))
+		assert.False(t, (&Recall{Status: RecallStatusDue, DueDate: "2026-02-28", TimeZone: "America/Los_Angeles"}).IsOverdue(now))
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+
+		t.Run("NoErrors", func(t *testing.T) {
+			scheduledDate := "2026-03-10"
+			sut := &RecallUpdate{ID: "recall", Status: RecallStatusScheduled, ScheduledDate: &scheduledDate}
+
+			assert.Nil(t, sut.Validate())
+		})
+
+		t.Run("InvalidStatus", func(t *testing.T) {
+			sut := &RecallUpdate{ID: "recall", Status: "LATER"}
+
+			err := sut.Validate()
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "invalid status LATER, expecting one of DUE, SCHEDULED, DONE, SKIPPED", err.Error())
+		})
+
+		t.Run("ScheduledWithoutDate", func(t *testing.T) {
+			sut := &RecallUpdate{ID: "recall", Status: RecallStatusScheduled}
+
+			err := sut.Validate()
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "scheduledDate", err.(*Error).Fields[0].Field)
+		})
+
+		t.Run("InvalidScheduledDate", func(t *testing.T) {
+			scheduledDate := "10/03/2026"
+			sut := &RecallUpdate{ID: "recall", Status: RecallStatusScheduled, ScheduledDate: &scheduledDate}
+
+			err := sut.Validate()
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "invalid date format, expecting yyyy-mm-dd", err.Error())
+		})
+	})
+}
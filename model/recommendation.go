@@ -0,0 +1,232 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Recommendation targets are the sections of the survey report a rule adds its
+// text to. Reminder, guidance and supervision sections list the texts of every
+// matching rule, while operator suggestions take the text of the first one.
+const (
+	RecommendationTargetParentReminder    = "PARENT_REMINDER"
+	RecommendationTargetParentGuidance    = "PARENT_GUIDANCE"
+	RecommendationTargetParentSupervision = "PARENT_SUPERVISION"
+	RecommendationTargetTeacherReminder   = "TEACHER_REMINDER"
+	RecommendationTargetTeacherGuidance   = "TEACHER_GUIDANCE"
+	RecommendationTargetOperatorRecurring = "OPERATOR_RECURRING"
+	RecommendationTargetOperatorFluoride  = "OPERATOR_FLUORIDE"
+	RecommendationTargetOperatorDiet      = "OPERATOR_DIET"
+	RecommendationTargetOperatorSealant   = "OPERATOR_SEALANT"
+	RecommendationTargetOperatorART       = "OPERATOR_ART"
+)
+
+// RecommendationTargets lists the report sections rules can target
+var RecommendationTargets = []string{
+	RecommendationTargetParentReminder,
+	RecommendationTargetParentGuidance,
+	RecommendationTargetParentSupervision,
+	RecommendationTargetTeacherReminder,
+	RecommendationTargetTeacherGuidance,
+	RecommendationTargetOperatorRecurring,
+	RecommendationTargetOperatorFluoride,
+	RecommendationTargetOperatorDiet,
+	RecommendationTargetOperatorSealant,
+	RecommendationTargetOperatorART,
+}
+
+// RiskCategories lists the risk categories of surveys
+var RiskCategories = []string{RiskCategoryLow, RiskCategoryMedium, RiskCategoryHigh}
+
+// RecommendationRuleSet is a published version of the recommendation rules.
+// Rule sets never change; changes are published as a new version.
+type RecommendationRuleSet struct {
+	ID        string
+	Version   int32
+	Note      *string
+	CreatedBy *string `db:"created_by"`
+	CreatedAt string  `db:"created_at"`
+	Rules     []*RecommendationRule
+}
+
+// RecommendationRule adds a text to a section of the reports of surveys in a
+// risk category. The optional conditions narrow the rule down to students in
+// an age band, surveys with an answer to a question and surveys with a case of
+// a diagnosis.
+type RecommendationRule struct {
+	ID                   string
+	RuleSetID            string `db:"rule_set_id"`
+	Position             int32
+	RiskCategory         string        `db:"risk_category"`
+	Target               string        `db:"target"`
+	MinAge               *int32        `db:"min_age"`
+	MaxAge               *int32        `db:"max_age"`
+	QuestionCode         *string       `db:"question_code"`
+	AnswerValue          *string       `db:"answer_value"`
+	DiagnosisAndActionID *string       `db:"diagnosis_and_action_id"`
+	Text                 LocalizedText `db:"-"`
+}
+
+// RecommendationFacts are what rules know of a survey
+type RecommendationFacts struct {
+	RiskCategory string
+	// Age is the age of the student in years at the survey date, nil when the
+	// date of birth is unknown
+	Age *int32
+	// Answers maps question codes to the answers of the survey
+	Answers map[string]string
+	// Diagnoses holds the diagnosis and action IDs of the cases of the survey
+	Diagnoses map[string]bool
+}
+
+// RecommendationRuleInput is the input for recommendation rule entity
+type RecommendationRuleInput struct {
+	RiskCategory         string
+	Target               string
+	MinAge               *int32
+	MaxAge               *int32
+	QuestionCode         *string
+	Answer               *string
+	DiagnosisAndActionID *string
+	Texts                []*LocalizedTextInput
+}
+
+// LocalizedTextInput is the text of a rule in a locale
+type LocalizedTextInput struct {
+	Locale string
+	Text   string
+}
+
+// Matches tells whether the rule applies to a survey
+func (r *RecommendationRule) Matches(f RecommendationFacts) bool {
+	if r.RiskCategory != f.RiskCategory {
+		return false
+	}
+	if r.MinAge != nil && (f.Age == nil || *f.Age < *r.MinAge) {
+		return false
+	}
+	if r.MaxAge != nil && (f.Age == nil || *f.Age > *r.MaxAge) {
+		return false
+	}
+	if r.QuestionCode != nil && r.AnswerValue != nil && f.Answers[*r.QuestionCode] != *r.AnswerValue {
+		return false
+	}
+	if r.DiagnosisAndActionID != nil && !f.Diagnoses[*r.DiagnosisAndActionID] {
+		return false
+	}
+	return true
+}
+
+// NewRecommendationRuleSetFromInput validates the rules of a new version,
+// keeping their order. The version itself is set when the rule set is
+// published.
+func NewRecommendationRuleSetFromInput(input []*RecommendationRuleInput, note *string, createdBy string) (RecommendationRuleSet, error) {
+	v := &validator{}
+
+	if len(input) == 0 {
+		v.add("rules", "at least one rule is required")
+	}
+	if note != nil && len(*note) > 255 {
+		v.add("note", "note must be at most 255 characters")
+	}
+
+	ruleSet := RecommendationRuleSet{
+		Note:      note,
+		CreatedBy: &createdBy,
+		Rules:     make([]*RecommendationRule, 0, len(input)),
+	}
+	for i, ri := range input {
+		rule := ri.validate(v, fmt.Sprintf("rules[%d]", i))
+		rule.Position = int32(i + 1)
+		ruleSet.Rules = append(ruleSet.Rules, rule)
+	}
+
+	if err := v.err(); err != nil {
+		return RecommendationRuleSet{}, err
+	}
+	return ruleSet, nil
+}
+
+func (ri *RecommendationRuleInput) validate(v *validator, field string) *RecommendationRule {
+	if !isOneOf(ri.RiskCategory, RiskCategories) {
+		v.addf(field+".riskCategory", "invalid risk category %s, expecting one of %s", ri.RiskCategory, strings.Join(RiskCategories, ", "))
+	}
+	if !isOneOf(ri.Target, RecommendationTargets) {
+		v.addf(field+".target", "invalid target %s, expecting one of %s", ri.Target, strings.Join(RecommendationTargets, ", "))
+	}
+	if ri.MinAge != nil && *ri.MinAge < 0 {
+		v.add(field+".minAge", "minimum age must not be negative")
+	}
+	if ri.MinAge != nil && ri.MaxAge != nil && *ri.MaxAge < *ri.MinAge {
+		v.add(field+".maxAge", "maximum age must not be less than the minimum age")
+	}
+	if (ri.QuestionCode == nil) != (ri.Answer == nil) {
+		v.add(field+".answer", "question code and answer must be given together")
+	}
+
+	text := make(LocalizedText)
+	for j, t := range ri.Texts {
+		textField := fmt.Sprintf("%s.texts[%d]", field, j)
+		if err := ValidateLanguage(textField+".locale", t.Locale); err != nil {
+			v.merge(err, "")
+			continue
+		}
+		if _, ok := text[t.Locale]; ok {
+			v.addf(textField+".locale", "text in %s is given more than once", t.Locale)
+			continue
+		}
+		if strings.TrimSpace(t.Text) == "" || len(t.Text) > 1000 {
+			v.add(textField+".text", "text must be between 1 and 1000 characters")
+			continue
+		}
+		text[t.Locale] = t.Text
+	}
+	if _, ok := text[DefaultLanguage]; !ok {
+		v.addf(field+".texts", "text in %s is required", DefaultLanguage)
+	}
+
+	return &RecommendationRule{
+		RiskCategory:         ri.RiskCategory,
+		Target:               ri.Target,
+		MinAge:               ri.MinAge,
+		MaxAge:               ri.MaxAge,
+		QuestionCode:         ri.QuestionCode,
+		AnswerValue:          ri.Answer,
+		DiagnosisAndActionID: ri.DiagnosisAndActionID,
+		Text:                 text,
+	}
+}
+
+// Input returns the rule as the input it could be published from
+func (r *RecommendationRule) Input() *RecommendationRuleInput {
+	ri := &RecommendationRuleInput{
+		RiskCategory:         r.RiskCategory,
+		Target:               r.Target,
+		MinAge:               r.MinAge,
+		MaxAge:               r.MaxAge,
+		QuestionCode:         r.QuestionCode,
+		Answer:               r.AnswerValue,
+		DiagnosisAndActionID: r.DiagnosisAndActionID,
+	}
+	locales := make([]string, 0, len(r.Text))
+	for locale := range r.Text {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		ri.Texts = append(ri.Texts, &LocalizedTextInput{Locale: locale, Text: r.Text[locale]})
+	}
+	return ri
+}
+
+// AgeAt returns the age in whole years on a date of someone born on
+// dateOfBirth
+func AgeAt(dateOfBirth time.Time, date time.Time) int32 {
+	age := int32(date.Year() - dateOfBirth.Year())
+	if date.Month() < dateOfBirth.Month() || (date.Month() == dateOfBirth.Month() && date.Day() < dateOfBirth.Day()) {
+		age--
+	}
+	return age
+}
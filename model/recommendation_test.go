@@ -0,0 +1,137 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecommendationRuleMatches(t *testing.T) {
+	age := func(a int32) *int32 { return &a }
+	str := func(s string) *string { return &s }
+
+	facts := RecommendationFacts{
+		RiskCategory: RiskCategoryHigh,
+		Age:          age(7),
+		Answers:      map[string]string{"s1q1": "High"},
+		Diagnoses:    map[string]bool{"pit-fissure": true},
+	}
+
+	tests := []struct {
+		name    string
+		rule    RecommendationRule
+		matches bool
+	}{
+		{"RiskCategory", RecommendationRule{RiskCategory: RiskCategoryHigh}, true},
+		{"OtherRiskCategory", RecommendationRule{RiskCategory: RiskCategoryLow}, false},
+		{"InAgeBand", RecommendationRule{RiskCategory: RiskCategoryHigh, MinAge: age(6), MaxAge: age(7)}, true},
+		{"BelowAgeBand", RecommendationRule{RiskCategory: RiskCategoryHigh, MinAge: age(8)}, false},
+		{"AboveAgeBand", RecommendationRule{RiskCategory: RiskCategoryHigh, MaxAge: age(6)}, false},
+		{"Answer", RecommendationRule{RiskCategory: RiskCategoryHigh, QuestionCode: str("s1q1"), AnswerValue: str("High")}, true},
+		{"OtherAnswer", RecommendationRule{RiskCategory: RiskCategoryHigh, QuestionCode: str("s1q1"), AnswerValue: str("Low")}, false},
+		{"UnansweredQuestion", RecommendationRule{RiskCategory: RiskCategoryHigh, QuestionCode: str("s2q1"), AnswerValue: str("High")}, false},
+		{"Diagnosis", RecommendationRule{RiskCategory: RiskCategoryHigh, DiagnosisAndActionID: str("pit-fissure")}, true},
+		{"MissingDiagnosis", RecommendationRule{RiskCategory: RiskCategoryHigh, DiagnosisAndActionID: str("caries")}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.matches, test.rule.Matches(facts))
+		})
+	}
+
+	t.Run("UnknownAge", func(t *testing.T) {
+		rule := RecommendationRule{RiskCategory: RiskCategoryHigh, MinAge: age(6)}
+		assert.False(t, rule.Matches(RecommendationFacts{RiskCategory: RiskCategoryHigh}))
+	})
+}
+
+func TestNewRecommendationRuleSetFromInput(t *testing.T) {
+	min, max := int32(6), int32(5)
+	question := "s1q1"
+
+	t.Run("Valid", func(t *testing.T) {
+		ruleSet, err := NewRecommendationRuleSetFromInput([]*RecommendationRuleInput{
+			{RiskCategory: RiskCategoryLow, Target: RecommendationTargetParentGuidance, Texts: []*LocalizedTextInput{{Locale: LanguageIndonesian, Text: "Sikat gigi"}}},
+			{RiskCategory: RiskCategoryHigh, Target: RecommendationTargetOperatorART, Texts: []*LocalizedTextInput{{Locale: LanguageIndonesian, Text: "Restorasi"}, {Locale: LanguageEnglish, Text: "Restore"}}},
+		}, nil, "user")
+
+		assert.NoError(t, err)
+		assert.Len(t, ruleSet.Rules, 2)
+		assert.Equal(t, int32(1), ruleSet.Rules[0].Position)
+		assert.Equal(t, int32(2), ruleSet.Rules[1].Position)
+		assert.Equal(t, "Restore", ruleSet.Rules[1].Text[LanguageEnglish])
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := NewRecommendationRuleSetFromInput([]*RecommendationRuleInput{
+			{RiskCategory: "severe", Target: "PRINCIPAL", MinAge: &min, MaxAge: &max, QuestionCode: &question, Texts: []*LocalizedTextInput{{Locale: "xx", Text: "?"}, {Locale: LanguageEnglish, Text: " "}}},
+		}, nil, "user")
+
+		fields := map[string]bool{}
+		for _, f := range AsError(err).Fields {
+			fields[f.Field] = true
+		}
+		assert.Equal(t, map[string]bool{
+			"rules[0].riskCategory":    true,
+			"rules[0].target":          true,
+			"rules[0].maxAge":          true,
+			"rules[0].answer":          true,
+			"rules[0].texts[0].locale": true,
+			"rules[0].texts[1].text":   true,
+			"rules[0].texts":           true,
+		}, fields)
+	})
+
+	t.Run("NoRules", func(t *testing.T) {
+		_, err := NewRecommendationRuleSetFromInput(nil, nil, "user")
+		assert.Equal(t, ErrorCodeValidationFailed, AsError(err).Code)
+	})
+}
+
+func TestRecommendationRuleInputRoundTrip(t *testing.T) {
+	question, answer := "s1q1", "High"
+	rule := &RecommendationRule{
+		RiskCategory: RiskCategoryMedium,
+		Target:       RecommendationTargetParentSupervision,
+		QuestionCode: &question,
+		AnswerValue:  &answer,
+		Text:         LocalizedText{LanguageIndonesian: "Diet", LanguageEnglish: "Diet"},
+	}
+
+	ruleSet, err := NewRecommendationRuleSetFromInput([]*RecommendationRuleInput{rule.Input()}, nil, "user")
+	assert.NoError(t, err)
+	assert.Equal(t, rule.Text, ruleSet.Rules[0].Text)
+	assert.Equal(t, answer, *ruleSet.Rules[0].AnswerValue)
+}
+
+func TestSurveyReportSetup(t *testing.T) {
+	text := func(id, en string) LocalizedText {
+		return LocalizedText{LanguageIndonesian: id, LanguageEnglish: en}
+	}
+	young := int32(5)
+	ruleSet := &RecommendationRuleSet{
+		Version: 3,
+		Rules: []*RecommendationRule{
+			{RiskCategory: RiskCategoryHigh, Target: RecommendationTargetParentGuidance, Text: text("Sikat gigi", "Brush teeth")},
+			{RiskCategory: RiskCategoryHigh, Target: RecommendationTargetParentGuidance, Text: LocalizedText{LanguageIndonesian: "Pasta gigi"}},
+			{RiskCategory: RiskCategoryHigh, Target: RecommendationTargetOperatorSealant, MaxAge: &young, Text: text("Tunda sealant", "Delay sealant")},
+			{RiskCategory: RiskCategoryHigh, Target: RecommendationTargetOperatorSealant, Text: text("Sealant", "Sealant recommended")},
+			{RiskCategory: RiskCategoryHigh, Target: RecommendationTargetOperatorART, Text: text("Restorasi", "Restore")},
+			{RiskCategory: RiskCategoryLow, Target: RecommendationTargetParentGuidance, Text: text("Rendah", "Low")},
+		},
+	}
+	age := AgeAt(time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, int32(7), age)
+
+	sr := &SurveyReport{SCAPercentage: 80, Language: LanguageEnglish}
+	sr.Setup(ruleSet, RecommendationFacts{Age: &age})
+
+	assert.Equal(t, RiskCategoryHigh, sr.RiskProfile)
+	assert.Equal(t, int32(3), sr.RecommendationVersion)
+	assert.Equal(t, []string{"Brush teeth", "Pasta gigi"}, sr.ParentGuidance)
+	assert.Equal(t, "Sealant recommended", sr.OperatorSuggestionSealant)
+	assert.Equal(t, "Restore", sr.OperatorSuggestionART)
+	assert.Empty(t, sr.TeacherGuidance)
+}
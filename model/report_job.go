@@ -0,0 +1,102 @@
+package model
+
+import (
+	"fmt"
+	"path"
+	"time"
+)
+
+const (
+	ReportJobStatusQueued  = "queued"
+	ReportJobStatusRunning = "running"
+	ReportJobStatusDone    = "done"
+	ReportJobStatusFailed  = "failed"
+)
+
+const (
+	// ReportJobKindSchoolReport prints the reports of every student of a
+	// school into an archive
+	ReportJobKindSchoolReport = "SCHOOL_REPORT"
+	// ReportJobKindSurveyExport exports surveys into a CSV or XLSX file, which
+	// only the user who asked for it may download
+	ReportJobKindSurveyExport = "SURVEY_EXPORT"
+)
+
+// ReportJob is the generation of a report file, such as the reports of every
+// student of a school or an export of surveys, run in the background. The file
+// is kept until the job expires.
+type ReportJob struct {
+	ID          string
+	Kind        string
+	SchoolID    string
+	Language    string
+	RequestedBy *string
+	Status      string
+	// Completed counts the reports generated so far out of Total
+	Completed int
+	Total     int
+	Error     string
+	// ArchivePath is where the finished archive is kept and FileName the name
+	// it is downloaded as
+	ArchivePath string
+	FileName    string
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+	ExpiresAt   *time.Time
+}
+
+// IsFinished reports whether the job is done or failed
+func (j *ReportJob) IsFinished() bool {
+	return j.Status == ReportJobStatusDone || j.Status == ReportJobStatusFailed
+}
+
+// IsExpired reports whether the job finished long enough ago for it and its
+// archive to be removed
+func (j *ReportJob) IsExpired(now time.Time) bool {
+	return j.ExpiresAt != nil && !now.Before(*j.ExpiresAt)
+}
+
+// Progress returns the share of the reports generated so far, from 0 to 1
+func (j *ReportJob) Progress() float64 {
+	if j.Status == ReportJobStatusDone {
+		return 1
+	}
+	if j.Total == 0 {
+		return 0
+	}
+	return float64(j.Completed) / float64(j.Total)
+}
+
+// DownloadURL returns where the archive of a finished job is downloaded from,
+// or an empty string while there is nothing to download
+func (j *ReportJob) DownloadURL() string {
+	if j.Status != ReportJobStatusDone {
+		return ""
+	}
+	return fmt.Sprintf("/reports/jobs/%s/download", j.ID)
+}
+
+// ContentType returns the media type of the file of the job
+func (j *ReportJob) ContentType() string {
+	return ContentTypeOf(j.FileName)
+}
+
+// ContentTypeOf returns the media type of a report or export file by its
+// name, archives being the default
+func ContentTypeOf(fileName string) string {
+	switch path.Ext(fileName) {
+	case ".csv":
+		return "text/csv; charset=utf-8"
+	case ".xlsx":
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/zip"
+	}
+}
+
+// CanDownload reports whether a user may see and download the job. Only the
+// user who asked for a job may, whatever its kind.
+func (j *ReportJob) CanDownload(userID string) bool {
+	return j.RequestedBy != nil && *j.RequestedBy == userID
+}
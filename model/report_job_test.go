@@ -0,0 +1,48 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportJob(t *testing.T) {
+
+	t.Run("Progress", func(t *testing.T) {
+		assert.Equal(t, float64(0), (&ReportJob{Status: ReportJobStatusQueued}).Progress())
+		assert.Equal(t, 0.25, (&ReportJob{Status: ReportJobStatusRunning, Completed: 1, Total: 4}).Progress())
+		assert.Equal(t, float64(1), (&ReportJob{Status: ReportJobStatusDone}).Progress())
+	})
+
+	t.Run("DownloadURL", func(t *testing.T) {
+		assert.Equal(t, "", (&ReportJob{ID: "job", Status: ReportJobStatusRunning}).DownloadURL())
+		assert.Equal(t, "/reports/jobs/job/download", (&ReportJob{ID: "job", Status: ReportJobStatusDone}).DownloadURL())
+	})
+
+	t.Run("ContentType", func(t *testing.T) {
+		assert.Equal(t, "application/zip", (&ReportJob{FileName: "school.zip"}).ContentType())
+		assert.Equal(t, "text/csv; charset=utf-8", (&ReportJob{FileName: "surveys.csv"}).ContentType())
+		assert.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", (&ReportJob{FileName: "surveys.xlsx"}).ContentType())
+	})
+
+	t.Run("CanDownload", func(t *testing.T) {
+		requestedBy := "user"
+		assert.True(t, (&ReportJob{Kind: ReportJobKindSchoolReport, RequestedBy: &requestedBy}).CanDownload("user"))
+		assert.False(t, (&ReportJob{Kind: ReportJobKindSchoolReport, RequestedBy: &requestedBy}).CanDownload("other"))
+		assert.False(t, (&ReportJob{Kind: ReportJobKindSchoolReport}).CanDownload(""))
+		assert.True(t, (&ReportJob{Kind: ReportJobKindSurveyExport, RequestedBy: &requestedBy}).CanDownload("user"))
+		assert.False(t, (&ReportJob{Kind: ReportJobKindSurveyExport, RequestedBy: &requestedBy}).CanDownload("other"))
+		assert.False(t, (&ReportJob{Kind: ReportJobKindSurveyExport}).CanDownload(""))
+	})
+
+	t.Run("IsExpired", func(t *testing.T) {
+		now := time.Now()
+		expiresAt := now.Add(time.Hour)
+		job := &ReportJob{Status: ReportJobStatusDone, ExpiresAt: &expiresAt}
+
+		assert.False(t, (&ReportJob{Status: ReportJobStatusRunning}).IsExpired(now))
+		assert.False(t, job.IsExpired(now))
+		assert.True(t, job.IsExpired(expiresAt))
+	})
+}
@@ -0,0 +1,217 @@
+package model
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// MaxReportLogoSize is the largest report logo accepted, in bytes
+	MaxReportLogoSize = 1 << 20
+	// MaxReportLogoPixels is the largest report logo accepted, in pixels
+	MaxReportLogoPixels = 4 * 1000 * 1000
+)
+
+// reportColorPattern matches colours written as #rrggbb
+var reportColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// ReportTemplate brands the survey reports of the schools and organizations it
+// is attached to. Reports of schools without a template keep the plain layout.
+type ReportTemplate struct {
+	ID              string
+	Name            string
+	Title           *string `db:"title"`
+	HeaderText      *string `db:"header_text"`
+	FooterText      *string `db:"footer_text"`
+	PrimaryColor    string  `db:"primary_color"`
+	AccentColor     string  `db:"accent_color"`
+	LogoKey         *string `db:"logo_key"`
+	LogoContentType *string `db:"logo_content_type"`
+	CreatedAt       string  `db:"created_at"`
+	UpdatedAt       string  `db:"updated_at"`
+	// Logo is the logo image, loaded from storage when printing a report
+	Logo []byte `db:"-"`
+}
+
+// ReportTemplateInput is the input for report template entity. The logo is a
+// base64 encoded PNG or JPEG image; an empty logo removes the current one.
+type ReportTemplateInput struct {
+	Name         string
+	Title        *string
+	HeaderText   *string
+	FooterText   *string
+	PrimaryColor *string
+	AccentColor  *string
+	Logo         *string
+}
+
+// Organization is a partner organization running surveys at several schools
+type Organization struct {
+	ID               string
+	Name             string
+	ReportTemplateID *string `db:"report_template_id"`
+	CreatedAt        string  `db:"created_at"`
+	UpdatedAt        string  `db:"updated_at"`
+}
+
+// NewReportTemplateFromInput validates the input and returns the template
+// with the decoded logo. A nil logo means the logo is left as it is, while an
+// empty logo removes it. id is the ID of the template being changed, empty
+// for a new template.
+func NewReportTemplateFromInput(id string, input *ReportTemplateInput) (*ReportTemplate, []byte, error) {
+	v := &validator{}
+
+	if strings.TrimSpace(input.Name) == "" || len(input.Name) > 255 {
+		v.add("name", "name must be between 1 and 255 characters")
+	}
+	if input.Title != nil && len(*input.Title) > 255 {
+		v.add("title", "title cannot be longer than 255 characters")
+	}
+	if input.HeaderText != nil && len(*input.HeaderText) > 1000 {
+		v.add("headerText", "header text cannot be longer than 1000 characters")
+	}
+	if input.FooterText != nil && len(*input.FooterText) > 1000 {
+		v.add("footerText", "footer text cannot be longer than 1000 characters")
+	}
+
+	template := &ReportTemplate{
+		ID:           id,
+		Name:         input.Name,
+		Title:        input.Title,
+		HeaderText:   input.HeaderText,
+		FooterText:   input.FooterText,
+		PrimaryColor: "#000000",
+		AccentColor:  "#000000",
+	}
+	if template.ID == "" {
+		template.ID = uuid.NewV4().String()
+	}
+	if input.PrimaryColor != nil {
+		if !reportColorPattern.MatchString(*input.PrimaryColor) {
+			v.add("primaryColor", "invalid colour, expecting #rrggbb")
+		}
+		template.PrimaryColor = strings.ToLower(*input.PrimaryColor)
+	}
+	if input.AccentColor != nil {
+		if !reportColorPattern.MatchString(*input.AccentColor) {
+			v.add("accentColor", "invalid colour, expecting #rrggbb")
+		}
+		template.AccentColor = strings.ToLower(*input.AccentColor)
+	}
+
+	var logo []byte
+	if input.Logo != nil && *input.Logo != "" {
+		data, err := base64.StdEncoding.DecodeString(*input.Logo)
+		switch {
+		case err != nil:
+			v.add("logo", "logo must be base64 encoded")
+		case len(data) > MaxReportLogoSize:
+			v.addf("logo", "logo is larger than %s", formatBytes(MaxReportLogoSize))
+		default:
+			contentType := http.DetectContentType(data)
+			if !isOneOf(contentType, PhotoContentTypes) {
+				v.addf("logo", "invalid content type %s, expecting one of %s", contentType, strings.Join(PhotoContentTypes, ", "))
+			} else {
+				v.merge(validateLogoImage(data), "")
+			}
+			template.LogoContentType = &contentType
+			key := fmt.Sprintf("templates/%s/logo.%s", template.ID, photoExtensions[contentType])
+			template.LogoKey = &key
+			logo = data
+		}
+	} else if input.Logo != nil {
+		logo = []byte{}
+	}
+
+	if err := v.err(); err != nil {
+		return nil, nil, err
+	}
+	return template, logo, nil
+}
+
+// validateLogoImage makes sure a logo decodes as a whole image, checking its
+// dimensions before decoding it so a small file cannot claim a huge image
+func validateLogoImage(data []byte) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return NewFieldError("logo", "logo is not a valid image")
+	}
+	if err := ValidateImageDimensions("logo", config.Width, config.Height, MaxReportLogoPixels); err != nil {
+		return err
+	}
+	if _, _, err := image.Decode(bytes.NewReader(data)); err != nil {
+		return NewFieldError("logo", "logo is truncated or corrupt")
+	}
+	return nil
+}
+
+// NewOrganization validates the name of a new organization
+func NewOrganization(id string, name string) (*Organization, error) {
+	v := &validator{}
+	if strings.TrimSpace(name) == "" || len(name) > 255 {
+		v.add("name", "name must be between 1 and 255 characters")
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return &Organization{ID: id, Name: name}, nil
+}
+
+// RGB returns the red, green and blue components of a #rrggbb colour, black
+// for invalid colours
+func RGB(color string) (int, int, int) {
+	if !reportColorPattern.MatchString(color) {
+		return 0, 0, 0
+	}
+	value, _ := strconv.ParseUint(color[1:], 16, 32)
+	return int(value >> 16 & 0xff), int(value >> 8 & 0xff), int(value & 0xff)
+}
+
+// TextLines returns the lines of an optional text, without empty lines
+func TextLines(text *string) []string {
+	lines := make([]string, 0)
+	if text == nil {
+		return lines
+	}
+	for _, line := range strings.Split(*text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// sampleSurveyDate is the date of the made-up survey of template previews
+var sampleSurveyDate = time.Date(2026, time.January, 15, 0, 0, 0, 0, time.UTC)
+
+// SampleSurveyReport returns a report of a made-up survey in a language, used
+// to preview report templates
+func SampleSurveyReport(language string, template *ReportTemplate) SurveyReport {
+	l := NewLocalizer(language)
+	return SurveyReport{
+		StudentID:     "sample",
+		StudentName:   l.T("template.sample.studentName"),
+		SchoolName:    l.T("template.sample.schoolName"),
+		DateOfSurvey:  sampleSurveyDate,
+		SCAPercentage: 45,
+		DValue:        2,
+		MValue:        0,
+		FValue:        1,
+		Status:        SurveyStatusApproved,
+		DateOfBirth:   sampleSurveyDate.AddDate(-8, 0, 0),
+		Sample:        true,
+		Language:      language,
+		Template:      template,
+	}
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportTemplate(t *testing.T) {
+
+	var logo bytes.Buffer
+	png.Encode(&logo, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	encodedLogo := base64.StdEncoding.EncodeToString(logo.Bytes())
+
+	t.Run("NewReportTemplateFromInput", func(t *testing.T) {
+		primary := "#1A73E8"
+		template, data, err := NewReportTemplateFromInput("", &ReportTemplateInput{Name: "Puskesmas", PrimaryColor: &primary, Logo: &encodedLogo})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, template.ID)
+		assert.Equal(t, "#1a73e8", template.PrimaryColor)
+		assert.Equal(t, "#000000", template.AccentColor)
+		assert.Equal(t, PhotoContentTypePNG, *template.LogoContentType)
+		assert.Equal(t, "templates/"+template.ID+"/logo.png", *template.LogoKey)
+		assert.Equal(t, logo.Bytes(), data)
+	})
+
+	t.Run("KeepAndRemoveLogo", func(t *testing.T) {
+		template, data, err := NewReportTemplateFromInput("template", &ReportTemplateInput{Name: "Puskesmas"})
+		assert.Nil(t, err)
+		assert.Equal(t, "template", template.ID)
+		assert.Nil(t, data)
+
+		empty := ""
+		_, data, err = NewReportTemplateFromInput("template", &ReportTemplateInput{Name: "Puskesmas", Logo: &empty})
+		assert.Nil(t, err)
+		assert.NotNil(t, data)
+		assert.Empty(t, data)
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+
+		t.Run("MissingNameAndInvalidColour", func(t *testing.T) {
+			color := "blue"
+			_, _, err := NewReportTemplateFromInput("", &ReportTemplateInput{AccentColor: &color})
+			assert.NotNil(t, err)
+			assert.Len(t, err.(*Error).Fields, 2)
+		})
+
+		t.Run("LogoNotAnImage", func(t *testing.T) {
+			pdf := base64.StdEncoding.EncodeToString([]byte("%PDF-1.4"))
+			_, _, err := NewReportTemplateFromInput("", &ReportTemplateInput{Name: "Puskesmas", Logo: &pdf})
+			assert.NotNil(t, err)
+			assert.Equal(t, "logo", err.(*Error).Fields[0].Field)
+		})
+
+		t.Run("TruncatedLogo", func(t *testing.T) {
+			truncated := base64.StdEncoding.EncodeToString(logo.Bytes()[:logo.Len()-16])
+			_, _, err := NewReportTemplateFromInput("", &ReportTemplateInput{Name: "Puskesmas", Logo: &truncated})
+			assert.NotNil(t, err)
+			assert.Equal(t, "logo is truncated or corrupt", err.(*Error).Fields[0].Message)
+		})
+
+		t.Run("LogoTooManyPixels", func(t *testing.T) {
+			var large bytes.Buffer
+			png.Encode(&large, image.NewGray(image.Rect(0, 0, 5000, 1000)))
+			encoded := base64.StdEncoding.EncodeToString(large.Bytes())
+			_, _, err := NewReportTemplateFromInput("", &ReportTemplateInput{Name: "Puskesmas", Logo: &encoded})
+			assert.NotNil(t, err)
+			assert.Equal(t, "logo is 5000x1000 pixels, larger than the 4 megapixels accepted", err.(*Error).Fields[0].Message)
+		})
+
+		t.Run("LogoNotBase64", func(t *testing.T) {
+			invalid := "not base64!"
+			_, _, err := NewReportTemplateFromInput("", &ReportTemplateInput{Name: "Puskesmas", Logo: &invalid})
+			assert.NotNil(t, err)
+			assert.Equal(t, "logo must be base64 encoded", err.(*Error).Fields[0].Message)
+		})
+
+		t.Run("LongFooter", func(t *testing.T) {
+			footer := strings.Repeat("a", 1001)
+			_, _, err := NewReportTemplateFromInput("", &ReportTemplateInput{Name: "Puskesmas", FooterText: &footer})
+			assert.NotNil(t, err)
+			assert.Equal(t, "footerText", err.(*Error).Fields[0].Field)
+		})
+	})
+
+	t.Run("NewOrganization", func(t *testing.T) {
+		organization, err := NewOrganization("organization", "Yayasan Senyum")
+		assert.Nil(t, err)
+		assert.Equal(t, "Yayasan Senyum", organization.Name)
+
+		_, err = NewOrganization("organization", " ")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("RGB", func(t *testing.T) {
+		r, g, b := RGB("#1a73e8")
+		assert.Equal(t, []int{26, 115, 232}, []int{r, g, b})
+		r, g, b = RGB("invalid")
+		assert.Equal(t, []int{0, 0, 0}, []int{r, g, b})
+	})
+
+	t.Run("TextLines", func(t *testing.T) {
+		text := "Jl. Merdeka 1\n\n  Telp. 021 555 1234  \n"
+		assert.Equal(t, []string{"Jl. Merdeka 1", "Telp. 021 555 1234"}, TextLines(&text))
+		assert.Empty(t, TextLines(nil))
+	})
+
+	t.Run("SampleSurveyReport", func(t *testing.T) {
+		template := &ReportTemplate{ID: "template"}
+		report := SampleSurveyReport(LanguageIndonesian, template)
+		assert.True(t, report.Sample)
+		assert.Equal(t, template, report.Template)
+		assert.Equal(t, "SD Contoh", report.SchoolName)
+		assert.Equal(t, int32(8), AgeAt(report.DateOfBirth, report.DateOfSurvey))
+	})
+}
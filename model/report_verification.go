@@ -0,0 +1,125 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+const (
+	// reportVerificationCodeLength is the number of characters of a
+	// verification code, about 50 bits of randomness
+	reportVerificationCodeLength = 10
+	// reportVerificationAlphabet leaves out characters easily mistaken for
+	// one another, such as 0 and O or 1 and I
+	reportVerificationAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+)
+
+// ReportVerification records a printed survey report so its authenticity can
+// be checked with the verification code printed on it. The SHA-256 hash is of
+// the whole PDF as it was sent.
+type ReportVerification struct {
+	Code         string    `db:"code"`
+	SurveyID     string    `db:"survey_id"`
+	SurveyStatus string    `db:"survey_status"`
+	Language     string    `db:"language"`
+	SHA256       string    `db:"sha256"`
+	GeneratedAt  time.Time `db:"generated_at"`
+	// SurveyDate is the date of the survey, loaded with the record
+	SurveyDate time.Time `db:"survey_date"`
+	// URL is where the report can be verified, printed as a QR code
+	URL string `db:"-"`
+}
+
+// NewReportVerification returns the verification of a report about to be
+// printed, with a random code. baseURL is the address of the verification
+// endpoint the code is appended to.
+func NewReportVerification(surveyID string, surveyStatus string, language string, baseURL string, now time.Time) (*ReportVerification, error) {
+	random := make([]byte, reportVerificationCodeLength)
+	if _, err := rand.Read(random); err != nil {
+		return nil, err
+	}
+	code := make([]byte, reportVerificationCodeLength)
+	for i, b := range random {
+		code[i] = reportVerificationAlphabet[int(b)%len(reportVerificationAlphabet)]
+	}
+
+	v := &ReportVerification{
+		Code:         string(code),
+		SurveyID:     surveyID,
+		SurveyStatus: surveyStatus,
+		Language:     language,
+		GeneratedAt:  now.UTC().Truncate(time.Second),
+	}
+	v.URL = strings.TrimSuffix(baseURL, "/") + "/" + v.FormattedCode()
+	return v, nil
+}
+
+// FormattedCode returns the code in two halves, as it is printed on reports
+func (v *ReportVerification) FormattedCode() string {
+	half := len(v.Code) / 2
+	return v.Code[:half] + "-" + v.Code[half:]
+}
+
+// Seal records the hash of the printed report
+func (v *ReportVerification) Seal(pdf []byte) {
+	v.SHA256 = hashReport(pdf)
+}
+
+// Matches reports whether a PDF is the report as it was printed
+func (v *ReportVerification) Matches(pdf []byte) bool {
+	return hashReport(pdf) == v.SHA256
+}
+
+func hashReport(pdf []byte) string {
+	sum := sha256.Sum256(pdf)
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeVerificationCode returns a code as it is kept, from a code typed
+// in by hand or read from a QR code, dropping separators and the case
+func NormalizeVerificationCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, code)
+}
+
+// ReportVerificationResult is what the public verification endpoint tells
+// about a report. It leaves out who the student is and where they study, so
+// a code in the wrong hands reveals nothing about the child. Matches is only
+// set when a PDF is given to compare with the printed report.
+type ReportVerificationResult struct {
+	Code         string    `json:"code"`
+	Authentic    bool      `json:"authentic"`
+	SurveyDate   string    `json:"surveyDate"`
+	SurveyStatus string    `json:"surveyStatus"`
+	GeneratedAt  time.Time `json:"generatedAt"`
+	SHA256       string    `json:"sha256"`
+	Matches      *bool     `json:"matches,omitempty"`
+}
+
+// NewReportVerificationResult describes a recorded report, comparing it with
+// a PDF when one is given. Only reports of approved surveys are authentic, so
+// previews printed before the survey was approved are never taken as genuine.
+func NewReportVerificationResult(v *ReportVerification, pdf []byte) *ReportVerificationResult {
+	result := &ReportVerificationResult{
+		Code:         v.FormattedCode(),
+		Authentic:    v.SurveyStatus == SurveyStatusApproved,
+		SurveyDate:   v.SurveyDate.Format("2006-01-02"),
+		SurveyStatus: v.SurveyStatus,
+		GeneratedAt:  v.GeneratedAt,
+		SHA256:       v.SHA256,
+	}
+	if len(pdf) > 0 {
+		matches := v.Matches(pdf)
+		result.Matches = &matches
+		result.Authentic = result.Authentic && matches
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportVerification(t *testing.T) {
+
+	now := time.Date(2026, time.March, 2, 9, 30, 15, 500, time.UTC)
+
+	t.Run("NewReportVerification", func(t *testing.T) {
+		v, err := NewReportVerification("survey", SurveyStatusApproved, LanguageEnglish, "https://idcra.example/verify/", now)
+		assert.Nil(t, err)
+		assert.Len(t, v.Code, reportVerificationCodeLength)
+		for _, r := range v.Code {
+			assert.Contains(t, reportVerificationAlphabet, string(r))
+		}
+		assert.Equal(t, "https://idcra.example/verify/"+v.FormattedCode(), v.URL)
+		assert.Equal(t, now.Truncate(time.Second), v.GeneratedAt)
+
+		other, err := NewReportVerification("survey", SurveyStatusApproved, LanguageEnglish, "https://idcra.example/verify", now)
+		assert.Nil(t, err)
+		assert.NotEqual(t, v.Code, other.Code)
+		assert.Equal(t, "https://idcra.example/verify/"+other.FormattedCode(), other.URL)
+	})
+
+	t.Run("FormattedCode", func(t *testing.T) {
+		v := &ReportVerification{Code: "ABCDE23456"}
+		assert.Equal(t, "ABCDE-23456", v.FormattedCode())
+		assert.Equal(t, v.Code, NormalizeVerificationCode("abcde-23456"))
+		assert.Equal(t, v.Code, NormalizeVerificationCode("ABCDE 23456"))
+	})
+
+	t.Run("SealAndMatch", func(t *testing.T) {
+		v := &ReportVerification{Code: "ABCDE23456"}
+		v.Seal([]byte("%PDF-1.3 report"))
+		assert.Len(t, v.SHA256, 64)
+		assert.True(t, v.Matches([]byte("%PDF-1.3 report")))
+		assert.False(t, v.Matches([]byte("%PDF-1.3 forged")))
+	})
+
+	t.Run("NewReportVerificationResult", func(t *testing.T) {
+		v := &ReportVerification{
+			Code:         "ABCDE23456",
+			SurveyID:     "survey",
+			SurveyStatus: SurveyStatusApproved,
+			GeneratedAt:  now,
+			SurveyDate:   time.Date(2026, time.February, 27, 0, 0, 0, 0, time.UTC),
+		}
+		v.Seal([]byte("%PDF-1.3 report"))
+
+		result := NewReportVerificationResult(v, nil)
+		assert.True(t, result.Authentic)
+		assert.Nil(t, result.Matches)
+		assert.Equal(t, "ABCDE-23456", result.Code)
+		assert.Equal(t, "2026-02-27", result.SurveyDate)
+
+		result = NewReportVerificationResult(v, []byte("%PDF-1.3 forged"))
+		assert.False(t, result.Authentic)
+		assert.False(t, *result.Matches)
+
+		result = NewReportVerificationResult(v, []byte("%PDF-1.3 report"))
+		assert.True(t, result.Authentic)
+		assert.True(t, *result.Matches)
+
+		// a preview recorded before the survey was approved
+		v.SurveyStatus = SurveyStatusSubmitted
+		result = NewReportVerificationResult(v, []byte("%PDF-1.3 report"))
+		assert.False(t, result.Authentic)
+		assert.True(t, *result.Matches)
+		assert.Equal(t, SurveyStatusSubmitted, result.SurveyStatus)
+	})
+
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+const (
+	RiskCategoryLow    = "low"
+	RiskCategoryMedium = "medium"
+	RiskCategoryHigh   = "high"
+)
+
+// RiskModel is a caries risk assessment model scoring surveys.
+type RiskModel interface {
+	// Name identifies the model in the configuration and in stored assessments.
+	Name() string
+	// Assess returns the score and the risk category of the survey. The
+	// meaning of the score depends on the model.
+	Assess(s *Survey) (score float64, category string)
+}
+
+// RiskAssessment is the caries risk of a survey according to a risk model
+type RiskAssessment struct {
+	SurveyID  string `db:"survey_id"`
+	Model     string `db:"model"`
+	Score     float64
+	Category  string
+	CreatedAt string `db:"created_at"`
+	UpdatedAt string `db:"updated_at"`
+}
+
+var (
+	riskModelsMu sync.RWMutex
+	riskModels   = make(map[string]RiskModel)
+)
+
+// RegisterRiskModel makes a risk model available by its name. It panics when a
+// model with the same name is already registered.
+func RegisterRiskModel(m RiskModel) {
+	riskModelsMu.Lock()
+	defer riskModelsMu.Unlock()
+
+	if _, ok := riskModels[m.Name()]; ok {
+		panic(fmt.Sprintf("risk model %s is already registered", m.Name()))
+	}
+	riskModels[m.Name()] = m
+}
+
+// FindRiskModel returns the registered risk model with the given name
+func FindRiskModel(name string) (RiskModel, bool) {
+	riskModelsMu.RLock()
+	defer riskModelsMu.RUnlock()
+
+	m, ok := riskModels[name]
+	return m, ok
+}
+
+// RiskModelNames returns the names of the registered risk models in order
+func RiskModelNames() []string {
+	riskModelsMu.RLock()
+	defer riskModelsMu.RUnlock()
+
+	names := make([]string, 0, len(riskModels))
+	for name := range riskModels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Assess returns the assessment of the survey according to the risk model
+func Assess(m RiskModel, s *Survey) *RiskAssessment {
+	score, category := m.Assess(s)
+	return &RiskAssessment{
+		SurveyID: s.ID,
+		Model:    m.Name(),
+		Score:    score,
+		Category: category,
+	}
+}
+
+// RiskAssessment returns the stored assessment of the survey by the named risk
+// model, or nil when the survey was not assessed with it
+func (s *Survey) RiskAssessment(modelName string) *RiskAssessment {
+	for _, assessment := range s.RiskAssessments {
+		if assessment.Model == modelName {
+			return assessment
+		}
+	}
+	return nil
+}
+
+// RiskCategory returns the risk category of the survey according to the named
+// risk model, taken from its stored assessment. Surveys not assessed with the
+// model are assessed on the spot, falling back to the IDCRA category of their
+// subjective score when the model is not registered.
+func (s *Survey) RiskCategory(modelName string) string {
+	if assessment := s.RiskAssessment(modelName); assessment != nil {
+		return assessment.Category
+	}
+	if m, ok := FindRiskModel(modelName); ok {
+		_, category := m.Assess(s)
+		return category
+	}
+	return idcraRiskCategory(float64(s.SubjectiveScore))
+}
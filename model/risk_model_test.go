@@ -0,0 +1,88 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRiskModel struct{}
+
+func (fakeRiskModel) Name() string {
+	return "fake"
+}
+
+func (fakeRiskModel) Assess(s *Survey) (float64, string) {
+	return 0, RiskCategoryLow
+}
+
+func TestRiskModelRegistry(t *testing.T) {
+
+	t.Run("BuiltIn", func(t *testing.T) {
+		assert.Subset(t, RiskModelNames(), []string{RiskModelADA, RiskModelCAMBRA, RiskModelIDCRA})
+	})
+
+	t.Run("Register", func(t *testing.T) {
+		RegisterRiskModel(fakeRiskModel{})
+		t.Cleanup(func() {
+			riskModelsMu.Lock()
+			defer riskModelsMu.Unlock()
+			delete(riskModels, "fake")
+		})
+
+		m, ok := FindRiskModel("fake")
+		assert.True(t, ok)
+		assert.Equal(t, "fake", m.Name())
+		assert.Panics(t, func() { RegisterRiskModel(fakeRiskModel{}) })
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		_, ok := FindRiskModel("unknown")
+		assert.False(t, ok)
+	})
+}
+
+func TestRiskModels(t *testing.T) {
+	idcra, _ := FindRiskModel(RiskModelIDCRA)
+	cambra, _ := FindRiskModel(RiskModelCAMBRA)
+	ada, _ := FindRiskModel(RiskModelADA)
+
+	t.Run("IDCRA", func(t *testing.T) {
+		assessment := Assess(idcra, &Survey{ID: "fake_survey_id", SubjectiveScore: 50})
+
+		assert.Equal(t, "fake_survey_id", assessment.SurveyID)
+		assert.Equal(t, RiskModelIDCRA, assessment.Model)
+		assert.Equal(t, float64(50), assessment.Score)
+		assert.Equal(t, RiskCategoryMedium, assessment.Category)
+		assert.Equal(t, RiskCategoryLow, Assess(idcra, &Survey{SubjectiveScore: 33}).Category)
+		assert.Equal(t, RiskCategoryHigh, Assess(idcra, &Survey{SubjectiveScore: 67}).Category)
+	})
+
+	t.Run("CAMBRA", func(t *testing.T) {
+		assert.Equal(t, RiskCategoryHigh, Assess(cambra, &Survey{UpperD: 1}).Category)
+		assert.Equal(t, RiskCategoryMedium, Assess(cambra, &Survey{LowerF: 1}).Category)
+		assert.Equal(t, RiskCategoryMedium, Assess(cambra, &Survey{SubjectiveScore: 60}).Category)
+		assert.Equal(t, RiskCategoryLow, Assess(cambra, &Survey{SubjectiveScore: 50}).Category)
+		assert.Equal(t, float64(100), Assess(cambra, &Survey{UpperD: 1, UpperF: 1, SubjectiveScore: 100}).Score)
+	})
+
+	t.Run("ADA", func(t *testing.T) {
+		assert.Equal(t, RiskCategoryHigh, Assess(ada, &Survey{UpperD: 1, LowerD: 1, LowerF: 1}).Category)
+		assert.Equal(t, RiskCategoryMedium, Assess(ada, &Survey{UpperF: 2}).Category)
+		assert.Equal(t, RiskCategoryMedium, Assess(ada, &Survey{SubjectiveScore: 67}).Category)
+		assert.Equal(t, RiskCategoryLow, Assess(ada, &Survey{}).Category)
+		assert.Equal(t, float64(3), Assess(ada, &Survey{UpperD: 1, UpperF: 1, LowerD: 1, UpperM: 4}).Score)
+	})
+}
+
+func TestSurveyRiskCategory(t *testing.T) {
+	survey := &Survey{SubjectiveScore: 20, RiskAssessments: []*RiskAssessment{
+		{Model: RiskModelCAMBRA, Category: RiskCategoryHigh},
+	}}
+
+	assert.Equal(t, RiskCategoryHigh, survey.RiskAssessment(RiskModelCAMBRA).Category)
+	assert.Nil(t, survey.RiskAssessment(RiskModelIDCRA))
+	assert.Equal(t, RiskCategoryHigh, survey.RiskCategory(RiskModelCAMBRA))
+	assert.Equal(t, RiskCategoryLow, survey.RiskCategory(RiskModelIDCRA))
+	assert.Equal(t, RiskCategoryLow, survey.RiskCategory("unknown"))
+}
@@ -0,0 +1,94 @@
+package model
+
+const (
+	RiskModelIDCRA  = "idcra"
+	RiskModelCAMBRA = "cambra"
+	RiskModelADA    = "ada"
+)
+
+func init() {
+	RegisterRiskModel(idcraRiskModel{})
+	RegisterRiskModel(cambraRiskModel{})
+	RegisterRiskModel(adaRiskModel{})
+}
+
+// idcraRiskModel is the original IDCRA rule: the subjective score of the
+// questionnaire answers, split into thirds.
+type idcraRiskModel struct{}
+
+func (idcraRiskModel) Name() string {
+	return RiskModelIDCRA
+}
+
+func (idcraRiskModel) Assess(s *Survey) (float64, string) {
+	score := float64(s.SubjectiveScore)
+	return score, idcraRiskCategory(score)
+}
+
+func idcraRiskCategory(score float64) string {
+	switch {
+	case score > 66:
+		return RiskCategoryHigh
+	case score > 33:
+		return RiskCategoryMedium
+	default:
+		return RiskCategoryLow
+	}
+}
+
+// cambraRiskModel follows CAMBRA in weighing disease indicators before risk
+// factors: decayed teeth make the risk high, while past caries experience or
+// a questionnaire leaning towards risk factors makes it medium. The score adds
+// up disease indicators (up to 60) and the subjective score (up to 40).
+type cambraRiskModel struct{}
+
+func (cambraRiskModel) Name() string {
+	return RiskModelCAMBRA
+}
+
+func (cambraRiskModel) Assess(s *Survey) (float64, string) {
+	decayed := s.UpperD + s.LowerD
+	experienced := s.UpperM + s.UpperF + s.LowerE + s.LowerF
+
+	score := float64(s.SubjectiveScore) * 0.4
+	if decayed > 0 {
+		score += 40
+	}
+	if experienced > 0 {
+		score += 20
+	}
+
+	switch {
+	case decayed > 0:
+		return score, RiskCategoryHigh
+	case experienced > 0 || s.SubjectiveScore > 50:
+		return score, RiskCategoryMedium
+	default:
+		return score, RiskCategoryLow
+	}
+}
+
+// adaRiskModel follows the clinical conditions of the ADA caries risk
+// assessment form: three or more carious lesions or restorations make the risk
+// high, one or two make it medium. Without lesions, a high subjective score
+// still makes the risk medium. The score is the number of lesions and
+// restorations.
+type adaRiskModel struct{}
+
+func (adaRiskModel) Name() string {
+	return RiskModelADA
+}
+
+func (adaRiskModel) Assess(s *Survey) (float64, string) {
+	lesions := s.UpperD + s.UpperF + s.LowerD + s.LowerF
+	score := float64(lesions)
+
+	switch {
+	case lesions >= 3:
+		return score, RiskCategoryHigh
+	case lesions > 0 || s.SubjectiveScore > 66:
+		return score, RiskCategoryMedium
+	default:
+		return score, RiskCategoryLow
+	}
+}
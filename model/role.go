@@ -1,7 +1,25 @@
 package model
 
+const (
+	RoleAdmin      = "ADMIN"
+	RoleSurveyor   = "SURVEYOR"
+	RoleSupervisor = "SUPERVISOR"
+)
+
 type Role struct {
 	ID        string
 	Name      string
 	CreatedAt string `db:"created_at"`
 }
+
+// HasRole reports whether one of the roles is named after any of the names
+func HasRole(roles []*Role, names ...string) bool {
+	for _, role := range roles {
+		for _, name := range names {
+			if role.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
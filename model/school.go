@@ -1,9 +1,66 @@
 package model
 
+import (
+	"fmt"
+	"time"
+)
+
 // School is the school entity
 type School struct {
-	ID        string
-	Name      string
-	CreatedAt string `db:"created_at"`
+	ID   string
+	Name string
+	// OrganizationID is the partner organization running the school, if any.
+	// Reports of the school are branded with ReportTemplateID, or else with the
+	// template of the organization.
+	OrganizationID   *string `db:"organization_id"`
+	ReportTemplateID *string `db:"report_template_id"`
+	// TimeZone is the IANA time zone of the school, nil for the configured
+	// default time zone
+	TimeZone  *string `db:"time_zone"`
+	CreatedAt string  `db:"created_at"`
+	UpdatedAt string  `db:"updated_at"`
 	Students  []*Student
 }
+
+// SchoolAccess is the set of schools a user works with. Admins and supervisors
+// work with every school, other users with the schools they are assigned to
+// and the schools of their students.
+type SchoolAccess struct {
+	All       bool
+	SchoolIDs []string
+}
+
+// NewSchoolAccess returns the access of a user with the given roles to the
+// schools they are assigned to
+func NewSchoolAccess(roles []*Role, schoolIDs []string) SchoolAccess {
+	if HasRole(roles, RoleAdmin, RoleSupervisor) {
+		return SchoolAccess{All: true}
+	}
+	return SchoolAccess{SchoolIDs: schoolIDs}
+}
+
+// Allows reports whether the user works with a school
+func (a SchoolAccess) Allows(schoolID string) bool {
+	return a.All || isOneOf(schoolID, a.SchoolIDs)
+}
+
+// Check returns a forbidden error for the first school the user does not work
+// with
+func (a SchoolAccess) Check(schoolIDs []string) error {
+	for _, schoolID := range schoolIDs {
+		if !a.Allows(schoolID) {
+			return NewForbiddenError(fmt.Sprintf("not assigned to school %s", schoolID))
+		}
+	}
+	return nil
+}
+
+// ValidateTimeZone makes sure a time zone is a known IANA time zone name
+func ValidateTimeZone(timeZone string) error {
+	// the empty name and Local are valid names of UTC and of the server's
+	// time zone, not of the time zone of a school
+	if _, err := time.LoadLocation(timeZone); err != nil || timeZone == "" || timeZone == "Local" {
+		return NewFieldError("timeZone", fmt.Sprintf("unknown time zone %s, expecting an IANA time zone such as Asia/Jakarta", timeZone))
+	}
+	return nil
+}
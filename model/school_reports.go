@@ -1,9 +1,114 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
 
+const (
+	// unnamedStudentFolder names the folder of a student whose name has no
+	// character that can be kept in a file name
+	unnamedStudentFolder = "siswa"
+	// maxReportFolderLength is the longest folder name in runes
+	maxReportFolderLength = 100
+)
+
+// SchoolReports is a survey printed in the report archive of a school, as
+// FileName in the archive
 type SchoolReports struct {
-	ID   string    `db:"id"`
-	Name string    `db:"name"`
-	Date time.Time `db:"date"`
+	ID        string    `db:"id"`
+	StudentID string    `db:"student_id"`
+	Name      string    `db:"name"`
+	Date      time.Time `db:"date"`
+	FileName  string    `db:"-"`
+}
+
+// NameSchoolReports orders the surveys of a school archive by student and date
+// and names their files student/yyyy-mm-dd.pdf. Names are made safe for any
+// file system, students with the same name get numbered folders and surveys of
+// a student on the same date numbered files, so the same surveys are always
+// named the same.
+func NameSchoolReports(reports []*SchoolReports) {
+	sort.SliceStable(reports, func(i, j int) bool {
+		a, b := reports[i], reports[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.StudentID != b.StudentID {
+			return a.StudentID < b.StudentID
+		}
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.ID < b.ID
+	})
+
+	folders := make(map[string]string)
+	takenFolders := make(map[string]bool)
+	takenFiles := make(map[string]bool)
+	for _, r := range reports {
+		folder, ok := folders[r.StudentID]
+		if !ok {
+			folder = uniqueName(safeFileName(r.Name), "", takenFolders)
+			folders[r.StudentID] = folder
+		}
+
+		file := uniqueName(folder+"/"+r.Date.Format("2006-01-02"), ".pdf", takenFiles)
+		r.FileName = file + ".pdf"
+	}
+}
+
+// uniqueName returns name, or name numbered from 2 when it is already taken,
+// comparing names regardless of case as some file systems do
+func uniqueName(name string, extension string, taken map[string]bool) string {
+	unique := name
+	for i := 2; taken[strings.ToLower(unique+extension)]; i++ {
+		unique = fmt.Sprintf("%s (%d)", name, i)
+	}
+	taken[strings.ToLower(unique+extension)] = true
+	return unique
+}
+
+// safeFileName replaces the characters of a name that are not allowed in file
+// names on common file systems
+func safeFileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune(" -_.,'()", r) {
+			return r
+		}
+		if unicode.IsSpace(r) {
+			return ' '
+		}
+		return '_'
+	}, name)
+
+	safe = strings.Join(strings.Fields(safe), " ")
+	if runes := []rune(safe); len(runes) > maxReportFolderLength {
+		safe = string(runes[:maxReportFolderLength])
+	}
+	safe = strings.Trim(safe, " .")
+	if strings.Trim(safe, "_") == "" {
+		return unnamedStudentFolder
+	}
+	if isReservedFileName(safe) {
+		return "_" + safe
+	}
+	return safe
+}
+
+// isReservedFileName tells whether Windows keeps a name for a device, with or
+// without an extension
+func isReservedFileName(name string) bool {
+	base := strings.ToUpper(strings.TrimSpace(strings.SplitN(name, ".", 2)[0]))
+	switch base {
+	case "CON", "PRN", "AUX", "NUL":
+		return true
+	}
+	if len(base) == 4 && (strings.HasPrefix(base, "COM") || strings.HasPrefix(base, "LPT")) {
+		return base[3] >= '1' && base[3] <= '9'
+	}
+	return false
 }
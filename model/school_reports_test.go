@@ -0,0 +1,76 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNameSchoolReports(t *testing.T) {
+	day := func(d string) time.Time {
+		parsed, _ := time.Parse("2006-01-02", d)
+		return parsed
+	}
+
+	t.Run("DuplicatesAndUnsafeNames", func(t *testing.T) {
+		reports := []*SchoolReports{
+			{ID: "s5", StudentID: "b", Name: "Budi", Date: day("2026-01-05")},
+			{ID: "s1", StudentID: "a", Name: "Budi", Date: day("2026-01-05")},
+			{ID: "s2", StudentID: "a", Name: "Budi", Date: day("2026-01-05")},
+			{ID: "s3", StudentID: "c", Name: "AC/DC: \"Rock\"?", Date: day("2026-02-01")},
+			{ID: "s4", StudentID: "d", Name: " ../.. ", Date: day("2026-03-01")},
+			{ID: "s6", StudentID: "e", Name: "budi", Date: day("2026-01-05")},
+		}
+
+		NameSchoolReports(reports)
+
+		names := map[string]string{}
+		for _, r := range reports {
+			names[r.ID] = r.FileName
+		}
+		assert.Equal(t, "Budi/2026-01-05.pdf", names["s1"])
+		assert.Equal(t, "Budi/2026-01-05 (2).pdf", names["s2"])
+		assert.Equal(t, "Budi (2)/2026-01-05.pdf", names["s5"])
+		assert.Equal(t, "budi (3)/2026-01-05.pdf", names["s6"])
+		assert.Equal(t, "AC_DC_ _Rock__/2026-02-01.pdf", names["s3"])
+		assert.Equal(t, "siswa/2026-03-01.pdf", names["s4"])
+	})
+
+	t.Run("ReservedNames", func(t *testing.T) {
+		reports := []*SchoolReports{
+			{ID: "1", StudentID: "a", Name: "Con", Date: day("2026-01-01")},
+			{ID: "2", StudentID: "b", Name: "nul.txt", Date: day("2026-01-01")},
+			{ID: "3", StudentID: "c", Name: "COM1", Date: day("2026-01-01")},
+			{ID: "4", StudentID: "d", Name: "Connie", Date: day("2026-01-01")},
+			{ID: "5", StudentID: "e", Name: "COM0", Date: day("2026-01-01")},
+		}
+
+		NameSchoolReports(reports)
+
+		names := map[string]string{}
+		for _, r := range reports {
+			names[r.ID] = r.FileName
+		}
+		assert.Equal(t, "_Con/2026-01-01.pdf", names["1"])
+		assert.Equal(t, "_nul.txt/2026-01-01.pdf", names["2"])
+		assert.Equal(t, "_COM1/2026-01-01.pdf", names["3"])
+		assert.Equal(t, "Connie/2026-01-01.pdf", names["4"])
+		assert.Equal(t, "COM0/2026-01-01.pdf", names["5"])
+	})
+
+	t.Run("Deterministic", func(t *testing.T) {
+		first := []*SchoolReports{
+			{ID: "1", StudentID: "a", Name: "Ani", Date: day("2026-01-01")},
+			{ID: "2", StudentID: "b", Name: "Ani", Date: day("2026-01-01")},
+		}
+		second := []*SchoolReports{first[1], first[0]}
+
+		NameSchoolReports(first)
+		NameSchoolReports(second)
+
+		assert.Equal(t, first[0].FileName, second[0].FileName)
+		assert.Equal(t, "Ani/2026-01-01.pdf", first[0].FileName)
+		assert.Equal(t, "Ani (2)/2026-01-01.pdf", first[1].FileName)
+	})
+}
@@ -0,0 +1,289 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// schoolSummaryTopDiagnoses is the number of diagnoses listed in a school
+// summary
+const schoolSummaryTopDiagnoses = 5
+
+// AgeBand is a range of ages in years. Bands without a maximum age are open
+// ended.
+type AgeBand struct {
+	Min int32
+	Max *int32
+}
+
+func (b AgeBand) String() string {
+	if b.Max == nil {
+		return fmt.Sprintf("%d+", b.Min)
+	}
+	return fmt.Sprintf("%d-%d", b.Min, *b.Max)
+}
+
+// Contains tells whether an age falls within the band
+func (b AgeBand) Contains(age int32) bool {
+	return age >= b.Min && (b.Max == nil || age <= *b.Max)
+}
+
+func closedAgeBand(min, max int32) AgeBand {
+	return AgeBand{Min: min, Max: &max}
+}
+
+// SchoolSummaryAgeBands are the age bands tooth indices are broken down by in
+// school summaries, following the school levels
+var SchoolSummaryAgeBands = []AgeBand{
+	closedAgeBand(0, 5),
+	closedAgeBand(6, 8),
+	closedAgeBand(9, 11),
+	closedAgeBand(12, 14),
+	{Min: 15},
+}
+
+// SchoolSummarySurvey is an approved survey of a student of the school
+type SchoolSummarySurvey struct {
+	ID              string    `db:"id"`
+	StudentID       string    `db:"student_id"`
+	DateOfBirth     time.Time `db:"date_of_birth"`
+	Sex             *string   `db:"sex"`
+	Date            time.Time `db:"date"`
+	SubjectiveScore float64   `db:"subjective_score"`
+	LowerD          int32     `db:"lower_d"`
+	LowerE          int32     `db:"lower_e"`
+	LowerF          int32     `db:"lower_f"`
+	UpperD          int32     `db:"upper_d"`
+	UpperM          int32     `db:"upper_m"`
+	UpperF          int32     `db:"upper_f"`
+}
+
+// SchoolSummaryCase is the diagnosis and action of a case of an approved
+// survey, with the cost of the action and how many times it was performed
+type SchoolSummaryCase struct {
+	SurveyID  string  `db:"survey_id"`
+	Diagnosis string  `db:"diagnosis"`
+	Action    string  `db:"action"`
+	UnitCost  float64 `db:"unit_cost"`
+	Performed int32   `db:"performed"`
+}
+
+// SchoolSummary sums up the latest approved survey of every surveyed student
+// of a school within a period
+type SchoolSummary struct {
+	SchoolName string
+	StartDate  time.Time
+	EndDate    time.Time
+	// Language is the language the summary is printed in
+	Language string
+
+	Students            int
+	Surveyed            int
+	RiskDistribution    []*RiskCategoryCount
+	MeanSubjectiveScore float64
+	MeanDMFT            float64
+	MeanDefT            float64
+	// AgeBands are the mean tooth indices of every age band with surveyed
+	// students, and ToothIndices those of every sex within the age band
+	AgeBands     []*ToothIndexGroup
+	ToothIndices []*ToothIndexGroup
+	TopDiagnoses []*DiagnosisCount
+	// Costs are the estimated treatment costs of the cases of the counted
+	// surveys by action, with the total as the last row
+	Costs []*CostReport
+}
+
+// RiskCategoryCount is the number of students in a risk category
+type RiskCategoryCount struct {
+	Category string
+	Count    int
+}
+
+// ToothIndexGroup holds the mean DMF-T and def-t of the students of an age
+// band and sex. Sex is empty for groups of every sex.
+type ToothIndexGroup struct {
+	AgeBand  AgeBand
+	Sex      string
+	Students int
+	MeanDMFT float64
+	MeanDefT float64
+
+	dmft int32
+	deft int32
+}
+
+func (g *ToothIndexGroup) add(s *SchoolSummarySurvey) {
+	g.Students++
+	g.dmft += s.UpperD + s.UpperM + s.UpperF
+	g.deft += s.LowerD + s.LowerE + s.LowerF
+	g.MeanDMFT = float64(g.dmft) / float64(g.Students)
+	g.MeanDefT = float64(g.deft) / float64(g.Students)
+}
+
+// DiagnosisCount is the number of cases of a diagnosis
+type DiagnosisCount struct {
+	Diagnosis string
+	Count     int
+}
+
+// ParticipationRate is the percentage of the students of the school surveyed
+func (s *SchoolSummary) ParticipationRate() float64 {
+	if s.Students == 0 {
+		return 0
+	}
+	return float64(s.Surveyed) / float64(s.Students) * 100
+}
+
+// NewSchoolSummary sums up the surveys of the students of a school. Only the
+// latest survey of every student counts, along with the cases of that survey.
+func NewSchoolSummary(students int, surveys []*SchoolSummarySurvey, cases []*SchoolSummaryCase) *SchoolSummary {
+	latest := make(map[string]*SchoolSummarySurvey)
+	for _, s := range surveys {
+		current, ok := latest[s.StudentID]
+		if !ok || s.Date.After(current.Date) || (s.Date.Equal(current.Date) && s.ID > current.ID) {
+			latest[s.StudentID] = s
+		}
+	}
+
+	counted := make([]*SchoolSummarySurvey, 0, len(latest))
+	countedSurveys := make(map[string]bool, len(latest))
+	for _, s := range latest {
+		counted = append(counted, s)
+		countedSurveys[s.ID] = true
+	}
+	sort.Slice(counted, func(i, j int) bool {
+		return counted[i].StudentID < counted[j].StudentID
+	})
+
+	summary := &SchoolSummary{
+		Students: students,
+		Surveyed: len(counted),
+	}
+
+	riskCounts := make(map[string]int)
+	total := &ToothIndexGroup{}
+	bands := make(map[int]*ToothIndexGroup)
+	groups := make(map[string]*ToothIndexGroup)
+	var score float64
+	for _, s := range counted {
+		score += s.SubjectiveScore
+		riskCounts[idcraRiskCategory(s.SubjectiveScore)]++
+		total.add(s)
+
+		band, ok := ageBandIndex(AgeAt(s.DateOfBirth, s.Date))
+		if !ok {
+			continue
+		}
+		sex := SexUnknown
+		if s.Sex != nil {
+			sex = *s.Sex
+		}
+
+		if bands[band] == nil {
+			bands[band] = &ToothIndexGroup{AgeBand: SchoolSummaryAgeBands[band]}
+		}
+		bands[band].add(s)
+
+		key := fmt.Sprintf("%d/%s", band, sex)
+		if groups[key] == nil {
+			groups[key] = &ToothIndexGroup{AgeBand: SchoolSummaryAgeBands[band], Sex: sex}
+		}
+		groups[key].add(s)
+	}
+
+	if len(counted) > 0 {
+		summary.MeanSubjectiveScore = score / float64(len(counted))
+	}
+	summary.MeanDMFT = total.MeanDMFT
+	summary.MeanDefT = total.MeanDefT
+
+	for _, category := range RiskCategories {
+		summary.RiskDistribution = append(summary.RiskDistribution, &RiskCategoryCount{Category: category, Count: riskCounts[category]})
+	}
+
+	sexes := append(append([]string{}, Sexes...), SexUnknown)
+	for i := range SchoolSummaryAgeBands {
+		if band, ok := bands[i]; ok {
+			summary.AgeBands = append(summary.AgeBands, band)
+		}
+		for _, sex := range sexes {
+			if group, ok := groups[fmt.Sprintf("%d/%s", i, sex)]; ok {
+				summary.ToothIndices = append(summary.ToothIndices, group)
+			}
+		}
+	}
+
+	countedCases := make([]*SchoolSummaryCase, 0, len(cases))
+	for _, c := range cases {
+		if countedSurveys[c.SurveyID] {
+			countedCases = append(countedCases, c)
+		}
+	}
+	summary.TopDiagnoses = topDiagnoses(countedCases)
+	summary.Costs = summaryCosts(countedCases)
+
+	return summary
+}
+
+func ageBandIndex(age int32) (int, bool) {
+	for i, band := range SchoolSummaryAgeBands {
+		if band.Contains(age) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// topDiagnoses counts the cases of the counted surveys by diagnosis, most
+// common first
+func topDiagnoses(cases []*SchoolSummaryCase) []*DiagnosisCount {
+	counts := make(map[string]int)
+	for _, c := range cases {
+		counts[c.Diagnosis]++
+	}
+
+	diagnoses := make([]*DiagnosisCount, 0, len(counts))
+	for diagnosis, count := range counts {
+		diagnoses = append(diagnoses, &DiagnosisCount{Diagnosis: diagnosis, Count: count})
+	}
+	sort.Slice(diagnoses, func(i, j int) bool {
+		if diagnoses[i].Count != diagnoses[j].Count {
+			return diagnoses[i].Count > diagnoses[j].Count
+		}
+		return diagnoses[i].Diagnosis < diagnoses[j].Diagnosis
+	})
+	if len(diagnoses) > schoolSummaryTopDiagnoses {
+		diagnoses = diagnoses[:schoolSummaryTopDiagnoses]
+	}
+	return diagnoses
+}
+
+// summaryCosts sums the cost of the cases of the counted surveys by action,
+// in the order of the actions, followed by the total
+func summaryCosts(cases []*SchoolSummaryCase) []*CostReport {
+	byAction := make(map[string]*CostReport)
+	total := &CostReport{Description: "Total"}
+	for _, c := range cases {
+		cost, ok := byAction[c.Action]
+		if !ok {
+			cost = &CostReport{Description: c.Action}
+			byAction[c.Action] = cost
+		}
+		for _, r := range []*CostReport{cost, total} {
+			r.Cost += c.UnitCost
+			r.PerformedCost += c.UnitCost * float64(c.Performed)
+			r.PlannedCount++
+			r.PerformedCount += c.Performed
+		}
+	}
+
+	costs := make([]*CostReport, 0, len(byAction)+1)
+	for _, cost := range byAction {
+		costs = append(costs, cost)
+	}
+	sort.Slice(costs, func(i, j int) bool {
+		return costs[i].Description < costs[j].Description
+	})
+	return append(costs, total)
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSchoolSummary(t *testing.T) {
+	day := func(d string) time.Time {
+		parsed, _ := time.Parse("2006-01-02", d)
+		return parsed
+	}
+	male, female := SexMale, SexFemale
+
+	surveys := []*SchoolSummarySurvey{
+		// an earlier survey of the first student is left out
+		{ID: "s0", StudentID: "a", DateOfBirth: day("2019-05-01"), Sex: &male, Date: day("2026-01-10"), SubjectiveScore: 90, UpperD: 9},
+		{ID: "s1", StudentID: "a", DateOfBirth: day("2019-05-01"), Sex: &male, Date: day("2026-03-10"), SubjectiveScore: 20, LowerD: 2, LowerF: 1},
+		{ID: "s2", StudentID: "b", DateOfBirth: day("2019-02-01"), Sex: &female, Date: day("2026-03-10"), SubjectiveScore: 50, LowerD: 4, UpperD: 1},
+		{ID: "s3", StudentID: "c", DateOfBirth: day("2014-01-01"), Date: day("2026-03-11"), SubjectiveScore: 80, UpperD: 2, UpperF: 1},
+		{ID: "s4", StudentID: "d", DateOfBirth: day("2019-08-01"), Sex: &male, Date: day("2026-03-12"), SubjectiveScore: 70, LowerE: 1},
+	}
+	cases := []*SchoolSummaryCase{
+		{SurveyID: "s0", Diagnosis: "Karies Superficial", Action: "Tumpatan", UnitCost: 100},
+		{SurveyID: "s0", Diagnosis: "Karies Superficial", Action: "Tumpatan", UnitCost: 100},
+		{SurveyID: "s1", Diagnosis: "Pit Fissure Dalam", Action: "Sealant", UnitCost: 50, Performed: 1},
+		{SurveyID: "s2", Diagnosis: "Free Karies", Action: "Tumpatan", UnitCost: 100},
+		{SurveyID: "s3", Diagnosis: "Pit Fissure Dalam", Action: "Sealant", UnitCost: 50},
+	}
+
+	summary := NewSchoolSummary(8, surveys, cases)
+
+	assert.Equal(t, 4, summary.Surveyed)
+	assert.Equal(t, 50.0, summary.ParticipationRate())
+	assert.Equal(t, 55.0, summary.MeanSubjectiveScore)
+	assert.Equal(t, 1.0, summary.MeanDMFT)
+	assert.Equal(t, 2.0, summary.MeanDefT)
+
+	assert.Equal(t, []*RiskCategoryCount{
+		{Category: RiskCategoryLow, Count: 1},
+		{Category: RiskCategoryMedium, Count: 1},
+		{Category: RiskCategoryHigh, Count: 2},
+	}, summary.RiskDistribution)
+
+	groups := make([]string, len(summary.ToothIndices))
+	for i, g := range summary.ToothIndices {
+		groups[i] = g.AgeBand.String() + " " + g.Sex
+	}
+	assert.Equal(t, []string{"6-8 MALE", "6-8 FEMALE", "12-14 UNKNOWN"}, groups)
+	assert.Equal(t, 2, summary.ToothIndices[0].Students)
+	assert.Equal(t, 2.0, summary.ToothIndices[0].MeanDefT)
+	assert.Equal(t, 3.0, summary.ToothIndices[2].MeanDMFT)
+
+	assert.Len(t, summary.AgeBands, 2)
+	assert.Equal(t, 3, summary.AgeBands[0].Students)
+	assert.InDelta(t, 1.0/3, summary.AgeBands[0].MeanDMFT, 0.0001)
+
+	assert.Equal(t, []*DiagnosisCount{
+		{Diagnosis: "Pit Fissure Dalam", Count: 2},
+		{Diagnosis: "Free Karies", Count: 1},
+	}, summary.TopDiagnoses)
+
+	// the costs leave out the cases of the earlier survey too
+	assert.Equal(t, []*CostReport{
+		{Description: "Sealant", Cost: 100, PerformedCost: 50, PlannedCount: 2, PerformedCount: 1},
+		{Description: "Tumpatan", Cost: 100, PlannedCount: 1},
+		{Description: "Total", Cost: 200, PerformedCost: 50, PlannedCount: 3, PerformedCount: 1},
+	}, summary.Costs)
+}
+
+func TestNewSchoolSummaryWithoutSurveys(t *testing.T) {
+	summary := NewSchoolSummary(0, nil, nil)
+
+	assert.Equal(t, 0, summary.Surveyed)
+	assert.Equal(t, 0.0, summary.ParticipationRate())
+	assert.Equal(t, 0.0, summary.MeanSubjectiveScore)
+	assert.Len(t, summary.RiskDistribution, 3)
+	assert.Empty(t, summary.ToothIndices)
+	assert.Empty(t, summary.TopDiagnoses)
+	assert.Equal(t, []*CostReport{{Description: "Total"}}, summary.Costs)
+}
+
+func TestAgeBand(t *testing.T) {
+	assert.Equal(t, "6-8", SchoolSummaryAgeBands[1].String())
+	assert.Equal(t, "15+", SchoolSummaryAgeBands[4].String())
+	assert.True(t, SchoolSummaryAgeBands[4].Contains(40))
+	assert.False(t, SchoolSummaryAgeBands[1].Contains(9))
+}
+
+func TestValidateSex(t *testing.T) {
+	sex := "OTHER"
+	assert.NoError(t, ValidateSex("sex", nil))
+	assert.Error(t, ValidateSex("sex", &sex))
+}
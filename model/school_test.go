@@ -0,0 +1,47 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchoolAccess(t *testing.T) {
+
+	t.Run("Supervisor", func(t *testing.T) {
+		access := NewSchoolAccess([]*Role{{Name: RoleSupervisor}}, nil)
+
+		assert.True(t, access.Allows("fakeSchoolID"))
+		assert.Nil(t, access.Check([]string{"fakeSchoolID"}))
+	})
+
+	t.Run("AssignedSchools", func(t *testing.T) {
+		access := NewSchoolAccess([]*Role{{Name: RoleSurveyor}}, []string{"school1"})
+
+		assert.True(t, access.Allows("school1"))
+		assert.False(t, access.Allows("school2"))
+		assert.Nil(t, access.Check([]string{"school1"}))
+
+		err := access.Check([]string{"school1", "school2"})
+		assert.NotNil(t, err)
+		assert.Equal(t, ErrorCodeForbidden, AsError(err).Code)
+	})
+
+	t.Run("NoSchools", func(t *testing.T) {
+		access := NewSchoolAccess(nil, nil)
+
+		assert.False(t, access.Allows("school1"))
+		assert.Nil(t, access.Check(nil))
+	})
+}
+
+func TestValidateTimeZone(t *testing.T) {
+	assert.Nil(t, ValidateTimeZone("Asia/Jakarta"))
+	assert.Nil(t, ValidateTimeZone("UTC"))
+
+	for _, timeZone := range []string{"", "Local", "Mars/Olympus"} {
+		err := ValidateTimeZone(timeZone)
+		assert.NotNil(t, err)
+		assert.Equal(t, "timeZone", err.(*Error).Fields[0].Field)
+	}
+}
@@ -1,11 +1,37 @@
 package model
 
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	SexMale   = "MALE"
+	SexFemale = "FEMALE"
+	// SexUnknown groups students whose sex is not recorded
+	SexUnknown = "UNKNOWN"
+)
+
+// Sexes lists the sexes a student can be recorded with
+var Sexes = []string{SexMale, SexFemale}
+
 // Student is the student entity
 type Student struct {
 	ID          string
 	Name        string
-	DateOfBirth string `db:"date_of_birth"`
-	SchoolID    string `db:"school_id"`
+	DateOfBirth string  `db:"date_of_birth"`
+	Sex         *string `db:"sex"`
+	SchoolID    string  `db:"school_id"`
 	// School      *School
 	CreatedAt string `db:"created_at"`
+	UpdatedAt string `db:"updated_at"`
+}
+
+// ValidateSex makes sure the recorded sex of a student, when given, is one of
+// Sexes
+func ValidateSex(field string, sex *string) error {
+	if sex != nil && !isOneOf(*sex, Sexes) {
+		return NewFieldError(field, fmt.Sprintf("invalid sex %s, expecting one of %s", *sex, strings.Join(Sexes, ", ")))
+	}
+	return nil
 }
@@ -0,0 +1,166 @@
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// StudentHistory is the oral health of a student over the surveys recorded
+// for them, oldest first
+type StudentHistory struct {
+	StudentID string
+	Visits    []*StudentVisit
+}
+
+// StudentVisit is the oral health of a student at one survey
+type StudentVisit struct {
+	SurveyID        string
+	Date            string
+	SubjectiveScore int32
+	RiskCategory    string
+	RiskAssessments []*RiskAssessment
+	Indices         ToothIndices
+	// Lesions lists the teeth with caries, sorted by FDI code
+	Lesions []int32
+
+	// Change, NewLesions and ResolvedLesions compare the visit to the previous
+	// one and are empty for the first visit
+	Change          *StudentVisitChange
+	NewLesions      []int32
+	ResolvedLesions []int32
+}
+
+// StudentVisitChange is the difference between a visit and the previous one
+type StudentVisitChange struct {
+	DaysSincePrevious   int32
+	SubjectiveScore     int32
+	Indices             ToothIndices
+	RiskCategoryChanged bool
+}
+
+// NewStudentVisit records the survey as a visit with caries on the given
+// teeth, categorized with the named risk model
+func NewStudentVisit(s *Survey, lesions []int32, riskModel string) *StudentVisit {
+	sorted := append([]int32{}, lesions...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &StudentVisit{
+		SurveyID:        s.ID,
+		Date:            s.Date,
+		SubjectiveScore: s.SubjectiveScore,
+		RiskCategory:    s.RiskCategory(riskModel),
+		RiskAssessments: s.RiskAssessments,
+		Indices:         s.Indices(),
+		Lesions:         sorted,
+	}
+}
+
+// NewStudentHistory orders the visits of a student by date and compares every
+// visit to the previous one.
+func NewStudentHistory(studentID string, visits []*StudentVisit) *StudentHistory {
+	sort.SliceStable(visits, func(i, j int) bool {
+		return visitDate(visits[i]).Before(visitDate(visits[j]))
+	})
+
+	for i := 1; i < len(visits); i++ {
+		previous, current := visits[i-1], visits[i]
+
+		current.Change = &StudentVisitChange{
+			DaysSincePrevious:   int32(visitDate(current).Sub(visitDate(previous)).Hours() / 24),
+			SubjectiveScore:     current.SubjectiveScore - previous.SubjectiveScore,
+			Indices:             current.Indices.Sub(previous.Indices),
+			RiskCategoryChanged: current.RiskCategory != previous.RiskCategory,
+		}
+		current.NewLesions = teethDifference(current.Lesions, previous.Lesions)
+		current.ResolvedLesions = teethDifference(previous.Lesions, current.Lesions)
+	}
+
+	return &StudentHistory{StudentID: studentID, Visits: visits}
+}
+
+// Until returns the history up to and including the visit of the given survey
+func (h *StudentHistory) Until(surveyID string) *StudentHistory {
+	for i, visit := range h.Visits {
+		if visit.SurveyID == surveyID {
+			return &StudentHistory{StudentID: h.StudentID, Visits: h.Visits[:i+1]}
+		}
+	}
+	return h
+}
+
+// Sub returns the difference between two sets of counts
+func (t ToothIndices) Sub(other ToothIndices) ToothIndices {
+	return ToothIndices{
+		LowerD: t.LowerD - other.LowerD,
+		LowerE: t.LowerE - other.LowerE,
+		LowerF: t.LowerF - other.LowerF,
+		UpperD: t.UpperD - other.UpperD,
+		UpperM: t.UpperM - other.UpperM,
+		UpperF: t.UpperF - other.UpperF,
+	}
+}
+
+// DMFT returns the DMF-T index of the permanent dentition
+func (t ToothIndices) DMFT() int32 {
+	return t.UpperD + t.UpperM + t.UpperF
+}
+
+// DEFT returns the def-t index of the primary dentition
+func (t ToothIndices) DEFT() int32 {
+	return t.LowerD + t.LowerE + t.LowerF
+}
+
+// LesionTeeth returns the teeth with caries according to the cases and the
+// dental chart of a survey, given the index component of every diagnosis
+// keyed by diagnosis and action ID.
+func LesionTeeth(cases []*Case, components map[string]string, odontogram *Odontogram) []int32 {
+	teeth := make(map[int32]bool)
+
+	for _, c := range cases {
+		if components[c.DiagnosisAndActionID] == IndexComponentDecayed {
+			teeth[c.ToothNumber] = true
+		}
+	}
+
+	if odontogram != nil {
+		for _, tooth := range odontogram.Teeth {
+			if tooth.Status == ToothStatusDecayed {
+				teeth[tooth.ToothNumber] = true
+			}
+			for _, surface := range tooth.Surfaces {
+				if surface.Condition == SurfaceConditionCaries {
+					teeth[tooth.ToothNumber] = true
+				}
+			}
+		}
+	}
+
+	lesions := make([]int32, 0, len(teeth))
+	for toothNumber := range teeth {
+		lesions = append(lesions, toothNumber)
+	}
+	sort.Slice(lesions, func(i, j int) bool { return lesions[i] < lesions[j] })
+
+	return lesions
+}
+
+// teethDifference returns the teeth of a that are not in b
+func teethDifference(a []int32, b []int32) []int32 {
+	inB := make(map[int32]bool)
+	for _, toothNumber := range b {
+		inB[toothNumber] = true
+	}
+
+	difference := make([]int32, 0)
+	for _, toothNumber := range a {
+		if !inB[toothNumber] {
+			difference = append(difference, toothNumber)
+		}
+	}
+	return difference
+}
+
+func visitDate(v *StudentVisit) time.Time {
+	t, _ := parseStoredDate(v.Date)
+	return t
+}
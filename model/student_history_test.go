@@ -0,0 +1,61 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStudentHistory(t *testing.T) {
+	decayedID, filledID := "decayed", "filled"
+	components := map[string]string{
+		decayedID: IndexComponentDecayed,
+		filledID:  IndexComponentFilled,
+	}
+
+	t.Run("LesionTeeth", func(t *testing.T) {
+		cases := []*Case{
+			{ToothNumber: 36, DiagnosisAndActionID: decayedID},
+			{ToothNumber: 26, DiagnosisAndActionID: filledID},
+		}
+		odontogram := &Odontogram{Teeth: []*Tooth{
+			{ToothNumber: 16, Status: ToothStatusSound, Surfaces: []*ToothSurface{{Surface: SurfaceOcclusal, Condition: SurfaceConditionCaries}}},
+			{ToothNumber: 55, Status: ToothStatusDecayed},
+			{ToothNumber: 46, Status: ToothStatusSound, Surfaces: []*ToothSurface{{Surface: SurfaceBuccal, Condition: SurfaceConditionSealant}}},
+		}}
+
+		assert.Equal(t, []int32{16, 36, 55}, LesionTeeth(cases, components, odontogram))
+		assert.Equal(t, []int32{36}, LesionTeeth(cases, components, nil))
+	})
+
+	t.Run("ComparesVisits", func(t *testing.T) {
+		first := NewStudentVisit(&Survey{ID: "first", Date: "2025-01-10T00:00:00Z", SubjectiveScore: 30, UpperD: 2}, []int32{36, 16}, RiskModelIDCRA)
+		second := NewStudentVisit(&Survey{ID: "second", Date: "2025-07-09", SubjectiveScore: 70, UpperD: 1, UpperF: 1}, []int32{46, 16}, RiskModelIDCRA)
+
+		sut := NewStudentHistory("student", []*StudentVisit{second, first})
+
+		assert.Equal(t, "first", sut.Visits[0].SurveyID)
+		assert.Equal(t, []int32{16, 36}, sut.Visits[0].Lesions)
+		assert.Nil(t, sut.Visits[0].Change)
+
+		change := sut.Visits[1].Change
+		assert.Equal(t, int32(180), change.DaysSincePrevious)
+		assert.Equal(t, int32(40), change.SubjectiveScore)
+		assert.Equal(t, ToothIndices{UpperD: -1, UpperF: 1}, change.Indices)
+		assert.Equal(t, int32(0), change.Indices.DMFT())
+		assert.True(t, change.RiskCategoryChanged)
+		assert.Equal(t, []int32{46}, sut.Visits[1].NewLesions)
+		assert.Equal(t, []int32{36}, sut.Visits[1].ResolvedLesions)
+	})
+
+	t.Run("Until", func(t *testing.T) {
+		sut := NewStudentHistory("student", []*StudentVisit{
+			{SurveyID: "first", Date: "2025-01-10"},
+			{SurveyID: "second", Date: "2025-07-10"},
+			{SurveyID: "third", Date: "2026-01-10"},
+		})
+
+		assert.Len(t, sut.Until("second").Visits, 2)
+		assert.Len(t, sut.Until("unknown").Visits, 3)
+	})
+}
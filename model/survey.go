@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	uuid "github.com/satori/go.uuid"
@@ -10,69 +11,120 @@ import (
 // Survey is the survey entity
 type Survey struct {
 	ID              string
-	StudentID       string `db:"student_id"`
-	SurveyorID      string `db:"surveyor_id"`
-	Date            string `db:"date"`
-	S1Q1            string `db:"s1q1"`
-	S1Q2            string `db:"s1q2"`
-	S1Q3            string `db:"s1q3"`
-	S1Q4            string `db:"s1q4"`
-	S1Q5            string `db:"s1q5"`
-	S1Q6            string `db:"s1q6"`
-	S1Q7            string `db:"s1q7"`
-	S2Q1            string `db:"s2q1"`
-	S2Q2            string `db:"s2q2"`
-	S2Q3            string `db:"s2q3"`
-	S2Q4            string `db:"s2q4"`
-	S2Q5            string `db:"s2q5"`
-	S2Q6            string `db:"s2q6"`
-	S2Q7            string `db:"s2q7"`
-	S2Q8            string `db:"s2q8"`
-	S2Q9            string `db:"s2q9"`
-	LowerD          int32  `db:"lower_d"`
-	LowerE          int32  `db:"lower_e"`
-	LowerF          int32  `db:"lower_f"`
-	UpperD          int32  `db:"upper_d"`
-	UpperM          int32  `db:"upper_m"`
-	UpperF          int32  `db:"upper_f"`
-	SubjectiveScore int32  `db:"subjective_score"`
-	CreatedAt       string `db:"created_at"`
+	StudentID       string  `db:"student_id"`
+	SurveyorID      string  `db:"surveyor_id"`
+	Date            string  `db:"date"`
+	QuestionnaireID *string `db:"questionnaire_id"`
+	S1Q1            string  `db:"-"`
+	S1Q2            string  `db:"-"`
+	S1Q3            string  `db:"-"`
+	S1Q4            string  `db:"-"`
+	S1Q5            string  `db:"-"`
+	S1Q6            string  `db:"-"`
+	S1Q7            string  `db:"-"`
+	S2Q1            string  `db:"-"`
+	S2Q2            string  `db:"-"`
+	S2Q3            string  `db:"-"`
+	S2Q4            string  `db:"-"`
+	S2Q5            string  `db:"-"`
+	S2Q6            string  `db:"-"`
+	S2Q7            string  `db:"-"`
+	S2Q8            string  `db:"-"`
+	S2Q9            string  `db:"-"`
+	LowerD          int32   `db:"lower_d"`
+	LowerE          int32   `db:"lower_e"`
+	LowerF          int32   `db:"lower_f"`
+	UpperD          int32   `db:"upper_d"`
+	UpperM          int32   `db:"upper_m"`
+	UpperF          int32   `db:"upper_f"`
+	ComputedLowerD  int32   `db:"computed_lower_d"`
+	ComputedLowerE  int32   `db:"computed_lower_e"`
+	ComputedLowerF  int32   `db:"computed_lower_f"`
+	ComputedUpperD  int32   `db:"computed_upper_d"`
+	ComputedUpperM  int32   `db:"computed_upper_m"`
+	ComputedUpperF  int32   `db:"computed_upper_f"`
+	// ToothIndexMismatch flags surveys whose entered def-t or DMF-T counts
+	// differ from the counts derived from their cases
+	ToothIndexMismatch bool  `db:"tooth_index_mismatch"`
+	SubjectiveScore    int32 `db:"subjective_score"`
+	// Status is where the survey is in the review workflow; SubmittedAt and
+	// the review fields are those of the latest submission and review
+	Status          string  `db:"status"`
+	SubmittedAt     *string `db:"submitted_at"`
+	ReviewedBy      *string `db:"reviewed_by"`
+	ReviewedAt      *string `db:"reviewed_at"`
+	ReviewComment   *string `db:"review_comment"`
+	CreatedAt       string  `db:"created_at"`
+	UpdatedAt       string  `db:"updated_at"`
 	Cases           []*Case
+	Answers         []*SurveyAnswer
+	RiskAssessments []*RiskAssessment
+
+	// RecommendationVersion is the version of the recommendation rules
+	// published when the survey was approved, which its reports are printed
+	// with
+	RecommendationVersion *int32 `db:"recommendation_version"`
+
+	// omittedToothCounts lists the counts left out of the input, to be filled
+	// in with the counts derived from the cases
+	omittedToothCounts []string
 }
 
-func (s *Survey) GetScore(answer string) int {
-	switch answer {
-	case "Low":
-		return 1
-	case "Medium":
-		return 2
-	case "High":
-		return 3
-	default:
-		return 0
+// legacyQuestionCodes lists the questions of the legacy questionnaire in order
+var legacyQuestionCodes = []string{
+	"s1q1", "s1q2", "s1q3", "s1q4", "s1q5", "s1q6", "s1q7",
+	"s2q1", "s2q2", "s2q3", "s2q4", "s2q5", "s2q6", "s2q7", "s2q8", "s2q9",
+}
+
+// SurveyAnswer is the answer to a questionnaire question
+type SurveyAnswer struct {
+	SurveyID     string `db:"survey_id"`
+	QuestionID   string `db:"question_id"`
+	QuestionCode string `db:"question_code"`
+	Value        string
+	Score        float64
+}
+
+// AnswerInput is the input for survey answer entity
+type AnswerInput struct {
+	QuestionCode string
+	Value        string
+}
+
+// legacyAnswers returns the fields holding the answers to the legacy
+// questionnaire, keyed by question code.
+func (s *Survey) legacyAnswers() map[string]*string {
+	return map[string]*string{
+		"s1q1": &s.S1Q1,
+		"s1q2": &s.S1Q2,
+		"s1q3": &s.S1Q3,
+		"s1q4": &s.S1Q4,
+		"s1q5": &s.S1Q5,
+		"s1q6": &s.S1Q6,
+		"s1q7": &s.S1Q7,
+		"s2q1": &s.S2Q1,
+		"s2q2": &s.S2Q2,
+		"s2q3": &s.S2Q3,
+		"s2q4": &s.S2Q4,
+		"s2q5": &s.S2Q5,
+		"s2q6": &s.S2Q6,
+		"s2q7": &s.S2Q7,
+		"s2q8": &s.S2Q8,
+		"s2q9": &s.S2Q9,
 	}
 }
 
-func (s *Survey) CalculateScore() {
-	score := 0
-	score += s.GetScore(s.S1Q1)
-	score += s.GetScore(s.S1Q2)
-	score += s.GetScore(s.S1Q3)
-	score += s.GetScore(s.S1Q4)
-	score += s.GetScore(s.S1Q5)
-	score += s.GetScore(s.S1Q6)
-	score += s.GetScore(s.S1Q7)
-	score += s.GetScore(s.S2Q1)
-	score += s.GetScore(s.S2Q2)
-	score += s.GetScore(s.S2Q3)
-	score += s.GetScore(s.S2Q4)
-	score += s.GetScore(s.S2Q5)
-	score += s.GetScore(s.S2Q6)
-	score += s.GetScore(s.S2Q7)
-	score += s.GetScore(s.S2Q8)
-	score += s.GetScore(s.S2Q9)
-	normalizedScore := (score - 16) * 100 / (37 - 16)
-	s.SubjectiveScore = int32(normalizedScore)
+// SetAnswers sets the answers of the survey, filling in the legacy answer
+// fields for answers to questions with a legacy question code.
+func (s *Survey) SetAnswers(answers []*SurveyAnswer) {
+	s.Answers = answers
+
+	legacyAnswers := s.legacyAnswers()
+	for _, answer := range answers {
+		if field, ok := legacyAnswers[answer.QuestionCode]; ok {
+			*field = answer.Value
+		}
+	}
 }
 
 // SurveyInput is the input for section entity
@@ -103,115 +155,181 @@ type SurveyInput struct {
 	UpperM     *int32
 	UpperF     *int32
 	Cases      *[]*CaseInput
-}
 
-func (si *SurveyInput) Validate() error {
-	if si.StudentID == nil {
-		return fmt.Errorf("student ID is required")
-	}
-
-	if si.SurveyorID == nil {
-		return fmt.Errorf("surveyor ID is required")
-	}
+	// ToothIndexMode is MANUAL when omitted. In COMPUTED mode the def-t and
+	// DMF-T counts are optional and filled in from the cases.
+	ToothIndexMode *string
 
-	if si.Date == nil {
-		return fmt.Errorf("date is required")
-	}
-
-	_, err := time.Parse("2006-01-02", *si.Date)
-	if err != nil {
-		return fmt.Errorf("invalid date format, expecting yyyy-mm-dd")
-	}
-
-	if si.S1Q1 == nil {
-		return fmt.Errorf("section 1 question 1 is required")
-	}
-
-	if si.S1Q2 == nil {
-		return fmt.Errorf("section 1 question 2 is required")
-	}
-
-	if si.S1Q3 == nil {
-		return fmt.Errorf("section 1 question 3 is required")
-	}
-
-	if si.S1Q4 == nil {
-		return fmt.Errorf("section 1 question 4 is required")
-	}
-
-	if si.S1Q5 == nil {
-		return fmt.Errorf("section 1 question 5 is required")
-	}
+	// QuestionnaireID and Answers record the survey against a questionnaire.
+	// Without answers, S1Q1 to S2Q9 answer the legacy questionnaire.
+	QuestionnaireID *string
+	Answers         *[]*AnswerInput
+}
 
-	if si.S1Q6 == nil {
-		return fmt.Errorf("section 1 question 6 is required")
-	}
+// RiskAnswers lists the answers to the questions of the legacy questionnaire
+var RiskAnswers = []string{"Low", "Medium", "High"}
 
-	if si.S1Q7 == nil {
-		return fmt.Errorf("section 1 question 7 is required")
+// AssignSurveyor records the survey under the signed-in user. A different
+// surveyor in the input is refused, so nobody can record a survey in another
+// user's name and then review it.
+func (si *SurveyInput) AssignSurveyor(userID string) error {
+	if si.SurveyorID != nil && *si.SurveyorID != userID {
+		return NewForbiddenError("surveys can only be recorded by the signed-in surveyor")
 	}
+	si.SurveyorID = &userID
+	return nil
+}
 
-	if si.S2Q1 == nil {
-		return fmt.Errorf("section 2 question 1 is required")
-	}
+func (si *SurveyInput) Validate() error {
+	return si.validate(false)
+}
 
-	if si.S2Q2 == nil {
-		return fmt.Errorf("section 2 question 2 is required")
-	}
+// ValidateDraft checks a survey saved as a draft. Only the student, surveyor
+// and date are required, while every other field is checked when given.
+func (si *SurveyInput) ValidateDraft() error {
+	return si.validate(true)
+}
 
-	if si.S2Q3 == nil {
-		return fmt.Errorf("section 2 question 3 is required")
-	}
+func (si *SurveyInput) validate(draft bool) error {
+	v := &validator{}
 
-	if si.S2Q4 == nil {
-		return fmt.Errorf("section 2 question 4 is required")
+	if si.StudentID == nil {
+		v.add("studentId", "student ID is required")
 	}
 
-	if si.S2Q5 == nil {
-		return fmt.Errorf("section 2 question 5 is required")
+	if si.SurveyorID == nil {
+		v.add("surveyorId", "surveyor ID is required")
 	}
 
-	if si.S2Q6 == nil {
-		return fmt.Errorf("section 2 question 6 is required")
+	if si.Date == nil {
+		v.add("date", "date is required")
+	} else if _, err := time.Parse("2006-01-02", *si.Date); err != nil {
+		v.add("date", "invalid date format, expecting yyyy-mm-dd")
+	} else if *si.Date > time.Now().Format("2006-01-02") {
+		v.add("date", "date must not be in the future")
 	}
 
-	if si.S2Q7 == nil {
-		return fmt.Errorf("section 2 question 7 is required")
+	if si.Answers == nil {
+		si.validateLegacyAnswers(v, draft)
 	}
 
-	if si.S2Q8 == nil {
-		return fmt.Errorf("section 2 question 8 is required")
+	if si.ToothIndexMode != nil && !isOneOf(*si.ToothIndexMode, ToothIndexModes) {
+		v.addf("toothIndexMode", "invalid tooth index mode %s, expecting one of %s", *si.ToothIndexMode, strings.Join(ToothIndexModes, ", "))
 	}
 
-	if si.S2Q9 == nil {
-		return fmt.Errorf("section 2 question 9 is required")
+	si.validateToothCounts(v, draft, "lower d, e and f", MaxPrimaryTeeth, []toothCount{
+		{"lowerD", "lower d", si.LowerD},
+		{"lowerE", "lower e", si.LowerE},
+		{"lowerF", "lower f", si.LowerF},
+	})
+	si.validateToothCounts(v, draft, "upper D, M and F", MaxPermanentTeeth, []toothCount{
+		{"upperD", "upper D", si.UpperD},
+		{"upperM", "upper M", si.UpperM},
+		{"upperF", "upper F", si.UpperF},
+	})
+
+	if si.Cases != nil {
+		teeth := make(map[int32]int)
+		for i, ci := range *si.Cases {
+			field := fmt.Sprintf("cases[%d]", i)
+			if ci == nil {
+				v.add(field, "case is required")
+				continue
+			}
+
+			v.merge(ci.Validate(), field+".")
+
+			if ci.ToothNumber == nil {
+				continue
+			}
+			if j, ok := teeth[*ci.ToothNumber]; ok {
+				v.addf(field+".toothNumber", "tooth %d is already recorded in cases[%d]", *ci.ToothNumber, j)
+				continue
+			}
+			teeth[*ci.ToothNumber] = i
+		}
 	}
 
-	if si.LowerD == nil {
-		return fmt.Errorf("lower d is required")
-	}
+	return v.err()
+}
 
-	if si.LowerE == nil {
-		return fmt.Errorf("lower e is required")
+func (si *SurveyInput) validateLegacyAnswers(v *validator, draft bool) {
+	answers := []struct {
+		field string
+		name  string
+		value *string
+	}{
+		{"s1q1", "section 1 question 1", si.S1Q1},
+		{"s1q2", "section 1 question 2", si.S1Q2},
+		{"s1q3", "section 1 question 3", si.S1Q3},
+		{"s1q4", "section 1 question 4", si.S1Q4},
+		{"s1q5", "section 1 question 5", si.S1Q5},
+		{"s1q6", "section 1 question 6", si.S1Q6},
+		{"s1q7", "section 1 question 7", si.S1Q7},
+		{"s2q1", "section 2 question 1", si.S2Q1},
+		{"s2q2", "section 2 question 2", si.S2Q2},
+		{"s2q3", "section 2 question 3", si.S2Q3},
+		{"s2q4", "section 2 question 4", si.S2Q4},
+		{"s2q5", "section 2 question 5", si.S2Q5},
+		{"s2q6", "section 2 question 6", si.S2Q6},
+		{"s2q7", "section 2 question 7", si.S2Q7},
+		{"s2q8", "section 2 question 8", si.S2Q8},
+		{"s2q9", "section 2 question 9", si.S2Q9},
 	}
 
-	if si.LowerF == nil {
-		return fmt.Errorf("lower f is required")
+	for _, answer := range answers {
+		if answer.value == nil {
+			if !draft {
+				v.addf(answer.field, "%s is required", answer.name)
+			}
+		} else if !isOneOf(*answer.value, RiskAnswers) {
+			v.addf(answer.field, "invalid answer %s to %s, expecting one of %s", *answer.value, answer.name, strings.Join(RiskAnswers, ", "))
+		}
 	}
+}
 
-	if si.UpperD == nil {
-		return fmt.Errorf("upper D is required")
-	}
+type toothCount struct {
+	field string
+	name  string
+	value *int32
+}
 
-	if si.UpperM == nil {
-		return fmt.Errorf("upper M is required")
+// validateToothCounts checks the decayed, missing or extracted, and filled
+// tooth counts of a dentition with the given number of teeth. Counts are
+// optional in drafts.
+func (si *SurveyInput) validateToothCounts(v *validator, draft bool, names string, teeth int32, counts []toothCount) {
+	var (
+		total    int32
+		complete = true
+	)
+
+	for _, count := range counts {
+		switch {
+		case count.value == nil && (draft || si.computesToothIndices()):
+			complete = false
+		case count.value == nil:
+			v.addf(count.field, "%s is required", count.name)
+			complete = false
+		case *count.value < 0:
+			v.addf(count.field, "%s must not be negative", count.name)
+			complete = false
+		case *count.value > teeth:
+			v.addf(count.field, "%s must not exceed %d", count.name, teeth)
+			complete = false
+		default:
+			total += *count.value
+		}
 	}
 
-	if si.UpperF == nil {
-		return fmt.Errorf("upper F is required")
+	if complete && total > teeth {
+		v.addf(counts[0].field, "%s must not exceed %d teeth in total", names, teeth)
 	}
+}
 
-	return nil
+// computesToothIndices reports whether counts left out of the input are filled
+// in from the cases.
+func (si *SurveyInput) computesToothIndices() bool {
+	return si.ToothIndexMode != nil && *si.ToothIndexMode == ToothIndexModeComputed
 }
 
 func NewSurveyFromInput(input SurveyInput) (s Survey, err error) {
@@ -224,36 +342,60 @@ func NewSurveyFromInput(input SurveyInput) (s Survey, err error) {
 		StudentID:  *input.StudentID,
 		SurveyorID: *input.SurveyorID,
 		Date:       *input.Date,
-		S1Q1:       *input.S1Q1,
-		S1Q2:       *input.S1Q2,
-		S1Q3:       *input.S1Q3,
-		S1Q4:       *input.S1Q4,
-		S1Q5:       *input.S1Q5,
-		S1Q6:       *input.S1Q6,
-		S1Q7:       *input.S1Q7,
-		S2Q1:       *input.S2Q1,
-		S2Q2:       *input.S2Q2,
-		S2Q3:       *input.S2Q3,
-		S2Q4:       *input.S2Q4,
-		S2Q5:       *input.S2Q5,
-		S2Q6:       *input.S2Q6,
-		S2Q7:       *input.S2Q7,
-		S2Q8:       *input.S2Q8,
-		S2Q9:       *input.S2Q9,
-		LowerD:     *input.LowerD,
-		LowerE:     *input.LowerE,
-		LowerF:     *input.LowerF,
-		UpperD:     *input.UpperD,
-		UpperM:     *input.UpperM,
-		UpperF:     *input.UpperF,
+		Status:     SurveyStatusSubmitted,
 		CreatedAt:  time.Now().Format("2006-01-02 15:04:05"),
 		Cases:      []*Case{},
 	}
 
-	for _, ci := range *input.Cases {
+	counts := s.toothCounts()
+	inputCounts := map[string]*int32{
+		"lowerD": input.LowerD,
+		"lowerE": input.LowerE,
+		"lowerF": input.LowerF,
+		"upperD": input.UpperD,
+		"upperM": input.UpperM,
+		"upperF": input.UpperF,
+	}
+	for _, field := range toothCountFields {
+		if inputCounts[field] == nil {
+			s.omittedToothCounts = append(s.omittedToothCounts, field)
+			continue
+		}
+		*counts[field] = *inputCounts[field]
+	}
+
+	var answers []*SurveyAnswer
+	if input.Answers != nil {
+		if input.QuestionnaireID != nil {
+			questionnaireID := *input.QuestionnaireID
+			s.QuestionnaireID = &questionnaireID
+		}
+
+		for _, ai := range *input.Answers {
+			answers = append(answers, &SurveyAnswer{SurveyID: s.ID, QuestionCode: ai.QuestionCode, Value: ai.Value})
+		}
+	} else {
+		questionnaireID := LegacyQuestionnaireID
+		s.QuestionnaireID = &questionnaireID
+
+		legacyAnswers := []*string{
+			input.S1Q1, input.S1Q2, input.S1Q3, input.S1Q4, input.S1Q5, input.S1Q6, input.S1Q7,
+			input.S2Q1, input.S2Q2, input.S2Q3, input.S2Q4, input.S2Q5, input.S2Q6, input.S2Q7, input.S2Q8, input.S2Q9,
+		}
+		for i, code := range legacyQuestionCodes {
+			answers = append(answers, &SurveyAnswer{SurveyID: s.ID, QuestionCode: code, Value: *legacyAnswers[i]})
+		}
+	}
+	s.SetAnswers(answers)
+
+	if input.Cases == nil {
+		return
+	}
+
+	for i, ci := range *input.Cases {
 		c, err := NewCaseFromInput(*ci, s.ID)
 		if err != nil {
-			return Survey{}, err
+			return Survey{}, prefixFieldErrors(err, fmt.Sprintf("cases[%d].", i))
 		}
 
 		s.Cases = append(s.Cases, &c)
@@ -0,0 +1,173 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	ExportFormatCSV  = "CSV"
+	ExportFormatXLSX = "XLSX"
+)
+
+// ExportFormats lists the file formats surveys can be exported as
+var ExportFormats = []string{ExportFormatCSV, ExportFormatXLSX}
+
+// SurveyExportFilter chooses the surveys to export and the file format. Dates
+// are yyyy-mm-dd, the end date not being part of the period.
+type SurveyExportFilter struct {
+	Format     string
+	SchoolID   *string
+	StartDate  *string
+	EndDate    *string
+	SurveyorID *string
+}
+
+// Validate checks the format and the period of the filter
+func (f *SurveyExportFilter) Validate() error {
+	v := &validator{}
+
+	if !isOneOf(f.Format, ExportFormats) {
+		v.addf("format", "invalid format %s, expecting one of %s", f.Format, strings.Join(ExportFormats, ", "))
+	}
+
+	var start, end time.Time
+	var err error
+	if f.StartDate != nil {
+		if start, err = time.Parse("2006-01-02", *f.StartDate); err != nil {
+			v.add("startDate", "invalid date format, expecting yyyy-mm-dd")
+		}
+	}
+	if f.EndDate != nil {
+		if end, err = time.Parse("2006-01-02", *f.EndDate); err != nil {
+			v.add("endDate", "invalid date format, expecting yyyy-mm-dd")
+		}
+	}
+	if !start.IsZero() && !end.IsZero() && !end.After(start) {
+		v.add("endDate", "end date must be after the start date")
+	}
+
+	return v.err()
+}
+
+// FileName returns the name an export made at a time is downloaded as
+func (f *SurveyExportFilter) FileName(now time.Time) string {
+	return fmt.Sprintf("surveys-%s.%s", now.Format("20060102-150405"), strings.ToLower(f.Format))
+}
+
+// DataScope is the part of the surveys a user may see. Admins and supervisors
+// see every survey, surveyors the surveys they recorded and parents the
+// surveys of their students.
+type DataScope struct {
+	All        bool
+	SurveyorID string
+	ParentID   string
+}
+
+// NewDataScope returns the scope of a user with the given roles
+func NewDataScope(userID string, roles []*Role) DataScope {
+	switch {
+	case HasRole(roles, RoleAdmin, RoleSupervisor):
+		return DataScope{All: true}
+	case HasRole(roles, RoleSurveyor):
+		return DataScope{SurveyorID: userID}
+	default:
+		return DataScope{ParentID: userID}
+	}
+}
+
+// SurveyExportRow is a survey as it is exported
+type SurveyExportRow struct {
+	ID              string    `db:"id"`
+	SchoolID        string    `db:"school_id"`
+	SchoolName      string    `db:"school_name"`
+	StudentID       string    `db:"student_id"`
+	Sex             *string   `db:"sex"`
+	DateOfBirth     time.Time `db:"date_of_birth"`
+	SurveyorID      string    `db:"surveyor_id"`
+	Date            time.Time `db:"date"`
+	Status          string    `db:"status"`
+	QuestionnaireID *string   `db:"questionnaire_id"`
+	SubjectiveScore float64   `db:"subjective_score"`
+	LowerD          int32     `db:"lower_d"`
+	LowerE          int32     `db:"lower_e"`
+	LowerF          int32     `db:"lower_f"`
+	UpperD          int32     `db:"upper_d"`
+	UpperM          int32     `db:"upper_m"`
+	UpperF          int32     `db:"upper_f"`
+	// Answers maps question codes to the answers of the survey
+	Answers map[string]string   `db:"-"`
+	Cases   []*SurveyExportCase `db:"-"`
+}
+
+// SurveyExportCase is a case of an exported survey
+type SurveyExportCase struct {
+	SurveyID        string  `db:"survey_id"`
+	ToothNumber     int32   `db:"tooth_number"`
+	Diagnosis       string  `db:"diagnosis"`
+	Action          string  `db:"action"`
+	TreatmentStatus string  `db:"treatment_status"`
+	UnitCost        float64 `db:"unit_cost"`
+}
+
+var surveyExportColumns = []string{
+	"survey_id", "school_id", "school_name", "student_id", "sex", "age",
+	"surveyor_id", "date", "status", "questionnaire_id", "subjective_score",
+	"risk_category", "lower_d", "lower_e", "lower_f", "def_t",
+	"upper_d", "upper_m", "upper_f", "dmf_t",
+}
+
+// SurveyExportCaseColumns are the headers of the cases of exported surveys
+var SurveyExportCaseColumns = []string{"survey_id", "tooth", "diagnosis", "action", "treatment_status", "unit_cost"}
+
+// SurveyExportColumns returns the headers of exported surveys, with a column
+// for the answer to every question and, when cases are flattened, a column
+// listing the cases
+func SurveyExportColumns(questionCodes []string, flattenCases bool) []string {
+	columns := append([]string{}, surveyExportColumns...)
+	for _, code := range questionCodes {
+		columns = append(columns, "answer_"+code)
+	}
+	if flattenCases {
+		columns = append(columns, "cases")
+	}
+	return columns
+}
+
+// Cells returns the values of the survey in the order of SurveyExportColumns.
+// Values are strings, int32 or float64 so spreadsheets keep numbers as
+// numbers.
+func (r *SurveyExportRow) Cells(questionCodes []string, flattenCases bool) []interface{} {
+	sex := ""
+	if r.Sex != nil {
+		sex = *r.Sex
+	}
+	questionnaireID := ""
+	if r.QuestionnaireID != nil {
+		questionnaireID = *r.QuestionnaireID
+	}
+
+	cells := []interface{}{
+		r.ID, r.SchoolID, r.SchoolName, r.StudentID, sex, AgeAt(r.DateOfBirth, r.Date),
+		r.SurveyorID, r.Date.Format("2006-01-02"), r.Status, questionnaireID, r.SubjectiveScore,
+		idcraRiskCategory(r.SubjectiveScore), r.LowerD, r.LowerE, r.LowerF, r.LowerD + r.LowerE + r.LowerF,
+		r.UpperD, r.UpperM, r.UpperF, r.UpperD + r.UpperM + r.UpperF,
+	}
+	for _, code := range questionCodes {
+		cells = append(cells, r.Answers[code])
+	}
+	if flattenCases {
+		cases := make([]string, len(r.Cases))
+		for i, c := range r.Cases {
+			cases[i] = fmt.Sprintf("%d %s - %s [%s]", c.ToothNumber, c.Diagnosis, c.Action, c.TreatmentStatus)
+		}
+		cells = append(cells, strings.Join(cases, "; "))
+	}
+	return cells
+}
+
+// Cells returns the values of the case in the order of SurveyExportCaseColumns
+func (c *SurveyExportCase) Cells() []interface{} {
+	return []interface{}{c.SurveyID, c.ToothNumber, c.Diagnosis, c.Action, c.TreatmentStatus, c.UnitCost}
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSurveyExportFilter(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	t.Run("Valid", func(t *testing.T) {
+		filter := SurveyExportFilter{Format: ExportFormatXLSX, StartDate: str("2026-01-01"), EndDate: str("2026-02-01")}
+		assert.NoError(t, filter.Validate())
+		assert.Equal(t, "surveys-20260102-030405.xlsx", filter.FileName(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		filter := SurveyExportFilter{Format: "PDF", StartDate: str("2026-02-01"), EndDate: str("2026-01-01")}
+		fields := map[string]bool{}
+		for _, f := range AsError(filter.Validate()).Fields {
+			fields[f.Field] = true
+		}
+		assert.Equal(t, map[string]bool{"format": true, "endDate": true}, fields)
+
+		filter = SurveyExportFilter{Format: ExportFormatCSV, StartDate: str("01/02/2026")}
+		assert.Equal(t, "startDate", AsError(filter.Validate()).Fields[0].Field)
+	})
+}
+
+func TestNewDataScope(t *testing.T) {
+	role := func(name string) *Role { return &Role{Name: name} }
+
+	assert.Equal(t, DataScope{All: true}, NewDataScope("user", []*Role{role(RoleSurveyor), role(RoleSupervisor)}))
+	assert.Equal(t, DataScope{All: true}, NewDataScope("user", []*Role{role(RoleAdmin)}))
+	assert.Equal(t, DataScope{SurveyorID: "user"}, NewDataScope("user", []*Role{role(RoleSurveyor)}))
+	assert.Equal(t, DataScope{ParentID: "user"}, NewDataScope("user", nil))
+}
+
+func TestSurveyExportRowCells(t *testing.T) {
+	sex := SexFemale
+	row := &SurveyExportRow{
+		ID:              "survey",
+		SchoolID:        "school",
+		SchoolName:      "SD 1",
+		StudentID:       "student",
+		Sex:             &sex,
+		DateOfBirth:     time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC),
+		SurveyorID:      "surveyor",
+		Date:            time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC),
+		Status:          SurveyStatusApproved,
+		SubjectiveScore: 70,
+		LowerD:          2,
+		LowerF:          1,
+		UpperM:          1,
+		Answers:         map[string]string{"s1q1": "High"},
+		Cases: []*SurveyExportCase{
+			{SurveyID: "survey", ToothNumber: 54, Diagnosis: "Caries", Action: "Filling", TreatmentStatus: "PLANNED"},
+			{SurveyID: "survey", ToothNumber: 16, Diagnosis: "Pit", Action: "Sealant", TreatmentStatus: "DONE"},
+		},
+	}
+	codes := []string{"s1q1", "s1q2"}
+
+	columns := SurveyExportColumns(codes, true)
+	cells := row.Cells(codes, true)
+	assert.Len(t, cells, len(columns))
+
+	byColumn := map[string]interface{}{}
+	for i, column := range columns {
+		byColumn[column] = cells[i]
+	}
+	assert.Equal(t, int32(7), byColumn["age"])
+	assert.Equal(t, "2026-05-31", byColumn["date"])
+	assert.Equal(t, RiskCategoryHigh, byColumn["risk_category"])
+	assert.Equal(t, int32(3), byColumn["def_t"])
+	assert.Equal(t, int32(1), byColumn["dmf_t"])
+	assert.Equal(t, "High", byColumn["answer_s1q1"])
+	assert.Equal(t, "", byColumn["answer_s1q2"])
+	assert.Equal(t, "54 Caries - Filling [PLANNED]; 16 Pit - Sealant [DONE]", byColumn["cases"])
+
+	assert.Len(t, row.Cells(codes, false), len(SurveyExportColumns(codes, false)))
+}
@@ -5,13 +5,37 @@ import "time"
 type SurveyReport struct {
 
 	// Preset values
+	StudentID     string    `db:"studentid"`
 	StudentName   string    `db:"studentname"`
+	SchoolID      string    `db:"schoolid"`
 	SchoolName    string    `db:"schoolname"`
 	DateOfSurvey  time.Time `db:"dateofsurvey"`
 	SCAPercentage float64   `db:"scapercentage"`
 	DValue        float64   `db:"dvalue"`
 	MValue        float64   `db:"mvalue"`
 	FValue        float64   `db:"fvalue"`
+	Status        string    `db:"status"`
+	DateOfBirth   time.Time `db:"dateofbirth"`
+	// ApprovedRecommendationVersion is the version of the recommendation
+	// rules recorded when the survey was approved, nil for unapproved surveys
+	ApprovedRecommendationVersion *int32 `db:"approvedrecommendationversion"`
+
+	// Preview marks a report printed before the survey is approved
+	Preview bool `db:"-"`
+	// Language is the language the report is printed in
+	Language string `db:"-"`
+	// Sample marks a report of made-up data printed to preview a template
+	Sample bool `db:"-"`
+	// Template brands the report, nil for the plain layout
+	Template *ReportTemplate `db:"-"`
+	// Verification is printed on the report so its authenticity can be
+	// checked, nil for reports that are not recorded
+	Verification *ReportVerification `db:"-"`
+
+	// Loaded separately
+	Odontogram *Odontogram          `db:"-"`
+	History    *StudentHistory      `db:"-"`
+	Photos     []*SurveyReportPhoto `db:"-"`
 
 	// Calculated values
 	RiskProfile                 string
@@ -25,95 +49,62 @@ type SurveyReport struct {
 	ParentSupervision           []string
 	TeacherReminder             []string
 	TeacherGuidance             []string
-}
 
-func (sr *SurveyReport) Setup() {
-	sr.RiskProfile = "low"
+	// RecommendationVersion is the version of the recommendation rules the
+	// report was printed with
+	RecommendationVersion int32
+}
 
-	if sr.SCAPercentage > 66 {
-		sr.RiskProfile = "high"
-	} else if sr.SCAPercentage > 33 && sr.SCAPercentage <= 66 {
-		sr.RiskProfile = "medium"
-	}
+// SurveyReportPhoto is a clinical photo printed in the survey report
+type SurveyReportPhoto struct {
+	Caption string
+	JPEG    []byte
+}
 
-	switch sr.RiskProfile {
+// Setup sets the risk profile of the survey and the recommendations of the
+// rules matching the survey, in the language of the report. Rules are applied
+// in order, so the first matching rule sets each operator suggestion.
+func (sr *SurveyReport) Setup(ruleSet *RecommendationRuleSet, facts RecommendationFacts) {
+	sr.RiskProfile = idcraRiskCategory(sr.SCAPercentage)
+	sr.RecommendationVersion = ruleSet.Version
+	facts.RiskCategory = sr.RiskProfile
 
-	case "low":
-		sr.ParentReminder = []string{
-			"Orang tua mengingatkan agar kontrol ke dokter gigi setiap 6 bulan sekali",
+	for _, rule := range ruleSet.Rules {
+		if !rule.Matches(facts) {
+			continue
 		}
-		sr.ParentGuidance = []string{
-			"Orang tua mengajarkan cara menyikat gigi yang benar",
-			"Orang tua mengingatkan agar menyikat gigi 2x sehari dengan pasta gigi ber fluoride",
+		text := rule.Text.Get(&sr.Language)
+		if text == nil {
+			continue
 		}
-		sr.ParentSupervision = []string{
-			"Orang tua memberikan pengawasan terhadap makanan manis dan lengket yang dikonsumsi sehari - hari",
-		}
-		sr.TeacherReminder = []string{
-			"Guru mengingatkan agar kontrol ke dokter gigi setiap 6 bulan sekali",
-		}
-		sr.TeacherGuidance = []string{
-			"Guru mengajarkan cara menyikat gigi yang benar",
-			"Guru mengingatkan agar menyikat gigi 2x sehari dengan pasta gigi ber fluoride",
-		}
-		sr.OperatorSuggestionRecurring = "setiap 6-12 bulan"
-		sr.OperatorSuggestionFluoride = "pasta gigi 2x sehari"
-		sr.OperatorSuggestionDiet = "pemeliharaan asupan diet"
-		sr.OperatorSuggestionSealant = "fissure sealant dilakukan jika diperlukan"
-		sr.OperatorSuggestionART = "pengawasan karies baru"
 
-	case "medium":
-		sr.ParentReminder = []string{
-			"Orang tua mengingatkan agar kontrol ke dokter gigi setiap 4-6 bulan sekali",
-		}
-		sr.ParentGuidance = []string{
-			"Orang tua mengajarkan cara menyikat gigi yang benar",
-			"Orang tua mengingatkan agar menyikat gigi 2x sehari dengan pasta gigi ber fluoride",
-			"Orang tua mengingatkan agar dilakukan perawatan topical aplikasi fluoride",
-		}
-		sr.ParentSupervision = []string{
-			"Orang tua melakukan diet makanan manis dan lengket yang dikonsumsi sehari- hari",
+		switch rule.Target {
+		case RecommendationTargetParentReminder:
+			sr.ParentReminder = append(sr.ParentReminder, *text)
+		case RecommendationTargetParentGuidance:
+			sr.ParentGuidance = append(sr.ParentGuidance, *text)
+		case RecommendationTargetParentSupervision:
+			sr.ParentSupervision = append(sr.ParentSupervision, *text)
+		case RecommendationTargetTeacherReminder:
+			sr.TeacherReminder = append(sr.TeacherReminder, *text)
+		case RecommendationTargetTeacherGuidance:
+			sr.TeacherGuidance = append(sr.TeacherGuidance, *text)
+		case RecommendationTargetOperatorRecurring:
+			setSuggestion(&sr.OperatorSuggestionRecurring, *text)
+		case RecommendationTargetOperatorFluoride:
+			setSuggestion(&sr.OperatorSuggestionFluoride, *text)
+		case RecommendationTargetOperatorDiet:
+			setSuggestion(&sr.OperatorSuggestionDiet, *text)
+		case RecommendationTargetOperatorSealant:
+			setSuggestion(&sr.OperatorSuggestionSealant, *text)
+		case RecommendationTargetOperatorART:
+			setSuggestion(&sr.OperatorSuggestionART, *text)
 		}
-		sr.TeacherReminder = []string{
-			"Guru mengingatkan agar kontrol ke dokter gigi setiap 4-6 bulan sekali",
-		}
-		sr.TeacherGuidance = []string{
-			"Guru mengajarkan cara menyikat gigi yang benar",
-			"Guru mengingatkan agar menyikat gigi 2x sehari dengan pasta gigi ber fluoride",
-			"Guru mengingatkan agar dilakukan perawatan topical aplikasi fluoride",
-		}
-		sr.OperatorSuggestionRecurring = "setiap 4-6 bulan"
-		sr.OperatorSuggestionFluoride = "pasta gigi 2x sehari + Topikal aplikasi"
-		sr.OperatorSuggestionDiet = "diet dengan pengawasan"
-		sr.OperatorSuggestionSealant = "fissure sealant dilakukan jika diperlukan"
-		sr.OperatorSuggestionART = "pengawasan karies baru + restorasi dari kavitas baru"
-
-	case "high":
-		sr.ParentReminder = []string{
-			"Orang tua mengingatkan agar kontrol ke dokter gigi setiap 3-4 bulan sekali",
-		}
-		sr.ParentGuidance = []string{
-			"Orang tua mengajarkan cara menyikat gigi yang benar",
-			"Orang tua mengingatkan agar menyikat gigi 2x sehari dengan pasta gigi ber fluoride",
-			"Orang tua mengingatkan agar dilakukan perawatan topical aplikasi fluoride",
-		}
-		sr.ParentSupervision = []string{
-			"Orang tua melakukan diet makanan manis dan lengket yang dikonsumsi sehari- hari",
-			"Orang tua mengganti konsumsi permen yang manis dengan permen xylitol",
-		}
-		sr.TeacherReminder = []string{
-			"Guru mengingatkan agar kontrol ke dokter gigi setiap 3-4 bulan sekali",
-		}
-		sr.TeacherGuidance = []string{
-			"Guru mengajarkan cara menyikat gigi yang benar",
-			"Guru mengingatkan agar menyikat gigi 2x sehari dengan pasta gigi ber fluoride",
-			"Guru mengingatkan agar dilakukan perawatan topical aplikasi fluoride",
-		}
-		sr.OperatorSuggestionRecurring = "setiap 3-4 bulan"
-		sr.OperatorSuggestionFluoride = "topikal aplikasi + pasta gigi 2x sehari"
-		sr.OperatorSuggestionDiet = "diet dengan pengawasan + xylitol"
-		sr.OperatorSuggestionSealant = "direkomendasikan fissure sealant"
-		sr.OperatorSuggestionSealant = "pengawasan karies baru + restorasi dari kavitas baru"
+	}
+}
 
+func setSuggestion(suggestion *string, text string) {
+	if *suggestion == "" {
+		*suggestion = text
 	}
 }
@@ -0,0 +1,229 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	SurveyStatusDraft     = "DRAFT"
+	SurveyStatusSubmitted = "SUBMITTED"
+	SurveyStatusApproved  = "APPROVED"
+	SurveyStatusRejected  = "REJECTED"
+
+	// maxReviewCommentLength is the longest comment a supervisor can leave on
+	// a review
+	maxReviewCommentLength = 1000
+)
+
+// SurveyStatuses lists where a survey can be in the review workflow
+var SurveyStatuses = []string{
+	SurveyStatusDraft,
+	SurveyStatusSubmitted,
+	SurveyStatusApproved,
+	SurveyStatusRejected,
+}
+
+// SurveyDraft is the input of a draft survey as last saved by the surveyor,
+// kept as JSON until the draft is submitted
+type SurveyDraft struct {
+	SurveyID  string `db:"survey_id"`
+	Input     string `db:"input"`
+	UpdatedAt string `db:"updated_at"`
+}
+
+// SurveyReview is the approval or rejection of a submitted survey by a
+// supervisor
+type SurveyReview struct {
+	ID         string
+	SurveyID   string  `db:"survey_id"`
+	Status     string  `db:"status"`
+	ReviewerID string  `db:"reviewer_id"`
+	Comment    *string `db:"comment"`
+	CreatedAt  string  `db:"created_at"`
+}
+
+// NewSurveyDraftFromInput validates the draft of a survey and returns the
+// survey to store for it, with the student, surveyor, date and the tooth
+// counts given so far, together with its input.
+func NewSurveyDraftFromInput(input SurveyInput) (Survey, *SurveyDraft, error) {
+	if err := input.ValidateDraft(); err != nil {
+		return Survey{}, nil, err
+	}
+
+	encoded, err := json.Marshal(input)
+	if err != nil {
+		return Survey{}, nil, err
+	}
+
+	s := Survey{
+		ID:         uuid.NewV4().String(),
+		StudentID:  *input.StudentID,
+		SurveyorID: *input.SurveyorID,
+		Date:       *input.Date,
+		Status:     SurveyStatusDraft,
+		CreatedAt:  time.Now().Format("2006-01-02 15:04:05"),
+		Cases:      []*Case{},
+	}
+	if input.QuestionnaireID != nil {
+		questionnaireID := *input.QuestionnaireID
+		s.QuestionnaireID = &questionnaireID
+	}
+
+	counts := s.toothCounts()
+	inputCounts := map[string]*int32{
+		"lowerD": input.LowerD,
+		"lowerE": input.LowerE,
+		"lowerF": input.LowerF,
+		"upperD": input.UpperD,
+		"upperM": input.UpperM,
+		"upperF": input.UpperF,
+	}
+	for _, field := range toothCountFields {
+		if inputCounts[field] != nil {
+			*counts[field] = *inputCounts[field]
+		}
+	}
+
+	return s, &SurveyDraft{SurveyID: s.ID, Input: string(encoded)}, nil
+}
+
+// SurveyInput returns the input saved with the draft
+func (d *SurveyDraft) SurveyInput() (SurveyInput, error) {
+	var input SurveyInput
+	if err := json.Unmarshal([]byte(d.Input), &input); err != nil {
+		return SurveyInput{}, fmt.Errorf("invalid draft of survey %s: %v", d.SurveyID, err)
+	}
+	return input, nil
+}
+
+// NewSurveyFromDraft validates the saved input of a draft as a complete survey
+// and returns the survey to submit, keeping the ID of the draft.
+func NewSurveyFromDraft(d *SurveyDraft) (Survey, error) {
+	input, err := d.SurveyInput()
+	if err != nil {
+		return Survey{}, err
+	}
+
+	s, err := NewSurveyFromInput(input)
+	if err != nil {
+		return Survey{}, err
+	}
+
+	s.ID = d.SurveyID
+	for _, answer := range s.Answers {
+		answer.SurveyID = d.SurveyID
+	}
+	for _, c := range s.Cases {
+		c.SurveyID = d.SurveyID
+	}
+
+	return s, nil
+}
+
+// NewSurveyReview validates the review of a survey by a supervisor. A
+// rejection needs a comment telling the surveyor what to correct.
+func NewSurveyReview(surveyID string, status string, reviewerID string, comment *string) (*SurveyReview, error) {
+	v := &validator{}
+
+	if status != SurveyStatusApproved && status != SurveyStatusRejected {
+		v.addf("status", "invalid review status %s, expecting %s or %s", status, SurveyStatusApproved, SurveyStatusRejected)
+	}
+
+	if comment != nil && strings.TrimSpace(*comment) == "" {
+		comment = nil
+	}
+	if comment == nil && status == SurveyStatusRejected {
+		v.add("comment", "comment is required to reject a survey")
+	} else if comment != nil && len(*comment) > maxReviewCommentLength {
+		v.addf("comment", "comment cannot be longer than %d characters", maxReviewCommentLength)
+	}
+
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	return &SurveyReview{
+		ID:         uuid.NewV4().String(),
+		SurveyID:   surveyID,
+		Status:     status,
+		ReviewerID: reviewerID,
+		Comment:    comment,
+	}, nil
+}
+
+// Review applies the review of a supervisor to a submitted survey
+func (s *Survey) Review(r *SurveyReview) error {
+	if s.Status != SurveyStatusSubmitted {
+		return NewConflictError(fmt.Sprintf("survey %s is %s, only submitted surveys can be reviewed", s.ID, s.Status))
+	}
+	if s.SurveyorID == r.ReviewerID {
+		return NewForbiddenError("surveyors cannot review their own surveys")
+	}
+
+	s.Status = r.Status
+	s.ReviewedBy = &r.ReviewerID
+	s.ReviewComment = r.Comment
+	return nil
+}
+
+// CanSaveDraft makes sure the survey can be changed as a draft, which is the
+// case for drafts and for rejected surveys that are reopened
+func (s *Survey) CanSaveDraft() error {
+	if s.Status != SurveyStatusDraft && s.Status != SurveyStatusRejected {
+		return NewFieldError("status", fmt.Sprintf("survey %s is %s, only drafts and rejected surveys can be changed", s.ID, s.Status))
+	}
+	return nil
+}
+
+// CheckSurveyor makes sure the survey is recorded by the user, as only the
+// surveyor of a draft can change or submit it
+func (s *Survey) CheckSurveyor(userID string) error {
+	if s.SurveyorID != userID {
+		return NewForbiddenError(fmt.Sprintf("survey %s is recorded by another surveyor", s.ID))
+	}
+	return nil
+}
+
+// CanChangeOdontogram makes sure a user can chart the teeth of the survey,
+// which only its surveyor and the reviewers can until the survey is approved
+func (s *Survey) CanChangeOdontogram(userID string, roles []*Role) error {
+	if err := s.CanPreview(userID, roles); err != nil {
+		return NewForbiddenError(fmt.Sprintf("the odontogram of survey %s can only be changed by its surveyor and reviewers", s.ID))
+	}
+	if s.IsApproved() {
+		return NewFieldError("status", fmt.Sprintf("survey %s is %s, the odontogram of approved surveys cannot be changed", s.ID, s.Status))
+	}
+	return nil
+}
+
+// CanPreview makes sure a user can print the survey before it is approved,
+// which only its surveyor and the reviewers can
+func (s *Survey) CanPreview(userID string, roles []*Role) error {
+	if s.SurveyorID == userID || HasRole(roles, RoleSupervisor, RoleAdmin) {
+		return nil
+	}
+	return NewForbiddenError(fmt.Sprintf("survey %s can only be previewed by its surveyor and reviewers", s.ID))
+}
+
+// CanSubmit makes sure the survey is a draft
+func (s *Survey) CanSubmit() error {
+	if s.Status != SurveyStatusDraft {
+		return NewFieldError("status", fmt.Sprintf("survey %s is %s, only drafts can be submitted", s.ID, s.Status))
+	}
+	return nil
+}
+
+// IsApproved reports whether a supervisor approved the survey
+func (s *Survey) IsApproved() bool {
+	return s.Status == SurveyStatusApproved
+}
+
+// IsSurveyStatus reports whether the status is a known survey status
+func IsSurveyStatus(status string) bool {
+	return isOneOf(status, SurveyStatuses)
+}
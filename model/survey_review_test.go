@@ -0,0 +1,146 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSurveyDraft(t *testing.T) {
+
+	t.Run("PartialDraft", func(t *testing.T) {
+		sut := &SurveyInput{StudentID: &studentID, SurveyorID: &surveyorID, Date: &date, LowerD: &lowerD}
+
+		assert.NotNil(t, sut.Validate())
+		assert.Nil(t, sut.ValidateDraft())
+
+		survey, draft, err := NewSurveyDraftFromInput(*sut)
+		assert.Nil(t, err)
+		assert.Equal(t, SurveyStatusDraft, survey.Status)
+		assert.Equal(t, lowerD, survey.LowerD)
+		assert.Equal(t, int32(0), survey.UpperD)
+		assert.Equal(t, survey.ID, draft.SurveyID)
+	})
+
+	t.Run("DraftStillChecksGivenFields", func(t *testing.T) {
+		invalid := "Unknown"
+		sut := &SurveyInput{StudentID: &studentID, SurveyorID: &surveyorID, Date: &date, S1Q1: &invalid}
+
+		assert.NotNil(t, sut.ValidateDraft())
+	})
+
+	t.Run("DraftRequiresStudentSurveyorAndDate", func(t *testing.T) {
+		sut := &SurveyInput{StudentID: &studentID}
+
+		assert.NotNil(t, sut.ValidateDraft())
+	})
+
+	t.Run("SubmitKeepsDraftID", func(t *testing.T) {
+		survey, draft, err := NewSurveyDraftFromInput(*getValidSurveyInput())
+		assert.Nil(t, err)
+
+		submitted, err := NewSurveyFromDraft(draft)
+		assert.Nil(t, err)
+		assert.Equal(t, survey.ID, submitted.ID)
+		assert.Equal(t, SurveyStatusSubmitted, submitted.Status)
+		for _, c := range submitted.Cases {
+			assert.Equal(t, survey.ID, c.SurveyID)
+		}
+	})
+
+	t.Run("SubmitIncompleteDraft", func(t *testing.T) {
+		_, draft, err := NewSurveyDraftFromInput(SurveyInput{StudentID: &studentID, SurveyorID: &surveyorID, Date: &date})
+		assert.Nil(t, err)
+
+		_, err = NewSurveyFromDraft(draft)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSurveyReview(t *testing.T) {
+	comment := "upper M does not match the odontogram"
+
+	t.Run("Approve", func(t *testing.T) {
+		survey := &Survey{ID: "survey", Status: SurveyStatusSubmitted}
+
+		review, err := NewSurveyReview("survey", SurveyStatusApproved, "supervisor", nil)
+		assert.Nil(t, err)
+		assert.Nil(t, survey.Review(review))
+		assert.True(t, survey.IsApproved())
+		assert.Equal(t, "supervisor", *survey.ReviewedBy)
+	})
+
+	t.Run("RejectRequiresComment", func(t *testing.T) {
+		blank := "  "
+		_, err := NewSurveyReview("survey", SurveyStatusRejected, "supervisor", &blank)
+		assert.NotNil(t, err)
+
+		review, err := NewSurveyReview("survey", SurveyStatusRejected, "supervisor", &comment)
+		assert.Nil(t, err)
+
+		survey := &Survey{ID: "survey", Status: SurveyStatusSubmitted}
+		assert.Nil(t, survey.Review(review))
+		assert.Equal(t, SurveyStatusRejected, survey.Status)
+		assert.Equal(t, &comment, survey.ReviewComment)
+		assert.Nil(t, survey.CanSaveDraft())
+		assert.NotNil(t, survey.CanSubmit())
+	})
+
+	t.Run("InvalidStatus", func(t *testing.T) {
+		_, err := NewSurveyReview("survey", SurveyStatusDraft, "supervisor", &comment)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("OnlySubmittedSurveys", func(t *testing.T) {
+		review, _ := NewSurveyReview("survey", SurveyStatusApproved, "supervisor", nil)
+
+		for _, status := range []string{SurveyStatusDraft, SurveyStatusApproved, SurveyStatusRejected} {
+			survey := &Survey{ID: "survey", Status: status}
+			err := survey.Review(review)
+			assert.NotNil(t, err, status)
+			assert.Equal(t, ErrorCodeConflict, err.(*Error).Code, status)
+		}
+	})
+
+	t.Run("ApprovedSurveysCannotChange", func(t *testing.T) {
+		survey := &Survey{ID: "survey", Status: SurveyStatusApproved}
+
+		assert.NotNil(t, survey.CanSaveDraft())
+		assert.NotNil(t, survey.CanSubmit())
+	})
+	t.Run("OwnSurvey", func(t *testing.T) {
+		survey := &Survey{ID: "survey", SurveyorID: "supervisor", Status: SurveyStatusSubmitted}
+		review, _ := NewSurveyReview("survey", SurveyStatusApproved, "supervisor", nil)
+
+		err := survey.Review(review)
+		assert.NotNil(t, err)
+		assert.Equal(t, ErrorCodeForbidden, err.(*Error).Code)
+		assert.Equal(t, SurveyStatusSubmitted, survey.Status)
+	})
+
+	t.Run("Surveyor", func(t *testing.T) {
+		survey := &Survey{ID: "survey", SurveyorID: "surveyor", Status: SurveyStatusDraft}
+
+		assert.Nil(t, survey.CheckSurveyor("surveyor"))
+		assert.Equal(t, ErrorCodeForbidden, survey.CheckSurveyor("other").(*Error).Code)
+	})
+
+	t.Run("Odontogram", func(t *testing.T) {
+		survey := &Survey{ID: "survey", SurveyorID: "surveyor", Status: SurveyStatusSubmitted}
+
+		assert.Nil(t, survey.CanChangeOdontogram("surveyor", nil))
+		assert.Nil(t, survey.CanChangeOdontogram("supervisor", []*Role{{Name: RoleSupervisor}}))
+		assert.Equal(t, ErrorCodeForbidden, survey.CanChangeOdontogram("other", []*Role{{Name: RoleSurveyor}}).(*Error).Code)
+
+		survey.Status = SurveyStatusApproved
+		assert.Equal(t, ErrorCodeValidationFailed, survey.CanChangeOdontogram("surveyor", nil).(*Error).Code)
+	})
+
+	t.Run("Preview", func(t *testing.T) {
+		survey := &Survey{ID: "survey", SurveyorID: "surveyor", Status: SurveyStatusSubmitted}
+
+		assert.Nil(t, survey.CanPreview("surveyor", nil))
+		assert.Nil(t, survey.CanPreview("supervisor", []*Role{{Name: RoleSupervisor}}))
+		assert.NotNil(t, survey.CanPreview("parent", []*Role{{Name: RoleSurveyor}}))
+	})
+}
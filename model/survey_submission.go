@@ -0,0 +1,98 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	SubmissionStatusCreated          = "CREATED"
+	SubmissionStatusAlreadySubmitted = "ALREADY_SUBMITTED"
+	SubmissionStatusFailed           = "FAILED"
+
+	MaxSurveySubmissions = 100
+)
+
+// SurveySubmissionInput is the input for a survey uploaded by an offline client
+type SurveySubmissionInput struct {
+	ID             *string
+	IdempotencyKey string
+	Survey         SurveyInput
+}
+
+// SurveySubmission is a survey together with the idempotency key it was
+// uploaded with. Keys are scoped to the signed-in surveyor, and the hash of
+// the uploaded payload tells a retry from a different survey reusing a key.
+type SurveySubmission struct {
+	IdempotencyKey    string
+	PayloadHash       string
+	ClientGeneratedID bool
+	Survey            *Survey
+}
+
+// SurveySubmissionResult is the outcome of a single survey submission
+type SurveySubmissionResult struct {
+	IdempotencyKey string
+	Status         string
+	Survey         *Survey
+	Error          *Error
+}
+
+func NewSurveySubmissionFromInput(input SurveySubmissionInput) (SurveySubmission, error) {
+	if input.IdempotencyKey == "" {
+		return SurveySubmission{}, NewFieldError("idempotencyKey", "idempotency key is required")
+	}
+
+	survey, err := NewSurveyFromInput(input.Survey)
+	if err != nil {
+		return SurveySubmission{}, prefixFieldErrors(err, "survey.")
+	}
+
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return SurveySubmission{}, err
+	}
+	hash := sha256.Sum256(payload)
+
+	submission := SurveySubmission{
+		IdempotencyKey: input.IdempotencyKey,
+		PayloadHash:    hex.EncodeToString(hash[:]),
+		Survey:         &survey,
+	}
+
+	if input.ID != nil {
+		id, err := uuid.FromString(*input.ID)
+		if err != nil {
+			return SurveySubmission{}, NewFieldError("id", "id must be a UUID")
+		}
+
+		survey.ID = id.String()
+		for _, c := range survey.Cases {
+			c.SurveyID = survey.ID
+		}
+		submission.ClientGeneratedID = true
+	}
+
+	return submission, nil
+}
+
+func NewFailedSurveySubmissionResult(idempotencyKey string, err error) *SurveySubmissionResult {
+	return &SurveySubmissionResult{
+		IdempotencyKey: idempotencyKey,
+		Status:         SubmissionStatusFailed,
+		Error:          AsError(err),
+	}
+}
+
+// CheckRetry returns a conflict error when a submission reuses the idempotency
+// key of a stored submission with a different payload. Submissions stored
+// without a payload hash are taken as retries.
+func (s SurveySubmission) CheckRetry(storedPayloadHash *string) error {
+	if storedPayloadHash != nil && *storedPayloadHash != s.PayloadHash {
+		return NewConflictError("idempotency key was already used for a different survey")
+	}
+	return nil
+}
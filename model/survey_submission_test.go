@@ -0,0 +1,78 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSurveySubmissionInput(t *testing.T) {
+
+	t.Run("WithoutID", func(t *testing.T) {
+		input := SurveySubmissionInput{IdempotencyKey: "fake_key", Survey: *getValidSurveyInput()}
+		submission, err := NewSurveySubmissionFromInput(input)
+
+		assert.Nil(t, err)
+		assert.False(t, submission.ClientGeneratedID)
+		assert.NotEmpty(t, submission.Survey.ID)
+	})
+
+	t.Run("WithID", func(t *testing.T) {
+		id := "0b7e9a8e-7c1a-4a4e-9f4d-1d2f3c4b5a69"
+		input := SurveySubmissionInput{ID: &id, IdempotencyKey: "fake_key", Survey: *getValidSurveyInput()}
+		submission, err := NewSurveySubmissionFromInput(input)
+
+		assert.Nil(t, err)
+		assert.True(t, submission.ClientGeneratedID)
+		assert.Equal(t, id, submission.Survey.ID)
+		assert.Equal(t, id, submission.Survey.Cases[0].SurveyID)
+	})
+
+	t.Run("MalformedID", func(t *testing.T) {
+		id := "fake_id"
+		input := SurveySubmissionInput{ID: &id, IdempotencyKey: "fake_key", Survey: *getValidSurveyInput()}
+		_, err := NewSurveySubmissionFromInput(input)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "id", err.(*Error).Fields[0].Field)
+	})
+
+	t.Run("EmptyIdempotencyKey", func(t *testing.T) {
+		input := SurveySubmissionInput{Survey: *getValidSurveyInput()}
+		_, err := NewSurveySubmissionFromInput(input)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "idempotencyKey", err.(*Error).Fields[0].Field)
+	})
+
+	t.Run("InvalidSurvey", func(t *testing.T) {
+		input := SurveySubmissionInput{IdempotencyKey: "fake_key", Survey: *getValidSurveyInput()}
+		input.Survey.StudentID = nil
+		_, err := NewSurveySubmissionFromInput(input)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "survey.studentId", err.(*Error).Fields[0].Field)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		input := SurveySubmissionInput{IdempotencyKey: "fake_key", Survey: *getValidSurveyInput()}
+		submission, _ := NewSurveySubmissionFromInput(input)
+		retry, _ := NewSurveySubmissionFromInput(input)
+
+		assert.NotEmpty(t, submission.PayloadHash)
+		assert.Nil(t, retry.CheckRetry(&submission.PayloadHash))
+		assert.Nil(t, retry.CheckRetry(nil))
+	})
+
+	t.Run("ReusedKey", func(t *testing.T) {
+		input := SurveySubmissionInput{IdempotencyKey: "fake_key", Survey: *getValidSurveyInput()}
+		submission, _ := NewSurveySubmissionFromInput(input)
+		lowerD := int32(5)
+		input.Survey.LowerD = &lowerD
+		other, _ := NewSurveySubmissionFromInput(input)
+
+		err := other.CheckRetry(&submission.PayloadHash)
+		assert.NotNil(t, err)
+		assert.Equal(t, ErrorCodeConflict, err.(*Error).Code)
+	})
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -75,11 +76,24 @@ func TestSurveyInput(t *testing.T) {
 
 		assert.Nil(t, err)
 
-		survey.CalculateScore()
+		err = getLegacyQuestionnaire().Apply(&survey)
 
+		assert.Nil(t, err)
 		assert.Equal(t, int32(0), survey.SubjectiveScore)
 	})
 
+	t.Run("AssignSurveyor", func(t *testing.T) {
+		input := getValidSurveyInput()
+		input.SurveyorID = nil
+		assert.Nil(t, input.AssignSurveyor("surveyor"))
+		assert.Equal(t, "surveyor", *input.SurveyorID)
+		assert.Nil(t, input.AssignSurveyor("surveyor"))
+
+		err := input.AssignSurveyor("other")
+		assert.Equal(t, ErrorCodeForbidden, err.(*Error).Code)
+		assert.Equal(t, "surveyor", *input.SurveyorID)
+	})
+
 	t.Run("validation", func(t *testing.T) {
 
 		t.Run("NoErrors", func(t *testing.T) {
@@ -323,6 +337,94 @@ func TestSurveyInput(t *testing.T) {
 			assert.Equal(t, "upper F is required", err.Error())
 		})
 
+		t.Run("InvalidAnswer", func(t *testing.T) {
+			sut := getValidSurveyInput()
+			answer := "Banana"
+			sut.S1Q1 = &answer
+			err := sut.Validate()
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "s1q1", err.(*Error).Fields[0].Field)
+			assert.Equal(t, "invalid answer Banana to section 1 question 1, expecting one of Low, Medium, High", err.Error())
+		})
+
+		t.Run("FutureDate", func(t *testing.T) {
+			sut := getValidSurveyInput()
+			tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+			sut.Date = &tomorrow
+			err := sut.Validate()
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "date must not be in the future", err.Error())
+		})
+
+		t.Run("NegativeCount", func(t *testing.T) {
+			sut := getValidSurveyInput()
+			negative := int32(-1)
+			sut.UpperM = &negative
+			err := sut.Validate()
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "upper M must not be negative", err.Error())
+		})
+
+		t.Run("TooManyPrimaryTeeth", func(t *testing.T) {
+			sut := getValidSurveyInput()
+			count := int32(18)
+			sut.LowerE = &count
+			err := sut.Validate()
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "lowerD", err.(*Error).Fields[0].Field)
+			assert.Equal(t, "lower d, e and f must not exceed 20 teeth in total", err.Error())
+		})
+
+		t.Run("DuplicateTooth", func(t *testing.T) {
+			sut := getValidSurveyInput()
+			cases := []*CaseInput{getValidCaseInput(), getValidCaseInput()}
+			sut.Cases = &cases
+			err := sut.Validate()
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "cases[1].toothNumber", err.(*Error).Fields[0].Field)
+		})
+
+		t.Run("InvalidToothIndexMode", func(t *testing.T) {
+			sut := getValidSurveyInput()
+			mode := "GUESSED"
+			sut.ToothIndexMode = &mode
+			err := sut.Validate()
+
+			assert.NotNil(t, err)
+			assert.Equal(t, "invalid tooth index mode GUESSED, expecting one of MANUAL, COMPUTED", err.Error())
+		})
+
+		t.Run("ToothCountsOptionalWhenComputed", func(t *testing.T) {
+			sut := getValidSurveyInput()
+			mode := ToothIndexModeComputed
+			sut.ToothIndexMode = &mode
+			sut.LowerD = nil
+			sut.UpperM = nil
+			err := sut.Validate()
+
+			assert.Nil(t, err)
+		})
+
+		t.Run("AllErrors", func(t *testing.T) {
+			sut := getValidSurveyInput()
+			sut.StudentID = nil
+			sut.S2Q9 = nil
+			sut.UpperF = nil
+			err := sut.Validate()
+
+			assert.NotNil(t, err)
+			fields := err.(*Error).Fields
+			assert.Equal(t, 3, len(fields))
+			assert.Equal(t, "studentId", fields[0].Field)
+			assert.Equal(t, "s2q9", fields[1].Field)
+			assert.Equal(t, "upperF", fields[2].Field)
+		})
+
 	})
 }
 
@@ -354,6 +456,10 @@ func TestNewSurveyFromInput(t *testing.T) {
 		assert.Equal(t, s2q7, s.S2Q7)
 		assert.Equal(t, s2q8, s.S2Q8)
 		assert.Equal(t, s2q9, s.S2Q9)
+		assert.Equal(t, LegacyQuestionnaireID, *s.QuestionnaireID)
+		assert.Equal(t, 16, len(s.Answers))
+		assert.Equal(t, "s1q1", s.Answers[0].QuestionCode)
+		assert.Equal(t, s1q1, s.Answers[0].Value)
 		assert.Equal(t, lowerD, s.LowerD)
 		assert.Equal(t, lowerE, s.LowerE)
 		assert.Equal(t, lowerF, s.LowerF)
@@ -368,6 +474,39 @@ func TestNewSurveyFromInput(t *testing.T) {
 		assert.Equal(t, toothNumber, s.Cases[0].ToothNumber)
 	})
 
+	t.Run("ComputedToothCounts", func(t *testing.T) {
+		input := getValidSurveyInput()
+		mode := ToothIndexModeComputed
+		input.ToothIndexMode = &mode
+		input.UpperD = nil
+
+		s, err := NewSurveyFromInput(*input)
+		assert.Nil(t, err)
+
+		s.SetComputedToothIndices(ComputeToothIndices(s.Cases, map[string]string{daID: IndexComponentDecayed}))
+
+		assert.Equal(t, int32(1), s.UpperD)
+		assert.Equal(t, int32(1), s.ComputedUpperD)
+		assert.Equal(t, lowerD, s.LowerD)
+	})
+
+	t.Run("WithAnswers", func(t *testing.T) {
+		questionnaireID := "fake_questionnaire_id"
+		answers := []*AnswerInput{{QuestionCode: "s1q1", Value: "High"}}
+		input := getValidSurveyInput()
+		input.S1Q1 = nil
+		input.QuestionnaireID = &questionnaireID
+		input.Answers = &answers
+
+		s, err := NewSurveyFromInput(*input)
+
+		assert.Nil(t, err)
+		assert.Equal(t, questionnaireID, *s.QuestionnaireID)
+		assert.Equal(t, 1, len(s.Answers))
+		assert.Equal(t, s.ID, s.Answers[0].SurveyID)
+		assert.Equal(t, "High", s.S1Q1)
+	})
+
 	t.Run("WithErrors", func(t *testing.T) {
 		input := getValidSurveyInput()
 		input.StudentID = nil
@@ -391,6 +530,7 @@ func TestNewSurveyFromInput(t *testing.T) {
 
 		assert.NotNil(t, err)
 		assert.Equal(t, "tooth number is required", err.Error())
+		assert.Equal(t, "cases[0].toothNumber", err.(*Error).Fields[0].Field)
 	})
 
 }
@@ -0,0 +1,28 @@
+package model
+
+const (
+	// MaxPermanentTeeth is the number of teeth of the permanent dentition
+	MaxPermanentTeeth = 32
+	// MaxPrimaryTeeth is the number of teeth of the primary dentition
+	MaxPrimaryTeeth = 20
+)
+
+// IsPermanentTooth reports whether n is an FDI code of a permanent tooth,
+// quadrants 1 to 4 with teeth 1 to 8.
+func IsPermanentTooth(n int32) bool {
+	quadrant, tooth := n/10, n%10
+	return quadrant >= 1 && quadrant <= 4 && tooth >= 1 && tooth <= 8
+}
+
+// IsPrimaryTooth reports whether n is an FDI code of a primary tooth,
+// quadrants 5 to 8 with teeth 1 to 5.
+func IsPrimaryTooth(n int32) bool {
+	quadrant, tooth := n/10, n%10
+	return quadrant >= 5 && quadrant <= 8 && tooth >= 1 && tooth <= 5
+}
+
+// IsValidToothNumber reports whether n is an FDI code of a permanent or
+// primary tooth.
+func IsValidToothNumber(n int32) bool {
+	return IsPermanentTooth(n) || IsPrimaryTooth(n)
+}
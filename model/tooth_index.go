@@ -0,0 +1,152 @@
+package model
+
+const (
+	IndexComponentDecayed = "DECAYED"
+	IndexComponentMissing = "MISSING"
+	IndexComponentFilled  = "FILLED"
+
+	// ToothIndexModeManual requires the surveyor to enter the def-t and
+	// DMF-T counts, which are checked against the counts derived from the
+	// cases.
+	ToothIndexModeManual = "MANUAL"
+	// ToothIndexModeComputed fills in the counts the surveyor leaves out with
+	// the counts derived from the cases.
+	ToothIndexModeComputed = "COMPUTED"
+)
+
+// ToothIndexModes lists the ways the def-t and DMF-T counts of a survey are
+// recorded
+var ToothIndexModes = []string{
+	ToothIndexModeManual,
+	ToothIndexModeComputed,
+}
+
+// toothCountFields lists the input fields of the def-t and DMF-T counts
+var toothCountFields = []string{"lowerD", "lowerE", "lowerF", "upperD", "upperM", "upperF"}
+
+// indexComponentPrecedence decides the component of a tooth with cases
+// counting towards more than one component
+var indexComponentPrecedence = map[string]int{
+	IndexComponentDecayed: 3,
+	IndexComponentMissing: 2,
+	IndexComponentFilled:  1,
+}
+
+// ToothIndices are the def-t counts of the primary dentition (lower case d, e
+// and f) and the DMF-T counts of the permanent dentition (upper case D, M and
+// F).
+type ToothIndices struct {
+	LowerD int32
+	LowerE int32
+	LowerF int32
+	UpperD int32
+	UpperM int32
+	UpperF int32
+}
+
+// ComputeToothIndices derives the def-t and DMF-T counts from cases, given the
+// index component of every diagnosis keyed by diagnosis and action ID. Every
+// tooth counts once, as decayed before missing before filled.
+func ComputeToothIndices(cases []*Case, components map[string]string) ToothIndices {
+	toothComponents := make(map[int32]string)
+	for _, c := range cases {
+		component, ok := components[c.DiagnosisAndActionID]
+		if !ok {
+			continue
+		}
+		if indexComponentPrecedence[component] > indexComponentPrecedence[toothComponents[c.ToothNumber]] {
+			toothComponents[c.ToothNumber] = component
+		}
+	}
+
+	var indices ToothIndices
+	for toothNumber, component := range toothComponents {
+		switch {
+		case IsPrimaryTooth(toothNumber) && component == IndexComponentDecayed:
+			indices.LowerD++
+		case IsPrimaryTooth(toothNumber) && component == IndexComponentMissing:
+			indices.LowerE++
+		case IsPrimaryTooth(toothNumber) && component == IndexComponentFilled:
+			indices.LowerF++
+		case IsPermanentTooth(toothNumber) && component == IndexComponentDecayed:
+			indices.UpperD++
+		case IsPermanentTooth(toothNumber) && component == IndexComponentMissing:
+			indices.UpperM++
+		case IsPermanentTooth(toothNumber) && component == IndexComponentFilled:
+			indices.UpperF++
+		}
+	}
+
+	return indices
+}
+
+// toothCounts returns the fields holding the def-t and DMF-T counts, keyed by
+// their input field name.
+func (t *ToothIndices) toothCounts() map[string]*int32 {
+	return map[string]*int32{
+		"lowerD": &t.LowerD,
+		"lowerE": &t.LowerE,
+		"lowerF": &t.LowerF,
+		"upperD": &t.UpperD,
+		"upperM": &t.UpperM,
+		"upperF": &t.UpperF,
+	}
+}
+
+// toothCounts returns the fields holding the entered def-t and DMF-T counts
+// of the survey, keyed by their input field name.
+func (s *Survey) toothCounts() map[string]*int32 {
+	return map[string]*int32{
+		"lowerD": &s.LowerD,
+		"lowerE": &s.LowerE,
+		"lowerF": &s.LowerF,
+		"upperD": &s.UpperD,
+		"upperM": &s.UpperM,
+		"upperF": &s.UpperF,
+	}
+}
+
+// Indices returns the def-t and DMF-T counts entered for the survey
+func (s *Survey) Indices() ToothIndices {
+	return ToothIndices{
+		LowerD: s.LowerD,
+		LowerE: s.LowerE,
+		LowerF: s.LowerF,
+		UpperD: s.UpperD,
+		UpperM: s.UpperM,
+		UpperF: s.UpperF,
+	}
+}
+
+// ComputedIndices returns the def-t and DMF-T counts derived from the cases
+// of the survey
+func (s *Survey) ComputedIndices() ToothIndices {
+	return ToothIndices{
+		LowerD: s.ComputedLowerD,
+		LowerE: s.ComputedLowerE,
+		LowerF: s.ComputedLowerF,
+		UpperD: s.ComputedUpperD,
+		UpperM: s.ComputedUpperM,
+		UpperF: s.ComputedUpperF,
+	}
+}
+
+// SetComputedToothIndices records the counts derived from the cases of the
+// survey, fills in the counts the surveyor left out, and flags the survey when
+// the entered counts differ from the derived ones.
+func (s *Survey) SetComputedToothIndices(computed ToothIndices) {
+	s.ComputedLowerD = computed.LowerD
+	s.ComputedLowerE = computed.LowerE
+	s.ComputedLowerF = computed.LowerF
+	s.ComputedUpperD = computed.UpperD
+	s.ComputedUpperM = computed.UpperM
+	s.ComputedUpperF = computed.UpperF
+
+	entered, derived := s.toothCounts(), computed.toothCounts()
+	for _, field := range s.omittedToothCounts {
+		*entered[field] = *derived[field]
+	}
+	s.omittedToothCounts = nil
+
+	s.ToothIndexMismatch = s.Indices() != computed
+}
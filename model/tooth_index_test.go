@@ -0,0 +1,67 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToothIndices(t *testing.T) {
+	decayedID, missingID, filledID, sealantID := "decayed", "missing", "filled", "sealant"
+	components := map[string]string{
+		decayedID: IndexComponentDecayed,
+		missingID: IndexComponentMissing,
+		filledID:  IndexComponentFilled,
+	}
+
+	t.Run("Compute", func(t *testing.T) {
+		cases := []*Case{
+			{ToothNumber: 16, DiagnosisAndActionID: decayedID},
+			{ToothNumber: 26, DiagnosisAndActionID: filledID},
+			{ToothNumber: 36, DiagnosisAndActionID: missingID},
+			{ToothNumber: 46, DiagnosisAndActionID: sealantID},
+			{ToothNumber: 55, DiagnosisAndActionID: decayedID},
+			{ToothNumber: 65, DiagnosisAndActionID: decayedID},
+			{ToothNumber: 75, DiagnosisAndActionID: missingID},
+			{ToothNumber: 85, DiagnosisAndActionID: filledID},
+		}
+
+		indices := ComputeToothIndices(cases, components)
+
+		assert.Equal(t, ToothIndices{LowerD: 2, LowerE: 1, LowerF: 1, UpperD: 1, UpperM: 1, UpperF: 1}, indices)
+	})
+
+	t.Run("ToothCountsOnce", func(t *testing.T) {
+		cases := []*Case{
+			{ToothNumber: 16, DiagnosisAndActionID: filledID},
+			{ToothNumber: 16, DiagnosisAndActionID: decayedID},
+			{ToothNumber: 26, DiagnosisAndActionID: filledID},
+			{ToothNumber: 26, DiagnosisAndActionID: missingID},
+		}
+
+		indices := ComputeToothIndices(cases, components)
+
+		assert.Equal(t, ToothIndices{UpperD: 1, UpperM: 1}, indices)
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		s := &Survey{UpperD: 2}
+
+		s.SetComputedToothIndices(ToothIndices{UpperD: 1})
+		assert.True(t, s.ToothIndexMismatch)
+		assert.Equal(t, int32(2), s.UpperD)
+
+		s.UpperD = 1
+		s.SetComputedToothIndices(ToothIndices{UpperD: 1})
+		assert.False(t, s.ToothIndexMismatch)
+	})
+
+	t.Run("FillsOmittedCounts", func(t *testing.T) {
+		s := &Survey{UpperD: 2, omittedToothCounts: []string{"upperF", "lowerD"}}
+
+		s.SetComputedToothIndices(ToothIndices{LowerD: 3, UpperD: 2, UpperF: 4})
+
+		assert.Equal(t, ToothIndices{LowerD: 3, UpperD: 2, UpperF: 4}, s.Indices())
+		assert.False(t, s.ToothIndexMismatch)
+	})
+}
@@ -0,0 +1,124 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	TreatmentStatusPlanned   = "PLANNED"
+	TreatmentStatusPerformed = "PERFORMED"
+	TreatmentStatusFailed    = "FAILED"
+	TreatmentStatusRedone    = "REDONE"
+)
+
+// TreatmentStatuses lists the statuses of the treatment of a case
+var TreatmentStatuses = []string{
+	TreatmentStatusPlanned,
+	TreatmentStatusPerformed,
+	TreatmentStatusFailed,
+	TreatmentStatusRedone,
+}
+
+// treatmentTransitions lists the statuses a treatment can move to from every
+// status. A failed treatment is redone, and a redone treatment can fail again.
+var treatmentTransitions = map[string][]string{
+	TreatmentStatusPlanned:   {TreatmentStatusPerformed},
+	TreatmentStatusPerformed: {TreatmentStatusFailed},
+	TreatmentStatusFailed:    {TreatmentStatusRedone},
+	TreatmentStatusRedone:    {TreatmentStatusFailed},
+}
+
+// Treatment is a change of the treatment status of a case
+type Treatment struct {
+	ID            string
+	CaseID        string  `db:"case_id"`
+	Status        string  `db:"status"`
+	PerformedBy   *string `db:"performed_by"`
+	PerformedDate *string `db:"performed_date"`
+	Notes         *string `db:"notes"`
+	CreatedAt     string  `db:"created_at"`
+}
+
+// TreatmentUpdate is a change of the treatment status of a case by an operator
+type TreatmentUpdate struct {
+	CaseID        string
+	Status        string
+	PerformedBy   *string
+	PerformedDate *string
+	Notes         *string
+}
+
+// IsPerformed reports whether the status records the action as carried out
+func IsPerformed(status string) bool {
+	return status == TreatmentStatusPerformed || status == TreatmentStatusRedone
+}
+
+// Validate makes sure the status is known and a performed treatment has the
+// operator who performed it and a valid date
+func (u *TreatmentUpdate) Validate() error {
+	v := &validator{}
+
+	if !isOneOf(u.Status, TreatmentStatuses) {
+		v.addf("status", "invalid status %s, expecting one of %s", u.Status, strings.Join(TreatmentStatuses, ", "))
+	}
+
+	if u.PerformedBy == nil && IsPerformed(u.Status) {
+		v.addf("performedBy", "performing operator is required for status %s", u.Status)
+	}
+
+	if u.PerformedDate != nil {
+		if date, err := time.Parse("2006-01-02", *u.PerformedDate); err != nil {
+			v.add("performedDate", "invalid date format, expecting yyyy-mm-dd")
+		} else if date.After(time.Now()) {
+			v.add("performedDate", "performed date cannot be in the future")
+		}
+	} else if IsPerformed(u.Status) {
+		v.addf("performedDate", "performed date is required for status %s", u.Status)
+	}
+
+	if u.Notes != nil && len(*u.Notes) > 255 {
+		v.add("notes", "notes cannot be longer than 255 characters")
+	}
+
+	return v.err()
+}
+
+// ApplyTreatment moves the case to the status of the update and returns the
+// change to record, or a validation error when the case cannot move to that
+// status from its current one.
+func (c *Case) ApplyTreatment(u *TreatmentUpdate) (*Treatment, error) {
+	if err := u.Validate(); err != nil {
+		return nil, err
+	}
+
+	if !canTransition(c.TreatmentStatus, u.Status) {
+		return nil, NewFieldError("status", fmt.Sprintf("cannot change treatment from %s to %s", c.TreatmentStatus, u.Status))
+	}
+
+	c.TreatmentStatus = u.Status
+	c.PerformedBy = u.PerformedBy
+	c.PerformedDate = u.PerformedDate
+	c.TreatmentNotes = u.Notes
+
+	return &Treatment{
+		ID:            uuid.NewV4().String(),
+		CaseID:        c.ID,
+		Status:        u.Status,
+		PerformedBy:   u.PerformedBy,
+		PerformedDate: u.PerformedDate,
+		Notes:         u.Notes,
+	}, nil
+}
+
+func canTransition(from string, to string) bool {
+	for _, status := range treatmentTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
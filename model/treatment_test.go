@@ -0,0 +1,88 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreatment(t *testing.T) {
+	operator := "operator"
+	date := "2026-03-10"
+
+	t.Run("Lifecycle", func(t *testing.T) {
+		c := &Case{ID: "case", TreatmentStatus: TreatmentStatusPlanned}
+
+		treatment, err := c.ApplyTreatment(&TreatmentUpdate{CaseID: "case", Status: TreatmentStatusPerformed, PerformedBy: &operator, PerformedDate: &date})
+		assert.Nil(t, err)
+		assert.Equal(t, TreatmentStatusPerformed, c.TreatmentStatus)
+		assert.Equal(t, &operator, c.PerformedBy)
+		assert.Equal(t, "case", treatment.CaseID)
+		assert.Equal(t, TreatmentStatusPerformed, treatment.Status)
+		assert.NotEmpty(t, treatment.ID)
+
+		notes := "sealant lost"
+		_, err = c.ApplyTreatment(&TreatmentUpdate{CaseID: "case", Status: TreatmentStatusFailed, Notes: &notes})
+		assert.Nil(t, err)
+		assert.Equal(t, TreatmentStatusFailed, c.TreatmentStatus)
+		assert.Nil(t, c.PerformedBy)
+		assert.Equal(t, &notes, c.TreatmentNotes)
+
+		_, err = c.ApplyTreatment(&TreatmentUpdate{CaseID: "case", Status: TreatmentStatusRedone, PerformedBy: &operator, PerformedDate: &date})
+		assert.Nil(t, err)
+		assert.Equal(t, TreatmentStatusRedone, c.TreatmentStatus)
+
+		_, err = c.ApplyTreatment(&TreatmentUpdate{CaseID: "case", Status: TreatmentStatusFailed})
+		assert.Nil(t, err)
+	})
+
+	t.Run("InvalidTransitions", func(t *testing.T) {
+		for _, transition := range [][2]string{
+			{TreatmentStatusPlanned, TreatmentStatusFailed},
+			{TreatmentStatusPlanned, TreatmentStatusRedone},
+			{TreatmentStatusPerformed, TreatmentStatusPerformed},
+			{TreatmentStatusPerformed, TreatmentStatusPlanned},
+			{TreatmentStatusFailed, TreatmentStatusPerformed},
+		} {
+			c := &Case{ID: "case", TreatmentStatus: transition[0]}
+
+			_, err := c.ApplyTreatment(&TreatmentUpdate{CaseID: "case", Status: transition[1], PerformedBy: &operator, PerformedDate: &date})
+			assert.NotNil(t, err, "%s to %s", transition[0], transition[1])
+			assert.Equal(t, transition[0], c.TreatmentStatus)
+			assert.Equal(t, "status", err.(*Error).Fields[0].Field)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+
+		t.Run("UnknownStatus", func(t *testing.T) {
+			err := (&TreatmentUpdate{Status: "DONE"}).Validate()
+			assert.NotNil(t, err)
+			assert.Equal(t, "status", err.(*Error).Fields[0].Field)
+		})
+
+		t.Run("PerformedWithoutOperatorAndDate", func(t *testing.T) {
+			err := (&TreatmentUpdate{Status: TreatmentStatusPerformed}).Validate()
+			assert.NotNil(t, err)
+			assert.Len(t, err.(*Error).Fields, 2)
+			assert.Equal(t, "performedBy", err.(*Error).Fields[0].Field)
+			assert.Equal(t, "performedDate", err.(*Error).Fields[1].Field)
+		})
+
+		t.Run("InvalidDate", func(t *testing.T) {
+			invalid := "10-03-2026"
+			err := (&TreatmentUpdate{Status: TreatmentStatusFailed, PerformedDate: &invalid}).Validate()
+			assert.NotNil(t, err)
+			assert.Equal(t, "performedDate", err.(*Error).Fields[0].Field)
+
+			future := "2999-01-01"
+			err = (&TreatmentUpdate{Status: TreatmentStatusRedone, PerformedBy: &operator, PerformedDate: &future}).Validate()
+			assert.NotNil(t, err)
+			assert.Equal(t, "performedDate", err.(*Error).Fields[0].Field)
+		})
+
+		t.Run("FailedWithoutOperator", func(t *testing.T) {
+			assert.Nil(t, (&TreatmentUpdate{Status: TreatmentStatusFailed}).Validate())
+		})
+	})
+}
@@ -10,9 +10,12 @@ type User struct {
 	Email     string
 	Password  string
 	IPAddress string `db:"ip_address"`
-	CreatedAt string `db:"created_at"`
-	Roles     []*Role
-	Students  []*Student
+	// PreferredLanguage is the language reports are printed in for the user
+	// when none is asked for
+	PreferredLanguage *string `db:"preferred_language"`
+	CreatedAt         string  `db:"created_at"`
+	Roles             []*Role
+	Students          []*Student
 }
 
 func (user *User) HashedPassword() error {
@@ -0,0 +1,61 @@
+package model
+
+import "fmt"
+
+// validator collects the field errors of an input so that every problem is
+// reported at once.
+type validator struct {
+	fields []FieldError
+}
+
+func (v *validator) add(field string, message string) {
+	v.fields = append(v.fields, FieldError{Field: field, Message: message})
+}
+
+func (v *validator) addf(field string, format string, args ...interface{}) {
+	v.add(field, fmt.Sprintf(format, args...))
+}
+
+// merge adds the field errors of a nested input, prefixing their paths.
+func (v *validator) merge(err error, prefix string) {
+	if err == nil {
+		return
+	}
+	if e, ok := prefixFieldErrors(err, prefix).(*Error); ok && e.Code == ErrorCodeValidationFailed {
+		v.fields = append(v.fields, e.Fields...)
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return NewValidationError(v.fields...)
+}
+
+// JoinValidationErrors combines validation errors into one listing every field
+// error. Any other error is returned as is, and nil is returned when all
+// errors are nil.
+func JoinValidationErrors(errs ...error) error {
+	v := &validator{}
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		if e, ok := err.(*Error); !ok || e.Code != ErrorCodeValidationFailed {
+			return err
+		}
+		v.merge(err, "")
+	}
+	return v.err()
+}
+
+// isOneOf reports whether a value is one of the accepted values
+func isOneOf(value string, values []string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinValidationErrors(t *testing.T) {
+
+	t.Run("NoErrors", func(t *testing.T) {
+		assert.Nil(t, JoinValidationErrors(nil, nil))
+	})
+
+	t.Run("ValidationErrors", func(t *testing.T) {
+		err := JoinValidationErrors(NewFieldError("date", "date is required"), nil, NewFieldError("studentId", "student ID is required"))
+
+		assert.NotNil(t, err)
+		assert.Equal(t, "date is required; student ID is required", err.Error())
+		assert.Equal(t, 2, len(err.(*Error).Fields))
+	})
+
+	t.Run("OtherError", func(t *testing.T) {
+		cause := errors.New("fake error")
+		err := JoinValidationErrors(NewFieldError("date", "date is required"), cause)
+
+		assert.Equal(t, cause, err)
+	})
+}
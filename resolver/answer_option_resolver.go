@@ -0,0 +1,30 @@
+package resolver
+
+import (
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/model"
+)
+
+type answerOptionResolver struct {
+	o *model.AnswerOption
+}
+
+func (o *answerOptionResolver) ID() graphql.ID {
+	return graphql.ID(o.o.ID)
+}
+
+func (o *answerOptionResolver) Value() string {
+	return o.o.Value
+}
+
+func (o *answerOptionResolver) Position() int32 {
+	return o.o.Position
+}
+
+func (o *answerOptionResolver) Score() float64 {
+	return o.o.Score
+}
+
+func (o *answerOptionResolver) Label(args struct{ Locale *string }) *string {
+	return o.o.Label.Get(args.Locale)
+}
@@ -0,0 +1,31 @@
+package resolver
+
+import (
+	"fmt"
+	"strings"
+
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+// authorizeRoles makes sure the signed in user has one of the roles, and
+// describes the action refused to the others
+func authorizeRoles(ctx context.Context, action string, roles ...string) error {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	userRoles, err := ctx.Value("roleService").(*service.RoleService).FindByUserId(userID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return err
+	}
+	if !model.HasRole(userRoles, roles...) {
+		return model.NewForbiddenError(fmt.Sprintf("only %s users can %s", strings.ToLower(strings.Join(roles, " and ")), action))
+	}
+	return nil
+}
@@ -1,10 +1,9 @@
 package resolver
 
 import (
-	"errors"
-
 	gcontext "github.com/kerti/idcra-api/context"
 	"github.com/kerti/idcra-api/loader"
+	"github.com/kerti/idcra-api/model"
 	"github.com/op/go-logging"
 	"golang.org/x/net/context"
 )
@@ -13,7 +12,7 @@ func (r *Resolver) Case(ctx context.Context, args struct {
 	ID string
 }) (*caseResolver, error) {
 	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
-		return nil, errors.New(gcontext.CredentialsError)
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
 	}
 	userID := ctx.Value("user_id").(*string)
 
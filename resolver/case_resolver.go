@@ -5,6 +5,9 @@ import (
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
 )
 
 type caseResolver struct {
@@ -27,6 +30,55 @@ func (c *caseResolver) ToothNumber() *int32 {
 	return &c.c.ToothNumber
 }
 
+func (c *caseResolver) TreatmentStatus() string {
+	return c.c.TreatmentStatus
+}
+
+func (c *caseResolver) PerformedBy() *string {
+	return c.c.PerformedBy
+}
+
+func (c *caseResolver) PerformedDate() (*graphql.Time, error) {
+	if c.c.PerformedDate == nil {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, *c.c.PerformedDate)
+	return &graphql.Time{Time: t}, err
+}
+
+func (c *caseResolver) TreatmentNotes() *string {
+	return c.c.TreatmentNotes
+}
+
+func (c *caseResolver) Treatments(ctx context.Context) ([]*treatmentResolver, error) {
+	treatments, err := ctx.Value("caseService").(*service.CaseService).FindTreatments(c.c.ID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	l := make([]*treatmentResolver, len(treatments))
+	for i := range treatments {
+		l[i] = &treatmentResolver{treatments[i]}
+	}
+	return l, nil
+}
+
+func (c *caseResolver) Photos(ctx context.Context) ([]*photoResolver, error) {
+	photos, err := ctx.Value("photoService").(*service.PhotoService).FindByCaseID(c.c.ID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	l := make([]*photoResolver, len(photos))
+	for i := range photos {
+		l[i] = &photoResolver{photos[i]}
+	}
+	return l, nil
+}
+
 func (c *caseResolver) CreatedAt() (*graphql.Time, error) {
 	if c.c.CreatedAt == "" {
 		return nil, nil
@@ -35,3 +87,12 @@ func (c *caseResolver) CreatedAt() (*graphql.Time, error) {
 	t, err := time.Parse(time.RFC3339, c.c.CreatedAt)
 	return &graphql.Time{Time: t}, err
 }
+
+func (c *caseResolver) UpdatedAt() (*graphql.Time, error) {
+	if c.c.UpdatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, c.c.UpdatedAt)
+	return &graphql.Time{Time: t}, err
+}
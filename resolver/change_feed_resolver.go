@@ -0,0 +1,29 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+)
+
+type changeFeedResolver struct {
+	changes []*model.Change
+	cursor  string
+	hasMore bool
+}
+
+func (r *changeFeedResolver) Changes() []*changeResolver {
+	l := make([]*changeResolver, len(r.changes))
+	for i := range l {
+		l[i] = &changeResolver{
+			c: r.changes[i],
+		}
+	}
+	return l
+}
+
+func (r *changeFeedResolver) Cursor() string {
+	return r.cursor
+}
+
+func (r *changeFeedResolver) HasMore() bool {
+	return r.hasMore
+}
@@ -0,0 +1,53 @@
+package resolver
+
+import (
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+func (r *Resolver) Changes(ctx context.Context, args struct {
+	Since       *string
+	EntityTypes *[]string
+	SchoolIDs   *[]string
+	First       *int32
+}) (*changeFeedResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	var entityTypes, schoolIDs []string
+	if args.EntityTypes != nil {
+		entityTypes = *args.EntityTypes
+	}
+	if args.SchoolIDs != nil {
+		schoolIDs = *args.SchoolIDs
+	}
+
+	access, err := ctx.Value("schoolService").(*service.SchoolService).FindAccess(*userID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	changes, hasMore, err := ctx.Value("changeService").(*service.ChangeService).List(args.First, args.Since, entityTypes, schoolIDs, access)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Retrieved %d changes by user_id[%s]", len(changes), *userID)
+
+	// an empty page keeps the client at its current position
+	cursor := service.EncodeChangeCursor(0)
+	if len(changes) > 0 {
+		cursor = service.EncodeChangeCursor(changes[len(changes)-1].Seq)
+	} else if args.Since != nil {
+		cursor = *args.Since
+	}
+
+	return &changeFeedResolver{changes: changes, cursor: cursor, hasMore: hasMore}, nil
+}
@@ -0,0 +1,114 @@
+package resolver
+
+import (
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/loader"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	"golang.org/x/net/context"
+)
+
+// changeResolver resolves a change log entry. The changed entity is returned
+// in its current state, and is null for tombstones and for entities that have
+// been deleted since.
+type changeResolver struct {
+	c *model.Change
+}
+
+func (c *changeResolver) Cursor() string {
+	return service.EncodeChangeCursor(c.c.Seq)
+}
+
+func (c *changeResolver) EntityType() string {
+	return c.c.EntityType
+}
+
+func (c *changeResolver) EntityID() graphql.ID {
+	return graphql.ID(c.c.EntityID)
+}
+
+func (c *changeResolver) Operation() string {
+	return c.c.Operation
+}
+
+func (c *changeResolver) ChangedAt() (*graphql.Time, error) {
+	if c.c.ChangedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, c.c.ChangedAt)
+	return &graphql.Time{Time: t}, err
+}
+
+func (c *changeResolver) School(ctx context.Context) (*schoolResolver, error) {
+	if !c.isLive(model.ChangeEntitySchool) {
+		return nil, nil
+	}
+
+	school, err := loader.LoadSchoolByID(ctx, c.c.EntityID)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return &schoolResolver{school}, nil
+}
+
+func (c *changeResolver) Student(ctx context.Context) (*studentResolver, error) {
+	if !c.isLive(model.ChangeEntityStudent) {
+		return nil, nil
+	}
+
+	student, err := loader.LoadStudentByID(ctx, c.c.EntityID)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return &studentResolver{student}, nil
+}
+
+func (c *changeResolver) DiagnosisAndAction(ctx context.Context) (*diagnosisAndActionResolver, error) {
+	if !c.isLive(model.ChangeEntityDiagnosisAndAction) {
+		return nil, nil
+	}
+
+	diagnosisAndAction, err := loader.LoadDiagnosisAndActionByID(ctx, c.c.EntityID)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return &diagnosisAndActionResolver{diagnosisAndAction}, nil
+}
+
+func (c *changeResolver) Survey(ctx context.Context) (*surveyResolver, error) {
+	if !c.isLive(model.ChangeEntitySurvey) {
+		return nil, nil
+	}
+
+	survey, err := loader.LoadSurveyByID(ctx, c.c.EntityID)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return &surveyResolver{survey}, nil
+}
+
+func (c *changeResolver) Case(ctx context.Context) (*caseResolver, error) {
+	if !c.isLive(model.ChangeEntityCase) {
+		return nil, nil
+	}
+
+	foundCase, err := loader.LoadCaseByID(ctx, c.c.EntityID)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return &caseResolver{foundCase}, nil
+}
+
+func (c *changeResolver) isLive(entityType string) bool {
+	return c.c.EntityType == entityType && c.c.Operation != model.ChangeOperationDeleted
+}
+
+func ignoreNotFound(err error) error {
+	if appErr, ok := err.(*model.Error); ok && appErr.Code == model.ErrorCodeNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+)
+
+type costAnalyticsResolver struct {
+	a *model.CostAnalytics
+}
+
+func (r *costAnalyticsResolver) Groups() []*costGroupResolver {
+	result := make([]*costGroupResolver, len(r.a.Groups))
+	for i, g := range r.a.Groups {
+		result[i] = &costGroupResolver{g, r.a.ProjectOutstanding}
+	}
+	return result
+}
+
+func (r *costAnalyticsResolver) Total() *costGroupResolver {
+	return &costGroupResolver{r.a.Total, r.a.ProjectOutstanding}
+}
+
+type costGroupResolver struct {
+	g                  *model.CostGroup
+	projectOutstanding bool
+}
+
+func (r *costGroupResolver) SchoolID() *string {
+	return r.g.SchoolID
+}
+
+func (r *costGroupResolver) SchoolName() *string {
+	return r.g.SchoolName
+}
+
+func (r *costGroupResolver) Month() *string {
+	return r.g.Month
+}
+
+func (r *costGroupResolver) Diagnosis() *string {
+	return r.g.Diagnosis
+}
+
+func (r *costGroupResolver) Action() *string {
+	return r.g.Action
+}
+
+func (r *costGroupResolver) RiskCategory() *string {
+	return r.g.RiskCategory
+}
+
+func (r *costGroupResolver) PlannedCost() float64 {
+	return r.g.Cost
+}
+
+func (r *costGroupResolver) PerformedCost() float64 {
+	return r.g.PerformedCost
+}
+
+func (r *costGroupResolver) PlannedCount() int32 {
+	return r.g.PlannedCount
+}
+
+func (r *costGroupResolver) PerformedCount() int32 {
+	return r.g.PerformedCount
+}
+
+func (r *costGroupResolver) OutstandingCost() *float64 {
+	if !r.projectOutstanding {
+		return nil
+	}
+	return &r.g.OutstandingCost
+}
+
+func (r *costGroupResolver) OutstandingCount() *int32 {
+	if !r.projectOutstanding {
+		return nil
+	}
+	return &r.g.OutstandingCount
+}
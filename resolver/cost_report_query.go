@@ -1,9 +1,8 @@
 package resolver
 
 import (
-	"errors"
-
 	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
 	"github.com/kerti/idcra-api/service"
 	"github.com/op/go-logging"
 	"golang.org/x/net/context"
@@ -15,7 +14,7 @@ func (r *Resolver) CostBreakdownBySchoolAndDateRange(ctx context.Context, args s
 	EndDate   string
 }) (*[]*costReportResolver, error) {
 	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
-		return nil, errors.New(gcontext.CredentialsError)
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
 	}
 	userID := ctx.Value("user_id").(*string)
 
@@ -42,3 +41,38 @@ func (r *Resolver) CostBreakdownBySchoolAndDateRange(ctx context.Context, args s
 
 	return &result, nil
 }
+
+func (r *Resolver) CostAnalytics(ctx context.Context, args struct {
+	SchoolIDs          *[]string
+	StartDate          string
+	EndDate            string
+	GroupBy            []string
+	ProjectOutstanding *bool
+}) (*costAnalyticsResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	filter := model.CostAnalyticsFilter{
+		StartDate: args.StartDate,
+		EndDate:   args.EndDate,
+		GroupBy:   args.GroupBy,
+	}
+	if args.SchoolIDs != nil {
+		filter.SchoolIDs = *args.SchoolIDs
+	}
+	if args.ProjectOutstanding != nil {
+		filter.ProjectOutstanding = *args.ProjectOutstanding
+	}
+
+	analytics, err := ctx.Value("reportService").(*service.ReportService).CostAnalytics(filter)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Retrieved cost analytics of %d groups by user_id[%s]", len(analytics.Groups), *userID)
+
+	return &costAnalyticsResolver{analytics}, nil
+}
@@ -15,3 +15,19 @@ func (c *costReportResolver) Description() string {
 func (c *costReportResolver) Cost() float64 {
 	return c.c.Cost
 }
+
+func (c *costReportResolver) PlannedCost() float64 {
+	return c.c.Cost
+}
+
+func (c *costReportResolver) PerformedCost() float64 {
+	return c.c.PerformedCost
+}
+
+func (c *costReportResolver) PlannedCount() int32 {
+	return c.c.PlannedCount
+}
+
+func (c *costReportResolver) PerformedCount() int32 {
+	return c.c.PerformedCount
+}
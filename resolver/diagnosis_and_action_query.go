@@ -1,10 +1,9 @@
 package resolver
 
 import (
-	"errors"
-
 	gcontext "github.com/kerti/idcra-api/context"
 	"github.com/kerti/idcra-api/loader"
+	"github.com/kerti/idcra-api/model"
 	"github.com/kerti/idcra-api/service"
 	"github.com/op/go-logging"
 	"golang.org/x/net/context"
@@ -14,7 +13,7 @@ func (r *Resolver) DiagnosisAndAction(ctx context.Context, args struct {
 	ID string
 }) (*diagnosisAndActionResolver, error) {
 	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
-		return nil, errors.New(gcontext.CredentialsError)
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
 	}
 	userID := ctx.Value("user_id").(*string)
 
@@ -34,7 +33,7 @@ func (r *Resolver) DiagnosisAndActions(ctx context.Context, args struct {
 	After *string
 }) (*diagnosisAndActionsConnectionResolver, error) {
 	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
-		return nil, errors.New(gcontext.CredentialsError)
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
 	}
 	userID := ctx.Value("user_id").(*string)
 
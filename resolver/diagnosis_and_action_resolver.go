@@ -27,6 +27,10 @@ func (d *diagnosisAndActionResolver) UnitCost() *float64 {
 	return &d.d.UnitCost
 }
 
+func (d *diagnosisAndActionResolver) IndexComponent() *string {
+	return d.d.IndexComponent
+}
+
 func (d *diagnosisAndActionResolver) CreatedAt() (*graphql.Time, error) {
 	if d.d.CreatedAt == "" {
 		return nil, nil
@@ -35,3 +39,12 @@ func (d *diagnosisAndActionResolver) CreatedAt() (*graphql.Time, error) {
 	t, err := time.Parse(time.RFC3339, d.d.CreatedAt)
 	return &graphql.Time{Time: t}, err
 }
+
+func (d *diagnosisAndActionResolver) UpdatedAt() (*graphql.Time, error) {
+	if d.d.UpdatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, d.d.UpdatedAt)
+	return &graphql.Time{Time: t}, err
+}
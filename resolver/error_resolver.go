@@ -0,0 +1,41 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+)
+
+type errorResolver struct {
+	e *model.Error
+}
+
+func (r *errorResolver) Code() string {
+	return string(r.e.Code)
+}
+
+func (r *errorResolver) Message() string {
+	return r.e.Message
+}
+
+func (r *errorResolver) Fields() *[]*fieldErrorResolver {
+	if len(r.e.Fields) == 0 {
+		return nil
+	}
+
+	fields := make([]*fieldErrorResolver, len(r.e.Fields))
+	for i := range r.e.Fields {
+		fields[i] = &fieldErrorResolver{&r.e.Fields[i]}
+	}
+	return &fields
+}
+
+type fieldErrorResolver struct {
+	f *model.FieldError
+}
+
+func (r *fieldErrorResolver) Field() string {
+	return r.f.Field
+}
+
+func (r *fieldErrorResolver) Message() string {
+	return r.f.Message
+}
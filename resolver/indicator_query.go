@@ -0,0 +1,44 @@
+package resolver
+
+import (
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+func (r *Resolver) Indicators(ctx context.Context, args struct {
+	SchoolIDs *[]string
+	StartDate string
+	EndDate   string
+	GroupBy   []string
+	Period    *string
+}) (*indicatorsResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	filter := model.IndicatorFilter{
+		StartDate: args.StartDate,
+		EndDate:   args.EndDate,
+		GroupBy:   args.GroupBy,
+	}
+	if args.SchoolIDs != nil {
+		filter.SchoolIDs = *args.SchoolIDs
+	}
+	if args.Period != nil {
+		filter.Period = *args.Period
+	}
+
+	indicators, err := ctx.Value("reportService").(*service.ReportService).FindIndicators(filter)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Retrieved indicators of %d groups by user_id[%s]", len(indicators.Groups), *userID)
+
+	return &indicatorsResolver{indicators}, nil
+}
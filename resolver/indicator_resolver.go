@@ -0,0 +1,116 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+)
+
+type indicatorsResolver struct {
+	i *model.Indicators
+}
+
+func (r *indicatorsResolver) ConfidenceLevel() float64 {
+	return model.IndicatorConfidenceLevel
+}
+
+func (r *indicatorsResolver) Groups() []*indicatorGroupResolver {
+	result := make([]*indicatorGroupResolver, len(r.i.Groups))
+	for i, g := range r.i.Groups {
+		result[i] = &indicatorGroupResolver{g}
+	}
+	return result
+}
+
+func (r *indicatorsResolver) Total() *indicatorGroupResolver {
+	return &indicatorGroupResolver{r.i.Total}
+}
+
+type indicatorGroupResolver struct {
+	g *model.IndicatorGroup
+}
+
+func (r *indicatorGroupResolver) SchoolID() *string {
+	return r.g.SchoolID
+}
+
+func (r *indicatorGroupResolver) SchoolName() *string {
+	return r.g.SchoolName
+}
+
+func (r *indicatorGroupResolver) AgeBand() *string {
+	return r.g.AgeBand
+}
+
+func (r *indicatorGroupResolver) Sex() *string {
+	return r.g.Sex
+}
+
+func (r *indicatorGroupResolver) Period() *string {
+	return r.g.Period
+}
+
+func (r *indicatorGroupResolver) Surveyed() int32 {
+	return r.g.Surveyed
+}
+
+func (r *indicatorGroupResolver) CariesPrevalence() *estimateResolver {
+	return &estimateResolver{r.g.CariesPrevalence}
+}
+
+func (r *indicatorGroupResolver) MeanDMFT() *estimateResolver {
+	return &estimateResolver{r.g.MeanDMFT}
+}
+
+func (r *indicatorGroupResolver) MeanDefT() *estimateResolver {
+	return &estimateResolver{r.g.MeanDefT}
+}
+
+func (r *indicatorGroupResolver) SignificantCariesIndex() *estimateResolver {
+	return &estimateResolver{r.g.SignificantCariesIndex}
+}
+
+func (r *indicatorGroupResolver) CareIndex() *estimateResolver {
+	if r.g.CareIndex == nil {
+		return nil
+	}
+	return &estimateResolver{*r.g.CareIndex}
+}
+
+func (r *indicatorGroupResolver) RiskDistribution() []*riskShareResolver {
+	result := make([]*riskShareResolver, len(r.g.RiskDistribution))
+	for i, s := range r.g.RiskDistribution {
+		result[i] = &riskShareResolver{s}
+	}
+	return result
+}
+
+type estimateResolver struct {
+	e model.Estimate
+}
+
+func (r *estimateResolver) Value() float64 {
+	return r.e.Value
+}
+
+func (r *estimateResolver) Lower() float64 {
+	return r.e.Lower
+}
+
+func (r *estimateResolver) Upper() float64 {
+	return r.e.Upper
+}
+
+type riskShareResolver struct {
+	s *model.RiskShare
+}
+
+func (r *riskShareResolver) Category() string {
+	return r.s.Category
+}
+
+func (r *riskShareResolver) Count() int32 {
+	return r.s.Count
+}
+
+func (r *riskShareResolver) Percentage() *estimateResolver {
+	return &estimateResolver{r.s.Percentage}
+}
@@ -0,0 +1,18 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+	"golang.org/x/net/context"
+)
+
+// Languages lists the languages reports can be printed in
+func (r *Resolver) Languages(ctx context.Context) []*languageResolver {
+	languages := model.Languages()
+	l := make([]*languageResolver, 0, len(languages))
+	for _, language := range languages {
+		if c, ok := model.FindCatalog(language); ok {
+			l = append(l, &languageResolver{c})
+		}
+	}
+	return l
+}
@@ -0,0 +1,15 @@
+package resolver
+
+import "github.com/kerti/idcra-api/model"
+
+type languageResolver struct {
+	c *model.MessageCatalog
+}
+
+func (r *languageResolver) Code() string {
+	return r.c.Language
+}
+
+func (r *languageResolver) Name() string {
+	return r.c.Name
+}
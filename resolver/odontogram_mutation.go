@@ -0,0 +1,49 @@
+package resolver
+
+import (
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+func (r *Resolver) SaveOdontogram(ctx context.Context, args *struct {
+	SurveyID string
+	Teeth    []*model.ToothInput
+}) (*odontogramResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	survey, err := ctx.Value("surveyService").(*service.SurveyService).FindByID(args.SurveyID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+	roles, err := ctx.Value("roleService").(*service.RoleService).FindByUserId(userID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+	if err := survey.CanChangeOdontogram(*userID, roles); err != nil {
+		return nil, err
+	}
+
+	odontogram, err := model.NewOdontogramFromInput(args.SurveyID, args.Teeth)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	savedOdontogram, err := ctx.Value("odontogramService").(*service.OdontogramService).Save(&odontogram)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Saved odontogram of survey %s by user_id[%s]", savedOdontogram.SurveyID, *userID)
+
+	return &odontogramResolver{savedOdontogram}, nil
+}
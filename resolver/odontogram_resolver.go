@@ -0,0 +1,23 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+)
+
+type odontogramResolver struct {
+	o *model.Odontogram
+}
+
+func (o *odontogramResolver) SurveyID() string {
+	return o.o.SurveyID
+}
+
+func (o *odontogramResolver) Teeth() []*toothResolver {
+	l := make([]*toothResolver, len(o.o.Teeth))
+	for i := range l {
+		l[i] = &toothResolver{
+			t: o.o.Teeth[i],
+		}
+	}
+	return l
+}
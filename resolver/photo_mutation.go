@@ -0,0 +1,78 @@
+package resolver
+
+import (
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+// authorizePhoto makes sure the signed in user may see and change the photos
+// of the survey a photo belongs to
+func authorizePhoto(ctx context.Context, id string) error {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	photo, err := ctx.Value("photoService").(*service.PhotoService).FindByID(id)
+	if err != nil {
+		return err
+	}
+	roles, err := ctx.Value("roleService").(*service.RoleService).FindByUserId(userID)
+	if err != nil {
+		return err
+	}
+	return ctx.Value("surveyService").(*service.SurveyService).CheckAccess(*userID, roles, photo.SurveyID)
+}
+
+func (r *Resolver) UpdatePhoto(ctx context.Context, args *struct {
+	ID              string
+	Caption         *string
+	IncludeInReport *bool
+}) (*photoResolver, error) {
+	if err := authorizePhoto(ctx, args.ID); err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	update := &model.PhotoUpdate{
+		ID:              args.ID,
+		Caption:         args.Caption,
+		IncludeInReport: args.IncludeInReport,
+	}
+	if err := update.Validate(); err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	photo, err := ctx.Value("photoService").(*service.PhotoService).Update(update)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Updated photo : %v", photo)
+
+	return &photoResolver{photo}, nil
+}
+
+func (r *Resolver) DeletePhoto(ctx context.Context, args *struct {
+	ID string
+}) (*photoResolver, error) {
+	if err := authorizePhoto(ctx, args.ID); err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	photo, err := ctx.Value("photoService").(*service.PhotoService).Delete(args.ID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Deleted photo : %v", photo)
+
+	return &photoResolver{photo}, nil
+}
@@ -0,0 +1,70 @@
+package resolver
+
+import (
+	"fmt"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/model"
+)
+
+type photoResolver struct {
+	p *model.Photo
+}
+
+func (p *photoResolver) ID() graphql.ID {
+	return graphql.ID(p.p.ID)
+}
+
+func (p *photoResolver) SurveyID() string {
+	return p.p.SurveyID
+}
+
+func (p *photoResolver) CaseID() *string {
+	return p.p.CaseID
+}
+
+func (p *photoResolver) ContentType() string {
+	return p.p.ContentType
+}
+
+func (p *photoResolver) Size() int32 {
+	return int32(p.p.Size)
+}
+
+func (p *photoResolver) Width() int32 {
+	return p.p.Width
+}
+
+func (p *photoResolver) Height() int32 {
+	return p.p.Height
+}
+
+func (p *photoResolver) URL() string {
+	return fmt.Sprintf("/photos/%s", p.p.ID)
+}
+
+func (p *photoResolver) ThumbnailURL() string {
+	return fmt.Sprintf("/photos/%s/thumbnail", p.p.ID)
+}
+
+func (p *photoResolver) Caption() *string {
+	return p.p.Caption
+}
+
+func (p *photoResolver) IncludeInReport() bool {
+	return p.p.IncludeInReport
+}
+
+func (p *photoResolver) UploadedBy() *string {
+	return p.p.UploadedBy
+}
+
+func (p *photoResolver) CreatedAt() (*graphql.Time, error) {
+	if p.p.CreatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, p.p.CreatedAt)
+	return &graphql.Time{Time: t}, err
+}
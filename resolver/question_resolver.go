@@ -0,0 +1,44 @@
+package resolver
+
+import (
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/model"
+)
+
+type questionResolver struct {
+	q *model.Question
+}
+
+func (q *questionResolver) ID() graphql.ID {
+	return graphql.ID(q.q.ID)
+}
+
+func (q *questionResolver) Code() string {
+	return q.q.Code
+}
+
+func (q *questionResolver) Position() int32 {
+	return q.q.Position
+}
+
+func (q *questionResolver) Text(args struct{ Locale *string }) *string {
+	return q.q.Text.Get(args.Locale)
+}
+
+func (q *questionResolver) Required() bool {
+	return q.q.Required
+}
+
+func (q *questionResolver) Weight() float64 {
+	return q.q.Weight
+}
+
+func (q *questionResolver) Options() []*answerOptionResolver {
+	l := make([]*answerOptionResolver, len(q.q.Options))
+	for i := range l {
+		l[i] = &answerOptionResolver{
+			o: q.q.Options[i],
+		}
+	}
+	return l
+}
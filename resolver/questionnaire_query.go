@@ -0,0 +1,42 @@
+package resolver
+
+import (
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+func (r *Resolver) Questionnaire(ctx context.Context, args struct {
+	ID      *string
+	Code    *string
+	Version *int32
+}) (*questionnaireResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+
+	var (
+		questionnaire *model.Questionnaire
+		err           error
+	)
+
+	questionnaireService := ctx.Value("questionnaireService").(*service.QuestionnaireService)
+	switch {
+	case args.ID != nil:
+		questionnaire, err = questionnaireService.FindByID(*args.ID)
+	case args.Code != nil:
+		questionnaire, err = questionnaireService.FindByCode(*args.Code, args.Version)
+	default:
+		err = model.NewFieldError("code", "either id or code is required")
+	}
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Retrieved questionnaire : %s version %d", questionnaire.Code, questionnaire.Version)
+
+	return &questionnaireResolver{questionnaire}, nil
+}
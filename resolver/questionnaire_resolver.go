@@ -0,0 +1,43 @@
+package resolver
+
+import (
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/model"
+)
+
+type questionnaireResolver struct {
+	q *model.Questionnaire
+}
+
+func (q *questionnaireResolver) ID() graphql.ID {
+	return graphql.ID(q.q.ID)
+}
+
+func (q *questionnaireResolver) Code() string {
+	return q.q.Code
+}
+
+func (q *questionnaireResolver) Version() int32 {
+	return q.q.Version
+}
+
+func (q *questionnaireResolver) CreatedAt() (*graphql.Time, error) {
+	if q.q.CreatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, q.q.CreatedAt)
+	return &graphql.Time{Time: t}, err
+}
+
+func (q *questionnaireResolver) Sections() []*questionnaireSectionResolver {
+	l := make([]*questionnaireSectionResolver, len(q.q.Sections))
+	for i := range l {
+		l[i] = &questionnaireSectionResolver{
+			s: q.q.Sections[i],
+		}
+	}
+	return l
+}
@@ -0,0 +1,36 @@
+package resolver
+
+import (
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/model"
+)
+
+type questionnaireSectionResolver struct {
+	s *model.QuestionnaireSection
+}
+
+func (s *questionnaireSectionResolver) ID() graphql.ID {
+	return graphql.ID(s.s.ID)
+}
+
+func (s *questionnaireSectionResolver) Code() string {
+	return s.s.Code
+}
+
+func (s *questionnaireSectionResolver) Position() int32 {
+	return s.s.Position
+}
+
+func (s *questionnaireSectionResolver) Title(args struct{ Locale *string }) *string {
+	return s.s.Title.Get(args.Locale)
+}
+
+func (s *questionnaireSectionResolver) Questions() []*questionResolver {
+	l := make([]*questionResolver, len(s.s.Questions))
+	for i := range l {
+		l[i] = &questionResolver{
+			q: s.s.Questions[i],
+		}
+	}
+	return l
+}
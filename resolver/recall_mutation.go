@@ -0,0 +1,56 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+func (r *Resolver) UpdateRecall(ctx context.Context, args *struct {
+	ID            string
+	Status        string
+	ScheduledDate *string
+	Note          *string
+}) (*recallResolver, error) {
+	if err := authorizeRoles(ctx, "update recalls", model.RoleSurveyor, model.RoleSupervisor, model.RoleAdmin); err != nil {
+		return nil, err
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	recallService := ctx.Value("recallService").(*service.RecallService)
+	existing, err := recallService.FindByID(args.ID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+	access, err := ctx.Value("schoolService").(*service.SchoolService).FindAccess(*userID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+	if err := access.Check([]string{existing.SchoolID}); err != nil {
+		return nil, err
+	}
+
+	update := &model.RecallUpdate{
+		ID:            args.ID,
+		Status:        args.Status,
+		ScheduledDate: args.ScheduledDate,
+		Note:          args.Note,
+	}
+	if err := update.Validate(); err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	recall, err := recallService.Update(update)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Updated recall by user_id[%s] : %v", *userID, recall)
+
+	return &recallResolver{recall}, nil
+}
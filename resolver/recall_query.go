@@ -0,0 +1,49 @@
+package resolver
+
+import (
+	"time"
+
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+func (r *Resolver) RecallsDue(ctx context.Context, args struct {
+	SchoolID string
+	Before   string
+}) ([]*recallResolver, error) {
+	if err := authorizeRoles(ctx, "list recalls", model.RoleSurveyor, model.RoleSupervisor, model.RoleAdmin); err != nil {
+		return nil, err
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	access, err := ctx.Value("schoolService").(*service.SchoolService).FindAccess(*userID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+	if err := access.Check([]string{args.SchoolID}); err != nil {
+		return nil, err
+	}
+
+	if _, err := time.Parse("2006-01-02", args.Before); err != nil {
+		return nil, model.NewFieldError("before", "invalid date format, expecting yyyy-mm-dd")
+	}
+
+	recalls, err := ctx.Value("recallService").(*service.RecallService).ListDue(args.SchoolID, args.Before)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Retrieved %d due recalls by user_id[%s]", len(recalls), *userID)
+
+	l := make([]*recallResolver, len(recalls))
+	for i := range l {
+		l[i] = &recallResolver{
+			r: recalls[i],
+		}
+	}
+	return l, nil
+}
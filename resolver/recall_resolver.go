@@ -0,0 +1,90 @@
+package resolver
+
+import (
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/loader"
+	"github.com/kerti/idcra-api/model"
+	"golang.org/x/net/context"
+)
+
+type recallResolver struct {
+	r *model.Recall
+}
+
+func (r *recallResolver) ID() graphql.ID {
+	return graphql.ID(r.r.ID)
+}
+
+func (r *recallResolver) StudentID() string {
+	return r.r.StudentID
+}
+
+func (r *recallResolver) Student(ctx context.Context) (*studentResolver, error) {
+	student, err := loader.LoadStudentByID(ctx, r.r.StudentID)
+	if err != nil {
+		return nil, err
+	}
+	return &studentResolver{student}, nil
+}
+
+func (r *recallResolver) SurveyID() string {
+	return r.r.SurveyID
+}
+
+func (r *recallResolver) RiskCategory() string {
+	return r.r.RiskCategory
+}
+
+func (r *recallResolver) DueDate() (*graphql.Time, error) {
+	if r.r.DueDate == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, r.r.DueDate)
+	return &graphql.Time{Time: t}, err
+}
+
+func (r *recallResolver) Status() string {
+	return r.r.Status
+}
+
+func (r *recallResolver) Overdue() bool {
+	return r.r.IsOverdue(time.Now())
+}
+
+func (r *recallResolver) ScheduledDate() (*graphql.Time, error) {
+	if r.r.ScheduledDate == nil {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, *r.r.ScheduledDate)
+	return &graphql.Time{Time: t}, err
+}
+
+func (r *recallResolver) Note() *string {
+	return r.r.Note
+}
+
+func (r *recallResolver) CompletedSurveyID() *string {
+	return r.r.CompletedSurveyID
+}
+
+func (r *recallResolver) CreatedAt() (*graphql.Time, error) {
+	if r.r.CreatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, r.r.CreatedAt)
+	return &graphql.Time{Time: t}, err
+}
+
+func (r *recallResolver) UpdatedAt() (*graphql.Time, error) {
+	if r.r.UpdatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, r.r.UpdatedAt)
+	return &graphql.Time{Time: t}, err
+}
@@ -0,0 +1,54 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+func (r *Resolver) PublishRecommendationRules(ctx context.Context, args *struct {
+	Rules []*model.RecommendationRuleInput
+	Note  *string
+}) (*recommendationRuleSetResolver, error) {
+	if err := authorizeRoles(ctx, "manage recommendation rules", model.RoleAdmin); err != nil {
+		return nil, err
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	ruleSet, err := model.NewRecommendationRuleSetFromInput(args.Rules, args.Note, *userID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	published, err := ctx.Value("recommendationService").(*service.RecommendationService).Publish(ruleSet)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Published recommendation rules version %d by user_id[%s]", published.Version, *userID)
+
+	return &recommendationRuleSetResolver{published}, nil
+}
+
+func (r *Resolver) RestoreRecommendationRules(ctx context.Context, args *struct {
+	Version int32
+	Note    *string
+}) (*recommendationRuleSetResolver, error) {
+	if err := authorizeRoles(ctx, "manage recommendation rules", model.RoleAdmin); err != nil {
+		return nil, err
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	published, err := ctx.Value("recommendationService").(*service.RecommendationService).Restore(args.Version, args.Note, *userID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Restored recommendation rules version %d as version %d by user_id[%s]", args.Version, published.Version, *userID)
+
+	return &recommendationRuleSetResolver{published}, nil
+}
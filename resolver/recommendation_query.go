@@ -0,0 +1,27 @@
+package resolver
+
+import (
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+func (r *Resolver) RecommendationRules(ctx context.Context, args struct {
+	Version *int32
+}) (*recommendationRuleSetResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+
+	ruleSet, err := ctx.Value("recommendationService").(*service.RecommendationService).FindByVersion(args.Version)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Retrieved recommendation rules version %d", ruleSet.Version)
+
+	return &recommendationRuleSetResolver{ruleSet}, nil
+}
@@ -0,0 +1,50 @@
+package resolver
+
+import (
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/model"
+)
+
+type recommendationRuleResolver struct {
+	r *model.RecommendationRule
+}
+
+func (r *recommendationRuleResolver) ID() graphql.ID {
+	return graphql.ID(r.r.ID)
+}
+
+func (r *recommendationRuleResolver) Position() int32 {
+	return r.r.Position
+}
+
+func (r *recommendationRuleResolver) RiskCategory() string {
+	return r.r.RiskCategory
+}
+
+func (r *recommendationRuleResolver) Target() string {
+	return r.r.Target
+}
+
+func (r *recommendationRuleResolver) MinAge() *int32 {
+	return r.r.MinAge
+}
+
+func (r *recommendationRuleResolver) MaxAge() *int32 {
+	return r.r.MaxAge
+}
+
+func (r *recommendationRuleResolver) QuestionCode() *string {
+	return r.r.QuestionCode
+}
+
+func (r *recommendationRuleResolver) Answer() *string {
+	return r.r.AnswerValue
+}
+
+func (r *recommendationRuleResolver) DiagnosisAndActionID() *string {
+	return r.r.DiagnosisAndActionID
+}
+
+func (r *recommendationRuleResolver) Text(args struct{ Locale *string }) *string {
+	return r.r.Text.Get(args.Locale)
+}
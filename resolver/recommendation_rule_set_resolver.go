@@ -0,0 +1,47 @@
+package resolver
+
+import (
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/model"
+)
+
+type recommendationRuleSetResolver struct {
+	rs *model.RecommendationRuleSet
+}
+
+func (r *recommendationRuleSetResolver) ID() graphql.ID {
+	return graphql.ID(r.rs.ID)
+}
+
+func (r *recommendationRuleSetResolver) Version() int32 {
+	return r.rs.Version
+}
+
+func (r *recommendationRuleSetResolver) Note() *string {
+	return r.rs.Note
+}
+
+func (r *recommendationRuleSetResolver) CreatedBy() *string {
+	return r.rs.CreatedBy
+}
+
+func (r *recommendationRuleSetResolver) CreatedAt() (*graphql.Time, error) {
+	if r.rs.CreatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, r.rs.CreatedAt)
+	return &graphql.Time{Time: t}, err
+}
+
+func (r *recommendationRuleSetResolver) Rules() []*recommendationRuleResolver {
+	l := make([]*recommendationRuleResolver, len(r.rs.Rules))
+	for i := range l {
+		l[i] = &recommendationRuleResolver{
+			r: r.rs.Rules[i],
+		}
+	}
+	return l
+}
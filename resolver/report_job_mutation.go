@@ -0,0 +1,93 @@
+package resolver
+
+import (
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+func (r *Resolver) GenerateSchoolReport(ctx context.Context, args *struct {
+	SchoolID string
+	JobID    *string
+	Lang     *string
+}) (*reportJobResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	schoolService := ctx.Value("schoolService").(*service.SchoolService)
+	access, err := schoolService.FindAccess(*userID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+	if err := access.Check([]string{args.SchoolID}); err != nil {
+		return nil, err
+	}
+
+	school, err := schoolService.FindByID(args.SchoolID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	var lang string
+	if args.Lang != nil {
+		lang = *args.Lang
+	}
+	language, err := ctx.Value("userService").(*service.UserService).ReportLanguage(userID, lang)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	job, err := ctx.Value("reportJobService").(*service.ReportJobService).Enqueue(school.ID, language, args.JobID, userID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Queued report job %s of school %s by user_id[%s]", job.ID, school.ID, *userID)
+
+	return &reportJobResolver{job}, nil
+}
+
+func (r *Resolver) ExportSurveys(ctx context.Context, args *struct {
+	Format     string
+	SchoolID   *string
+	StartDate  *string
+	EndDate    *string
+	SurveyorID *string
+	JobID      *string
+}) (*reportJobResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	scope, err := ctx.Value("exportService").(*service.ExportService).DataScope(userID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	filter := model.SurveyExportFilter{
+		Format:     args.Format,
+		SchoolID:   args.SchoolID,
+		StartDate:  args.StartDate,
+		EndDate:    args.EndDate,
+		SurveyorID: args.SurveyorID,
+	}
+	job, err := ctx.Value("reportJobService").(*service.ReportJobService).EnqueueExport(filter, scope, args.JobID, userID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Queued survey export job %s by user_id[%s]", job.ID, *userID)
+
+	return &reportJobResolver{job}, nil
+}
@@ -0,0 +1,28 @@
+package resolver
+
+import (
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+func (r *Resolver) ReportJob(ctx context.Context, args struct {
+	ID string
+}) (*reportJobResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+
+	job, err := ctx.Value("reportJobService").(*service.ReportJobService).FindByID(args.ID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+	if !job.CanDownload(*ctx.Value("user_id").(*string)) {
+		return nil, model.NewNotFoundError("report job", args.ID)
+	}
+
+	return &reportJobResolver{job}, nil
+}
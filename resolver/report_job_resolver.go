@@ -0,0 +1,85 @@
+package resolver
+
+import (
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/model"
+)
+
+type reportJobResolver struct {
+	j *model.ReportJob
+}
+
+func (r *reportJobResolver) ID() graphql.ID {
+	return graphql.ID(r.j.ID)
+}
+
+func (r *reportJobResolver) Kind() string {
+	return r.j.Kind
+}
+
+func (r *reportJobResolver) SchoolID() *string {
+	if r.j.SchoolID == "" {
+		return nil
+	}
+	return &r.j.SchoolID
+}
+
+func (r *reportJobResolver) Language() string {
+	return r.j.Language
+}
+
+func (r *reportJobResolver) Status() string {
+	return r.j.Status
+}
+
+func (r *reportJobResolver) Completed() int32 {
+	return int32(r.j.Completed)
+}
+
+func (r *reportJobResolver) Total() int32 {
+	return int32(r.j.Total)
+}
+
+func (r *reportJobResolver) Progress() float64 {
+	return r.j.Progress()
+}
+
+func (r *reportJobResolver) Error() *string {
+	if r.j.Error == "" {
+		return nil
+	}
+	return &r.j.Error
+}
+
+func (r *reportJobResolver) DownloadURL() *string {
+	url := r.j.DownloadURL()
+	if url == "" {
+		return nil
+	}
+	return &url
+}
+
+func (r *reportJobResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.j.CreatedAt}
+}
+
+func (r *reportJobResolver) StartedAt() *graphql.Time {
+	if r.j.StartedAt == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *r.j.StartedAt}
+}
+
+func (r *reportJobResolver) FinishedAt() *graphql.Time {
+	if r.j.FinishedAt == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *r.j.FinishedAt}
+}
+
+func (r *reportJobResolver) ExpiresAt() *graphql.Time {
+	if r.j.ExpiresAt == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *r.j.ExpiresAt}
+}
@@ -0,0 +1,108 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+func (r *Resolver) SaveReportTemplate(ctx context.Context, args *struct {
+	ID       *string
+	Template *model.ReportTemplateInput
+}) (*reportTemplateResolver, error) {
+	if err := authorizeRoles(ctx, "manage report templates", model.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	template, err := ctx.Value("reportTemplateService").(*service.ReportTemplateService).Save(args.ID, args.Template)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Saved report template %s by user_id[%s]", template.ID, *ctx.Value("user_id").(*string))
+
+	return &reportTemplateResolver{template}, nil
+}
+
+func (r *Resolver) CreateOrganization(ctx context.Context, args *struct {
+	Name string
+}) (*organizationResolver, error) {
+	if err := authorizeRoles(ctx, "manage organizations", model.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	organization, err := ctx.Value("reportTemplateService").(*service.ReportTemplateService).CreateOrganization(args.Name)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Created organization : %v", *organization)
+
+	return &organizationResolver{organization}, nil
+}
+
+func (r *Resolver) SetOrganizationReportTemplate(ctx context.Context, args *struct {
+	OrganizationID   string
+	ReportTemplateID *string
+}) (*organizationResolver, error) {
+	if err := authorizeRoles(ctx, "manage organizations", model.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	organization, err := ctx.Value("reportTemplateService").(*service.ReportTemplateService).SetOrganizationReportTemplate(args.OrganizationID, args.ReportTemplateID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Set report template of organization %s", organization.ID)
+
+	return &organizationResolver{organization}, nil
+}
+
+func (r *Resolver) SetSchoolOrganization(ctx context.Context, args *struct {
+	SchoolID       string
+	OrganizationID *string
+}) (*schoolResolver, error) {
+	if err := authorizeRoles(ctx, "manage organizations", model.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Value("reportTemplateService").(*service.ReportTemplateService).SetSchoolOrganization(args.SchoolID, args.OrganizationID); err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	return findSchool(ctx, args.SchoolID)
+}
+
+func (r *Resolver) SetSchoolReportTemplate(ctx context.Context, args *struct {
+	SchoolID         string
+	ReportTemplateID *string
+}) (*schoolResolver, error) {
+	if err := authorizeRoles(ctx, "manage report templates", model.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Value("reportTemplateService").(*service.ReportTemplateService).SetSchoolReportTemplate(args.SchoolID, args.ReportTemplateID); err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	return findSchool(ctx, args.SchoolID)
+}
+
+func findSchool(ctx context.Context, id string) (*schoolResolver, error) {
+	school, err := ctx.Value("schoolService").(*service.SchoolService).FindByID(id)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Updated school : %v", *school)
+
+	return &schoolResolver{school}, nil
+}
@@ -0,0 +1,48 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	"github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+func (r *Resolver) ReportTemplates(ctx context.Context) ([]*reportTemplateResolver, error) {
+	if err := authorizeRoles(ctx, "manage report templates", model.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	templates, err := ctx.Value("reportTemplateService").(*service.ReportTemplateService).FindAll()
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Retrieved %d report templates", len(templates))
+
+	l := make([]*reportTemplateResolver, len(templates))
+	for i := range templates {
+		l[i] = &reportTemplateResolver{templates[i]}
+	}
+	return l, nil
+}
+
+func (r *Resolver) Organizations(ctx context.Context) ([]*organizationResolver, error) {
+	if err := authorizeRoles(ctx, "manage organizations", model.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	organizations, err := ctx.Value("reportTemplateService").(*service.ReportTemplateService).FindOrganizations()
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Retrieved %d organizations", len(organizations))
+
+	l := make([]*organizationResolver, len(organizations))
+	for i := range organizations {
+		l[i] = &organizationResolver{organizations[i]}
+	}
+	return l, nil
+}
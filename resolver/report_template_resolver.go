@@ -0,0 +1,96 @@
+package resolver
+
+import (
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/model"
+)
+
+type reportTemplateResolver struct {
+	t *model.ReportTemplate
+}
+
+func (r *reportTemplateResolver) ID() graphql.ID {
+	return graphql.ID(r.t.ID)
+}
+
+func (r *reportTemplateResolver) Name() string {
+	return r.t.Name
+}
+
+func (r *reportTemplateResolver) Title() *string {
+	return r.t.Title
+}
+
+func (r *reportTemplateResolver) HeaderText() *string {
+	return r.t.HeaderText
+}
+
+func (r *reportTemplateResolver) FooterText() *string {
+	return r.t.FooterText
+}
+
+func (r *reportTemplateResolver) PrimaryColor() string {
+	return r.t.PrimaryColor
+}
+
+func (r *reportTemplateResolver) AccentColor() string {
+	return r.t.AccentColor
+}
+
+func (r *reportTemplateResolver) HasLogo() bool {
+	return r.t.LogoKey != nil
+}
+
+func (r *reportTemplateResolver) CreatedAt() (*graphql.Time, error) {
+	if r.t.CreatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, r.t.CreatedAt)
+	return &graphql.Time{Time: t}, err
+}
+
+func (r *reportTemplateResolver) UpdatedAt() (*graphql.Time, error) {
+	if r.t.UpdatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, r.t.UpdatedAt)
+	return &graphql.Time{Time: t}, err
+}
+
+type organizationResolver struct {
+	o *model.Organization
+}
+
+func (r *organizationResolver) ID() graphql.ID {
+	return graphql.ID(r.o.ID)
+}
+
+func (r *organizationResolver) Name() string {
+	return r.o.Name
+}
+
+func (r *organizationResolver) ReportTemplateID() *string {
+	return r.o.ReportTemplateID
+}
+
+func (r *organizationResolver) CreatedAt() (*graphql.Time, error) {
+	if r.o.CreatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, r.o.CreatedAt)
+	return &graphql.Time{Time: t}, err
+}
+
+func (r *organizationResolver) UpdatedAt() (*graphql.Time, error) {
+	if r.o.UpdatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, r.o.UpdatedAt)
+	return &graphql.Time{Time: t}, err
+}
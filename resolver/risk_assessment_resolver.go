@@ -0,0 +1,21 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+)
+
+type riskAssessmentResolver struct {
+	r *model.RiskAssessment
+}
+
+func (r *riskAssessmentResolver) Model() string {
+	return r.r.Model
+}
+
+func (r *riskAssessmentResolver) Score() float64 {
+	return r.r.Score
+}
+
+func (r *riskAssessmentResolver) Category() string {
+	return r.r.Category
+}
@@ -22,3 +22,56 @@ func (r *Resolver) CreateSchool(ctx context.Context, args *struct {
 	ctx.Value("log").(*logging.Logger).Debugf("Created school : %v", *school)
 	return &schoolResolver{school}, nil
 }
+
+// AssignUserToSchool lets a surveyor or teacher work with a school
+func (r *Resolver) AssignUserToSchool(ctx context.Context, args *struct {
+	UserID   string
+	SchoolID string
+}) (*schoolResolver, error) {
+	if err := authorizeRoles(ctx, "assign users to schools", model.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	school, err := ctx.Value("schoolService").(*service.SchoolService).AssignUser(args.UserID, args.SchoolID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+	ctx.Value("log").(*logging.Logger).Debugf("Assigned user_id[%s] to school %s", args.UserID, school.ID)
+	return &schoolResolver{school}, nil
+}
+
+func (r *Resolver) RemoveUserFromSchool(ctx context.Context, args *struct {
+	UserID   string
+	SchoolID string
+}) (*schoolResolver, error) {
+	if err := authorizeRoles(ctx, "assign users to schools", model.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	school, err := ctx.Value("schoolService").(*service.SchoolService).UnassignUser(args.UserID, args.SchoolID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+	ctx.Value("log").(*logging.Logger).Debugf("Removed user_id[%s] from school %s", args.UserID, school.ID)
+	return &schoolResolver{school}, nil
+}
+
+// SetSchoolTimeZone changes the time zone recalls of the school are kept in
+func (r *Resolver) SetSchoolTimeZone(ctx context.Context, args *struct {
+	SchoolID string
+	TimeZone *string
+}) (*schoolResolver, error) {
+	if err := authorizeRoles(ctx, "change the time zone of schools", model.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	school, err := ctx.Value("schoolService").(*service.SchoolService).SetTimeZone(args.SchoolID, args.TimeZone)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+	ctx.Value("log").(*logging.Logger).Debugf("Changed time zone of school %s", school.ID)
+	return &schoolResolver{school}, nil
+}
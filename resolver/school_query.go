@@ -1,10 +1,9 @@
 package resolver
 
 import (
-	"errors"
-
 	gcontext "github.com/kerti/idcra-api/context"
 	"github.com/kerti/idcra-api/loader"
+	"github.com/kerti/idcra-api/model"
 	"github.com/kerti/idcra-api/service"
 	"github.com/op/go-logging"
 	"golang.org/x/net/context"
@@ -15,7 +14,7 @@ func (r *Resolver) School(ctx context.Context, args struct {
 	StudentName *string
 }) (*schoolResolver, error) {
 	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
-		return nil, errors.New(gcontext.CredentialsError)
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
 	}
 	userID := ctx.Value("user_id").(*string)
 
@@ -44,7 +43,7 @@ func (r *Resolver) Schools(ctx context.Context, args struct {
 	After *string
 }) (*schoolsConnectionResolver, error) {
 	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
-		return nil, errors.New(gcontext.CredentialsError)
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
 	}
 	userID := ctx.Value("user_id").(*string)
 
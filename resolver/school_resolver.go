@@ -19,6 +19,18 @@ func (s *schoolResolver) Name() *string {
 	return &s.s.Name
 }
 
+func (s *schoolResolver) OrganizationID() *string {
+	return s.s.OrganizationID
+}
+
+func (s *schoolResolver) ReportTemplateID() *string {
+	return s.s.ReportTemplateID
+}
+
+func (s *schoolResolver) TimeZone() *string {
+	return s.s.TimeZone
+}
+
 func (s *schoolResolver) CreatedAt() (*graphql.Time, error) {
 	if s.s.CreatedAt == "" {
 		return nil, nil
@@ -28,6 +40,15 @@ func (s *schoolResolver) CreatedAt() (*graphql.Time, error) {
 	return &graphql.Time{Time: t}, err
 }
 
+func (s *schoolResolver) UpdatedAt() (*graphql.Time, error) {
+	if s.s.UpdatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s.s.UpdatedAt)
+	return &graphql.Time{Time: t}, err
+}
+
 func (s *schoolResolver) Students() *[]*studentResolver {
 	l := make([]*studentResolver, len(s.s.Students))
 	for i := range l {
@@ -13,9 +13,15 @@ func (r *Resolver) CreateStudent(ctx context.Context, args *struct {
 	Name        string
 	DateOfBirth string
 	SchoolID    string
+	Sex         *string
 }) (*studentResolver, error) {
 	_, err := time.Parse("2006-01-02", args.DateOfBirth)
 	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, model.NewFieldError("dateOfBirth", "invalid date format, expecting yyyy-mm-dd")
+	}
+
+	if err := model.ValidateSex("sex", args.Sex); err != nil {
 		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
 		return nil, err
 	}
@@ -23,6 +29,7 @@ func (r *Resolver) CreateStudent(ctx context.Context, args *struct {
 	student := &model.Student{
 		Name:        args.Name,
 		DateOfBirth: args.DateOfBirth,
+		Sex:         args.Sex,
 		SchoolID:    args.SchoolID,
 	}
 
@@ -1,10 +1,9 @@
 package resolver
 
 import (
-	"errors"
-
 	gcontext "github.com/kerti/idcra-api/context"
 	"github.com/kerti/idcra-api/loader"
+	"github.com/kerti/idcra-api/model"
 	"github.com/kerti/idcra-api/service"
 	"github.com/op/go-logging"
 	"golang.org/x/net/context"
@@ -14,7 +13,7 @@ func (r *Resolver) Student(ctx context.Context, args struct {
 	ID string
 }) (*studentResolver, error) {
 	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
-		return nil, errors.New(gcontext.CredentialsError)
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
 	}
 	userID := ctx.Value("user_id").(*string)
 
@@ -36,7 +35,7 @@ func (r *Resolver) Students(ctx context.Context, args struct {
 	Keyword  *string
 }) (*studentsConnectionResolver, error) {
 	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
-		return nil, errors.New(gcontext.CredentialsError)
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
 	}
 	userID := ctx.Value("user_id").(*string)
 
@@ -5,6 +5,8 @@ import (
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	"golang.org/x/net/context"
 )
 
 type studentResolver struct {
@@ -28,6 +30,10 @@ func (s *studentResolver) DateOfBirth() (*graphql.Time, error) {
 	return &graphql.Time{Time: t}, err
 }
 
+func (s *studentResolver) Sex() *string {
+	return s.s.Sex
+}
+
 func (s *studentResolver) SchoolID() graphql.ID {
 	return graphql.ID(s.s.SchoolID)
 }
@@ -44,3 +50,27 @@ func (s *studentResolver) CreatedAt() (*graphql.Time, error) {
 	t, err := time.Parse(time.RFC3339, s.s.CreatedAt)
 	return &graphql.Time{Time: t}, err
 }
+
+func (s *studentResolver) UpdatedAt() (*graphql.Time, error) {
+	if s.s.UpdatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s.s.UpdatedAt)
+	return &graphql.Time{Time: t}, err
+}
+
+func (s *studentResolver) History(ctx context.Context) ([]*studentVisitResolver, error) {
+	history, err := ctx.Value("historyService").(*service.HistoryService).FindByStudentID(s.s.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	l := make([]*studentVisitResolver, len(history.Visits))
+	for i := range l {
+		l[i] = &studentVisitResolver{
+			v: history.Visits[i],
+		}
+	}
+	return l, nil
+}
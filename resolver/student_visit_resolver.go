@@ -0,0 +1,109 @@
+package resolver
+
+import (
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/model"
+)
+
+type studentVisitResolver struct {
+	v *model.StudentVisit
+}
+
+func (v *studentVisitResolver) SurveyID() string {
+	return v.v.SurveyID
+}
+
+func (v *studentVisitResolver) Date() (*graphql.Time, error) {
+	if v.v.Date == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v.v.Date)
+	return &graphql.Time{Time: t}, err
+}
+
+func (v *studentVisitResolver) SubjectiveScore() int32 {
+	return v.v.SubjectiveScore
+}
+
+func (v *studentVisitResolver) RiskCategory() string {
+	return v.v.RiskCategory
+}
+
+func (v *studentVisitResolver) RiskAssessments() []*riskAssessmentResolver {
+	l := make([]*riskAssessmentResolver, len(v.v.RiskAssessments))
+	for i := range l {
+		l[i] = &riskAssessmentResolver{
+			r: v.v.RiskAssessments[i],
+		}
+	}
+	return l
+}
+
+func (v *studentVisitResolver) Indices() *toothIndicesResolver {
+	return &toothIndicesResolver{v.v.Indices}
+}
+
+func (v *studentVisitResolver) Dmft() int32 {
+	return v.v.Indices.DMFT()
+}
+
+func (v *studentVisitResolver) Deft() int32 {
+	return v.v.Indices.DEFT()
+}
+
+func (v *studentVisitResolver) Lesions() []int32 {
+	return teethOrEmpty(v.v.Lesions)
+}
+
+func (v *studentVisitResolver) Change() *studentVisitChangeResolver {
+	if v.v.Change == nil {
+		return nil
+	}
+	return &studentVisitChangeResolver{v.v.Change}
+}
+
+func (v *studentVisitResolver) NewLesions() []int32 {
+	return teethOrEmpty(v.v.NewLesions)
+}
+
+func (v *studentVisitResolver) ResolvedLesions() []int32 {
+	return teethOrEmpty(v.v.ResolvedLesions)
+}
+
+type studentVisitChangeResolver struct {
+	c *model.StudentVisitChange
+}
+
+func (c *studentVisitChangeResolver) DaysSincePrevious() int32 {
+	return c.c.DaysSincePrevious
+}
+
+func (c *studentVisitChangeResolver) SubjectiveScore() int32 {
+	return c.c.SubjectiveScore
+}
+
+func (c *studentVisitChangeResolver) Indices() *toothIndicesResolver {
+	return &toothIndicesResolver{c.c.Indices}
+}
+
+func (c *studentVisitChangeResolver) Dmft() int32 {
+	return c.c.Indices.DMFT()
+}
+
+func (c *studentVisitChangeResolver) Deft() int32 {
+	return c.c.Indices.DEFT()
+}
+
+func (c *studentVisitChangeResolver) RiskCategoryChanged() bool {
+	return c.c.RiskCategoryChanged
+}
+
+func teethOrEmpty(teeth []int32) []int32 {
+	if teeth == nil {
+		return []int32{}
+	}
+	return teeth
+}
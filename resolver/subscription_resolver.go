@@ -0,0 +1,62 @@
+package resolver
+
+import (
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	"golang.org/x/net/context"
+)
+
+// SubscriptionResolver resolves the Subscription type. A subscription is
+// executed once for every event published on the event bus, with the event
+// attached to the context; fields resolve to null for events they do not match.
+type SubscriptionResolver struct{}
+
+func (r *SubscriptionResolver) SurveyCreated(ctx context.Context, args struct {
+	SchoolID string
+}) (*surveyResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+
+	event, ok := ctx.Value("event").(*service.Event)
+	if !ok || event.Topic != service.SurveyCreatedTopic {
+		return nil, nil
+	}
+
+	payload := event.Payload.(*model.SurveyCreatedEvent)
+	if payload.SchoolID != args.SchoolID {
+		return nil, nil
+	}
+
+	// surveys are only sent to users working with the school
+	access, err := ctx.Value("schoolService").(*service.SchoolService).FindAccess(*ctx.Value("user_id").(*string))
+	if err != nil {
+		return nil, err
+	}
+	if !access.Allows(payload.SchoolID) {
+		return nil, nil
+	}
+
+	return &surveyResolver{payload.Survey}, nil
+}
+
+func (r *SubscriptionResolver) ReportReady(ctx context.Context, args struct {
+	JobID string
+}) (*reportJobResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+
+	event, ok := ctx.Value("event").(*service.Event)
+	if !ok || event.Topic != service.ReportReadyTopic {
+		return nil, nil
+	}
+
+	payload := event.Payload.(*model.ReportReadyEvent)
+	if payload.Job.ID != args.JobID || !payload.Job.CanDownload(*ctx.Value("user_id").(*string)) {
+		return nil, nil
+	}
+
+	return &reportJobResolver{&payload.Job}, nil
+}
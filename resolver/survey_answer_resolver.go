@@ -0,0 +1,25 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+)
+
+type surveyAnswerResolver struct {
+	a *model.SurveyAnswer
+}
+
+func (a *surveyAnswerResolver) QuestionID() string {
+	return a.a.QuestionID
+}
+
+func (a *surveyAnswerResolver) QuestionCode() string {
+	return a.a.QuestionCode
+}
+
+func (a *surveyAnswerResolver) Value() string {
+	return a.a.Value
+}
+
+func (a *surveyAnswerResolver) Score() float64 {
+	return a.a.Score
+}
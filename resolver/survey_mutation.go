@@ -1,6 +1,9 @@
 package resolver
 
 import (
+	"fmt"
+
+	gcontext "github.com/kerti/idcra-api/context"
 	"github.com/kerti/idcra-api/model"
 	"github.com/kerti/idcra-api/service"
 	logging "github.com/op/go-logging"
@@ -10,6 +13,14 @@ import (
 func (r *Resolver) CreateSurvey(ctx context.Context, args *struct {
 	Survey *model.SurveyInput
 }) (*surveyResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	if err := args.Survey.AssignSurveyor(*userID); err != nil {
+		return nil, err
+	}
 	survey, err := model.NewSurveyFromInput(*args.Survey)
 	if err != nil {
 		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
@@ -26,3 +37,39 @@ func (r *Resolver) CreateSurvey(ctx context.Context, args *struct {
 
 	return &surveyResolver{createdSurvey}, nil
 }
+
+func (r *Resolver) SubmitSurveys(ctx context.Context, args *struct {
+	Surveys []*model.SurveySubmissionInput
+}) ([]*surveySubmissionResultResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	if len(args.Surveys) > model.MaxSurveySubmissions {
+		return nil, model.NewFieldError("surveys", fmt.Sprintf("at most %d surveys can be submitted at once", model.MaxSurveySubmissions))
+	}
+
+	surveyService := ctx.Value("surveyService").(*service.SurveyService)
+
+	results := make([]*surveySubmissionResultResolver, len(args.Surveys))
+	for i, input := range args.Surveys {
+		var result *model.SurveySubmissionResult
+
+		var submission model.SurveySubmission
+		err := input.Survey.AssignSurveyor(*userID)
+		if err == nil {
+			submission, err = model.NewSurveySubmissionFromInput(*input)
+		}
+		if err != nil {
+			result = model.NewFailedSurveySubmissionResult(input.IdempotencyKey, err)
+		} else {
+			result = surveyService.SubmitSurvey(*userID, &submission)
+		}
+
+		ctx.Value("log").(*logging.Logger).Debugf("Submitted survey %s : %s", result.IdempotencyKey, result.Status)
+		results[i] = &surveySubmissionResultResolver{result}
+	}
+
+	return results, nil
+}
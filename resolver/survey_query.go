@@ -1,10 +1,9 @@
 package resolver
 
 import (
-	"errors"
-
 	gcontext "github.com/kerti/idcra-api/context"
 	"github.com/kerti/idcra-api/loader"
+	"github.com/kerti/idcra-api/model"
 	"github.com/kerti/idcra-api/service"
 	"github.com/op/go-logging"
 	"golang.org/x/net/context"
@@ -14,7 +13,7 @@ func (r *Resolver) Survey(ctx context.Context, args struct {
 	ID string
 }) (*surveyResolver, error) {
 	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
-		return nil, errors.New(gcontext.CredentialsError)
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
 	}
 	userID := ctx.Value("user_id").(*string)
 
@@ -30,22 +29,35 @@ func (r *Resolver) Survey(ctx context.Context, args struct {
 }
 
 func (r *Resolver) Surveys(ctx context.Context, args struct {
-	First     *int32
-	After     *string
-	StudentID *string
+	First              *int32
+	After              *string
+	StudentID          *string
+	Status             *string
+	ToothIndexMismatch *bool
 }) (*surveysConnectionResolver, error) {
 	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
-		return nil, errors.New(gcontext.CredentialsError)
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
 	}
 	userID := ctx.Value("user_id").(*string)
 
-	surveys, err := ctx.Value("surveyService").(*service.SurveyService).List(args.First, args.After, args.StudentID)
+	// reviewers see every draft, surveyors only their own
+	roles, err := ctx.Value("roleService").(*service.RoleService).FindByUserId(userID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+	var draftsOf *string
+	if !model.HasRole(roles, model.RoleSupervisor, model.RoleAdmin) {
+		draftsOf = userID
+	}
+
+	surveys, err := ctx.Value("surveyService").(*service.SurveyService).List(args.First, args.After, args.StudentID, args.Status, args.ToothIndexMismatch, draftsOf)
 	if err != nil {
 		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
 		return nil, err
 	}
 
-	count, err := ctx.Value("surveyService").(*service.SurveyService).Count(args.StudentID)
+	count, err := ctx.Value("surveyService").(*service.SurveyService).Count(args.StudentID, args.Status, args.ToothIndexMismatch, draftsOf)
 	if err != nil {
 		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
 		return nil, err
@@ -67,3 +79,33 @@ func (r *Resolver) Surveys(ctx context.Context, args struct {
 	}
 	return &surveysConnectionResolver{surveys: surveys, totalCount: count, from: nil, to: nil}, nil
 }
+
+func (r *Resolver) ReviewQueue(ctx context.Context, args struct {
+	First    *int32
+	After    *string
+	SchoolID *string
+}) (*surveysConnectionResolver, error) {
+	if err := authorizeRoles(ctx, "review surveys", model.RoleSupervisor, model.RoleAdmin); err != nil {
+		return nil, err
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	surveys, err := ctx.Value("surveyService").(*service.SurveyService).ListForReview(args.First, args.After, args.SchoolID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	count, err := ctx.Value("surveyService").(*service.SurveyService).CountForReview(args.SchoolID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Retrieved review queue by user_id[%s] : %v surveys", *userID, count)
+
+	if len(surveys) > 0 {
+		return &surveysConnectionResolver{surveys: surveys, totalCount: count, from: &(surveys[0].ID), to: &(surveys[len(surveys)-1].ID)}, nil
+	}
+	return &surveysConnectionResolver{surveys: surveys, totalCount: count, from: nil, to: nil}, nil
+}
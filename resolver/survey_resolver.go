@@ -5,6 +5,8 @@ import (
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	"golang.org/x/net/context"
 )
 
 type surveyResolver struct {
@@ -32,68 +34,104 @@ func (s *surveyResolver) Date() (*graphql.Time, error) {
 	return &graphql.Time{Time: t}, err
 }
 
+func (s *surveyResolver) QuestionnaireID() *string {
+	return s.s.QuestionnaireID
+}
+
+func (s *surveyResolver) Questionnaire(ctx context.Context) (*questionnaireResolver, error) {
+	if s.s.QuestionnaireID == nil {
+		return nil, nil
+	}
+
+	questionnaire, err := ctx.Value("questionnaireService").(*service.QuestionnaireService).FindByID(*s.s.QuestionnaireID)
+	if err != nil {
+		return nil, err
+	}
+	return &questionnaireResolver{questionnaire}, nil
+}
+
+func (s *surveyResolver) Answers() []*surveyAnswerResolver {
+	l := make([]*surveyAnswerResolver, len(s.s.Answers))
+	for i := range l {
+		l[i] = &surveyAnswerResolver{
+			a: s.s.Answers[i],
+		}
+	}
+	return l
+}
+
+func (s *surveyResolver) RiskAssessments() []*riskAssessmentResolver {
+	l := make([]*riskAssessmentResolver, len(s.s.RiskAssessments))
+	for i := range l {
+		l[i] = &riskAssessmentResolver{
+			r: s.s.RiskAssessments[i],
+		}
+	}
+	return l
+}
+
 func (s *surveyResolver) S1Q1() *string {
-	return &s.s.S1Q1
+	return legacyAnswer(s.s.S1Q1)
 }
 
 func (s *surveyResolver) S1Q2() *string {
-	return &s.s.S1Q2
+	return legacyAnswer(s.s.S1Q2)
 }
 
 func (s *surveyResolver) S1Q3() *string {
-	return &s.s.S1Q3
+	return legacyAnswer(s.s.S1Q3)
 }
 
 func (s *surveyResolver) S1Q4() *string {
-	return &s.s.S1Q4
+	return legacyAnswer(s.s.S1Q4)
 }
 
 func (s *surveyResolver) S1Q5() *string {
-	return &s.s.S1Q5
+	return legacyAnswer(s.s.S1Q5)
 }
 
 func (s *surveyResolver) S1Q6() *string {
-	return &s.s.S1Q6
+	return legacyAnswer(s.s.S1Q6)
 }
 
 func (s *surveyResolver) S1Q7() *string {
-	return &s.s.S1Q7
+	return legacyAnswer(s.s.S1Q7)
 }
 
 func (s *surveyResolver) S2Q1() *string {
-	return &s.s.S2Q1
+	return legacyAnswer(s.s.S2Q1)
 }
 
 func (s *surveyResolver) S2Q2() *string {
-	return &s.s.S2Q2
+	return legacyAnswer(s.s.S2Q2)
 }
 
 func (s *surveyResolver) S2Q3() *string {
-	return &s.s.S2Q3
+	return legacyAnswer(s.s.S2Q3)
 }
 
 func (s *surveyResolver) S2Q4() *string {
-	return &s.s.S2Q4
+	return legacyAnswer(s.s.S2Q4)
 }
 
 func (s *surveyResolver) S2Q5() *string {
-	return &s.s.S2Q5
+	return legacyAnswer(s.s.S2Q5)
 }
 
 func (s *surveyResolver) S2Q6() *string {
-	return &s.s.S2Q6
+	return legacyAnswer(s.s.S2Q6)
 }
 
 func (s *surveyResolver) S2Q7() *string {
-	return &s.s.S2Q7
+	return legacyAnswer(s.s.S2Q7)
 }
 
 func (s *surveyResolver) S2Q8() *string {
-	return &s.s.S2Q8
+	return legacyAnswer(s.s.S2Q8)
 }
 
 func (s *surveyResolver) S2Q9() *string {
-	return &s.s.S2Q9
+	return legacyAnswer(s.s.S2Q9)
 }
 
 func (s *surveyResolver) LowerD() *int32 {
@@ -120,10 +158,83 @@ func (s *surveyResolver) UpperF() *int32 {
 	return &s.s.UpperF
 }
 
+func (s *surveyResolver) ComputedIndices() *toothIndicesResolver {
+	return &toothIndicesResolver{s.s.ComputedIndices()}
+}
+
+func (s *surveyResolver) ToothIndexMismatch() bool {
+	return s.s.ToothIndexMismatch
+}
+
 func (s *surveyResolver) SubjectiveScore() *int32 {
 	return &s.s.SubjectiveScore
 }
 
+func (s *surveyResolver) Status() string {
+	return s.s.Status
+}
+
+func (s *surveyResolver) SubmittedAt() (*graphql.Time, error) {
+	if s.s.SubmittedAt == nil {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, *s.s.SubmittedAt)
+	return &graphql.Time{Time: t}, err
+}
+
+func (s *surveyResolver) ReviewedBy() *string {
+	return s.s.ReviewedBy
+}
+
+func (s *surveyResolver) ReviewedAt() (*graphql.Time, error) {
+	if s.s.ReviewedAt == nil {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, *s.s.ReviewedAt)
+	return &graphql.Time{Time: t}, err
+}
+
+func (s *surveyResolver) ReviewComment() *string {
+	return s.s.ReviewComment
+}
+
+// Draft returns the input last saved with a draft survey, as JSON. Only the
+// surveyor of the draft and the reviewers can read it.
+func (s *surveyResolver) Draft(ctx context.Context) (*string, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, nil
+	}
+	userID := ctx.Value("user_id").(*string)
+	roles, err := ctx.Value("roleService").(*service.RoleService).FindByUserId(userID)
+	if err != nil {
+		return nil, err
+	}
+	if s.s.CanPreview(*userID, roles) != nil {
+		return nil, nil
+	}
+
+	draft, err := ctx.Value("surveyService").(*service.SurveyService).FindDraft(s.s.ID)
+	if err != nil || draft == nil {
+		return nil, err
+	}
+	return &draft.Input, nil
+}
+
+func (s *surveyResolver) Reviews(ctx context.Context) ([]*surveyReviewResolver, error) {
+	reviews, err := ctx.Value("surveyService").(*service.SurveyService).FindReviews(s.s.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	l := make([]*surveyReviewResolver, len(reviews))
+	for i := range reviews {
+		l[i] = &surveyReviewResolver{reviews[i]}
+	}
+	return l, nil
+}
+
 func (s *surveyResolver) CreatedAt() (*graphql.Time, error) {
 	if s.s.CreatedAt == "" {
 		return nil, nil
@@ -133,6 +244,15 @@ func (s *surveyResolver) CreatedAt() (*graphql.Time, error) {
 	return &graphql.Time{Time: t}, err
 }
 
+func (s *surveyResolver) UpdatedAt() (*graphql.Time, error) {
+	if s.s.UpdatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s.s.UpdatedAt)
+	return &graphql.Time{Time: t}, err
+}
+
 func (s *surveyResolver) Cases() *[]*caseResolver {
 	l := make([]*caseResolver, len(s.s.Cases))
 	for i := range l {
@@ -142,3 +262,33 @@ func (s *surveyResolver) Cases() *[]*caseResolver {
 	}
 	return &l
 }
+
+func (s *surveyResolver) Odontogram(ctx context.Context) (*odontogramResolver, error) {
+	odontogram, err := ctx.Value("odontogramService").(*service.OdontogramService).FindBySurveyID(s.s.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &odontogramResolver{odontogram}, nil
+}
+
+func (s *surveyResolver) Photos(ctx context.Context) ([]*photoResolver, error) {
+	photos, err := ctx.Value("photoService").(*service.PhotoService).FindBySurveyID(s.s.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	l := make([]*photoResolver, len(photos))
+	for i := range photos {
+		l[i] = &photoResolver{photos[i]}
+	}
+	return l, nil
+}
+
+// legacyAnswer returns nil for questions of the legacy questionnaire that
+// surveys recorded against other questionnaires did not answer.
+func legacyAnswer(answer string) *string {
+	if answer == "" {
+		return nil
+	}
+	return &answer
+}
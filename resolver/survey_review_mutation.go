@@ -0,0 +1,88 @@
+package resolver
+
+import (
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+func (r *Resolver) SaveSurveyDraft(ctx context.Context, args *struct {
+	ID     *string
+	Survey *model.SurveyInput
+}) (*surveyResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+
+	userID := ctx.Value("user_id").(*string)
+
+	survey, err := ctx.Value("surveyService").(*service.SurveyService).SaveDraft(*userID, args.ID, *args.Survey)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Saved survey draft : %v", survey)
+
+	return &surveyResolver{survey}, nil
+}
+
+func (r *Resolver) SubmitSurvey(ctx context.Context, args *struct {
+	ID string
+}) (*surveyResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+
+	userID := ctx.Value("user_id").(*string)
+
+	survey, err := ctx.Value("surveyService").(*service.SurveyService).SubmitDraft(*userID, args.ID)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Submitted survey : %v", survey)
+
+	return &surveyResolver{survey}, nil
+}
+
+func (r *Resolver) ApproveSurvey(ctx context.Context, args *struct {
+	ID      string
+	Comment *string
+}) (*surveyResolver, error) {
+	return reviewSurvey(ctx, args.ID, model.SurveyStatusApproved, args.Comment)
+}
+
+func (r *Resolver) RejectSurvey(ctx context.Context, args *struct {
+	ID      string
+	Comment string
+}) (*surveyResolver, error) {
+	return reviewSurvey(ctx, args.ID, model.SurveyStatusRejected, &args.Comment)
+}
+
+// reviewSurvey records the review of a survey by the signed in supervisor
+func reviewSurvey(ctx context.Context, surveyID string, status string, comment *string) (*surveyResolver, error) {
+	if err := authorizeRoles(ctx, "review surveys", model.RoleSupervisor, model.RoleAdmin); err != nil {
+		return nil, err
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	review, err := model.NewSurveyReview(surveyID, status, *userID, comment)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	survey, err := ctx.Value("surveyService").(*service.SurveyService).Review(review)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Reviewed survey by user_id[%s] : %v", *userID, survey)
+
+	return &surveyResolver{survey}, nil
+}
@@ -0,0 +1,41 @@
+package resolver
+
+import (
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/model"
+)
+
+type surveyReviewResolver struct {
+	r *model.SurveyReview
+}
+
+func (r *surveyReviewResolver) ID() graphql.ID {
+	return graphql.ID(r.r.ID)
+}
+
+func (r *surveyReviewResolver) SurveyID() string {
+	return r.r.SurveyID
+}
+
+func (r *surveyReviewResolver) Status() string {
+	return r.r.Status
+}
+
+func (r *surveyReviewResolver) ReviewerID() string {
+	return r.r.ReviewerID
+}
+
+func (r *surveyReviewResolver) Comment() *string {
+	return r.r.Comment
+}
+
+func (r *surveyReviewResolver) CreatedAt() (*graphql.Time, error) {
+	if r.r.CreatedAt == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, r.r.CreatedAt)
+	return &graphql.Time{Time: t}, err
+}
@@ -0,0 +1,31 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+)
+
+type surveySubmissionResultResolver struct {
+	r *model.SurveySubmissionResult
+}
+
+func (r *surveySubmissionResultResolver) IdempotencyKey() string {
+	return r.r.IdempotencyKey
+}
+
+func (r *surveySubmissionResultResolver) Status() string {
+	return r.r.Status
+}
+
+func (r *surveySubmissionResultResolver) Survey() *surveyResolver {
+	if r.r.Survey == nil {
+		return nil
+	}
+	return &surveyResolver{r.r.Survey}
+}
+
+func (r *surveySubmissionResultResolver) Error() *errorResolver {
+	if r.r.Error == nil {
+		return nil
+	}
+	return &errorResolver{r.r.Error}
+}
@@ -0,0 +1,33 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+)
+
+type toothIndicesResolver struct {
+	t model.ToothIndices
+}
+
+func (t *toothIndicesResolver) LowerD() int32 {
+	return t.t.LowerD
+}
+
+func (t *toothIndicesResolver) LowerE() int32 {
+	return t.t.LowerE
+}
+
+func (t *toothIndicesResolver) LowerF() int32 {
+	return t.t.LowerF
+}
+
+func (t *toothIndicesResolver) UpperD() int32 {
+	return t.t.UpperD
+}
+
+func (t *toothIndicesResolver) UpperM() int32 {
+	return t.t.UpperM
+}
+
+func (t *toothIndicesResolver) UpperF() int32 {
+	return t.t.UpperF
+}
@@ -0,0 +1,43 @@
+package resolver
+
+import (
+	"github.com/kerti/idcra-api/model"
+)
+
+type toothResolver struct {
+	t *model.Tooth
+}
+
+func (t *toothResolver) ToothNumber() int32 {
+	return t.t.ToothNumber
+}
+
+func (t *toothResolver) Dentition() string {
+	return t.t.Dentition()
+}
+
+func (t *toothResolver) Status() string {
+	return t.t.Status
+}
+
+func (t *toothResolver) Surfaces() []*toothSurfaceFindingResolver {
+	l := make([]*toothSurfaceFindingResolver, len(t.t.Surfaces))
+	for i := range l {
+		l[i] = &toothSurfaceFindingResolver{
+			s: t.t.Surfaces[i],
+		}
+	}
+	return l
+}
+
+type toothSurfaceFindingResolver struct {
+	s *model.ToothSurface
+}
+
+func (s *toothSurfaceFindingResolver) Surface() string {
+	return s.s.Surface
+}
+
+func (s *toothSurfaceFindingResolver) Condition() string {
+	return s.s.Condition
+}
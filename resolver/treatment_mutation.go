@@ -0,0 +1,42 @@
+package resolver
+
+import (
+	gcontext "github.com/kerti/idcra-api/context"
+	"github.com/kerti/idcra-api/model"
+	"github.com/kerti/idcra-api/service"
+	logging "github.com/op/go-logging"
+	"golang.org/x/net/context"
+)
+
+// UpdateTreatment records a change of the treatment of a case. Performed
+// treatments are recorded as performed by the signed-in operator.
+func (r *Resolver) UpdateTreatment(ctx context.Context, args *struct {
+	CaseID        string
+	Status        string
+	PerformedDate *string
+	Notes         *string
+}) (*caseResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+
+	update := &model.TreatmentUpdate{
+		CaseID:        args.CaseID,
+		Status:        args.Status,
+		PerformedDate: args.PerformedDate,
+		Notes:         args.Notes,
+	}
+	if model.IsPerformed(args.Status) {
+		update.PerformedBy = ctx.Value("user_id").(*string)
+	}
+
+	caseObj, err := ctx.Value("caseService").(*service.CaseService).UpdateTreatment(update)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+
+	ctx.Value("log").(*logging.Logger).Debugf("Updated treatment of case : %v", caseObj)
+
+	return &caseResolver{caseObj}, nil
+}
@@ -0,0 +1,50 @@
+package resolver
+
+import (
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/kerti/idcra-api/model"
+)
+
+type treatmentResolver struct {
+	t *model.Treatment
+}
+
+func (t *treatmentResolver) ID() graphql.ID {
+	return graphql.ID(t.t.ID)
+}
+
+func (t *treatmentResolver) CaseID() string {
+	return t.t.CaseID
+}
+
+func (t *treatmentResolver) Status() string {
+	return t.t.Status
+}
+
+func (t *treatmentResolver) PerformedBy() *string {
+	return t.t.PerformedBy
+}
+
+func (t *treatmentResolver) PerformedDate() (*graphql.Time, error) {
+	if t.t.PerformedDate == nil {
+		return nil, nil
+	}
+
+	d, err := time.Parse(time.RFC3339, *t.t.PerformedDate)
+	return &graphql.Time{Time: d}, err
+}
+
+func (t *treatmentResolver) Notes() *string {
+	return t.t.Notes
+}
+
+func (t *treatmentResolver) CreatedAt() (*graphql.Time, error) {
+	if t.t.CreatedAt == "" {
+		return nil, nil
+	}
+
+	d, err := time.Parse(time.RFC3339, t.t.CreatedAt)
+	return &graphql.Time{Time: d}, err
+}
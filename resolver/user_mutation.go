@@ -1,6 +1,7 @@
 package resolver
 
 import (
+	gcontext "github.com/kerti/idcra-api/context"
 	"github.com/kerti/idcra-api/model"
 	"github.com/kerti/idcra-api/service"
 	"github.com/op/go-logging"
@@ -61,3 +62,22 @@ func (r *Resolver) RemoveStudentFromParent(ctx context.Context, args *struct {
 	ctx.Value("log").(*logging.Logger).Debugf("Created user : %v", *user)
 	return &userResolver{user}, nil
 }
+
+// SetPreferredLanguage sets the language reports are printed in for the
+// signed in user
+func (r *Resolver) SetPreferredLanguage(ctx context.Context, args *struct {
+	Language string
+}) (*userResolver, error) {
+	if isAuthorized := ctx.Value("is_authorized").(bool); !isAuthorized {
+		return nil, model.NewUnauthenticatedError(gcontext.CredentialsError)
+	}
+	userID := ctx.Value("user_id").(*string)
+
+	user, err := ctx.Value("userService").(*service.UserService).SetPreferredLanguage(*userID, args.Language)
+	if err != nil {
+		ctx.Value("log").(*logging.Logger).Errorf("Graphql error : %v", err)
+		return nil, err
+	}
+	ctx.Value("log").(*logging.Logger).Debugf("Set preferred language of user_id[%s] : %s", *userID, args.Language)
+	return &userResolver{user}, nil
+}
@@ -27,6 +27,10 @@ func (r *userResolver) IPAddress() *string {
 	return &r.u.IPAddress
 }
 
+func (r *userResolver) PreferredLanguage() *string {
+	return r.u.PreferredLanguage
+}
+
 func (r *userResolver) CreatedAt() (*graphql.Time, error) {
 	if r.u.CreatedAt == "" {
 		return nil, nil
@@ -3,11 +3,33 @@ package schema
 
 import (
 	"bytes"
+	"strings"
 )
 
+// subscriptionDir holds the schema definition of the subscription schema
+const subscriptionDir = "subscription/"
+
 func GetRootSchema() string {
+	return concatAssets(func(name string) bool {
+		return !strings.HasPrefix(name, subscriptionDir)
+	})
+}
+
+// GetSubscriptionSchema returns the schema with the Subscription type as its
+// query entry point. Subscription operations are executed against it once per
+// published event.
+func GetSubscriptionSchema() string {
+	return concatAssets(func(name string) bool {
+		return name != "schema.graphql"
+	})
+}
+
+func concatAssets(include func(name string) bool) string {
 	buf := bytes.Buffer{}
 	for _, name := range AssetNames() {
+		if !include(name) {
+			continue
+		}
 		b := MustAsset(name)
 		buf.Write(b)
 
@@ -18,6 +18,10 @@ import (
 )
 
 func main() {
+	port := flag.String("port", "3001", "a port")
+	recomputeRisk := flag.Bool("recompute-risk", false, "recompute the risk assessments of all surveys and exit")
+	flag.Parse()
+
 	config := gcontext.LoadConfig(".")
 
 	db, err := gcontext.OpenDB(config)
@@ -26,18 +30,54 @@ func main() {
 	}
 	ctx := context.Background()
 	log := service.NewLogger(config)
+	eventBus := service.NewEventBus(log)
 	roleService := service.NewRoleService(db, log)
 	authService := service.NewAuthService(config, log)
 	studentService := service.NewStudentService(db, log)
-	schoolService := service.NewSchoolService(db, log)
+	schoolService := service.NewSchoolService(db, roleService, log)
 	diagnosisAndActionService := service.NewDiagnosisAndActionService(db, log)
 	caseService := service.NewCaseService(db, log)
-	surveyService := service.NewSurveyService(db, caseService, log)
-	reportService := service.NewReportService(db, log)
+	questionnaireService := service.NewQuestionnaireService(db, log)
+	riskService, err := service.NewRiskService(db, config.RiskModels, log)
+	if err != nil {
+		log.Fatalf("Unable to set up risk models: %s \n", err)
+	}
+	surveyService := service.NewSurveyService(db, caseService, diagnosisAndActionService, questionnaireService, riskService, eventBus, log)
+	odontogramService := service.NewOdontogramService(db, log)
+	storage, err := service.NewStorage(config)
+	if err != nil {
+		log.Fatalf("Unable to set up photo storage: %s \n", err)
+	}
+	photoService := service.NewPhotoService(db, storage, config.MaxPhotoSize, log)
+	historyService := service.NewHistoryService(surveyService, diagnosisAndActionService, odontogramService, riskService, log)
+	recommendationService := service.NewRecommendationService(db, log)
+	reportTemplateService := service.NewReportTemplateService(db, storage, log)
+	reportVerificationService := service.NewReportVerificationService(db, config.ReportVerifyURL, log)
+	reportService := service.NewReportService(db, odontogramService, photoService, historyService, recommendationService, reportTemplateService, reportVerificationService, log)
+	exportService := service.NewExportService(db, roleService, log)
+	reportJobService, err := service.NewReportJobService(reportService, exportService, eventBus, config.ReportPath, config.ReportWorkers, config.ReportQueueSize, config.ReportExpireIn, log)
+	if err != nil {
+		log.Fatalf("Unable to set up report jobs: %s \n", err)
+	}
+	changeService := service.NewChangeService(db, log)
+	recallService, err := service.NewRecallService(db, config.SchoolTimeZone, log)
+	if err != nil {
+		log.Fatalf("Unable to set up recalls: %s \n", err)
+	}
 	userService := service.NewUserService(db, roleService, studentService, log)
 
+	if *recomputeRisk {
+		count, err := surveyService.RecomputeRiskAssessments()
+		if err != nil {
+			log.Fatalf("Unable to recompute risk assessments: %s \n", err)
+		}
+		log.Infof("Recomputed risk assessments of %d surveys", count)
+		return
+	}
+
 	ctx = context.WithValue(ctx, "config", config)
 	ctx = context.WithValue(ctx, "log", log)
+	ctx = context.WithValue(ctx, "eventBus", eventBus)
 	ctx = context.WithValue(ctx, "roleService", roleService)
 	ctx = context.WithValue(ctx, "userService", userService)
 	ctx = context.WithValue(ctx, "authService", authService)
@@ -46,25 +86,44 @@ func main() {
 	ctx = context.WithValue(ctx, "schoolService", schoolService)
 	ctx = context.WithValue(ctx, "diagnosisAndActionService", diagnosisAndActionService)
 	ctx = context.WithValue(ctx, "caseService", caseService)
+	ctx = context.WithValue(ctx, "questionnaireService", questionnaireService)
 	ctx = context.WithValue(ctx, "surveyService", surveyService)
+	ctx = context.WithValue(ctx, "odontogramService", odontogramService)
+	ctx = context.WithValue(ctx, "photoService", photoService)
+	ctx = context.WithValue(ctx, "historyService", historyService)
 	ctx = context.WithValue(ctx, "reportService", reportService)
+	ctx = context.WithValue(ctx, "recommendationService", recommendationService)
+	ctx = context.WithValue(ctx, "reportTemplateService", reportTemplateService)
+	ctx = context.WithValue(ctx, "reportVerificationService", reportVerificationService)
+	ctx = context.WithValue(ctx, "exportService", exportService)
+	ctx = context.WithValue(ctx, "reportJobService", reportJobService)
+	ctx = context.WithValue(ctx, "changeService", changeService)
+	ctx = context.WithValue(ctx, "recallService", recallService)
 
 	graphqlSchema := graphql.MustParseSchema(schema.GetRootSchema(), &resolver.Resolver{})
+	subscriptionSchema := graphql.MustParseSchema(schema.GetSubscriptionSchema(), &resolver.SubscriptionResolver{})
 
 	http.Handle("/login", h.AddContext(ctx, h.Login()))
 
 	loggerHandler := &h.LoggerHandler{DebugMode: config.DebugMode}
-	http.Handle("/query", h.AddContext(ctx, loggerHandler.Logging(h.Authenticate(&h.GraphQL{Schema: graphqlSchema, Loaders: loader.NewLoaderCollection()}))))
+	http.Handle("/query", h.AddContext(ctx, loggerHandler.Logging(h.Authenticate(&h.GraphQL{Schema: graphqlSchema, Loaders: loader.NewLoaderCollection(), MaxBatchSize: config.GraphQLMaxBatchSize}))))
+
+	http.Handle("/subscriptions", h.AddContext(ctx, loggerHandler.Logging(h.Authenticate(&h.Subscription{Schema: subscriptionSchema, Loaders: loader.NewLoaderCollection()}))))
 
 	http.Handle("/reports/surveys/", h.AddContext(ctx, loggerHandler.Logging(h.Authenticate(h.SurveyReport()))))
+	http.Handle("/photos", h.AddContext(ctx, loggerHandler.Logging(h.Authenticate(h.UploadPhoto()))))
+	http.Handle("/photos/", h.AddContext(ctx, loggerHandler.Logging(h.Authenticate(h.Photo()))))
 	http.Handle("/reports/school/", h.AddContext(ctx, loggerHandler.Logging(h.Authenticate(h.SchoolReport()))))
+	http.Handle("/reports/summary/school/", h.AddContext(ctx, loggerHandler.Logging(h.Authenticate(h.SchoolSummaryReport()))))
+	http.Handle("/reports/stream/school/", h.AddContext(ctx, loggerHandler.Logging(h.Authenticate(h.StreamSchoolReport()))))
+	http.Handle("/reports/jobs/", h.AddContext(ctx, loggerHandler.Logging(h.Authenticate(h.ReportJobDownload()))))
+	http.Handle("/reports/templates/", h.AddContext(ctx, loggerHandler.Logging(h.Authenticate(h.ReportTemplatePreview()))))
+	http.Handle("/verify/", h.AddContext(ctx, loggerHandler.Logging(h.VerifyReport())))
+	http.Handle("/exports/surveys", h.AddContext(ctx, loggerHandler.Logging(h.Authenticate(h.ExportSurveys()))))
 
 	http.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		http.ServeFile(w, r, "graphiql.html")
 	}))
 
-	port := flag.String("port", "3001", "a port")
-	flag.Parse()
-
 	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%v", *port), nil))
 }
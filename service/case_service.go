@@ -25,7 +25,7 @@ func (c *CaseService) FindByID(id string) (*model.Case, error) {
 	row := udb.QueryRowx(caseSQL, id)
 	err := row.StructScan(caseObj)
 	if err == sql.ErrNoRows {
-		return caseObj, nil
+		return nil, model.NewNotFoundError("case", id)
 	}
 	if err != nil {
 		c.log.Errorf("Error in retrieving case : %v", err)
@@ -46,3 +46,62 @@ func (c *CaseService) FindBySurveyID(surveyID *string) ([]*model.Case, error) {
 
 	return cases, nil
 }
+
+// FindTreatments returns the changes of the treatment status of a case, oldest
+// first.
+func (c *CaseService) FindTreatments(caseID string) ([]*model.Treatment, error) {
+	treatments := make([]*model.Treatment, 0)
+	treatmentSQL := `SELECT * FROM case_treatments WHERE case_id = ? ORDER BY created_at ASC, id ASC`
+
+	err := c.db.Select(&treatments, treatmentSQL, caseID)
+	if err != nil {
+		c.log.Errorf("Error in retrieving treatments : %v", err)
+		return nil, err
+	}
+
+	return treatments, nil
+}
+
+// UpdateTreatment moves the treatment of a case to a new status and records
+// the change.
+func (c *CaseService) UpdateTreatment(update *model.TreatmentUpdate) (*model.Case, error) {
+	caseSQL := `
+		UPDATE cases
+		SET treatment_status = :treatment_status, performed_by = :performed_by,
+		performed_date = :performed_date, treatment_notes = :treatment_notes
+		WHERE id = :id`
+
+	treatmentSQL := `
+		INSERT INTO case_treatments
+		(id, case_id, status, performed_by, performed_date, notes, created_at)
+		VALUES
+		(:id, :case_id, :status, :performed_by, :performed_date, :notes, NOW())`
+
+	err := Transact(c.db, func(tx *sqlx.Tx) error {
+		caseObj := &model.Case{}
+		err := tx.Unsafe().QueryRowx(`SELECT * FROM cases WHERE id = ? FOR UPDATE`, update.CaseID).StructScan(caseObj)
+		if err == sql.ErrNoRows {
+			return model.NewNotFoundError("case", update.CaseID)
+		}
+		if err != nil {
+			return err
+		}
+
+		treatment, err := caseObj.ApplyTreatment(update)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.NamedExec(caseSQL, caseObj); err != nil {
+			return err
+		}
+		_, err = tx.NamedExec(treatmentSQL, treatment)
+		return err
+	})
+	if err != nil {
+		c.log.Errorf("Error in updating treatment : %v", err)
+		return nil, translateDBError(err)
+	}
+
+	return c.FindByID(update.CaseID)
+}
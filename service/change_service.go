@@ -0,0 +1,112 @@
+package service
+
+import (
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kerti/idcra-api/model"
+	"github.com/op/go-logging"
+)
+
+type ChangeService struct {
+	db  *sqlx.DB
+	log *logging.Logger
+}
+
+func NewChangeService(db *sqlx.DB, log *logging.Logger) *ChangeService {
+	return &ChangeService{db: db, log: log}
+}
+
+// List returns the changes recorded after the since cursor in commit order.
+// Changes can be narrowed down to the given entity types and to the given
+// schools, which must be schools the user works with; without schools the
+// changes of every school the user works with are returned. Changes that do not
+// belong to a school, like the diagnosis and action catalog, are always
+// included. The returned bool reports whether more changes are waiting after
+// the last one returned.
+func (s *ChangeService) List(first *int32, since *string, entityTypes []string, schoolIDs []string, access model.SchoolAccess) ([]*model.Change, bool, error) {
+	var fetchSize int32
+	if first == nil {
+		fetchSize = defaultListFetchSize
+	} else {
+		fetchSize = *first
+	}
+
+	seq, err := DecodeChangeCursor(since)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := model.ValidateChangeEntityTypes(entityTypes); err != nil {
+		return nil, false, err
+	}
+
+	if err := access.Check(schoolIDs); err != nil {
+		return nil, false, err
+	}
+	if len(schoolIDs) == 0 && !access.All {
+		schoolIDs = access.SchoolIDs
+	}
+
+	// sequence numbers are taken in commit order, see change_log_sequence
+	changeSQL := `SELECT * FROM change_log WHERE seq > ?`
+	args := []interface{}{seq}
+
+	if len(entityTypes) > 0 {
+		changeSQL += ` AND entity_type IN (?)`
+		args = append(args, entityTypes)
+	}
+	if len(schoolIDs) > 0 {
+		changeSQL += ` AND (school_id IS NULL OR school_id IN (?))`
+		args = append(args, schoolIDs)
+	} else if !access.All {
+		changeSQL += ` AND school_id IS NULL`
+	}
+
+	// fetch one more change than requested to tell whether there are more
+	changeSQL += ` ORDER BY seq ASC LIMIT ?;`
+	args = append(args, fetchSize+1)
+
+	changeSQL, args, err = sqlx.In(changeSQL, args...)
+	if err != nil {
+		return nil, false, err
+	}
+
+	changes := make([]*model.Change, 0)
+	if err := s.db.Select(&changes, s.db.Rebind(changeSQL), args...); err != nil {
+		s.log.Errorf("Error in retrieving changes : %v", err)
+		return nil, false, err
+	}
+
+	if len(changes) > int(fetchSize) {
+		return changes[:fetchSize], true, nil
+	}
+	return changes, false, nil
+}
+
+// EncodeChangeCursor returns the cursor to resume the change feed after the
+// change with the given sequence number.
+func EncodeChangeCursor(seq uint64) string {
+	s := strconv.FormatUint(seq, 10)
+	return string(EncodeCursor(&s))
+}
+
+// DecodeChangeCursor returns the sequence number of a change feed cursor. An
+// absent cursor starts the feed from the beginning.
+func DecodeChangeCursor(cursor *string) (uint64, error) {
+	if cursor == nil {
+		return 0, nil
+	}
+
+	decoded, err := DecodeCursor(cursor)
+	if err != nil {
+		return 0, model.NewFieldError("since", "invalid cursor")
+	}
+
+	seq, err := strconv.ParseUint(*decoded, 10, 64)
+	if err != nil {
+		return 0, model.NewFieldError("since", "invalid cursor")
+	}
+
+	return seq, nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/kerti/idcra-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeCursor(t *testing.T) {
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		cursor := EncodeChangeCursor(42)
+		seq, err := DecodeChangeCursor(&cursor)
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(42), seq)
+	})
+
+	t.Run("NilCursor", func(t *testing.T) {
+		seq, err := DecodeChangeCursor(nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), seq)
+	})
+
+	t.Run("MalformedCursor", func(t *testing.T) {
+		cursor := "fake_cursor"
+		_, err := DecodeChangeCursor(&cursor)
+
+		appErr, ok := err.(*model.Error)
+		assert.True(t, ok)
+		assert.Equal(t, model.ErrorCodeValidationFailed, appErr.Code)
+		assert.Equal(t, "since", appErr.Fields[0].Field)
+	})
+}
+
+func TestChangeList(t *testing.T) {
+
+	t.Run("UnassignedSchool", func(t *testing.T) {
+		service := NewChangeService(nil, nil)
+		access := model.SchoolAccess{SchoolIDs: []string{"school1"}}
+		_, _, err := service.List(nil, nil, nil, []string{"school2"}, access)
+
+		appErr, ok := err.(*model.Error)
+		assert.True(t, ok)
+		assert.Equal(t, model.ErrorCodeForbidden, appErr.Code)
+	})
+}
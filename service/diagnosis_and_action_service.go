@@ -25,7 +25,7 @@ func (d *DiagnosisAndActionService) FindByID(id string) (*model.DiagnosisAndActi
 	row := udb.QueryRowx(dnaSQL, id)
 	err := row.StructScan(diagnosisAndAction)
 	if err == sql.ErrNoRows {
-		return diagnosisAndAction, nil
+		return nil, model.NewNotFoundError("diagnosis and action", id)
 	}
 	if err != nil {
 		d.log.Errorf("Error in retrieving diagnosis and action : %v", err)
@@ -35,6 +35,36 @@ func (d *DiagnosisAndActionService) FindByID(id string) (*model.DiagnosisAndActi
 	return diagnosisAndAction, nil
 }
 
+// FindIndexComponents returns the def-t and DMF-T index components of the
+// given diagnoses keyed by diagnosis and action ID. Diagnoses that do not
+// count towards the indices are left out.
+func (d *DiagnosisAndActionService) FindIndexComponents(ids []string) (map[string]string, error) {
+	components := make(map[string]string)
+	if len(ids) == 0 {
+		return components, nil
+	}
+
+	dnaSQL, args, err := sqlx.In(`SELECT id, index_component FROM diagnosis_and_actions WHERE id IN (?) AND index_component IS NOT NULL`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []struct {
+		ID             string
+		IndexComponent string `db:"index_component"`
+	}{}
+	if err := d.db.Select(&rows, d.db.Rebind(dnaSQL), args...); err != nil {
+		d.log.Errorf("Error in retrieving index components : %v", err)
+		return nil, err
+	}
+
+	for _, row := range rows {
+		components[row.ID] = row.IndexComponent
+	}
+
+	return components, nil
+}
+
 func (d *DiagnosisAndActionService) List(first *int32, after *string) ([]*model.DiagnosisAndAction, error) {
 	diagnosisAndActions := make([]*model.DiagnosisAndAction, 0)
 	var fetchSize int32
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/kerti/idcra-api/model"
+)
+
+// MySQL server error numbers mapped to catalog errors.
+const (
+	mysqlDuplicateEntry  = 1062
+	mysqlDataTruncated   = 1265
+	mysqlIncorrectValue  = 1366
+	mysqlNoReferencedRow = 1452
+)
+
+// duplicateKeyMessages holds the client facing message for each unique index.
+var duplicateKeyMessages = map[string]string{
+	"users_idx_1":                    "a user with this email already exists",
+	"schools_idx_1":                  "a school with this name already exists",
+	"students_idx_1":                 "a student with this name and date of birth already exists in this school",
+	"diagnosis_and_actions_idx_1":    "this diagnosis and action already exists",
+	"survey_idx_1":                   "a survey for this student on this date already exists",
+	"rel_users_students_pk":          "this student is already assigned to a parent",
+	"recommendation_rule_sets_idx_1": "recommendation rules were published at the same time, try again",
+	"report_templates_idx_1":         "a report template with this name already exists",
+	"organizations_idx_1":            "an organization with this name already exists",
+}
+
+var (
+	foreignKeyColumn = regexp.MustCompile("FOREIGN KEY \\(`(\\w+)`\\)")
+	truncatedColumn  = regexp.MustCompile("column '(\\w+)'")
+)
+
+// translateDBError maps constraint violations reported by MySQL to catalog
+// errors. Other errors are returned unchanged and end up as internal errors.
+func translateDBError(err error) error {
+	mysqlErr, ok := err.(*mysql.MySQLError)
+	if !ok {
+		return err
+	}
+
+	switch mysqlErr.Number {
+	case mysqlDuplicateEntry:
+		for index, message := range duplicateKeyMessages {
+			// MySQL 8 prefixes the index name with the table name
+			if strings.HasSuffix(mysqlErr.Message, "'"+index+"'") || strings.HasSuffix(mysqlErr.Message, "."+index+"'") {
+				return &model.Error{Code: model.ErrorCodeConflict, Message: message, Cause: err}
+			}
+		}
+		return &model.Error{Code: model.ErrorCodeConflict, Message: "record already exists", Cause: err}
+
+	case mysqlNoReferencedRow:
+		if match := foreignKeyColumn.FindStringSubmatch(mysqlErr.Message); match != nil {
+			field := fieldName(match[1])
+			validationErr := model.NewFieldError(field, fmt.Sprintf("%s does not exist", field))
+			validationErr.Cause = err
+			return validationErr
+		}
+
+	case mysqlDataTruncated, mysqlIncorrectValue:
+		if match := truncatedColumn.FindStringSubmatch(mysqlErr.Message); match != nil {
+			field := fieldName(match[1])
+			validationErr := model.NewFieldError(field, fmt.Sprintf("invalid value for %s", field))
+			validationErr.Cause = err
+			return validationErr
+		}
+	}
+
+	return err
+}
+
+// fieldName converts a snake_case column name to the camelCase name of the
+// matching GraphQL field.
+func fieldName(column string) string {
+	parts := strings.Split(column, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/kerti/idcra-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTranslateDBError(t *testing.T) {
+
+	t.Run("DuplicateSurvey", func(t *testing.T) {
+		err := translateDBError(&mysql.MySQLError{
+			Number:  mysqlDuplicateEntry,
+			Message: "Duplicate entry 'fake_student_id-2018-01-01' for key 'survey_idx_1'",
+		})
+
+		appErr, ok := err.(*model.Error)
+		assert.True(t, ok)
+		assert.Equal(t, model.ErrorCodeConflict, appErr.Code)
+		assert.Equal(t, "a survey for this student on this date already exists", appErr.Message)
+	})
+
+	t.Run("DuplicateWithTablePrefix", func(t *testing.T) {
+		err := translateDBError(&mysql.MySQLError{
+			Number:  mysqlDuplicateEntry,
+			Message: "Duplicate entry 'someone@example.com' for key 'users.users_idx_1'",
+		})
+
+		assert.Equal(t, "a user with this email already exists", err.Error())
+	})
+
+	t.Run("MissingReference", func(t *testing.T) {
+		err := translateDBError(&mysql.MySQLError{
+			Number:  mysqlNoReferencedRow,
+			Message: "Cannot add or update a child row: a foreign key constraint fails (`idcra`.`surveys`, CONSTRAINT `fk_surveys_students` FOREIGN KEY (`student_id`) REFERENCES `students` (`id`))",
+		})
+
+		appErr, ok := err.(*model.Error)
+		assert.True(t, ok)
+		assert.Equal(t, model.ErrorCodeValidationFailed, appErr.Code)
+		assert.Equal(t, []model.FieldError{{Field: "studentId", Message: "studentId does not exist"}}, appErr.Fields)
+	})
+
+	t.Run("InvalidEnumValue", func(t *testing.T) {
+		err := translateDBError(&mysql.MySQLError{
+			Number:  mysqlDataTruncated,
+			Message: "Data truncated for column 's1q1' at row 1",
+		})
+
+		appErr, ok := err.(*model.Error)
+		assert.True(t, ok)
+		assert.Equal(t, "s1q1", appErr.Fields[0].Field)
+	})
+
+	t.Run("OtherErrorsUnchanged", func(t *testing.T) {
+		original := errors.New("connection refused")
+
+		assert.Equal(t, original, translateDBError(original))
+	})
+
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/op/go-logging"
+)
+
+const (
+	SurveyCreatedTopic = "surveyCreated"
+	ReportReadyTopic   = "reportReady"
+
+	eventBufferSize = 16
+)
+
+// Event is a message published on the event bus.
+type Event struct {
+	Topic   string
+	Payload interface{}
+}
+
+// EventBus is an in-process publish/subscribe hub used to feed GraphQL
+// subscriptions. Publishing never blocks: events are dropped for subscribers
+// whose buffer is full.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers map[int]chan Event
+	nextID      int
+	log         *logging.Logger
+}
+
+func NewEventBus(log *logging.Logger) *EventBus {
+	return &EventBus{subscribers: make(map[int]chan Event), log: log}
+}
+
+// Subscribe registers a new subscriber for all topics. The returned function
+// unregisters the subscriber and closes its channel.
+func (b *EventBus) Subscribe() (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	ch := make(chan Event, eventBufferSize)
+	b.subscribers[id] = ch
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subscribers, id)
+			close(ch)
+		})
+	}
+
+	return ch, unsubscribe
+}
+
+func (b *EventBus) Publish(topic string, payload interface{}) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	event := Event{Topic: topic, Payload: payload}
+	for id, ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			b.log.Warningf("Dropped %s event for slow subscriber %d", topic, id)
+		}
+	}
+}
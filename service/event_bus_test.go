@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBus(t *testing.T) {
+
+	t.Run("PublishToAllSubscribers", func(t *testing.T) {
+		bus := NewEventBus(authService.log)
+		first, unsubscribeFirst := bus.Subscribe()
+		second, unsubscribeSecond := bus.Subscribe()
+		defer unsubscribeFirst()
+		defer unsubscribeSecond()
+
+		bus.Publish(SurveyCreatedTopic, "payload")
+
+		event := <-first
+		assert.Equal(t, SurveyCreatedTopic, event.Topic)
+		assert.Equal(t, "payload", event.Payload)
+
+		event = <-second
+		assert.Equal(t, SurveyCreatedTopic, event.Topic)
+	})
+
+	t.Run("UnsubscribeClosesChannel", func(t *testing.T) {
+		bus := NewEventBus(authService.log)
+		events, unsubscribe := bus.Subscribe()
+
+		unsubscribe()
+		unsubscribe()
+		bus.Publish(ReportReadyTopic, "payload")
+
+		_, open := <-events
+		assert.False(t, open)
+	})
+
+	t.Run("DropEventsForSlowSubscribers", func(t *testing.T) {
+		bus := NewEventBus(authService.log)
+		events, unsubscribe := bus.Subscribe()
+		defer unsubscribe()
+
+		for i := 0; i < eventBufferSize+1; i++ {
+			bus.Publish(ReportReadyTopic, i)
+		}
+
+		assert.Equal(t, eventBufferSize, len(events))
+	})
+
+}
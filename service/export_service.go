@@ -0,0 +1,378 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kerti/idcra-api/model"
+	"github.com/op/go-logging"
+)
+
+// surveyExportBatchSize is how many surveys are read from the database at a
+// time while exporting
+const surveyExportBatchSize = 500
+
+// ExportService exports surveys into CSV and XLSX files. Surveys are read and
+// written in batches, so exports of any size are streamed without being held
+// in memory.
+type ExportService struct {
+	db          *sqlx.DB
+	roleService *RoleService
+	now         func() time.Time
+	log         *logging.Logger
+}
+
+func NewExportService(db *sqlx.DB, roleService *RoleService, log *logging.Logger) *ExportService {
+	return &ExportService{db: db, roleService: roleService, now: time.Now, log: log}
+}
+
+// DataScope returns the part of the surveys a user may export
+func (s *ExportService) DataScope(userID *string) (model.DataScope, error) {
+	roles, err := s.roleService.FindByUserId(userID)
+	if err != nil {
+		s.log.Errorf("Error in retrieving roles : %v", err)
+		return model.DataScope{}, err
+	}
+	return model.NewDataScope(*userID, roles), nil
+}
+
+// WriteSurveys exports the surveys matching the filter within the scope to w,
+// calling progress after every batch. Drafts and rejected surveys are left
+// out. CSV exports list the cases of a survey in a single column, while XLSX
+// exports list them in a second sheet. When w can be flushed, it is flushed
+// after every batch.
+func (s *ExportService) WriteSurveys(filter model.SurveyExportFilter, scope model.DataScope, w io.Writer, progress func(completed, total int)) error {
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
+	where, args := surveyExportConditions(filter, scope)
+
+	var total int
+	countSQL := `SELECT COUNT(*) FROM surveys s JOIN students st ON s.student_id = st.id WHERE ` + where
+	if err := s.db.Get(&total, countSQL, args...); err != nil {
+		s.log.Errorf("Error in counting surveys : %v", err)
+		return err
+	}
+
+	questionCodes := make([]string, 0)
+	questionSQL := `
+		SELECT DISTINCT q.code
+		FROM survey_answers a
+		JOIN questionnaire_questions q ON q.id = a.question_id
+		JOIN surveys s ON s.id = a.survey_id
+		JOIN students st ON s.student_id = st.id
+		WHERE ` + where + `
+		ORDER BY q.code ASC`
+	if err := s.db.Select(&questionCodes, questionSQL, args...); err != nil {
+		s.log.Errorf("Error in retrieving question codes : %v", err)
+		return err
+	}
+
+	flusher, canFlush := w.(http.Flusher)
+	completed := 0
+
+	if filter.Format == model.ExportFormatCSV {
+		out := csv.NewWriter(w)
+		if err := out.Write(model.SurveyExportColumns(questionCodes, true)); err != nil {
+			return err
+		}
+		err := s.eachSurveyBatch(where, args, true, func(batch []*model.SurveyExportRow) error {
+			for _, row := range batch {
+				if err := out.Write(csvRecord(row.Cells(questionCodes, true))); err != nil {
+					return err
+				}
+			}
+			out.Flush()
+			if err := out.Error(); err != nil {
+				return err
+			}
+			if canFlush {
+				flusher.Flush()
+			}
+			completed += len(batch)
+			progress(completed, total)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		out.Flush()
+		return out.Error()
+	}
+
+	out := newXLSXWriter(w)
+	if err := out.AddSheet("surveys"); err != nil {
+		return err
+	}
+	if err := out.WriteRow(cellsOf(model.SurveyExportColumns(questionCodes, false))); err != nil {
+		return err
+	}
+	err := s.eachSurveyBatch(where, args, false, func(batch []*model.SurveyExportRow) error {
+		for _, row := range batch {
+			if err := out.WriteRow(row.Cells(questionCodes, false)); err != nil {
+				return err
+			}
+		}
+		if canFlush {
+			if err := out.Flush(); err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+		completed += len(batch)
+		progress(completed, total)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := out.AddSheet("cases"); err != nil {
+		return err
+	}
+	if err := out.WriteRow(cellsOf(model.SurveyExportCaseColumns)); err != nil {
+		return err
+	}
+	caseSQL := `
+		SELECT c.survey_id, c.tooth_number, d.diagnosis, d.action, c.treatment_status, d.unit_cost
+		FROM cases c
+		JOIN diagnosis_and_actions d ON c.diagnosis_and_action_id = d.id
+		JOIN surveys s ON s.id = c.survey_id
+		JOIN students st ON s.student_id = st.id
+		WHERE ` + where + `
+		ORDER BY s.date ASC, s.id ASC, c.tooth_number ASC`
+	rows, err := s.db.Queryx(caseSQL, args...)
+	if err != nil {
+		s.log.Errorf("Error in retrieving survey cases : %v", err)
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		c := &model.SurveyExportCase{}
+		if err := rows.StructScan(c); err != nil {
+			return err
+		}
+		if err := out.WriteRow(c.Cells()); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return out.Close()
+}
+
+// ExportToFile exports surveys into a file in dir, returning the path of the
+// file and the name to download it as
+func (s *ExportService) ExportToFile(filter model.SurveyExportFilter, scope model.DataScope, dir string, progress func(completed, total int)) (string, string, error) {
+	if err := filter.Validate(); err != nil {
+		return "", "", err
+	}
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", "", err
+	}
+
+	filePath := filepath.Join(dir, "export."+strings.ToLower(filter.Format))
+	f, err := os.Create(filePath)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	if err := s.WriteSurveys(filter, scope, f, progress); err != nil {
+		return "", "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", "", err
+	}
+	return filePath, filter.FileName(s.now()), nil
+}
+
+// eachSurveyBatch reads the surveys matching the conditions in batches,
+// ordered by date, with their answers and optionally their cases
+func (s *ExportService) eachSurveyBatch(where string, args []interface{}, withCases bool, handle func(batch []*model.SurveyExportRow) error) error {
+	surveySQL := `
+		SELECT
+			s.id,
+			st.school_id,
+			sc.name school_name,
+			s.student_id,
+			st.sex,
+			st.date_of_birth,
+			s.surveyor_id,
+			s.date,
+			s.status,
+			s.questionnaire_id,
+			s.subjective_score,
+			s.lower_d,
+			s.lower_e,
+			s.lower_f,
+			s.upper_d,
+			s.upper_m,
+			s.upper_f
+		FROM
+			surveys s
+			JOIN students st ON s.student_id = st.id
+			JOIN schools sc ON st.school_id = sc.id
+		WHERE ` + where
+
+	var last *model.SurveyExportRow
+	for {
+		batchSQL := surveySQL
+		batchArgs := append([]interface{}{}, args...)
+		if last != nil {
+			lastDate := last.Date.Format("2006-01-02")
+			batchSQL += ` AND (s.date > ? OR (s.date = ? AND s.id > ?))`
+			batchArgs = append(batchArgs, lastDate, lastDate, last.ID)
+		}
+		batchSQL += ` ORDER BY s.date ASC, s.id ASC LIMIT ?`
+		batchArgs = append(batchArgs, surveyExportBatchSize)
+
+		batch := make([]*model.SurveyExportRow, 0, surveyExportBatchSize)
+		if err := s.db.Select(&batch, batchSQL, batchArgs...); err != nil {
+			s.log.Errorf("Error in retrieving surveys : %v", err)
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := s.loadExportDetails(batch, withCases); err != nil {
+			return err
+		}
+		if err := handle(batch); err != nil {
+			return err
+		}
+
+		if len(batch) < surveyExportBatchSize {
+			return nil
+		}
+		last = batch[len(batch)-1]
+	}
+}
+
+func (s *ExportService) loadExportDetails(batch []*model.SurveyExportRow, withCases bool) error {
+	ids := make([]string, len(batch))
+	byID := make(map[string]*model.SurveyExportRow, len(batch))
+	for i, row := range batch {
+		ids[i] = row.ID
+		row.Answers = make(map[string]string)
+		byID[row.ID] = row
+	}
+
+	answerSQL, args, err := sqlx.In(`
+		SELECT a.survey_id, q.code question_code, a.value
+		FROM survey_answers a
+		JOIN questionnaire_questions q ON q.id = a.question_id
+		WHERE a.survey_id IN (?)`, ids)
+	if err != nil {
+		return err
+	}
+	answers := make([]*model.SurveyAnswer, 0)
+	if err := s.db.Select(&answers, s.db.Rebind(answerSQL), args...); err != nil {
+		s.log.Errorf("Error in retrieving survey answers : %v", err)
+		return err
+	}
+	for _, answer := range answers {
+		byID[answer.SurveyID].Answers[answer.QuestionCode] = answer.Value
+	}
+
+	if !withCases {
+		return nil
+	}
+
+	caseSQL, args, err := sqlx.In(`
+		SELECT c.survey_id, c.tooth_number, d.diagnosis, d.action, c.treatment_status, d.unit_cost
+		FROM cases c
+		JOIN diagnosis_and_actions d ON c.diagnosis_and_action_id = d.id
+		WHERE c.survey_id IN (?)
+		ORDER BY c.survey_id ASC, c.tooth_number ASC`, ids)
+	if err != nil {
+		return err
+	}
+	cases := make([]*model.SurveyExportCase, 0)
+	if err := s.db.Select(&cases, s.db.Rebind(caseSQL), args...); err != nil {
+		s.log.Errorf("Error in retrieving survey cases : %v", err)
+		return err
+	}
+	for _, c := range cases {
+		byID[c.SurveyID].Cases = append(byID[c.SurveyID].Cases, c)
+	}
+	return nil
+}
+
+// surveyExportConditions returns the conditions on surveys s of students st
+// that match the filter within the scope, with their arguments
+func surveyExportConditions(filter model.SurveyExportFilter, scope model.DataScope) (string, []interface{}) {
+	conditions := []string{`s.status NOT IN (?, ?)`}
+	args := []interface{}{model.SurveyStatusDraft, model.SurveyStatusRejected}
+
+	if filter.SchoolID != nil {
+		conditions = append(conditions, `st.school_id = ?`)
+		args = append(args, *filter.SchoolID)
+	}
+	if filter.StartDate != nil {
+		conditions = append(conditions, `s.date >= ?`)
+		args = append(args, *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		conditions = append(conditions, `s.date < ?`)
+		args = append(args, *filter.EndDate)
+	}
+	if filter.SurveyorID != nil {
+		conditions = append(conditions, `s.surveyor_id = ?`)
+		args = append(args, *filter.SurveyorID)
+	}
+
+	switch {
+	case scope.All:
+	case scope.SurveyorID != "":
+		conditions = append(conditions, `s.surveyor_id = ?`)
+		args = append(args, scope.SurveyorID)
+	default:
+		conditions = append(conditions, `s.student_id IN (SELECT student_id FROM rel_users_students WHERE user_id = ?)`)
+		args = append(args, scope.ParentID)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// csvRecord formats cells as CSV fields. Text starting like a formula is
+// prefixed with a quote so spreadsheets opening the file show it as text.
+func csvRecord(cells []interface{}) []string {
+	record := make([]string, len(cells))
+	for i, cell := range cells {
+		switch value := cell.(type) {
+		case string:
+			if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+				value = "'" + value
+			}
+			record[i] = value
+		case int32:
+			record[i] = strconv.Itoa(int(value))
+		case float64:
+			record[i] = strconv.FormatFloat(value, 'f', -1, 64)
+		default:
+			record[i] = fmt.Sprint(value)
+		}
+	}
+	return record
+}
+
+func cellsOf(values []string) []interface{} {
+	cells := make([]interface{}, len(values))
+	for i, value := range values {
+		cells[i] = value
+	}
+	return cells
+}
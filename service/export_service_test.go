@@ -0,0 +1,79 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/kerti/idcra-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXLSXWriter(t *testing.T) {
+	var buf bytes.Buffer
+	out := newXLSXWriter(&buf)
+
+	assert.Error(t, out.WriteRow([]interface{}{"no sheet"}))
+	assert.Nil(t, out.AddSheet("surveys"))
+	assert.Nil(t, out.WriteRow([]interface{}{"id", "score"}))
+	assert.Nil(t, out.WriteRow([]interface{}{"a<b & c", 66.5, int32(3), ""}))
+	assert.Nil(t, out.AddSheet("cases"))
+	assert.Nil(t, out.WriteRow([]interface{}{"survey"}))
+	assert.Nil(t, out.Close())
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.Nil(t, err)
+	parts := map[string]string{}
+	for _, f := range archive.File {
+		r, err := f.Open()
+		assert.Nil(t, err)
+		content, _ := ioutil.ReadAll(r)
+		r.Close()
+		parts[f.Name] = string(content)
+	}
+
+	assert.Contains(t, parts, "[Content_Types].xml")
+	assert.Contains(t, parts, "_rels/.rels")
+	assert.Contains(t, parts, "xl/_rels/workbook.xml.rels")
+	assert.Contains(t, parts["xl/workbook.xml"], `<sheet name="surveys" sheetId="1" r:id="rId1"/><sheet name="cases" sheetId="2" r:id="rId2"/>`)
+
+	surveys := parts["xl/worksheets/sheet1.xml"]
+	assert.Contains(t, surveys, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">a&lt;b &amp; c</t></is></c>`)
+	assert.Contains(t, surveys, `<c r="B2"><v>66.5</v></c>`)
+	assert.Contains(t, surveys, `<c r="C2"><v>3</v></c>`)
+	assert.NotContains(t, surveys, `r="D2"`)
+	assert.True(t, strings.HasSuffix(surveys, `</sheetData></worksheet>`))
+	assert.Contains(t, parts["xl/worksheets/sheet2.xml"], `<row r="1"><c r="A1"`)
+}
+
+func TestXLSXColumn(t *testing.T) {
+	assert.Equal(t, "A", xlsxColumn(0))
+	assert.Equal(t, "Z", xlsxColumn(25))
+	assert.Equal(t, "AA", xlsxColumn(26))
+	assert.Equal(t, "AZ", xlsxColumn(51))
+	assert.Equal(t, "BA", xlsxColumn(52))
+}
+
+func TestSurveyExportConditions(t *testing.T) {
+	school, start := "school", "2026-01-01"
+	filter := model.SurveyExportFilter{Format: model.ExportFormatCSV, SchoolID: &school, StartDate: &start}
+
+	where, args := surveyExportConditions(filter, model.DataScope{All: true})
+	assert.Equal(t, `s.status NOT IN (?, ?) AND st.school_id = ? AND s.date >= ?`, where)
+	assert.Equal(t, []interface{}{model.SurveyStatusDraft, model.SurveyStatusRejected, school, start}, args)
+
+	where, args = surveyExportConditions(filter, model.DataScope{SurveyorID: "surveyor"})
+	assert.True(t, strings.HasSuffix(where, ` AND s.surveyor_id = ?`))
+	assert.Equal(t, "surveyor", args[len(args)-1])
+
+	where, args = surveyExportConditions(model.SurveyExportFilter{}, model.DataScope{ParentID: "parent"})
+	assert.Contains(t, where, `rel_users_students WHERE user_id = ?`)
+	assert.Equal(t, "parent", args[len(args)-1])
+}
+
+func TestCSVRecord(t *testing.T) {
+	assert.Equal(t, []string{"a", "7", "33.25", ""}, csvRecord([]interface{}{"a", int32(7), 33.25, ""}))
+	assert.Equal(t, []string{"'=1+1", "'+62", "'-", "'@SUM(A1)", "-2", "a=b"}, csvRecord([]interface{}{"=1+1", "+62", "-", "@SUM(A1)", int32(-2), "a=b"}))
+}
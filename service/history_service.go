@@ -0,0 +1,56 @@
+package service
+
+import (
+	"github.com/kerti/idcra-api/model"
+	"github.com/op/go-logging"
+)
+
+// HistoryService compares the surveys of a student over time.
+type HistoryService struct {
+	surveyService             *SurveyService
+	diagnosisAndActionService *DiagnosisAndActionService
+	odontogramService         *OdontogramService
+	riskService               *RiskService
+	log                       *logging.Logger
+}
+
+func NewHistoryService(surveyService *SurveyService, diagnosisAndActionService *DiagnosisAndActionService, odontogramService *OdontogramService, riskService *RiskService, log *logging.Logger) *HistoryService {
+	return &HistoryService{surveyService: surveyService, diagnosisAndActionService: diagnosisAndActionService, odontogramService: odontogramService, riskService: riskService, log: log}
+}
+
+// FindByStudentID returns the oral health history of a student, with the
+// teeth with caries taken from both the cases and the dental chart of every
+// survey, and the risk categories of the primary risk model.
+func (h *HistoryService) FindByStudentID(studentID string) (*model.StudentHistory, error) {
+	surveys, err := h.surveyService.FindByStudentID(studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	surveyIDs := make([]string, len(surveys))
+	diagnosisIDs := make([]string, 0)
+	for i, survey := range surveys {
+		surveyIDs[i] = survey.ID
+		for _, c := range survey.Cases {
+			diagnosisIDs = append(diagnosisIDs, c.DiagnosisAndActionID)
+		}
+	}
+	components, err := h.diagnosisAndActionService.FindIndexComponents(diagnosisIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	odontograms, err := h.odontogramService.FindBySurveyIDs(surveyIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	riskModel := h.riskService.PrimaryModel()
+	visits := make([]*model.StudentVisit, len(surveys))
+	for i, survey := range surveys {
+		lesions := model.LesionTeeth(survey.Cases, components, odontograms[survey.ID])
+		visits[i] = model.NewStudentVisit(survey, lesions, riskModel)
+	}
+
+	return model.NewStudentHistory(studentID, visits), nil
+}
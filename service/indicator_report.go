@@ -0,0 +1,175 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kerti/idcra-api/model"
+)
+
+// indicatorPeriodSQL names the period a survey s falls in for every length of
+// period
+var indicatorPeriodSQL = map[string]string{
+	model.IndicatorPeriodMonth:   `date_format(s.date, '%Y-%m')`,
+	model.IndicatorPeriodQuarter: `concat(year(s.date), '-Q', quarter(s.date))`,
+	model.IndicatorPeriodYear:    `date_format(s.date, '%Y')`,
+}
+
+// ageSQL is the age of the student st of a survey s at the survey date
+const ageSQL = `timestampdiff(year, st.date_of_birth, s.date)`
+
+// ageBandSQL names the age band of the student st of a survey s at the survey
+// date, null for ages outside every band
+var ageBandSQL = func() string {
+	cases := make([]string, 0, len(model.SchoolSummaryAgeBands))
+	for _, band := range model.SchoolSummaryAgeBands {
+		if band.Max == nil {
+			cases = append(cases, fmt.Sprintf(`when %s >= %d then '%s'`, ageSQL, band.Min, band))
+			continue
+		}
+		cases = append(cases, fmt.Sprintf(`when %s between %d and %d then '%s'`, ageSQL, band.Min, *band.Max, band))
+	}
+	return `case ` + strings.Join(cases, " ") + ` end`
+}()
+
+// indicatorDimensionSQL returns the columns selected, the expressions grouped
+// by and the expressions ordered by for a dimension. Age bands are ordered by
+// age rather than by name.
+func indicatorDimensionSQL(dimension string, period string) (string, string, string) {
+	switch dimension {
+	case model.IndicatorDimensionSchool:
+		return "st.school_id school_id, sc.name school_name", "st.school_id, sc.name", "sc.name, st.school_id"
+	case model.IndicatorDimensionAgeBand:
+		return ageBandSQL + " age_band", ageBandSQL, "min(" + ageSQL + ")"
+	case model.IndicatorDimensionSex:
+		sex := fmt.Sprintf(`coalesce(st.sex, '%s')`, model.SexUnknown)
+		return sex + " sex", sex, sex
+	default:
+		return indicatorPeriodSQL[period] + " period", indicatorPeriodSQL[period], indicatorPeriodSQL[period]
+	}
+}
+
+// FindIndicators computes the epidemiological indicators of the approved
+// surveys within the period, grouped by the dimensions of the filter in their
+// order. Only the latest survey of every student counts, or the latest of
+// every period when grouping by period.
+func (s *ReportService) FindIndicators(filter model.IndicatorFilter) (*model.Indicators, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	period := filter.Period
+	if period == "" {
+		period = model.IndicatorPeriodYear
+	}
+
+	columns := make([]string, 0, len(filter.GroupBy))
+	groupBy := make([]string, 0, len(filter.GroupBy))
+	orderBy := make([]string, 0, len(filter.GroupBy))
+	latestPeriod := `''`
+	for _, dimension := range filter.GroupBy {
+		column, group, order := indicatorDimensionSQL(dimension, period)
+		columns = append(columns, column+",")
+		groupBy = append(groupBy, group)
+		orderBy = append(orderBy, order)
+		if dimension == model.IndicatorDimensionPeriod {
+			latestPeriod = indicatorPeriodSQL[period]
+		}
+	}
+
+	// the latest approved survey of every student, within every period when
+	// grouping by period; of the surveys of a student on the same day, the one
+	// with the highest id counts
+	fromSQL := `
+	from
+		surveys s
+		join (
+			select s.student_id, ` + latestPeriod + ` period, max(s.date) date
+			from surveys s
+			where s.status = ? and s.date >= ? and s.date < ?
+			group by s.student_id, ` + latestPeriod + `
+		) latest on latest.student_id = s.student_id
+			and s.id = (
+				select max(l.id)
+				from surveys l
+				where l.student_id = latest.student_id and l.date = latest.date and l.status = ?
+			)
+		join students st on s.student_id = st.id
+		join schools sc on st.school_id = sc.id
+		` + riskAssessmentJoinSQL + `
+	where
+		s.status = ?`
+	args := []interface{}{model.SurveyStatusApproved, filter.StartDate, filter.EndDate, model.SurveyStatusApproved, model.SurveyStatusApproved}
+	if len(filter.SchoolIDs) > 0 {
+		fromSQL += `
+		and st.school_id in (?)`
+		args = append(args, filter.SchoolIDs)
+	}
+
+	groupSQL := ""
+	if len(groupBy) > 0 {
+		groupSQL = `
+	group by
+		` + strings.Join(groupBy, ", ")
+	}
+
+	dmft := `(s.upper_d + s.upper_m + s.upper_f)`
+	deft := `(s.lower_d + s.lower_e + s.lower_f)`
+	aggregateSQL := `
+	select
+		` + strings.Join(columns, "\n\t\t") + `
+		count(*) surveyed,
+		coalesce(sum(case when ` + dmft + ` + ` + deft + ` > 0 then 1 else 0 end), 0) with_caries,
+		coalesce(sum(` + dmft + `), 0) dmft,
+		coalesce(sum(` + dmft + ` * ` + dmft + `), 0) dmft_squares,
+		coalesce(sum(` + deft + `), 0) deft,
+		coalesce(sum(` + deft + ` * ` + deft + `), 0) deft_squares,
+		coalesce(sum(s.upper_d), 0) decayed,
+		coalesce(sum(s.upper_m), 0) missing,
+		coalesce(sum(s.upper_f), 0) filled,
+		coalesce(sum(case when ` + riskCategorySQL + ` = ? then 1 else 0 end), 0) risk_low,
+		coalesce(sum(case when ` + riskCategorySQL + ` = ? then 1 else 0 end), 0) risk_medium,
+		coalesce(sum(case when ` + riskCategorySQL + ` = ? then 1 else 0 end), 0) risk_high` +
+		fromSQL + groupSQL
+	aggregateSQL += `
+	having
+		count(*) > 0`
+	if len(orderBy) > 0 {
+		aggregateSQL += `
+	order by
+		` + strings.Join(orderBy, ", ")
+	}
+	aggregateArgs := append([]interface{}{model.RiskCategoryLow, model.RiskCategoryMedium, model.RiskCategoryHigh}, args...)
+
+	countSQL := `
+	select
+		` + strings.Join(columns, "\n\t\t") + `
+		` + dmft + ` dmft,
+		count(*) count` +
+		fromSQL + `
+	group by
+		` + strings.Join(append(groupBy, dmft), ", ")
+
+	aggregates := make([]*model.IndicatorAggregate, 0)
+	if err := s.selectIn(&aggregates, aggregateSQL, aggregateArgs...); err != nil {
+		s.log.Errorf("Error in retrieving indicators : %v", err)
+		return nil, err
+	}
+	counts := make([]*model.IndicatorDMFTCount, 0)
+	if err := s.selectIn(&counts, countSQL, args...); err != nil {
+		s.log.Errorf("Error in retrieving DMF-T distribution : %v", err)
+		return nil, err
+	}
+
+	return model.NewIndicators(aggregates, counts), nil
+}
+
+// selectIn selects rows with a query whose arguments may be slices for IN
+// clauses
+func (s *ReportService) selectIn(dest interface{}, query string, args ...interface{}) error {
+	query, args, err := sqlx.In(query, args...)
+	if err != nil {
+		return err
+	}
+	return s.db.Select(dest, s.db.Rebind(query), args...)
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+
+	"github.com/kerti/idcra-api/model"
+	"github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+const (
+	odontogramToothSize  = 36.0
+	odontogramToothGap   = 6.0
+	odontogramLabelSize  = 14.0
+	odontogramRowGap     = 8.0
+	odontogramPadding    = 12.0
+	odontogramLegendSize = 24.0
+)
+
+var (
+	odontogramOutlineColor = drawing.ColorFromHex("424242")
+	odontogramAbsentColor  = drawing.ColorFromHex("e0e0e0")
+	odontogramCrossColor   = drawing.ColorFromHex("c62828")
+
+	surfaceConditionColors = map[string]drawing.Color{
+		model.SurfaceConditionSound:    drawing.ColorWhite,
+		model.SurfaceConditionCaries:   drawing.ColorFromHex("e53935"),
+		model.SurfaceConditionFilling:  drawing.ColorFromHex("1e88e5"),
+		model.SurfaceConditionSealant:  drawing.ColorFromHex("43a047"),
+		model.SurfaceConditionFracture: drawing.ColorFromHex("fb8c00"),
+	}
+)
+
+// getOdontogramChart draws the dental chart of a survey as a PNG with every
+// tooth of both dentitions. Each tooth is drawn as a square split into its
+// five surfaces: the occlusal surface in the middle, buccal and lingual
+// towards the outside and inside of the mouth, and mesial towards the midline.
+func getOdontogramChart(l *model.Localizer, odontogram *model.Odontogram) (chartAsBase64 string, err error) {
+	rows := model.ChartRows()
+	widest := len(rows[0])
+
+	width := int(2*odontogramPadding + float64(widest)*(odontogramToothSize+odontogramToothGap))
+	height := int(2*odontogramPadding + float64(len(rows))*(odontogramToothSize+odontogramLabelSize+odontogramRowGap) + odontogramLegendSize)
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+
+	gc, err := drawing.NewRasterGraphicContext(img)
+	if err != nil {
+		return "", err
+	}
+
+	font, err := chart.GetDefaultFont()
+	if err != nil {
+		return "", err
+	}
+	gc.SetFont(font)
+	gc.SetFontSize(7)
+	gc.SetLineWidth(1)
+
+	midline := float64(width) / 2
+	y := odontogramPadding
+
+	for i, row := range rows {
+		// numbers go above the upper teeth and below the lower teeth
+		upper := i < len(rows)/2
+		toothY := y
+		labelY := y + odontogramToothSize + odontogramLabelSize - 3
+		if upper {
+			toothY = y + odontogramLabelSize
+			labelY = y + odontogramLabelSize - 4
+		}
+
+		x := midline - float64(len(row))/2*(odontogramToothSize+odontogramToothGap) + odontogramToothGap/2
+		for _, toothNumber := range row {
+			drawTooth(gc, odontogram.Tooth(toothNumber), x, toothY)
+
+			label := fmt.Sprintf("%d", toothNumber)
+			left, _, right, _, _ := gc.GetStringBounds(label)
+			gc.SetFillColor(odontogramOutlineColor)
+			gc.FillStringAt(label, x+(odontogramToothSize-(right-left))/2, labelY)
+
+			x += odontogramToothSize + odontogramToothGap
+		}
+
+		y += odontogramToothSize + odontogramLabelSize + odontogramRowGap
+	}
+
+	gc.SetStrokeColor(odontogramOutlineColor)
+	gc.MoveTo(midline, odontogramPadding)
+	gc.LineTo(midline, y-odontogramRowGap)
+	gc.Stroke()
+
+	drawOdontogramLegend(gc, l, odontogramPadding, y+odontogramLegendSize/2)
+
+	buffer := bytes.NewBuffer([]byte{})
+	if err = png.Encode(buffer, img); err != nil {
+		return "", err
+	}
+	chartAsBase64 = base64.StdEncoding.EncodeToString(buffer.Bytes())
+	return
+}
+
+func drawTooth(gc *drawing.RasterGraphicContext, tooth *model.Tooth, x, y float64) {
+	s := odontogramToothSize
+	inner := s / 4
+
+	if tooth.Status == model.ToothStatusMissing || tooth.Status == model.ToothStatusUnerupted {
+		gc.SetFillColor(odontogramAbsentColor)
+		gc.SetStrokeColor(odontogramOutlineColor)
+		drawPolygon(gc, [][2]float64{{x, y}, {x + s, y}, {x + s, y + s}, {x, y + s}})
+		if tooth.Status == model.ToothStatusMissing {
+			drawCross(gc, x, y)
+		}
+		return
+	}
+
+	outer, inside := model.SurfaceBuccal, model.SurfaceLingual
+	if !isUpperTooth(tooth.ToothNumber) {
+		outer, inside = inside, outer
+	}
+	left, right := model.SurfaceDistal, model.SurfaceMesial
+	if !isPatientRightTooth(tooth.ToothNumber) {
+		left, right = right, left
+	}
+
+	occlusal := tooth.Surface(model.SurfaceOcclusal)
+	// a tooth charted as decayed or filled without surfaces is drawn with the
+	// condition on its occlusal surface
+	if len(tooth.Surfaces) == 0 {
+		switch tooth.Status {
+		case model.ToothStatusDecayed:
+			occlusal = model.SurfaceConditionCaries
+		case model.ToothStatusFilled:
+			occlusal = model.SurfaceConditionFilling
+		}
+	}
+
+	surfaces := []struct {
+		condition string
+		points    [][2]float64
+	}{
+		{tooth.Surface(outer), [][2]float64{{x, y}, {x + s, y}, {x + s - inner, y + inner}, {x + inner, y + inner}}},
+		{tooth.Surface(inside), [][2]float64{{x, y + s}, {x + inner, y + s - inner}, {x + s - inner, y + s - inner}, {x + s, y + s}}},
+		{tooth.Surface(left), [][2]float64{{x, y}, {x + inner, y + inner}, {x + inner, y + s - inner}, {x, y + s}}},
+		{tooth.Surface(right), [][2]float64{{x + s, y}, {x + s, y + s}, {x + s - inner, y + s - inner}, {x + s - inner, y + inner}}},
+		{occlusal, [][2]float64{{x + inner, y + inner}, {x + s - inner, y + inner}, {x + s - inner, y + s - inner}, {x + inner, y + s - inner}}},
+	}
+
+	gc.SetStrokeColor(odontogramOutlineColor)
+	for _, surface := range surfaces {
+		gc.SetFillColor(surfaceConditionColors[surface.condition])
+		drawPolygon(gc, surface.points)
+	}
+
+	if tooth.Status == model.ToothStatusExtractionIndicated {
+		drawCross(gc, x, y)
+	}
+}
+
+func drawPolygon(gc *drawing.RasterGraphicContext, points [][2]float64) {
+	gc.MoveTo(points[0][0], points[0][1])
+	for _, p := range points[1:] {
+		gc.LineTo(p[0], p[1])
+	}
+	gc.Close()
+	gc.FillStroke()
+}
+
+func drawCross(gc *drawing.RasterGraphicContext, x, y float64) {
+	s := odontogramToothSize
+	gc.SetStrokeColor(odontogramCrossColor)
+	gc.SetLineWidth(2)
+	gc.MoveTo(x+2, y+2)
+	gc.LineTo(x+s-2, y+s-2)
+	gc.MoveTo(x+s-2, y+2)
+	gc.LineTo(x+2, y+s-2)
+	gc.Stroke()
+	gc.SetLineWidth(1)
+}
+
+func drawOdontogramLegend(gc *drawing.RasterGraphicContext, l *model.Localizer, x, y float64) {
+	entries := []struct {
+		color drawing.Color
+		label string
+	}{
+		{surfaceConditionColors[model.SurfaceConditionCaries], l.T("chart.odontogram.caries")},
+		{surfaceConditionColors[model.SurfaceConditionFilling], l.T("chart.odontogram.filling")},
+		{surfaceConditionColors[model.SurfaceConditionSealant], l.T("chart.odontogram.sealant")},
+		{surfaceConditionColors[model.SurfaceConditionFracture], l.T("chart.odontogram.fracture")},
+		{odontogramAbsentColor, l.T("chart.odontogram.missing")},
+	}
+
+	gc.SetFontSize(8)
+	for _, entry := range entries {
+		gc.SetFillColor(entry.color)
+		gc.SetStrokeColor(odontogramOutlineColor)
+		drawPolygon(gc, [][2]float64{{x, y - 8}, {x + 10, y - 8}, {x + 10, y + 2}, {x, y + 2}})
+
+		gc.SetFillColor(odontogramOutlineColor)
+		cursor, _ := gc.FillStringAt(entry.label, x+14, y+1)
+		x += 14 + cursor + 20
+	}
+}
+
+// isUpperTooth reports whether the tooth is in the upper jaw, quadrants 1, 2,
+// 5 and 6.
+func isUpperTooth(toothNumber int32) bool {
+	quadrant := toothNumber / 10
+	return quadrant == 1 || quadrant == 2 || quadrant == 5 || quadrant == 6
+}
+
+// isPatientRightTooth reports whether the tooth is on the patient's right,
+// quadrants 1, 4, 5 and 8, which a dental chart draws on the left.
+func isPatientRightTooth(toothNumber int32) bool {
+	quadrant := toothNumber / 10
+	return quadrant == 1 || quadrant == 4 || quadrant == 5 || quadrant == 8
+}
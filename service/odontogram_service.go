@@ -0,0 +1,144 @@
+package service
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kerti/idcra-api/model"
+	"github.com/op/go-logging"
+)
+
+type OdontogramService struct {
+	db  *sqlx.DB
+	log *logging.Logger
+}
+
+func NewOdontogramService(db *sqlx.DB, log *logging.Logger) *OdontogramService {
+	return &OdontogramService{db: db, log: log}
+}
+
+// FindBySurveyID returns the dental chart of a survey. A survey that was not
+// charted has an odontogram without teeth.
+func (o *OdontogramService) FindBySurveyID(surveyID string) (*model.Odontogram, error) {
+	odontograms, err := o.FindBySurveyIDs([]string{surveyID})
+	if err != nil {
+		return nil, err
+	}
+	return odontograms[surveyID], nil
+}
+
+// FindBySurveyIDs returns the dental charts of the surveys keyed by survey ID,
+// loading the teeth and surfaces of every survey at once. Surveys that were
+// not charted have an odontogram without teeth.
+func (o *OdontogramService) FindBySurveyIDs(surveyIDs []string) (map[string]*model.Odontogram, error) {
+	odontograms := make(map[string]*model.Odontogram)
+	if len(surveyIDs) == 0 {
+		return odontograms, nil
+	}
+	for _, surveyID := range surveyIDs {
+		odontograms[surveyID] = &model.Odontogram{SurveyID: surveyID, Teeth: make([]*model.Tooth, 0)}
+	}
+
+	toothSQL, args, err := sqlx.In(`SELECT survey_id, tooth_number, status FROM survey_teeth WHERE survey_id IN (?) ORDER BY survey_id ASC, tooth_number ASC`, surveyIDs)
+	if err != nil {
+		return nil, err
+	}
+	teeth := make([]*model.Tooth, 0)
+	if err := o.db.Select(&teeth, o.db.Rebind(toothSQL), args...); err != nil {
+		o.log.Errorf("Error in retrieving teeth : %v", err)
+		return nil, err
+	}
+
+	surfaceSQL, args, err := sqlx.In("SELECT survey_id, tooth_number, surface, `condition` FROM survey_tooth_surfaces WHERE survey_id IN (?)", surveyIDs)
+	if err != nil {
+		return nil, err
+	}
+	surfaces := make([]*model.ToothSurface, 0)
+	if err := o.db.Select(&surfaces, o.db.Rebind(surfaceSQL), args...); err != nil {
+		o.log.Errorf("Error in retrieving tooth surfaces : %v", err)
+		return nil, err
+	}
+
+	type toothKey struct {
+		surveyID    string
+		toothNumber int32
+	}
+	surfacesByTooth := make(map[toothKey][]*model.ToothSurface)
+	for _, surface := range surfaces {
+		key := toothKey{surface.SurveyID, surface.ToothNumber}
+		surfacesByTooth[key] = append(surfacesByTooth[key], surface)
+	}
+
+	for _, tooth := range teeth {
+		tooth.Surfaces = orderSurfaces(surfacesByTooth[toothKey{tooth.SurveyID, tooth.ToothNumber}])
+		odontogram := odontograms[tooth.SurveyID]
+		odontogram.Teeth = append(odontogram.Teeth, tooth)
+	}
+
+	return odontograms, nil
+}
+
+// Save replaces the dental chart of a survey.
+func (o *OdontogramService) Save(odontogram *model.Odontogram) (*model.Odontogram, error) {
+	var exists bool
+	err := o.db.Get(&exists, `SELECT 1 FROM surveys WHERE id = ?`, odontogram.SurveyID)
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("survey", odontogram.SurveyID)
+	}
+	if err != nil {
+		o.log.Errorf("Error in retrieving survey : %v", err)
+		return nil, err
+	}
+
+	toothSQL := `
+		INSERT INTO survey_teeth
+		(survey_id, tooth_number, status)
+		VALUES
+		(:survey_id, :tooth_number, :status)`
+
+	surfaceSQL := "INSERT INTO survey_tooth_surfaces (survey_id, tooth_number, surface, `condition`) VALUES (:survey_id, :tooth_number, :surface, :condition)"
+
+	err = Transact(o.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM survey_tooth_surfaces WHERE survey_id = ?`, odontogram.SurveyID); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`DELETE FROM survey_teeth WHERE survey_id = ?`, odontogram.SurveyID); err != nil {
+			return err
+		}
+
+		for _, tooth := range odontogram.Teeth {
+			if _, err := tx.NamedExec(toothSQL, tooth); err != nil {
+				return err
+			}
+			for _, surface := range tooth.Surfaces {
+				if _, err := tx.NamedExec(surfaceSQL, surface); err != nil {
+					return err
+				}
+			}
+		}
+
+		// the chart is part of the survey, so offline clients must see the
+		// survey as changed
+		_, err := tx.Exec(`UPDATE surveys SET updated_at = NOW() WHERE id = ?`, odontogram.SurveyID)
+		return err
+	})
+	if err != nil {
+		o.log.Errorf("Error in saving odontogram : %v", err)
+		return nil, translateDBError(err)
+	}
+
+	return o.FindBySurveyID(odontogram.SurveyID)
+}
+
+// orderSurfaces sorts the surfaces of a tooth in the order of model.ToothSurfaces
+func orderSurfaces(surfaces []*model.ToothSurface) []*model.ToothSurface {
+	ordered := make([]*model.ToothSurface, 0, len(surfaces))
+	for _, name := range model.ToothSurfaces {
+		for _, surface := range surfaces {
+			if surface.Surface == name {
+				ordered = append(ordered, surface)
+			}
+		}
+	}
+	return ordered
+}
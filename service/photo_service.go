@@ -0,0 +1,324 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"image"
+	"image/jpeg"
+	_ "image/png" // registers the PNG decoder for uploaded photos
+	"net/http"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kerti/idcra-api/model"
+	"github.com/op/go-logging"
+	"golang.org/x/image/draw"
+)
+
+const (
+	// photoThumbnailSize is the longest side of a thumbnail in pixels
+	photoThumbnailSize = 320
+	// photoReportSize is the longest side of a photo in the student report
+	photoReportSize = 1024
+)
+
+type PhotoService struct {
+	db      *sqlx.DB
+	storage Storage
+	maxSize int64
+	log     *logging.Logger
+}
+
+func NewPhotoService(db *sqlx.DB, storage Storage, maxSize int64, log *logging.Logger) *PhotoService {
+	if maxSize <= 0 {
+		maxSize = model.DefaultMaxPhotoSize
+	}
+	return &PhotoService{db: db, storage: storage, maxSize: maxSize, log: log}
+}
+
+// MaxSize returns the size of the largest photo accepted in bytes
+func (p *PhotoService) MaxSize() int64 {
+	return p.maxSize
+}
+
+func (p *PhotoService) FindByID(id string) (*model.Photo, error) {
+	photo := &model.Photo{}
+
+	photoSQL := `SELECT * FROM survey_photos WHERE id = ?`
+	udb := p.db.Unsafe()
+	row := udb.QueryRowx(photoSQL, id)
+	err := row.StructScan(photo)
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("photo", id)
+	}
+	if err != nil {
+		p.log.Errorf("Error in retrieving photo : %v", err)
+		return nil, err
+	}
+
+	return photo, nil
+}
+
+func (p *PhotoService) FindBySurveyID(surveyID string) ([]*model.Photo, error) {
+	photos := make([]*model.Photo, 0)
+	photoSQL := `SELECT * FROM survey_photos WHERE survey_id = ? ORDER BY created_at ASC, id ASC`
+
+	err := p.db.Unsafe().Select(&photos, photoSQL, surveyID)
+	if err != nil {
+		p.log.Errorf("Error in retrieving photos : %v", err)
+		return nil, err
+	}
+
+	return photos, nil
+}
+
+func (p *PhotoService) FindByCaseID(caseID string) ([]*model.Photo, error) {
+	photos := make([]*model.Photo, 0)
+	photoSQL := `SELECT * FROM survey_photos WHERE case_id = ? ORDER BY created_at ASC, id ASC`
+
+	err := p.db.Unsafe().Select(&photos, photoSQL, caseID)
+	if err != nil {
+		p.log.Errorf("Error in retrieving photos : %v", err)
+		return nil, err
+	}
+
+	return photos, nil
+}
+
+// Upload stores a photo and its thumbnail and attaches it to a survey. The
+// content type of the upload is detected from the data rather than trusted
+// from the client.
+func (p *PhotoService) Upload(upload *model.PhotoUpload, data []byte) (*model.Photo, error) {
+	upload.ContentType = http.DetectContentType(data)
+	upload.Size = int64(len(data))
+
+	photo, err := model.NewPhotoFromUpload(upload, p.maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.checkAttachment(photo); err != nil {
+		return nil, err
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, model.NewFieldError("photo", "photo is not a valid image")
+	}
+	if err := model.ValidateImageDimensions("photo", config.Width, config.Height, model.MaxPhotoPixels); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, model.NewFieldError("photo", "photo is not a valid image")
+	}
+	photo.Width = int32(img.Bounds().Dx())
+	photo.Height = int32(img.Bounds().Dy())
+
+	thumbnail, err := encodeJPEG(scalePhoto(img, photoThumbnailSize))
+	if err != nil {
+		p.log.Errorf("Error in creating thumbnail : %v", err)
+		return nil, err
+	}
+
+	if err := p.storage.Put(photo.StorageKey, data, photo.ContentType); err != nil {
+		p.log.Errorf("Error in storing photo : %v", err)
+		return nil, err
+	}
+	if err := p.storage.Put(photo.ThumbnailKey, thumbnail, model.PhotoContentTypeJPEG); err != nil {
+		p.log.Errorf("Error in storing thumbnail : %v", err)
+		p.removeObjects(photo)
+		return nil, err
+	}
+
+	photoSQL := `
+		INSERT INTO survey_photos
+		(id, survey_id, case_id, content_type, size, width, height, storage_key, thumbnail_key, caption, include_in_report, uploaded_by, created_at)
+		VALUES
+		(:id, :survey_id, :case_id, :content_type, :size, :width, :height, :storage_key, :thumbnail_key, :caption, :include_in_report, :uploaded_by, NOW())`
+
+	if _, err := p.db.NamedExec(photoSQL, photo); err != nil {
+		p.log.Errorf("Error in inserting photo : %v", err)
+		p.removeObjects(photo)
+		return nil, translateDBError(err)
+	}
+
+	return p.FindByID(photo.ID)
+}
+
+// Update changes the caption of a photo and whether it is included in the
+// student report, keeping what is not given.
+func (p *PhotoService) Update(update *model.PhotoUpdate) (*model.Photo, error) {
+	if _, err := p.FindByID(update.ID); err != nil {
+		return nil, err
+	}
+
+	photoSQL := `
+		UPDATE survey_photos
+		SET caption = COALESCE(?, caption), include_in_report = COALESCE(?, include_in_report)
+		WHERE id = ?`
+
+	if _, err := p.db.Exec(photoSQL, update.Caption, update.IncludeInReport, update.ID); err != nil {
+		p.log.Errorf("Error in updating photo : %v", err)
+		return nil, translateDBError(err)
+	}
+
+	return p.FindByID(update.ID)
+}
+
+// Delete removes a photo and its thumbnail from the survey and the storage
+func (p *PhotoService) Delete(id string) (*model.Photo, error) {
+	photo, err := p.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := p.db.Exec(`DELETE FROM survey_photos WHERE id = ?`, id); err != nil {
+		p.log.Errorf("Error in deleting photo : %v", err)
+		return nil, err
+	}
+	p.removeObjects(photo)
+
+	return photo, nil
+}
+
+// Open returns the content of a photo, or of its JPEG thumbnail
+func (p *PhotoService) Open(photo *model.Photo, thumbnail bool) ([]byte, error) {
+	key := photo.StorageKey
+	if thumbnail {
+		key = photo.ThumbnailKey
+	}
+
+	data, err := p.storage.Get(key)
+	if err == ErrObjectNotFound {
+		return nil, model.NewNotFoundError("photo", photo.ID)
+	}
+	if err != nil {
+		p.log.Errorf("Error in reading photo : %v", err)
+		return nil, err
+	}
+	return data, nil
+}
+
+// FindForReport returns the photos of a survey marked for the student report,
+// scaled down and encoded as JPEG.
+func (p *PhotoService) FindForReport(surveyID string) ([]*model.SurveyReportPhoto, error) {
+	photos, err := p.FindBySurveyID(surveyID)
+	if err != nil {
+		return nil, err
+	}
+
+	reportPhotos := make([]*model.SurveyReportPhoto, 0)
+	for _, photo := range photos {
+		if !photo.IncludeInReport {
+			continue
+		}
+
+		data, err := p.Open(photo, false)
+		if err != nil {
+			return nil, err
+		}
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			p.log.Errorf("Error in decoding photo %s : %v", photo.ID, err)
+			continue
+		}
+		jpg, err := encodeJPEG(scalePhoto(img, photoReportSize))
+		if err != nil {
+			return nil, err
+		}
+
+		reportPhoto := &model.SurveyReportPhoto{JPEG: jpg}
+		if photo.Caption != nil {
+			reportPhoto.Caption = *photo.Caption
+		}
+		reportPhotos = append(reportPhotos, reportPhoto)
+	}
+
+	return reportPhotos, nil
+}
+
+// checkAttachment makes sure the survey of a photo exists and its case, if
+// any, is one of the cases of the survey
+func (p *PhotoService) checkAttachment(photo *model.Photo) error {
+	var exists bool
+	err := p.db.Get(&exists, `SELECT 1 FROM surveys WHERE id = ?`, photo.SurveyID)
+	if err == sql.ErrNoRows {
+		return model.NewNotFoundError("survey", photo.SurveyID)
+	}
+	if err != nil {
+		p.log.Errorf("Error in retrieving survey : %v", err)
+		return err
+	}
+
+	if photo.CaseID == nil {
+		return nil
+	}
+
+	var surveyID string
+	err = p.db.Get(&surveyID, `SELECT survey_id FROM cases WHERE id = ?`, *photo.CaseID)
+	if err == sql.ErrNoRows {
+		return model.NewNotFoundError("case", *photo.CaseID)
+	}
+	if err != nil {
+		p.log.Errorf("Error in retrieving case : %v", err)
+		return err
+	}
+	if surveyID != photo.SurveyID {
+		return model.NewFieldError("caseId", "case does not belong to the survey")
+	}
+
+	return nil
+}
+
+// removeObjects deletes the stored photo and thumbnail, logging failures so
+// the caller can report its own error
+func (p *PhotoService) removeObjects(photo *model.Photo) {
+	for _, key := range []string{photo.StorageKey, photo.ThumbnailKey} {
+		if err := p.storage.Delete(key); err != nil {
+			p.log.Errorf("Error in removing %s from storage : %v", key, err)
+		}
+	}
+}
+
+// scalePhoto shrinks an image so its longest side is at most size pixels,
+// keeping its aspect ratio. Smaller images are returned unchanged.
+func scalePhoto(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return img
+	}
+
+	if width >= height {
+		height = height * size / width
+		width = size
+	} else {
+		width = width * size / height
+		height = size
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+	return scaled
+}
+
+// encodeJPEG encodes an image as JPEG on a white background, as photos with
+// transparency would otherwise turn black
+func encodeJPEG(img image.Image) ([]byte, error) {
+	opaque := image.NewRGBA(img.Bounds())
+	draw.Draw(opaque, opaque.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(opaque, opaque.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	buffer := bytes.NewBuffer([]byte{})
+	if err := jpeg.Encode(buffer, opaque, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
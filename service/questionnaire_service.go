@@ -0,0 +1,187 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kerti/idcra-api/model"
+	"github.com/op/go-logging"
+)
+
+// QuestionnaireService loads questionnaires. Questionnaires never change once
+// published, so every questionnaire is only loaded from the database once.
+type QuestionnaireService struct {
+	db  *sqlx.DB
+	log *logging.Logger
+
+	mu    sync.RWMutex
+	cache map[string]*model.Questionnaire
+}
+
+func NewQuestionnaireService(db *sqlx.DB, log *logging.Logger) *QuestionnaireService {
+	return &QuestionnaireService{db: db, log: log, cache: make(map[string]*model.Questionnaire)}
+}
+
+func (q *QuestionnaireService) FindByID(id string) (*model.Questionnaire, error) {
+	q.mu.RLock()
+	questionnaire, ok := q.cache[id]
+	q.mu.RUnlock()
+	if ok {
+		return questionnaire, nil
+	}
+
+	questionnaire = &model.Questionnaire{}
+
+	questionnaireSQL := `SELECT * FROM questionnaires WHERE id = ?`
+	udb := q.db.Unsafe()
+	row := udb.QueryRowx(questionnaireSQL, id)
+	err := row.StructScan(questionnaire)
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("questionnaire", id)
+	}
+	if err != nil {
+		q.log.Errorf("Error in retrieving questionnaire : %v", err)
+		return nil, err
+	}
+
+	if err := q.loadSections(questionnaire); err != nil {
+		q.log.Errorf("Error in retrieving questionnaire sections : %v", err)
+		return nil, err
+	}
+
+	q.mu.Lock()
+	q.cache[id] = questionnaire
+	q.mu.Unlock()
+
+	return questionnaire, nil
+}
+
+// FindByCode returns the given version of a questionnaire, or its latest
+// version when no version is given.
+func (q *QuestionnaireService) FindByCode(code string, version *int32) (*model.Questionnaire, error) {
+	var id string
+
+	questionnaireSQL := `SELECT id FROM questionnaires WHERE code = ? ORDER BY version DESC LIMIT 1`
+	args := []interface{}{code}
+	if version != nil {
+		questionnaireSQL = `SELECT id FROM questionnaires WHERE code = ? AND version = ?`
+		args = append(args, *version)
+	}
+
+	err := q.db.Get(&id, questionnaireSQL, args...)
+	if err == sql.ErrNoRows {
+		if version != nil {
+			return nil, model.NewNotFoundError("questionnaire", fmt.Sprintf("%s version %d", code, *version))
+		}
+		return nil, model.NewNotFoundError("questionnaire", code)
+	}
+	if err != nil {
+		q.log.Errorf("Error in retrieving questionnaire : %v", err)
+		return nil, err
+	}
+
+	return q.FindByID(id)
+}
+
+func (q *QuestionnaireService) loadSections(questionnaire *model.Questionnaire) error {
+	udb := q.db.Unsafe()
+
+	sectionSQL := `SELECT * FROM questionnaire_sections WHERE questionnaire_id = ? ORDER BY position ASC`
+	if err := udb.Select(&questionnaire.Sections, sectionSQL, questionnaire.ID); err != nil {
+		return err
+	}
+
+	questions := make([]*model.Question, 0)
+	questionSQL := `
+		SELECT q.*
+		FROM questionnaire_questions q
+		JOIN questionnaire_sections sec ON sec.id = q.section_id
+		WHERE sec.questionnaire_id = ?
+		ORDER BY q.position ASC`
+	if err := udb.Select(&questions, questionSQL, questionnaire.ID); err != nil {
+		return err
+	}
+
+	options := make([]*model.AnswerOption, 0)
+	optionSQL := `
+		SELECT o.*
+		FROM questionnaire_answer_options o
+		JOIN questionnaire_questions q ON q.id = o.question_id
+		JOIN questionnaire_sections sec ON sec.id = q.section_id
+		WHERE sec.questionnaire_id = ?
+		ORDER BY o.position ASC`
+	if err := udb.Select(&options, optionSQL, questionnaire.ID); err != nil {
+		return err
+	}
+
+	texts, err := q.findTexts(questionnaire.ID)
+	if err != nil {
+		return err
+	}
+
+	questionsBySection := make(map[string][]*model.Question)
+	for _, question := range questions {
+		question.Text = texts[question.ID]
+		questionsBySection[question.SectionID] = append(questionsBySection[question.SectionID], question)
+	}
+
+	optionsByQuestion := make(map[string][]*model.AnswerOption)
+	for _, option := range options {
+		option.Label = texts[option.ID]
+		optionsByQuestion[option.QuestionID] = append(optionsByQuestion[option.QuestionID], option)
+	}
+
+	for _, section := range questionnaire.Sections {
+		section.Title = texts[section.ID]
+		section.Questions = questionsBySection[section.ID]
+		for _, question := range section.Questions {
+			question.Options = optionsByQuestion[question.ID]
+		}
+	}
+
+	return nil
+}
+
+// findTexts returns the localized texts of a questionnaire keyed by the id of
+// their section, question or answer option.
+func (q *QuestionnaireService) findTexts(questionnaireID string) (map[string]model.LocalizedText, error) {
+	rows := []struct {
+		OwnerID string `db:"owner_id"`
+		Locale  string
+		Text    string
+	}{}
+
+	textSQL := `
+		SELECT t.owner_id, t.locale, t.text
+		FROM questionnaire_texts t
+		JOIN questionnaire_sections sec ON sec.id = t.owner_id
+		WHERE sec.questionnaire_id = ?
+		UNION ALL
+		SELECT t.owner_id, t.locale, t.text
+		FROM questionnaire_texts t
+		JOIN questionnaire_questions q ON q.id = t.owner_id
+		JOIN questionnaire_sections sec ON sec.id = q.section_id
+		WHERE sec.questionnaire_id = ?
+		UNION ALL
+		SELECT t.owner_id, t.locale, t.text
+		FROM questionnaire_texts t
+		JOIN questionnaire_answer_options o ON o.id = t.owner_id
+		JOIN questionnaire_questions q ON q.id = o.question_id
+		JOIN questionnaire_sections sec ON sec.id = q.section_id
+		WHERE sec.questionnaire_id = ?`
+	if err := q.db.Select(&rows, textSQL, questionnaireID, questionnaireID, questionnaireID); err != nil {
+		return nil, err
+	}
+
+	texts := make(map[string]model.LocalizedText)
+	for _, row := range rows {
+		if texts[row.OwnerID] == nil {
+			texts[row.OwnerID] = make(model.LocalizedText)
+		}
+		texts[row.OwnerID][row.Locale] = row.Text
+	}
+
+	return texts, nil
+}
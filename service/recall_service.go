@@ -0,0 +1,127 @@
+package service
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kerti/idcra-api/model"
+	"github.com/op/go-logging"
+)
+
+type RecallService struct {
+	db              *sqlx.DB
+	defaultTimeZone string
+	log             *logging.Logger
+}
+
+// NewRecallService returns the service, defaultTimeZone being the time zone of
+// the schools without their own
+func NewRecallService(db *sqlx.DB, defaultTimeZone string, log *logging.Logger) (*RecallService, error) {
+	if err := model.ValidateTimeZone(defaultTimeZone); err != nil {
+		return nil, err
+	}
+	return &RecallService{db: db, defaultTimeZone: defaultTimeZone, log: log}, nil
+}
+
+func (r *RecallService) FindByID(id string) (*model.Recall, error) {
+	recall := &model.Recall{}
+
+	recallSQL := `
+		SELECT r.*, st.school_id, COALESCE(sc.time_zone, ?) time_zone
+		FROM recalls r
+		JOIN students st ON st.id = r.student_id
+		JOIN schools sc ON sc.id = st.school_id
+		WHERE r.id = ?`
+	udb := r.db.Unsafe()
+	row := udb.QueryRowx(recallSQL, r.defaultTimeZone, id)
+	err := row.StructScan(recall)
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("recall", id)
+	}
+	if err != nil {
+		r.log.Errorf("Error in retrieving recall : %v", err)
+		return nil, err
+	}
+
+	return recall, nil
+}
+
+// ListDue returns the open recalls of the students of a school due before the
+// given date, overdue recalls first.
+func (r *RecallService) ListDue(schoolID string, before string) ([]*model.Recall, error) {
+	recalls := make([]*model.Recall, 0)
+
+	recallSQL := `
+		SELECT r.*, st.school_id, COALESCE(sc.time_zone, ?) time_zone
+		FROM recalls r
+		JOIN students st ON st.id = r.student_id
+		JOIN schools sc ON sc.id = st.school_id
+		WHERE st.school_id = ?
+		AND r.status IN (?, ?)
+		AND r.due_date < ?
+		ORDER BY r.due_date ASC, st.name ASC`
+
+	err := r.db.Unsafe().Select(&recalls, recallSQL, r.defaultTimeZone, schoolID, model.RecallStatusDue, model.RecallStatusScheduled, before)
+	if err != nil {
+		r.log.Errorf("Error in retrieving due recalls : %v", err)
+		return nil, err
+	}
+
+	return recalls, nil
+}
+
+// Update changes the status of a recall, keeping the scheduled date and note
+// when they are not given.
+func (r *RecallService) Update(update *model.RecallUpdate) (*model.Recall, error) {
+	if _, err := r.FindByID(update.ID); err != nil {
+		return nil, err
+	}
+
+	recallSQL := `
+		UPDATE recalls
+		SET status = ?, scheduled_date = COALESCE(?, scheduled_date), note = COALESCE(?, note)
+		WHERE id = ?`
+
+	if _, err := r.db.Exec(recallSQL, update.Status, update.ScheduledDate, update.Note, update.ID); err != nil {
+		r.log.Errorf("Error in updating recall : %v", err)
+		return nil, translateDBError(err)
+	}
+
+	return r.FindByID(update.ID)
+}
+
+// saveRecall schedules the next visit after the survey. Open recalls from
+// earlier surveys of the student are done, while the recall of a survey that
+// is older than the latest survey of the student, such as one uploaded late
+// by an offline client, is done right away.
+func saveRecall(tx *sqlx.Tx, survey *model.Survey) error {
+	recall, err := model.NewRecall(survey)
+	if err != nil {
+		return err
+	}
+
+	completeSQL := `
+		UPDATE recalls
+		SET status = ?, completed_survey_id = ?
+		WHERE student_id = ? AND status IN (?, ?) AND survey_date <= ?`
+	if _, err := tx.Exec(completeSQL, model.RecallStatusDone, survey.ID, survey.StudentID, model.RecallStatusDue, model.RecallStatusScheduled, survey.Date); err != nil {
+		return err
+	}
+
+	var later int
+	laterSQL := `SELECT COUNT(*) FROM recalls WHERE student_id = ? AND survey_date > ?`
+	if err := tx.Get(&later, laterSQL, survey.StudentID, survey.Date); err != nil {
+		return err
+	}
+	if later > 0 {
+		recall.Status = model.RecallStatusDone
+	}
+
+	recallSQL := `
+		INSERT INTO recalls
+		(id, student_id, survey_id, survey_date, risk_category, due_date, status, created_at)
+		VALUES
+		(:id, :student_id, :survey_id, :survey_date, :risk_category, :due_date, :status, NOW())`
+	_, err = tx.NamedExec(recallSQL, recall)
+	return err
+}
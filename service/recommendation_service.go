@@ -0,0 +1,213 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kerti/idcra-api/model"
+	"github.com/op/go-logging"
+	uuid "github.com/satori/go.uuid"
+)
+
+// RecommendationService publishes and loads the recommendation rules of survey
+// reports. Published versions never change, so every version is only loaded
+// from the database once.
+type RecommendationService struct {
+	db  *sqlx.DB
+	log *logging.Logger
+
+	mu    sync.RWMutex
+	cache map[int32]*model.RecommendationRuleSet
+}
+
+func NewRecommendationService(db *sqlx.DB, log *logging.Logger) *RecommendationService {
+	return &RecommendationService{db: db, log: log, cache: make(map[int32]*model.RecommendationRuleSet)}
+}
+
+// FindByVersion returns the given version of the rules, or the latest version
+// when no version is given
+func (s *RecommendationService) FindByVersion(version *int32) (*model.RecommendationRuleSet, error) {
+	if version == nil {
+		var latest int32
+		err := s.db.Get(&latest, `SELECT version FROM recommendation_rule_sets ORDER BY version DESC LIMIT 1`)
+		if err == sql.ErrNoRows {
+			return nil, model.NewNotFoundError("recommendation rules", "latest")
+		}
+		if err != nil {
+			s.log.Errorf("Error in retrieving recommendation rules : %v", err)
+			return nil, err
+		}
+		version = &latest
+	}
+
+	s.mu.RLock()
+	ruleSet, ok := s.cache[*version]
+	s.mu.RUnlock()
+	if ok {
+		return ruleSet, nil
+	}
+
+	ruleSet = &model.RecommendationRuleSet{}
+	udb := s.db.Unsafe()
+	err := udb.Get(ruleSet, `SELECT * FROM recommendation_rule_sets WHERE version = ?`, *version)
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("recommendation rules", fmt.Sprintf("version %d", *version))
+	}
+	if err != nil {
+		s.log.Errorf("Error in retrieving recommendation rules : %v", err)
+		return nil, err
+	}
+
+	if err := s.loadRules(ruleSet); err != nil {
+		s.log.Errorf("Error in retrieving recommendation rules : %v", err)
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[ruleSet.Version] = ruleSet
+	s.mu.Unlock()
+
+	return ruleSet, nil
+}
+
+func (s *RecommendationService) loadRules(ruleSet *model.RecommendationRuleSet) error {
+	udb := s.db.Unsafe()
+
+	ruleSQL := `SELECT * FROM recommendation_rules WHERE rule_set_id = ? ORDER BY position ASC`
+	if err := udb.Select(&ruleSet.Rules, ruleSQL, ruleSet.ID); err != nil {
+		return err
+	}
+
+	rows := []struct {
+		RuleID string `db:"rule_id"`
+		Locale string
+		Text   string
+	}{}
+	textSQL := `
+		SELECT t.rule_id, t.locale, t.text
+		FROM recommendation_rule_texts t
+		JOIN recommendation_rules r ON r.id = t.rule_id
+		WHERE r.rule_set_id = ?`
+	if err := s.db.Select(&rows, textSQL, ruleSet.ID); err != nil {
+		return err
+	}
+
+	rulesByID := make(map[string]*model.RecommendationRule)
+	for _, rule := range ruleSet.Rules {
+		rule.Text = make(model.LocalizedText)
+		rulesByID[rule.ID] = rule
+	}
+	for _, row := range rows {
+		if rule, ok := rulesByID[row.RuleID]; ok {
+			rule.Text[row.Locale] = row.Text
+		}
+	}
+
+	return nil
+}
+
+// Publish saves the rules as the next version, which reports are printed with
+// from then on
+func (s *RecommendationService) Publish(ruleSet model.RecommendationRuleSet) (*model.RecommendationRuleSet, error) {
+	ruleSet.ID = uuid.NewV4().String()
+
+	setSQL := `
+		INSERT INTO recommendation_rule_sets (id, version, note, created_by)
+		VALUES (:id, :version, :note, :created_by)`
+	ruleSQL := `
+		INSERT INTO recommendation_rules (id, rule_set_id, position, risk_category, target, min_age, max_age, question_code, answer_value, diagnosis_and_action_id)
+		VALUES (:id, :rule_set_id, :position, :risk_category, :target, :min_age, :max_age, :question_code, :answer_value, :diagnosis_and_action_id)`
+	textSQL := `INSERT INTO recommendation_rule_texts (rule_id, locale, text) VALUES (?, ?, ?)`
+
+	err := Transact(s.db, func(tx *sqlx.Tx) error {
+		err := tx.Get(&ruleSet.Version, `SELECT COALESCE(MAX(version), 0) + 1 FROM recommendation_rule_sets FOR UPDATE`)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.NamedExec(setSQL, ruleSet); err != nil {
+			return err
+		}
+
+		for _, rule := range ruleSet.Rules {
+			rule.ID = uuid.NewV4().String()
+			rule.RuleSetID = ruleSet.ID
+			if _, err := tx.NamedExec(ruleSQL, rule); err != nil {
+				return err
+			}
+			for locale, text := range rule.Text {
+				if _, err := tx.Exec(textSQL, rule.ID, locale, text); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.log.Errorf("Error in publishing recommendation rules : %v", err)
+		return nil, translateDBError(err)
+	}
+
+	return s.FindByVersion(&ruleSet.Version)
+}
+
+// Restore publishes the rules of an earlier version again as the next version
+func (s *RecommendationService) Restore(version int32, note *string, createdBy string) (*model.RecommendationRuleSet, error) {
+	previous, err := s.FindByVersion(&version)
+	if err != nil {
+		return nil, err
+	}
+
+	input := make([]*model.RecommendationRuleInput, len(previous.Rules))
+	for i, rule := range previous.Rules {
+		input[i] = rule.Input()
+	}
+	if note == nil {
+		restored := fmt.Sprintf("Restored version %d", version)
+		note = &restored
+	}
+
+	ruleSet, err := model.NewRecommendationRuleSetFromInput(input, note, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	return s.Publish(ruleSet)
+}
+
+// FindFacts returns the answers and the diagnoses of a survey that rules may
+// be conditioned on
+func (s *RecommendationService) FindFacts(surveyID string) (model.RecommendationFacts, error) {
+	facts := model.RecommendationFacts{
+		Answers:   make(map[string]string),
+		Diagnoses: make(map[string]bool),
+	}
+
+	answers := []struct {
+		QuestionCode string `db:"question_code"`
+		Value        string
+	}{}
+	answerSQL := `
+		SELECT q.code question_code, a.value
+		FROM survey_answers a
+		JOIN questionnaire_questions q ON q.id = a.question_id
+		WHERE a.survey_id = ?`
+	if err := s.db.Select(&answers, answerSQL, surveyID); err != nil {
+		s.log.Errorf("Error in retrieving survey answers : %v", err)
+		return facts, err
+	}
+	for _, answer := range answers {
+		facts.Answers[answer.QuestionCode] = answer.Value
+	}
+
+	diagnoses := []string{}
+	if err := s.db.Select(&diagnoses, `SELECT DISTINCT diagnosis_and_action_id FROM cases WHERE survey_id = ?`, surveyID); err != nil {
+		s.log.Errorf("Error in retrieving survey cases : %v", err)
+		return facts, err
+	}
+	for _, diagnosis := range diagnoses {
+		facts.Diagnoses[diagnosis] = true
+	}
+
+	return facts, nil
+}
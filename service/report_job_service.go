@@ -0,0 +1,283 @@
+package service
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/kerti/idcra-api/model"
+	"github.com/op/go-logging"
+	uuid "github.com/satori/go.uuid"
+)
+
+// reportJobSweepInterval is how often expired report jobs are removed
+const reportJobSweepInterval = time.Minute
+
+// schoolReportGenerator writes the reports of a school in a language into an
+// archive in dir, returning the path of the archive and the name to download
+// it as
+type schoolReportGenerator func(schoolID string, language string, dir string, progress func(completed, total int)) (string, string, error)
+
+// surveyExporter exports the surveys matching a filter within a scope into a
+// file in dir, returning the path of the file and the name to download it as
+type surveyExporter func(filter model.SurveyExportFilter, scope model.DataScope, dir string, progress func(completed, total int)) (string, string, error)
+
+// reportTask is a queued job with the work that produces its file in a
+// directory
+type reportTask struct {
+	job *model.ReportJob
+	run func(dir string, progress func(completed, total int)) (string, string, error)
+}
+
+// ReportJobService generates school reports and survey exports in the
+// background with a fixed number of workers. Every job works in its own
+// directory, so concurrent jobs never share files. Jobs are kept in memory
+// and, once finished, are removed with their file when they expire.
+type ReportJobService struct {
+	generate schoolReportGenerator
+	export   surveyExporter
+	eventBus *EventBus
+	dir      string
+	expireIn time.Duration
+	queue    chan *reportTask
+	now      func() time.Time
+	log      *logging.Logger
+
+	mu   sync.RWMutex
+	jobs map[string]*model.ReportJob
+}
+
+// NewReportJobService starts workers generating school reports and survey
+// exports under dir. Job directories left in dir by a previous run are
+// removed, as their jobs are gone.
+func NewReportJobService(reportService *ReportService, exportService *ExportService, eventBus *EventBus, dir string, workers int, queueSize int, expireIn time.Duration, log *logging.Logger) (*ReportJobService, error) {
+	s, err := newReportJobService(reportService.GenerateSchoolReport, eventBus, dir, queueSize, expireIn, log)
+	if err != nil {
+		return nil, err
+	}
+	s.export = exportService.ExportToFile
+	s.start(workers)
+	go s.sweep()
+	return s, nil
+}
+
+func newReportJobService(generate schoolReportGenerator, eventBus *EventBus, dir string, queueSize int, expireIn time.Duration, log *logging.Logger) (*ReportJobService, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if _, err := uuid.FromString(entry.Name()); err != nil || !entry.IsDir() {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return nil, err
+		}
+	}
+	if queueSize < 1 {
+		queueSize = 1
+	}
+
+	return &ReportJobService{
+		generate: generate,
+		eventBus: eventBus,
+		dir:      dir,
+		expireIn: expireIn,
+		queue:    make(chan *reportTask, queueSize),
+		now:      time.Now,
+		log:      log,
+		jobs:     make(map[string]*model.ReportJob),
+	}, nil
+}
+
+func (s *ReportJobService) start(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for task := range s.queue {
+				s.run(task)
+			}
+		}()
+	}
+}
+
+// Enqueue queues the report of a school in a language. Clients may pass their own job ID to
+// subscribe to reportReady before the job is queued. Jobs are refused while
+// the queue is full.
+func (s *ReportJobService) Enqueue(schoolID string, language string, jobID *string, requestedBy *string) (*model.ReportJob, error) {
+	job := &model.ReportJob{
+		Kind:        model.ReportJobKindSchoolReport,
+		SchoolID:    schoolID,
+		Language:    language,
+		RequestedBy: requestedBy,
+	}
+	return s.enqueue(job, jobID, func(dir string, progress func(completed, total int)) (string, string, error) {
+		return s.generate(schoolID, language, dir, progress)
+	})
+}
+
+// EnqueueExport queues an export of the surveys matching the filter within the
+// scope of the user asking for it, who is the only one able to download it
+func (s *ReportJobService) EnqueueExport(filter model.SurveyExportFilter, scope model.DataScope, jobID *string, requestedBy *string) (*model.ReportJob, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	job := &model.ReportJob{
+		Kind:        model.ReportJobKindSurveyExport,
+		RequestedBy: requestedBy,
+	}
+	if filter.SchoolID != nil {
+		job.SchoolID = *filter.SchoolID
+	}
+	return s.enqueue(job, jobID, func(dir string, progress func(completed, total int)) (string, string, error) {
+		return s.export(filter, scope, dir, progress)
+	})
+}
+
+func (s *ReportJobService) enqueue(job *model.ReportJob, jobID *string, run func(dir string, progress func(completed, total int)) (string, string, error)) (*model.ReportJob, error) {
+	id := uuid.NewV4().String()
+	if jobID != nil {
+		parsed, err := uuid.FromString(*jobID)
+		if err != nil {
+			return nil, model.NewFieldError("jobId", "job ID must be a UUID")
+		}
+		id = parsed.String()
+	}
+	job.ID = id
+	job.Status = model.ReportJobStatusQueued
+	job.CreatedAt = s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.jobs[id]; exists {
+		return nil, model.NewConflictError(fmt.Sprintf("report job %s already exists", id))
+	}
+
+	select {
+	case s.queue <- &reportTask{job: job, run: run}:
+	default:
+		return nil, model.NewConflictError("too many reports are being generated, try again later")
+	}
+	s.jobs[id] = job
+
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// FindByID returns the job as it currently is
+func (s *ReportJobService) FindByID(id string) (*model.ReportJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return nil, model.NewNotFoundError("report job", id)
+	}
+
+	snapshot := *job
+	return &snapshot, nil
+}
+
+// Open returns a finished job with its file, which the caller closes
+func (s *ReportJobService) Open(id string) (*model.ReportJob, *os.File, error) {
+	job, err := s.FindByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if job.Status != model.ReportJobStatusDone {
+		return nil, nil, model.NewConflictError(fmt.Sprintf("report job %s is %s, only finished reports can be downloaded", id, job.Status))
+	}
+
+	f, err := os.Open(job.ArchivePath)
+	if os.IsNotExist(err) {
+		return nil, nil, model.NewNotFoundError("report job", id)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return job, f, nil
+}
+
+func (s *ReportJobService) run(task *reportTask) {
+	job := task.job
+	s.update(job, func(j *model.ReportJob) {
+		now := s.now()
+		j.Status = model.ReportJobStatusRunning
+		j.StartedAt = &now
+	})
+
+	jobDir := filepath.Join(s.dir, job.ID)
+	archivePath, fileName, err := task.run(jobDir, func(completed, total int) {
+		s.update(job, func(j *model.ReportJob) {
+			j.Completed = completed
+			j.Total = total
+		})
+	})
+	if err != nil {
+		s.log.Errorf("Error in running report job %s : %v", job.ID, err)
+		os.RemoveAll(jobDir)
+	}
+
+	finished := s.update(job, func(j *model.ReportJob) {
+		now := s.now()
+		expiresAt := now.Add(s.expireIn)
+		j.FinishedAt = &now
+		j.ExpiresAt = &expiresAt
+		if err != nil {
+			j.Status = model.ReportJobStatusFailed
+			j.Error = model.AsError(err).Message
+			return
+		}
+		j.Status = model.ReportJobStatusDone
+		j.ArchivePath = archivePath
+		j.FileName = fileName
+	})
+
+	s.eventBus.Publish(ReportReadyTopic, &model.ReportReadyEvent{Job: finished})
+}
+
+// update changes a job under the lock and returns it as changed
+func (s *ReportJobService) update(job *model.ReportJob, change func(j *model.ReportJob)) model.ReportJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	change(job)
+	return *job
+}
+
+func (s *ReportJobService) sweep() {
+	for range time.Tick(reportJobSweepInterval) {
+		s.expire()
+	}
+}
+
+// expire removes the finished jobs that expired, with their archives
+func (s *ReportJobService) expire() {
+	now := s.now()
+
+	s.mu.Lock()
+	expired := make([]string, 0)
+	for id, job := range s.jobs {
+		if job.IsExpired(now) {
+			delete(s.jobs, id)
+			expired = append(expired, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, id := range expired {
+		if err := os.RemoveAll(filepath.Join(s.dir, id)); err != nil {
+			s.log.Errorf("Error in removing report job %s : %v", id, err)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kerti/idcra-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReportJobService(t *testing.T, generate schoolReportGenerator, queueSize int) (*ReportJobService, *EventBus, string) {
+	dir, err := ioutil.TempDir("", "reports")
+	assert.Nil(t, err)
+
+	bus := NewEventBus(authService.log)
+	s, err := newReportJobService(generate, bus, dir, queueSize, time.Hour, authService.log)
+	assert.Nil(t, err)
+	return s, bus, dir
+}
+
+func fakeSchoolReport(schoolID string, language string, dir string, progress func(completed, total int)) (string, string, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", "", err
+	}
+	for i := 1; i <= 3; i++ {
+		progress(i, 3)
+	}
+	archivePath := filepath.Join(dir, "schoolreports.zip")
+	return archivePath, schoolID + ".zip", ioutil.WriteFile(archivePath, []byte(schoolID), 0644)
+}
+
+func waitForReport(t *testing.T, events <-chan Event) model.ReportJob {
+	select {
+	case event := <-events:
+		assert.Equal(t, ReportReadyTopic, event.Topic)
+		return event.Payload.(*model.ReportReadyEvent).Job
+	case <-time.After(5 * time.Second):
+		t.Fatal("report job did not finish")
+		return model.ReportJob{}
+	}
+}
+
+func TestReportJobService(t *testing.T) {
+
+	t.Run("GenerateAndDownload", func(t *testing.T) {
+		s, bus, dir := newTestReportJobService(t, fakeSchoolReport, 4)
+		defer os.RemoveAll(dir)
+		events, unsubscribe := bus.Subscribe()
+		defer unsubscribe()
+		s.start(2)
+
+		first, err := s.Enqueue("first", model.DefaultLanguage, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, model.ReportJobStatusQueued, first.Status)
+		second, err := s.Enqueue("second", model.DefaultLanguage, nil, nil)
+		assert.Nil(t, err)
+
+		finished := map[string]model.ReportJob{}
+		for i := 0; i < 2; i++ {
+			job := waitForReport(t, events)
+			finished[job.ID] = job
+		}
+
+		for _, queued := range []*model.ReportJob{first, second} {
+			job := finished[queued.ID]
+			assert.Equal(t, model.ReportJobStatusDone, job.Status)
+			assert.Equal(t, 3, job.Completed)
+			assert.Equal(t, 3, job.Total)
+			assert.Equal(t, filepath.Join(dir, queued.ID, "schoolreports.zip"), job.ArchivePath)
+
+			found, archive, err := s.Open(queued.ID)
+			assert.Nil(t, err)
+			content, _ := ioutil.ReadAll(archive)
+			archive.Close()
+			assert.Equal(t, queued.SchoolID, string(content))
+			assert.Equal(t, queued.SchoolID+".zip", found.FileName)
+		}
+	})
+
+	t.Run("FailedJob", func(t *testing.T) {
+		s, bus, dir := newTestReportJobService(t, func(schoolID string, language string, dir string, progress func(completed, total int)) (string, string, error) {
+			return "", "", errors.New("disk full")
+		}, 1)
+		defer os.RemoveAll(dir)
+		events, unsubscribe := bus.Subscribe()
+		defer unsubscribe()
+		s.start(1)
+
+		queued, err := s.Enqueue("school", model.DefaultLanguage, nil, nil)
+		assert.Nil(t, err)
+
+		job := waitForReport(t, events)
+		assert.Equal(t, model.ReportJobStatusFailed, job.Status)
+		assert.NotEmpty(t, job.Error)
+		assert.NotNil(t, job.ExpiresAt)
+
+		_, _, err = s.Open(queued.ID)
+		assert.Equal(t, model.ErrorCodeConflict, model.AsError(err).Code)
+	})
+
+	t.Run("SurveyExport", func(t *testing.T) {
+		s, bus, dir := newTestReportJobService(t, fakeSchoolReport, 1)
+		defer os.RemoveAll(dir)
+		s.export = func(filter model.SurveyExportFilter, scope model.DataScope, dir string, progress func(completed, total int)) (string, string, error) {
+			if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+				return "", "", err
+			}
+			filePath := filepath.Join(dir, "export.csv")
+			return filePath, "surveys.csv", ioutil.WriteFile(filePath, []byte(scope.SurveyorID), 0644)
+		}
+		events, unsubscribe := bus.Subscribe()
+		defer unsubscribe()
+		s.start(1)
+
+		_, err := s.EnqueueExport(model.SurveyExportFilter{Format: "PDF"}, model.DataScope{}, nil, nil)
+		assert.Equal(t, model.ErrorCodeValidationFailed, model.AsError(err).Code)
+
+		requestedBy := "surveyor"
+		queued, err := s.EnqueueExport(model.SurveyExportFilter{Format: model.ExportFormatCSV}, model.DataScope{SurveyorID: requestedBy}, nil, &requestedBy)
+		assert.Nil(t, err)
+		assert.Equal(t, model.ReportJobKindSurveyExport, queued.Kind)
+
+		job := waitForReport(t, events)
+		assert.Equal(t, model.ReportJobStatusDone, job.Status)
+		assert.True(t, job.CanDownload(requestedBy))
+		assert.False(t, job.CanDownload("someone else"))
+
+		found, file, err := s.Open(queued.ID)
+		assert.Nil(t, err)
+		content, _ := ioutil.ReadAll(file)
+		file.Close()
+		assert.Equal(t, requestedBy, string(content))
+		assert.Equal(t, "text/csv; charset=utf-8", found.ContentType())
+	})
+
+	t.Run("ClientJobID", func(t *testing.T) {
+		s, _, dir := newTestReportJobService(t, fakeSchoolReport, 4)
+		defer os.RemoveAll(dir)
+
+		jobID := "5f1b9c2e-8a43-4d7b-9e61-2c0d7a4b3f18"
+		job, err := s.Enqueue("school", model.DefaultLanguage, &jobID, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, jobID, job.ID)
+
+		_, err = s.Enqueue("school", model.DefaultLanguage, &jobID, nil)
+		assert.Equal(t, model.ErrorCodeConflict, model.AsError(err).Code)
+
+		invalid := "not-a-uuid"
+		_, err = s.Enqueue("school", model.DefaultLanguage, &invalid, nil)
+		assert.Equal(t, model.ErrorCodeValidationFailed, model.AsError(err).Code)
+	})
+
+	t.Run("BoundedQueue", func(t *testing.T) {
+		s, _, dir := newTestReportJobService(t, fakeSchoolReport, 1)
+		defer os.RemoveAll(dir)
+
+		_, err := s.Enqueue("first", model.DefaultLanguage, nil, nil)
+		assert.Nil(t, err)
+		_, err = s.Enqueue("second", model.DefaultLanguage, nil, nil)
+		assert.Equal(t, model.ErrorCodeConflict, model.AsError(err).Code)
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		s, bus, dir := newTestReportJobService(t, fakeSchoolReport, 1)
+		defer os.RemoveAll(dir)
+		events, unsubscribe := bus.Subscribe()
+		defer unsubscribe()
+		s.start(1)
+
+		queued, err := s.Enqueue("school", model.DefaultLanguage, nil, nil)
+		assert.Nil(t, err)
+		waitForReport(t, events)
+
+		s.expire()
+		_, err = s.FindByID(queued.ID)
+		assert.Nil(t, err)
+
+		s.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		s.expire()
+		_, err = s.FindByID(queued.ID)
+		assert.Equal(t, model.ErrorCodeNotFound, model.AsError(err).Code)
+		_, err = os.Stat(filepath.Join(dir, queued.ID))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("RemovesLeftoverJobs", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "reports")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+
+		leftover := filepath.Join(dir, "5f1b9c2e-8a43-4d7b-9e61-2c0d7a4b3f18")
+		other := filepath.Join(dir, "keep")
+		assert.Nil(t, os.MkdirAll(leftover, os.ModePerm))
+		assert.Nil(t, os.MkdirAll(other, os.ModePerm))
+
+		_, err = newReportJobService(fakeSchoolReport, NewEventBus(authService.log), dir, 1, time.Hour, authService.log)
+		assert.Nil(t, err)
+
+		_, err = os.Stat(leftover)
+		assert.True(t, os.IsNotExist(err))
+		_, err = os.Stat(other)
+		assert.Nil(t, err)
+	})
+}
@@ -3,15 +3,18 @@ package service
 import (
 	"archive/zip"
 	"bytes"
+	"database/sql"
 	"encoding/base64"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
-	"sync"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/johnfercher/maroto/pkg/color"
 	"github.com/johnfercher/maroto/pkg/consts"
 	"github.com/johnfercher/maroto/pkg/pdf"
 	"github.com/johnfercher/maroto/pkg/props"
@@ -22,117 +25,300 @@ import (
 	"github.com/wcharczuk/go-chart/v2/drawing"
 )
 
+const (
+	// reportPhotosPerRow is the number of clinical photos printed side by side
+	// in the survey report
+	reportPhotosPerRow = 3
+	// reportLogoHeight is the height of the header row with a template logo
+	reportLogoHeight = 20
+	// reportVerificationHeight is the height of the row with the verification
+	// QR code
+	reportVerificationHeight = 30
+)
+
 type ReportService struct {
-	db  *sqlx.DB
-	log *logging.Logger
+	db                        *sqlx.DB
+	odontogramService         *OdontogramService
+	photoService              *PhotoService
+	historyService            *HistoryService
+	recommendationService     *RecommendationService
+	reportTemplateService     *ReportTemplateService
+	reportVerificationService *ReportVerificationService
+	log                       *logging.Logger
 }
 
-func NewReportService(db *sqlx.DB, log *logging.Logger) *ReportService {
-	return &ReportService{db: db, log: log}
+func NewReportService(db *sqlx.DB, odontogramService *OdontogramService, photoService *PhotoService, historyService *HistoryService, recommendationService *RecommendationService, reportTemplateService *ReportTemplateService, reportVerificationService *ReportVerificationService, log *logging.Logger) *ReportService {
+	return &ReportService{db: db, odontogramService: odontogramService, photoService: photoService, historyService: historyService, recommendationService: recommendationService, reportTemplateService: reportTemplateService, reportVerificationService: reportVerificationService, log: log}
 }
 
+// CostBreakdownBySchoolAndDateRange sums the cost of the actions of the cases
+// surveyed at a school within the date range, as planned and as performed so
+// far, followed by a total row.
 func (s *ReportService) CostBreakdownBySchoolAndDateRange(schoolID string, startDate string, endDate string) ([]*model.CostReport, error) {
-	results := make([]*model.CostReport, 0)
+	analytics, err := s.CostAnalytics(model.CostAnalyticsFilter{
+		SchoolIDs: []string{schoolID},
+		StartDate: startDate,
+		EndDate:   endDate,
+		GroupBy:   []string{model.CostDimensionAction},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*model.CostReport, 0, len(analytics.Groups)+1)
+	for _, g := range analytics.Groups {
+		description := ""
+		if g.Action != nil {
+			description = *g.Action
+		}
+		results = append(results, costReportOf(description, g))
+	}
+	results = append(results, costReportOf("Total", analytics.Total))
+
+	return results, nil
+}
+
+func costReportOf(description string, g *model.CostGroup) *model.CostReport {
+	return &model.CostReport{
+		Description:    description,
+		Cost:           g.Cost,
+		PerformedCost:  g.PerformedCost,
+		PlannedCount:   g.PlannedCount,
+		PerformedCount: g.PerformedCount,
+	}
+}
+
+// riskCategorySQL is the risk category of a survey s in the IDCRA assessment
+// stored with it, joined by riskAssessmentJoinSQL
+const riskCategorySQL = `ra.category`
+
+var riskAssessmentJoinSQL = fmt.Sprintf(`left join survey_risk_assessments ra on ra.survey_id = s.id and ra.model = '%s'`, model.RiskModelIDCRA)
+
+// costDimensionSQL holds the columns selected and the expressions grouped by
+// for every cost dimension
+var costDimensionSQL = map[string]struct {
+	columns string
+	groupBy string
+}{
+	model.CostDimensionSchool:       {"st.school_id school_id, sc.name school_name", "st.school_id, sc.name"},
+	model.CostDimensionMonth:        {"date_format(s.date, '%Y-%m') month", "date_format(s.date, '%Y-%m')"},
+	model.CostDimensionDiagnosis:    {"d.diagnosis diagnosis", "d.diagnosis"},
+	model.CostDimensionAction:       {"d.action action", "d.action"},
+	model.CostDimensionRiskCategory: {riskCategorySQL + " risk_category", riskCategorySQL},
+}
+
+// CostAnalytics sums the cost of the cases of the surveys within the period,
+// leaving out drafts and rejected surveys, grouped by the dimensions of the
+// filter in their order. Every group also sums the cost of its outstanding
+// cases, which is only reported when a projection is asked for.
+func (s *ReportService) CostAnalytics(filter model.CostAnalyticsFilter) (*model.CostAnalytics, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, 0, len(filter.GroupBy))
+	groupBy := make([]string, 0, len(filter.GroupBy))
+	for _, dimension := range filter.GroupBy {
+		columns = append(columns, costDimensionSQL[dimension].columns+",")
+		groupBy = append(groupBy, costDimensionSQL[dimension].groupBy)
+	}
 
 	reportSQL := `
 	select
-		d.action description,
-		sum(d.unit_cost) cost
+		` + strings.Join(columns, "\n\t\t") + `
+		coalesce(sum(d.unit_cost), 0) cost,
+		coalesce(sum(d.unit_cost * coalesce(t.performed, 0)), 0) performed_cost,
+		count(c.id) planned_count,
+		coalesce(sum(coalesce(t.performed, 0)), 0) performed_count,
+		coalesce(sum(case when c.treatment_status in (?) then d.unit_cost else 0 end), 0) outstanding_cost,
+		coalesce(sum(case when c.treatment_status in (?) then 1 else 0 end), 0) outstanding_count
 	from
 		cases c
-		left join surveys s on c.survey_id = s.id
-		left join students st on s.student_id = st.id
-		left join diagnosis_and_actions d on c.diagnosis_and_action_id = d.id
+		join surveys s on c.survey_id = s.id
+		join students st on s.student_id = st.id
+		join schools sc on st.school_id = sc.id
+		join diagnosis_and_actions d on c.diagnosis_and_action_id = d.id
+		left join (
+			select case_id, count(*) performed
+			from case_treatments
+			where status in (?, ?)
+			group by case_id
+		) t on t.case_id = c.id
+		` + riskAssessmentJoinSQL + `
 	where
-		st.school_id = ?
+		s.status not in (?, ?)
 		and s.date >= ?
-		and s.date < ?
+		and s.date < ?`
+	args := []interface{}{
+		model.OutstandingTreatmentStatuses,
+		model.OutstandingTreatmentStatuses,
+		model.TreatmentStatusPerformed, model.TreatmentStatusRedone,
+		model.SurveyStatusDraft, model.SurveyStatusRejected,
+		filter.StartDate, filter.EndDate,
+	}
+	if len(filter.SchoolIDs) > 0 {
+		reportSQL += `
+		and st.school_id in (?)`
+		args = append(args, filter.SchoolIDs)
+	}
+	if len(groupBy) > 0 {
+		reportSQL += `
 	group by
-		d.action;`
+		` + strings.Join(groupBy, ", ") + `
+	order by
+		` + strings.Join(groupBy, ", ")
+	}
 
-	err := s.db.Select(&results, reportSQL, schoolID, startDate, endDate)
+	reportSQL, args, err := sqlx.In(reportSQL, args...)
 	if err != nil {
 		return nil, err
 	}
 
-	var totalCost float64
-	for _, r := range results {
-		totalCost += r.Cost
+	groups := make([]*model.CostGroup, 0)
+	if err := s.db.Select(&groups, s.db.Rebind(reportSQL), args...); err != nil {
+		s.log.Errorf("Error in retrieving cost analytics : %v", err)
+		return nil, err
+	}
+
+	return model.NewCostAnalytics(groups, filter.ProjectOutstanding), nil
+}
+
+// GenerateSchoolReport prints the report of every approved survey of the
+// students of a school in a language into an archive in dir, calling progress
+// after every report. It returns the path of the archive and the name to
+// download it as.
+func (s *ReportService) GenerateSchoolReport(schoolID string, language string, dir string, progress func(completed, total int)) (archivePath string, fileName string, err error) {
+	fileName, reports, err := s.FindSchoolReports(schoolID)
+	if err != nil {
+		return "", "", err
 	}
 
-	summary := &model.CostReport{
-		Description: "Total",
-		Cost:        totalCost,
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", "", err
+	}
+	archivePath = filepath.Join(dir, "schoolreports.zip")
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return "", "", err
 	}
+	defer f.Close()
 
-	results = append(results, summary)
+	if err = s.WriteSchoolReport(schoolID, reports, language, f, progress); err != nil {
+		return "", "", err
+	}
 
-	return results, nil
+	return archivePath, fileName, f.Close()
 }
 
-func (s *ReportService) GenerateSchoolReport(schoolId string) (err error) {
-	models := []model.SchoolReports{}
-	reportSQL := `select s.id, students.name, s.date from students join surveys s on students.id = s.student_id where school_id = ?;`
-
-	err = s.db.Select(&models, reportSQL, schoolId)
+// FindSchoolReports returns the approved surveys of the students of a school,
+// named as they are kept in the report archive, with the name of the archive.
+func (s *ReportService) FindSchoolReports(schoolID string) (string, []*model.SchoolReports, error) {
+	var schoolName string
+	err := s.db.Get(&schoolName, `select name from schools where id = ?`, schoolID)
+	if err == sql.ErrNoRows {
+		return "", nil, model.NewNotFoundError("school", schoolID)
+	}
 	if err != nil {
-		return err
+		return "", nil, err
 	}
 
-	var wg sync.WaitGroup
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
+	reports := make([]*model.SchoolReports, 0)
+	reportSQL := `
+		select s.id, s.student_id, students.name, s.date
+		from students join surveys s on students.id = s.student_id
+		where school_id = ? and s.status = ?;`
 
-		for _, val := range models {
-			id, err := uuid.FromString(val.ID)
-			if err != nil {
-				return
-			}
+	err = s.db.Select(&reports, reportSQL, schoolID, model.SurveyStatusApproved)
+	if err != nil {
+		return "", nil, err
+	}
+	model.NameSchoolReports(reports)
 
-			reportByte, err := s.GenerateSurveyPDF(id)
-			if err != nil {
-				return
-			}
+	return fmt.Sprintf("%s.zip", schoolName), reports, nil
+}
 
-			err = os.Mkdir(fmt.Sprintf("./tmp/%s", val.Name), os.ModePerm)
-			f, err := os.Create(fmt.Sprintf("./tmp/%s/%s.pdf", val.Name, val.Date.Format("2006-01-02")))
+// WriteSchoolReport prints the surveys of a school in a language into a zip
+// archive written to w, one report at a time, calling progress after every
+// report. When w can be flushed, it is flushed after every report so the
+// archive is sent as it is printed. The template of the school is loaded once,
+// for all the reports.
+func (s *ReportService) WriteSchoolReport(schoolID string, reports []*model.SchoolReports, language string, w io.Writer, progress func(completed, total int)) error {
+	template, err := s.reportTemplateService.FindForSchool(schoolID)
+	if err != nil {
+		return err
+	}
 
-			defer f.Close()
-			if err != nil {
-				return
-			}
+	archive := zip.NewWriter(w)
+	flusher, canFlush := w.(http.Flusher)
 
-			_, err = f.Write(reportByte.Bytes())
-			if err != nil {
-				return
-			}
+	for i, report := range reports {
+		id, err := uuid.FromString(report.ID)
+		if err != nil {
+			return err
+		}
 
+		reportData, err := s.GenerateSurveyPDF(id, SurveyPDFOptions{Language: language, template: template, templateLoaded: true})
+		if err != nil {
+			return err
 		}
-	}()
 
-	wg.Wait()
+		entry, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     report.FileName,
+			Method:   zip.Deflate,
+			Modified: report.Date,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := entry.Write(reportData.Bytes()); err != nil {
+			return err
+		}
 
-	err = zipSource("./tmp/", "schoolreports.zip")
+		if canFlush {
+			if err := archive.Flush(); err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+		progress(i+1, len(reports))
+	}
 
-	permission := os.FileMode(0755)
-	os.Chmod("./schoolreports.zip", permission)
+	return archive.Close()
+}
 
-	return err
+// SurveyPDFOptions chooses how the report of a survey is printed
+type SurveyPDFOptions struct {
+	// IncludePhotos adds the photos marked for the report
+	IncludePhotos bool
+	// Preview prints surveys that are not approved yet, marked as a preview
+	Preview bool
+	// Language is the language of the report, the default language when empty
+	Language string
+
+	// template is the template of the school when templateLoaded, so reports
+	// of the same school do not load it again
+	template       *model.ReportTemplate
+	templateLoaded bool
 }
 
-func (s *ReportService) GenerateSurveyPDF(surveyID uuid.UUID) (reportData bytes.Buffer, err error) {
+// GenerateSurveyPDF prints the report of a survey. Only approved surveys are
+// printed, unless a preview is asked for.
+func (s *ReportService) GenerateSurveyPDF(surveyID uuid.UUID, options SurveyPDFOptions) (reportData bytes.Buffer, err error) {
 	models := []model.SurveyReport{}
 	reportSQL := `
 		select
+			s.student_id studentid,
 			student.name studentname,
+			student.school_id schoolid,
 			school.name schoolname,
 			s.date dateofsurvey,
 			s.subjective_score scapercentage,
 			s.upper_d dvalue,
 			s.upper_m mvalue,
-			s.upper_f fvalue
+			s.upper_f fvalue,
+			s.status status,
+			student.date_of_birth dateofbirth,
+			s.recommendation_version approvedrecommendationversion
 		from
 			surveys s
 			left join students student
@@ -149,34 +335,182 @@ func (s *ReportService) GenerateSurveyPDF(surveyID uuid.UUID) (reportData bytes.
 	println(fmt.Sprintf("MODEL: %v", models))
 
 	if len(models) != 1 {
-		return *bytes.NewBufferString(""), err
+		return *bytes.NewBufferString(""), model.NewNotFoundError("survey", surveyID.String())
 	}
 
 	modelReport := models[0]
-	modelReport.Setup()
+	modelReport.Language = options.Language
+	if modelReport.Status != model.SurveyStatusApproved {
+		if !options.Preview {
+			return *bytes.NewBufferString(""), model.NewForbiddenError(fmt.Sprintf("survey %s is %s, only approved surveys can be printed without a preview", surveyID, modelReport.Status))
+		}
+		modelReport.Preview = true
+	}
+
+	// Reports keep the recommendations of the rules the survey was approved
+	// with, previews follow the latest rules
+	ruleSet, err := s.recommendationService.FindByVersion(modelReport.ApprovedRecommendationVersion)
+	if err != nil {
+		return *bytes.NewBufferString(""), err
+	}
+	facts, err := s.recommendationService.FindFacts(surveyID.String())
+	if err != nil {
+		return *bytes.NewBufferString(""), err
+	}
+	age := model.AgeAt(modelReport.DateOfBirth, modelReport.DateOfSurvey)
+	facts.Age = &age
+	modelReport.Setup(ruleSet, facts)
+
+	modelReport.Odontogram, err = s.odontogramService.FindBySurveyID(surveyID.String())
+	if err != nil {
+		return *bytes.NewBufferString(""), err
+	}
+
+	history, err := s.historyService.FindByStudentID(modelReport.StudentID)
+	if err != nil {
+		return *bytes.NewBufferString(""), err
+	}
+	modelReport.History = history.Until(surveyID.String())
+
+	if options.IncludePhotos {
+		modelReport.Photos, err = s.photoService.FindForReport(surveyID.String())
+		if err != nil {
+			return *bytes.NewBufferString(""), err
+		}
+	}
+
+	modelReport.Template = options.template
+	if !options.templateLoaded {
+		modelReport.Template, err = s.reportTemplateService.FindForSchool(modelReport.SchoolID)
+		if err != nil {
+			return *bytes.NewBufferString(""), err
+		}
+	}
+
+	// previews are not verifiable, so they cannot pass for approved reports
+	if modelReport.Preview {
+		return getReport(modelReport)
+	}
+
+	verification, err := s.reportVerificationService.New(surveyID.String(), modelReport.Status, model.NewLocalizer(options.Language).Language())
+	if err != nil {
+		return *bytes.NewBufferString(""), err
+	}
+	modelReport.Verification = verification
+
 	reportData, err = getReport(modelReport)
+	if err != nil {
+		return *bytes.NewBufferString(""), err
+	}
+
+	// the report is only handed out once it can be verified
+	verification.Seal(reportData.Bytes())
+	if err = s.reportVerificationService.Record(verification); err != nil {
+		return *bytes.NewBufferString(""), err
+	}
 	return
 }
 
+// GenerateTemplatePreviewPDF prints the report of a made-up survey with a
+// report template, so the template can be checked before schools use it
+func (s *ReportService) GenerateTemplatePreviewPDF(templateID string, language string) (reportData bytes.Buffer, err error) {
+	template, err := s.reportTemplateService.FindByID(templateID)
+	if err != nil {
+		return *bytes.NewBufferString(""), err
+	}
+	if err := s.reportTemplateService.LoadLogo(template); err != nil {
+		return *bytes.NewBufferString(""), err
+	}
+
+	ruleSet, err := s.recommendationService.FindByVersion(nil)
+	if err != nil {
+		return *bytes.NewBufferString(""), err
+	}
+
+	modelReport := model.SampleSurveyReport(language, template)
+	age := model.AgeAt(modelReport.DateOfBirth, modelReport.DateOfSurvey)
+	modelReport.Setup(ruleSet, model.RecommendationFacts{Age: &age})
+
+	return getReport(modelReport)
+}
+
 func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err error) {
 	begin := time.Now()
+	l := model.NewLocalizer(reportModel.Language)
 
 	m := pdf.NewMaroto(consts.Portrait, consts.A4)
 	m.SetPageMargins(15, 15, 10)
 
+	template := reportModel.Template
+	primary, accent := reportColors(template)
+	title := l.T("report.title")
+	if template != nil && template.Title != nil && *template.Title != "" {
+		title = *template.Title
+	}
+
 	m.RegisterHeader(func() {
-		// meh
+		if template == nil {
+			return
+		}
+		lines := model.TextLines(template.HeaderText)
+		if len(template.Logo) == 0 && len(lines) == 0 {
+			return
+		}
+		height := float64(4*len(lines) + 2)
+		if len(template.Logo) > 0 && height < reportLogoHeight {
+			height = reportLogoHeight
+		}
+		m.Row(height, func() {
+			m.Col(3, func() {
+				if len(template.Logo) == 0 {
+					return
+				}
+				extension := consts.Png
+				if template.LogoContentType != nil && *template.LogoContentType == model.PhotoContentTypeJPEG {
+					extension = consts.Jpg
+				}
+				m.Base64Image(base64.StdEncoding.EncodeToString(template.Logo), extension, props.Rect{
+					Percent: 100,
+				})
+			})
+			m.Col(9, func() {
+				for i, line := range lines {
+					m.Text(line, props.Text{
+						Size:  8,
+						Top:   float64(4 * i),
+						Align: consts.Right,
+						Color: accent,
+					})
+				}
+			})
+		})
 	})
 
 	m.RegisterFooter(func() {
-		// meh
+		if template == nil {
+			return
+		}
+		for _, line := range model.TextLines(template.FooterText) {
+			line := line
+			m.Row(4, func() {
+				m.Col(12, func() {
+					m.Text(line, props.Text{
+						Size:  7,
+						Style: consts.Italic,
+						Align: consts.Center,
+						Color: accent,
+					})
+				})
+			})
+		}
 	})
 
 	// REPORT TITLE
 	m.Row(9, func() {
 		m.Col(12, func() {
-			m.Text("Laporan Survey IDCRA", props.Text{
+			m.Text(title, props.Text{
 				Size:  16,
+				Color: primary,
 				Top:   0,
 				Style: consts.Bold,
 				Align: consts.Center,
@@ -184,11 +518,38 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 		})
 	})
 
+	if reportModel.Preview {
+		m.Row(6, func() {
+			m.Col(12, func() {
+				m.Text(l.T("report.preview", l.T("status."+reportModel.Status)), props.Text{
+					Size:  10,
+					Top:   0,
+					Style: consts.Italic,
+					Align: consts.Center,
+				})
+			})
+		})
+	}
+
+	if reportModel.Sample {
+		m.Row(6, func() {
+			m.Col(12, func() {
+				m.Text(l.T("template.sample.notice"), props.Text{
+					Size:  10,
+					Top:   0,
+					Style: consts.Italic,
+					Align: consts.Center,
+				})
+			})
+		})
+	}
+
 	// REPORT IDENTITY
 	m.Row(6, func() {
 		m.Col(12, func() {
-			m.Text("Identitas", props.Text{
+			m.Text(l.T("report.identity"), props.Text{
 				Size:  12,
+				Color: primary,
 				Top:   0,
 				Style: consts.Bold,
 				Align: consts.Center,
@@ -197,7 +558,7 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 	})
 	m.Row(6, func() {
 		m.Col(3, func() {
-			m.Text("Nama Siswa", props.Text{
+			m.Text(l.T("report.studentName"), props.Text{
 				Top:   1,
 				Style: consts.Bold,
 				Align: consts.Left,
@@ -212,7 +573,7 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 	})
 	m.Row(6, func() {
 		m.Col(3, func() {
-			m.Text("Nama Sekolah", props.Text{
+			m.Text(l.T("report.schoolName"), props.Text{
 				Top:   1,
 				Style: consts.Bold,
 				Align: consts.Left,
@@ -227,14 +588,14 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 	})
 	m.Row(6, func() {
 		m.Col(3, func() {
-			m.Text("Tanggal Survey", props.Text{
+			m.Text(l.T("report.surveyDate"), props.Text{
 				Top:   1,
 				Style: consts.Bold,
 				Align: consts.Left,
 			})
 		})
 		m.Col(9, func() {
-			m.Text(reportModel.DateOfSurvey.Format("02 January 2006"), props.Text{
+			m.Text(l.FormatDate(reportModel.DateOfSurvey), props.Text{
 				Top:   1,
 				Align: consts.Left,
 			})
@@ -244,8 +605,9 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 	// REPORT GRAPHS
 	m.Row(12, func() {
 		m.Col(12, func() {
-			m.Text("Grafik Hasil Survey", props.Text{
+			m.Text(l.T("report.charts"), props.Text{
 				Size:  12,
+				Color: primary,
 				Top:   6,
 				Style: consts.Bold,
 				Align: consts.Center,
@@ -255,7 +617,7 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 
 	m.Row(45, func() {
 		m.Col(4, func() {
-			scaChart, err := getSCAPercentageChart(reportModel.SCAPercentage)
+			scaChart, err := getSCAPercentageChart(l, reportModel.SCAPercentage)
 			if err == nil {
 				m.Base64Image(scaChart, consts.Png)
 			}
@@ -268,11 +630,151 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 		})
 	})
 
+	// ODONTOGRAM
+	if reportModel.Odontogram != nil {
+		odontogramChart, err := getOdontogramChart(l, reportModel.Odontogram)
+		if err != nil {
+			return reportData, err
+		}
+
+		m.Row(12, func() {
+			m.Col(12, func() {
+				m.Text(l.T("report.odontogram"), props.Text{
+					Size:  12,
+					Color: primary,
+					Top:   6,
+					Style: consts.Bold,
+					Align: consts.Center,
+				})
+			})
+		})
+
+		m.Row(75, func() {
+			m.Col(12, func() {
+				m.Base64Image(odontogramChart, consts.Png)
+			})
+		})
+	}
+
+	// HISTORY
+	if reportModel.History != nil && len(reportModel.History.Visits) > 1 {
+		visits := reportModel.History.Visits
+		latest := visits[len(visits)-1]
+
+		scoreChart, err := getHistoryScoreChart(l, visits)
+		if err != nil {
+			return reportData, err
+		}
+		dmfChart, err := getHistoryDMFChart(visits)
+		if err != nil {
+			return reportData, err
+		}
+
+		m.Row(12, func() {
+			m.Col(12, func() {
+				m.Text(l.T("report.history"), props.Text{
+					Size:  12,
+					Color: primary,
+					Top:   6,
+					Style: consts.Bold,
+					Align: consts.Center,
+				})
+			})
+		})
+
+		m.Row(45, func() {
+			m.Col(6, func() {
+				m.Base64Image(scoreChart, consts.Png)
+			})
+			m.Col(6, func() {
+				m.Base64Image(dmfChart, consts.Png)
+			})
+		})
+
+		historyRows := []struct {
+			label string
+			value string
+		}{
+			{l.T("report.history.previousVisit"), l.T("report.history.daysAgo", latest.Change.DaysSincePrevious)},
+			{l.T("report.history.scoreChange"), fmt.Sprintf("%+d", latest.Change.SubjectiveScore)},
+			{l.T("report.history.indexChange"), fmt.Sprintf("%+d / %+d", latest.Change.Indices.DMFT(), latest.Change.Indices.DEFT())},
+			{l.T("report.history.newCaries"), formatTeeth(latest.NewLesions)},
+			{l.T("report.history.resolvedCaries"), formatTeeth(latest.ResolvedLesions)},
+		}
+
+		for _, row := range historyRows {
+			row := row
+			m.Row(6, func() {
+				m.Col(4, func() {
+					m.Text(row.label, props.Text{
+						Top:   1,
+						Style: consts.Bold,
+						Align: consts.Left,
+					})
+				})
+				m.Col(8, func() {
+					m.Text(row.value, props.Text{
+						Top:   1,
+						Align: consts.Left,
+					})
+				})
+			})
+		}
+	}
+
+	// PHOTOS
+	if len(reportModel.Photos) > 0 {
+		m.Row(12, func() {
+			m.Col(12, func() {
+				m.Text(l.T("report.photos"), props.Text{
+					Size:  12,
+					Color: primary,
+					Top:   6,
+					Style: consts.Bold,
+					Align: consts.Center,
+				})
+			})
+		})
+
+		for i := 0; i < len(reportModel.Photos); i += reportPhotosPerRow {
+			end := i + reportPhotosPerRow
+			if end > len(reportModel.Photos) {
+				end = len(reportModel.Photos)
+			}
+			photos := reportModel.Photos[i:end]
+
+			m.Row(50, func() {
+				for _, photo := range photos {
+					photo := photo
+					m.Col(12/reportPhotosPerRow, func() {
+						m.Base64Image(base64.StdEncoding.EncodeToString(photo.JPEG), consts.Jpg, props.Rect{
+							Center:  true,
+							Percent: 95,
+						})
+					})
+				}
+			})
+			m.Row(6, func() {
+				for _, photo := range photos {
+					photo := photo
+					m.Col(12/reportPhotosPerRow, func() {
+						m.Text(photo.Caption, props.Text{
+							Top:   1,
+							Size:  8,
+							Align: consts.Center,
+						})
+					})
+				}
+			})
+		}
+	}
+
 	// OPERATOR'S SUGGESTION
 	m.Row(6, func() {
 		m.Col(12, func() {
-			m.Text("Operator's Suggestions", props.Text{
+			m.Text(l.T("report.operator"), props.Text{
 				Size:  12,
+				Color: primary,
 				Top:   0,
 				Style: consts.Bold,
 				Align: consts.Center,
@@ -282,7 +784,7 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 
 	m.Row(6, func() {
 		m.Col(3, func() {
-			m.Text("RECURRING", props.Text{
+			m.Text(l.T("report.operator.recurring"), props.Text{
 				Top:   1,
 				Style: consts.Bold,
 				Align: consts.Left,
@@ -298,7 +800,7 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 
 	m.Row(6, func() {
 		m.Col(3, func() {
-			m.Text("FLUORIDE", props.Text{
+			m.Text(l.T("report.operator.fluoride"), props.Text{
 				Top:   1,
 				Style: consts.Bold,
 				Align: consts.Left,
@@ -314,7 +816,7 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 
 	m.Row(6, func() {
 		m.Col(3, func() {
-			m.Text("DIET", props.Text{
+			m.Text(l.T("report.operator.diet"), props.Text{
 				Top:   1,
 				Style: consts.Bold,
 				Align: consts.Left,
@@ -330,7 +832,7 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 
 	m.Row(6, func() {
 		m.Col(3, func() {
-			m.Text("SEALANT", props.Text{
+			m.Text(l.T("report.operator.sealant"), props.Text{
 				Top:   1,
 				Style: consts.Bold,
 				Align: consts.Left,
@@ -346,7 +848,7 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 
 	m.Row(6, func() {
 		m.Col(3, func() {
-			m.Text("ART", props.Text{
+			m.Text(l.T("report.operator.art"), props.Text{
 				Top:   1,
 				Style: consts.Bold,
 				Align: consts.Left,
@@ -363,8 +865,9 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 	// PARENT'S SUGGESTION
 	m.Row(12, func() {
 		m.Col(12, func() {
-			m.Text("Parent's Suggestions", props.Text{
+			m.Text(l.T("report.parent"), props.Text{
 				Size:  12,
+				Color: primary,
 				Top:   12,
 				Style: consts.Bold,
 				Align: consts.Center,
@@ -374,7 +877,7 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 
 	m.Row(10, func() {
 		m.Col(12, func() {
-			m.Text("Reminder", props.Text{
+			m.Text(l.T("report.reminder"), props.Text{
 				Size:  10,
 				Top:   6,
 				Style: consts.Bold,
@@ -396,7 +899,7 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 
 	m.Row(10, func() {
 		m.Col(12, func() {
-			m.Text("Guidance", props.Text{
+			m.Text(l.T("report.guidance"), props.Text{
 				Size:  10,
 				Top:   6,
 				Style: consts.Bold,
@@ -418,7 +921,7 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 
 	m.Row(10, func() {
 		m.Col(12, func() {
-			m.Text("Supervision", props.Text{
+			m.Text(l.T("report.supervision"), props.Text{
 				Size:  10,
 				Top:   6,
 				Style: consts.Bold,
@@ -441,8 +944,9 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 	// TEACHER'S SUGGESTION
 	m.Row(12, func() {
 		m.Col(12, func() {
-			m.Text("Teacher's Suggestions", props.Text{
+			m.Text(l.T("report.teacher"), props.Text{
 				Size:  12,
+				Color: primary,
 				Top:   12,
 				Style: consts.Bold,
 				Align: consts.Center,
@@ -452,7 +956,7 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 
 	m.Row(10, func() {
 		m.Col(12, func() {
-			m.Text("Reminder", props.Text{
+			m.Text(l.T("report.reminder"), props.Text{
 				Size:  10,
 				Top:   6,
 				Style: consts.Bold,
@@ -474,7 +978,7 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 
 	m.Row(10, func() {
 		m.Col(12, func() {
-			m.Text("Guidance", props.Text{
+			m.Text(l.T("report.guidance"), props.Text{
 				Size:  10,
 				Top:   6,
 				Style: consts.Bold,
@@ -494,14 +998,73 @@ func getReport(reportModel model.SurveyReport) (reportData bytes.Buffer, err err
 		})
 	}
 
+	m.Row(8, func() {
+		m.Col(12, func() {
+			m.Text(l.T("report.recommendationVersion", reportModel.RecommendationVersion), props.Text{
+				Size:  8,
+				Top:   4,
+				Style: consts.Italic,
+				Align: consts.Right,
+			})
+		})
+	})
+
+	// VERIFICATION
+	if verification := reportModel.Verification; verification != nil {
+		m.Row(reportVerificationHeight, func() {
+			m.Col(3, func() {
+				m.QrCode(verification.URL, props.Rect{
+					Center:  true,
+					Percent: 90,
+				})
+			})
+			m.Col(9, func() {
+				m.Text(l.T("report.verification"), props.Text{
+					Size:  10,
+					Top:   4,
+					Style: consts.Bold,
+					Align: consts.Left,
+					Color: primary,
+				})
+				m.Text(l.T("report.verification.code", verification.FormattedCode()), props.Text{
+					Size:  10,
+					Top:   10,
+					Align: consts.Left,
+				})
+				m.Text(l.T("report.verification.url", verification.URL), props.Text{
+					Size:  8,
+					Top:   16,
+					Align: consts.Left,
+				})
+				m.Text(l.T("report.verification.generatedAt", l.FormatDate(verification.GeneratedAt)), props.Text{
+					Size:  8,
+					Top:   21,
+					Style: consts.Italic,
+					Align: consts.Left,
+				})
+			})
+		})
+	}
+
 	end := time.Now()
 	fmt.Println(end.Sub(begin))
 	return m.Output()
 }
 
-func getSCAPercentageChart(riskPercentage float64) (chartAsBase64 string, err error) {
+// reportColors returns the primary and accent colours of a report template,
+// black for reports without a template
+func reportColors(template *model.ReportTemplate) (primary color.Color, accent color.Color) {
+	if template == nil {
+		return
+	}
+	primary.Red, primary.Green, primary.Blue = model.RGB(template.PrimaryColor)
+	accent.Red, accent.Green, accent.Blue = model.RGB(template.AccentColor)
+	return
+}
+
+func getSCAPercentageChart(l *model.Localizer, riskPercentage float64) (chartAsBase64 string, err error) {
 	graph := chart.BarChart{
-		Title: "Subjective Score",
+		Title: l.T("chart.subjectiveScore"),
 		Background: chart.Style{
 			Padding: chart.Box{
 				Top: 40,
@@ -530,7 +1093,7 @@ func getSCAPercentageChart(riskPercentage float64) (chartAsBase64 string, err er
 		Bars: []chart.Value{
 			{
 				Value: riskPercentage,
-				Label: "Risk (%)",
+				Label: l.T("chart.risk"),
 				Style: chart.Style{
 					FillColor:   chart.ColorOrange,
 					StrokeColor: chart.ColorOrange,
@@ -621,58 +1184,161 @@ func getSCADMFChart(D, M, F float64) (chartAsBase64 string, err error) {
 	return
 }
 
-func zipSource(source, target string) error {
-	// 1. Create a ZIP file and zip.Writer
-	f, err := os.Create(target)
-	if err != nil {
-		return err
+func getHistoryScoreChart(l *model.Localizer, visits []*model.StudentVisit) (chartAsBase64 string, err error) {
+	bars := make([]chart.Value, len(visits))
+	for i, visit := range visits {
+		bars[i] = chart.Value{
+			Value: float64(visit.SubjectiveScore),
+			Label: visitLabel(visit),
+			Style: chart.Style{
+				FillColor:   chart.ColorOrange,
+				StrokeColor: chart.ColorOrange,
+			},
+		}
+	}
+
+	graph := chart.BarChart{
+		Title: l.T("chart.subjectiveScore"),
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top: 40,
+			},
+		},
+		YAxis: chart.YAxis{
+			Style: chart.Style{
+				Hidden:      false,
+				StrokeColor: drawing.ColorBlack,
+				StrokeWidth: 1,
+			},
+			Range: &chart.ContinuousRange{
+				Min: 0.0,
+				Max: 100.0,
+			},
+		},
+		XAxis: chart.Style{
+			Hidden:      false,
+			StrokeColor: drawing.ColorBlack,
+			StrokeWidth: 1,
+		},
+		Height:   250,
+		Width:    400,
+		BarWidth: historyBarWidth(len(visits)),
+		Bars:     bars,
 	}
-	defer f.Close()
 
-	writer := zip.NewWriter(f)
-	defer writer.Close()
+	buffer := bytes.NewBuffer([]byte{})
+	err = graph.Render(chart.PNG, buffer)
+	chartAsBase64 = base64.StdEncoding.EncodeToString(buffer.Bytes())
+	return
+}
 
-	// 2. Go through all the files of the source
-	return filepath.Walk(source, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+func getHistoryDMFChart(visits []*model.StudentVisit) (chartAsBase64 string, err error) {
+	highestValue := 1.0
+	dates := make([]time.Time, len(visits))
+	dmft := make([]float64, len(visits))
+	deft := make([]float64, len(visits))
+	for i, visit := range visits {
+		dates[i], _ = time.Parse(time.RFC3339, visit.Date)
+		dmft[i] = float64(visit.Indices.DMFT())
+		deft[i] = float64(visit.Indices.DEFT())
+		if dmft[i] > highestValue {
+			highestValue = dmft[i]
 		}
-
-		// 3. Create a local file header
-		header, err := zip.FileInfoHeader(info)
-		if err != nil {
-			return err
+		if deft[i] > highestValue {
+			highestValue = deft[i]
 		}
+	}
 
-		// set compression
-		header.Method = zip.Deflate
+	graph := chart.Chart{
+		Title: "DMF-T / def-t",
+		TitleStyle: chart.Style{
+			FontSize: 10,
+		},
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top:  40,
+				Left: 20,
+			},
+		},
+		YAxis: chart.YAxis{
+			Style: chart.Style{
+				Hidden:      false,
+				StrokeColor: drawing.ColorBlack,
+				StrokeWidth: 1,
+			},
+			Range: &chart.ContinuousRange{
+				Min: 0.0,
+				Max: highestValue,
+			},
+		},
+		XAxis: chart.XAxis{
+			ValueFormatter: chart.TimeValueFormatterWithFormat("01/06"),
+			Style: chart.Style{
+				Hidden:      false,
+				StrokeColor: drawing.ColorBlack,
+				StrokeWidth: 1,
+			},
+		},
+		Height: 250,
+		Width:  400,
+		Series: []chart.Series{
+			chart.TimeSeries{
+				Name:    "DMF-T",
+				XValues: dates,
+				YValues: dmft,
+				Style: chart.Style{
+					StrokeColor: chart.ColorRed,
+					StrokeWidth: 2,
+					DotColor:    chart.ColorRed,
+					DotWidth:    4,
+				},
+			},
+			chart.TimeSeries{
+				Name:    "def-t",
+				XValues: dates,
+				YValues: deft,
+				Style: chart.Style{
+					StrokeColor: chart.ColorBlue,
+					StrokeWidth: 2,
+					DotColor:    chart.ColorBlue,
+					DotWidth:    4,
+				},
+			},
+		},
+	}
+	graph.Elements = []chart.Renderable{chart.Legend(&graph)}
 
-		// 4. Set relative path of a file as the header name
-		header.Name, err = filepath.Rel(filepath.Dir(source), path)
-		if err != nil {
-			return err
-		}
-		if info.IsDir() {
-			header.Name += "/"
-		}
+	buffer := bytes.NewBuffer([]byte{})
+	err = graph.Render(chart.PNG, buffer)
+	chartAsBase64 = base64.StdEncoding.EncodeToString(buffer.Bytes())
+	return
+}
 
-		// 5. Create writer for the file header and save content of the file
-		headerWriter, err := writer.CreateHeader(header)
-		if err != nil {
-			return err
-		}
+// historyBarWidth narrows the bars of a history chart as visits add up
+func historyBarWidth(bars int) int {
+	width := 300 / bars
+	if width > 70 {
+		return 70
+	}
+	return width
+}
 
-		if info.IsDir() {
-			return nil
-		}
+func visitLabel(visit *model.StudentVisit) string {
+	t, err := time.Parse(time.RFC3339, visit.Date)
+	if err != nil {
+		return visit.Date
+	}
+	return t.Format("01/06")
+}
 
-		f, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
+func formatTeeth(teeth []int32) string {
+	if len(teeth) == 0 {
+		return "-"
+	}
 
-		_, err = io.Copy(headerWriter, f)
-		return err
-	})
+	labels := make([]string, len(teeth))
+	for i, toothNumber := range teeth {
+		labels[i] = fmt.Sprintf("%d", toothNumber)
+	}
+	return strings.Join(labels, ", ")
 }
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnfercher/maroto/pkg/color"
+	"github.com/kerti/idcra-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostDimensionSQL(t *testing.T) {
+	for _, dimension := range model.CostDimensions {
+		assert.Contains(t, costDimensionSQL, dimension)
+	}
+	assert.Len(t, costDimensionSQL, len(model.CostDimensions))
+}
+
+func TestIndicatorDimensionSQL(t *testing.T) {
+	for _, dimension := range model.IndicatorDimensions {
+		column, group, order := indicatorDimensionSQL(dimension, model.IndicatorPeriodYear)
+		assert.NotEmpty(t, column)
+		assert.NotEmpty(t, group)
+		assert.NotEmpty(t, order)
+	}
+	for _, period := range model.IndicatorPeriods {
+		assert.Contains(t, indicatorPeriodSQL, period)
+	}
+	assert.True(t, strings.HasPrefix(ageBandSQL, "case when timestampdiff(year, st.date_of_birth, s.date) between 0 and 5 then '0-5' "))
+	assert.Contains(t, ageBandSQL, "when timestampdiff(year, st.date_of_birth, s.date) >= 15 then '15+' end")
+}
+
+func TestReportColors(t *testing.T) {
+	primary, accent := reportColors(nil)
+	assert.Equal(t, color.NewBlack(), primary)
+	assert.Equal(t, color.NewBlack(), accent)
+
+	primary, accent = reportColors(&model.ReportTemplate{PrimaryColor: "#1a73e8", AccentColor: "#ffffff"})
+	assert.Equal(t, color.Color{Red: 26, Green: 115, Blue: 232}, primary)
+	assert.Equal(t, color.NewWhite(), accent)
+}
+
+func TestGetReportWithTemplate(t *testing.T) {
+	var logo bytes.Buffer
+	png.Encode(&logo, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+	contentType := model.PhotoContentTypePNG
+	title := "Laporan Kesehatan Gigi"
+	header := "Puskesmas Senyum\nJl. Merdeka 1"
+	footer := "Hasil survey bukan diagnosis dokter gigi"
+
+	report := model.SampleSurveyReport(model.LanguageIndonesian, &model.ReportTemplate{
+		Title:           &title,
+		HeaderText:      &header,
+		FooterText:      &footer,
+		PrimaryColor:    "#1a73e8",
+		AccentColor:     "#5f6368",
+		LogoContentType: &contentType,
+		Logo:            logo.Bytes(),
+	})
+
+	data, err := getReport(report)
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(data.Bytes(), []byte("%PDF")))
+}
+
+func TestGetReportWithVerification(t *testing.T) {
+	verification, err := model.NewReportVerification("survey", model.SurveyStatusApproved, model.LanguageEnglish, "https://idcra.example/verify/", time.Now())
+	assert.Nil(t, err)
+
+	report := model.SampleSurveyReport(model.LanguageEnglish, nil)
+	report.Verification = verification
+
+	data, err := getReport(report)
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(data.Bytes(), []byte("%PDF")))
+
+	verification.Seal(data.Bytes())
+	assert.True(t, verification.Matches(data.Bytes()))
+}
@@ -0,0 +1,264 @@
+package service
+
+import (
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kerti/idcra-api/model"
+	"github.com/op/go-logging"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ReportTemplateService keeps the report templates and the organizations they
+// are attached to. Template logos are kept in the storage.
+type ReportTemplateService struct {
+	db      *sqlx.DB
+	storage Storage
+	log     *logging.Logger
+}
+
+func NewReportTemplateService(db *sqlx.DB, storage Storage, log *logging.Logger) *ReportTemplateService {
+	return &ReportTemplateService{db: db, storage: storage, log: log}
+}
+
+func (s *ReportTemplateService) FindAll() ([]*model.ReportTemplate, error) {
+	templates := make([]*model.ReportTemplate, 0)
+	templateSQL := `SELECT * FROM report_templates ORDER BY name ASC`
+
+	if err := s.db.Unsafe().Select(&templates, templateSQL); err != nil {
+		s.log.Errorf("Error in retrieving report templates : %v", err)
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+func (s *ReportTemplateService) FindByID(id string) (*model.ReportTemplate, error) {
+	template := &model.ReportTemplate{}
+
+	templateSQL := `SELECT * FROM report_templates WHERE id = ?`
+	row := s.db.Unsafe().QueryRowx(templateSQL, id)
+	err := row.StructScan(template)
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("report template", id)
+	}
+	if err != nil {
+		s.log.Errorf("Error in retrieving report template : %v", err)
+		return nil, err
+	}
+
+	return template, nil
+}
+
+// FindForSchool returns the template of a school, or of its organization when
+// the school has none, with its logo loaded. It returns nil when neither has a
+// template.
+func (s *ReportTemplateService) FindForSchool(schoolID string) (*model.ReportTemplate, error) {
+	template := &model.ReportTemplate{}
+
+	templateSQL := `
+		SELECT t.*
+		FROM schools sc
+		LEFT JOIN organizations o ON o.id = sc.organization_id
+		JOIN report_templates t ON t.id = COALESCE(sc.report_template_id, o.report_template_id)
+		WHERE sc.id = ?`
+	row := s.db.Unsafe().QueryRowx(templateSQL, schoolID)
+	err := row.StructScan(template)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		s.log.Errorf("Error in retrieving report template : %v", err)
+		return nil, err
+	}
+
+	if err := s.LoadLogo(template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// LoadLogo reads the logo of a template from the storage. A logo missing from
+// the storage is logged and left out so reports can still be printed.
+func (s *ReportTemplateService) LoadLogo(template *model.ReportTemplate) error {
+	if template.LogoKey == nil {
+		return nil
+	}
+
+	logo, err := s.storage.Get(*template.LogoKey)
+	if err == ErrObjectNotFound {
+		s.log.Errorf("Logo of report template %s is missing from storage", template.ID)
+		return nil
+	}
+	if err != nil {
+		s.log.Errorf("Error in reading report template logo : %v", err)
+		return err
+	}
+	template.Logo = logo
+	return nil
+}
+
+// Save creates a template, or changes the template with the ID when one is
+// given. The logo is kept when the input has none and removed when it is
+// empty.
+func (s *ReportTemplateService) Save(id *string, input *model.ReportTemplateInput) (*model.ReportTemplate, error) {
+	var current *model.ReportTemplate
+	templateID := ""
+	if id != nil {
+		var err error
+		if current, err = s.FindByID(*id); err != nil {
+			return nil, err
+		}
+		templateID = current.ID
+	}
+
+	template, logo, err := model.NewReportTemplateFromInput(templateID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// the stored logo to remove once the template no longer refers to it
+	var staleKey *string
+	switch {
+	case logo == nil && current != nil:
+		template.LogoKey = current.LogoKey
+		template.LogoContentType = current.LogoContentType
+	case logo != nil && len(logo) == 0:
+		template.LogoKey = nil
+		template.LogoContentType = nil
+		if current != nil {
+			staleKey = current.LogoKey
+		}
+	case len(logo) > 0:
+		if err := s.storage.Put(*template.LogoKey, logo, *template.LogoContentType); err != nil {
+			s.log.Errorf("Error in storing report template logo : %v", err)
+			return nil, err
+		}
+		if current != nil && current.LogoKey != nil && *current.LogoKey != *template.LogoKey {
+			staleKey = current.LogoKey
+		}
+	}
+
+	templateSQL := `
+		INSERT INTO report_templates
+		(id, name, title, header_text, footer_text, primary_color, accent_color, logo_key, logo_content_type, created_at, updated_at)
+		VALUES
+		(:id, :name, :title, :header_text, :footer_text, :primary_color, :accent_color, :logo_key, :logo_content_type, NOW(), NOW())`
+	if current != nil {
+		templateSQL = `
+			UPDATE report_templates
+			SET
+				name = :name,
+				title = :title,
+				header_text = :header_text,
+				footer_text = :footer_text,
+				primary_color = :primary_color,
+				accent_color = :accent_color,
+				logo_key = :logo_key,
+				logo_content_type = :logo_content_type
+			WHERE id = :id`
+	}
+
+	if _, err := s.db.NamedExec(templateSQL, template); err != nil {
+		s.log.Errorf("Error in saving report template : %v", err)
+		return nil, translateDBError(err)
+	}
+
+	if staleKey != nil {
+		if err := s.storage.Delete(*staleKey); err != nil {
+			s.log.Errorf("Error in removing %s from storage : %v", *staleKey, err)
+		}
+	}
+
+	return s.FindByID(template.ID)
+}
+
+func (s *ReportTemplateService) FindOrganizations() ([]*model.Organization, error) {
+	organizations := make([]*model.Organization, 0)
+	organizationSQL := `SELECT * FROM organizations ORDER BY name ASC`
+
+	if err := s.db.Unsafe().Select(&organizations, organizationSQL); err != nil {
+		s.log.Errorf("Error in retrieving organizations : %v", err)
+		return nil, err
+	}
+
+	return organizations, nil
+}
+
+func (s *ReportTemplateService) FindOrganizationByID(id string) (*model.Organization, error) {
+	organization := &model.Organization{}
+
+	organizationSQL := `SELECT * FROM organizations WHERE id = ?`
+	row := s.db.Unsafe().QueryRowx(organizationSQL, id)
+	err := row.StructScan(organization)
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("organization", id)
+	}
+	if err != nil {
+		s.log.Errorf("Error in retrieving organization : %v", err)
+		return nil, err
+	}
+
+	return organization, nil
+}
+
+func (s *ReportTemplateService) CreateOrganization(name string) (*model.Organization, error) {
+	organization, err := model.NewOrganization(uuid.NewV4().String(), name)
+	if err != nil {
+		return nil, err
+	}
+
+	organizationSQL := `INSERT INTO organizations (id, name, created_at, updated_at) VALUES (:id, :name, NOW(), NOW())`
+	if _, err := s.db.NamedExec(organizationSQL, organization); err != nil {
+		s.log.Errorf("Error in inserting organization : %v", err)
+		return nil, translateDBError(err)
+	}
+
+	return s.FindOrganizationByID(organization.ID)
+}
+
+// SetOrganizationReportTemplate attaches a template to an organization, or
+// detaches its template when templateID is nil
+func (s *ReportTemplateService) SetOrganizationReportTemplate(organizationID string, templateID *string) (*model.Organization, error) {
+	if _, err := s.FindOrganizationByID(organizationID); err != nil {
+		return nil, err
+	}
+
+	organizationSQL := `UPDATE organizations SET report_template_id = ? WHERE id = ?`
+	if _, err := s.db.Exec(organizationSQL, templateID, organizationID); err != nil {
+		s.log.Errorf("Error in updating organization : %v", err)
+		return nil, translateDBError(err)
+	}
+
+	return s.FindOrganizationByID(organizationID)
+}
+
+// SetSchoolOrganization puts a school in an organization, or takes it out of
+// its organization when organizationID is nil
+func (s *ReportTemplateService) SetSchoolOrganization(schoolID string, organizationID *string) error {
+	return s.updateSchool(`UPDATE schools SET organization_id = ? WHERE id = ?`, schoolID, organizationID)
+}
+
+// SetSchoolReportTemplate attaches a template to a school, or detaches its
+// template when templateID is nil so the template of its organization is used
+func (s *ReportTemplateService) SetSchoolReportTemplate(schoolID string, templateID *string) error {
+	return s.updateSchool(`UPDATE schools SET report_template_id = ? WHERE id = ?`, schoolID, templateID)
+}
+
+func (s *ReportTemplateService) updateSchool(schoolSQL string, schoolID string, value *string) error {
+	var exists bool
+	err := s.db.Get(&exists, `SELECT 1 FROM schools WHERE id = ?`, schoolID)
+	if err == sql.ErrNoRows {
+		return model.NewNotFoundError("school", schoolID)
+	}
+	if err != nil {
+		s.log.Errorf("Error in retrieving school : %v", err)
+		return err
+	}
+
+	if _, err := s.db.Exec(schoolSQL, value, schoolID); err != nil {
+		s.log.Errorf("Error in updating school : %v", err)
+		return translateDBError(err)
+	}
+	return nil
+}
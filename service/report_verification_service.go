@@ -0,0 +1,73 @@
+package service
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kerti/idcra-api/model"
+	"github.com/op/go-logging"
+)
+
+// ReportVerificationService records printed survey reports and looks them up
+// by the verification code printed on them
+type ReportVerificationService struct {
+	db      *sqlx.DB
+	baseURL string
+	now     func() time.Time
+	log     *logging.Logger
+}
+
+// NewReportVerificationService returns the service, baseURL being the public
+// address of the verification endpoint printed on reports
+func NewReportVerificationService(db *sqlx.DB, baseURL string, log *logging.Logger) *ReportVerificationService {
+	return &ReportVerificationService{db: db, baseURL: baseURL, now: time.Now, log: log}
+}
+
+// New returns the verification of a survey report about to be printed. It is
+// recorded with Record once the report is printed and its hash known.
+func (s *ReportVerificationService) New(surveyID string, surveyStatus string, language string) (*model.ReportVerification, error) {
+	verification, err := model.NewReportVerification(surveyID, surveyStatus, language, s.baseURL, s.now())
+	if err != nil {
+		s.log.Errorf("Error in creating verification code : %v", err)
+		return nil, err
+	}
+	return verification, nil
+}
+
+func (s *ReportVerificationService) Record(verification *model.ReportVerification) error {
+	verificationSQL := `
+		INSERT INTO report_verifications
+		(code, survey_id, survey_status, language, sha256, generated_at)
+		VALUES
+		(:code, :survey_id, :survey_status, :language, :sha256, :generated_at)`
+
+	if _, err := s.db.NamedExec(verificationSQL, verification); err != nil {
+		s.log.Errorf("Error in inserting report verification : %v", err)
+		return translateDBError(err)
+	}
+	return nil
+}
+
+// FindByCode returns the report printed with a verification code, the code
+// being normalized first
+func (s *ReportVerificationService) FindByCode(code string) (*model.ReportVerification, error) {
+	verification := &model.ReportVerification{}
+
+	verificationSQL := `
+		SELECT v.*, s.date survey_date
+		FROM report_verifications v
+		JOIN surveys s ON s.id = v.survey_id
+		WHERE v.code = ?`
+	row := s.db.Unsafe().QueryRowx(verificationSQL, model.NormalizeVerificationCode(code))
+	err := row.StructScan(verification)
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("report", code)
+	}
+	if err != nil {
+		s.log.Errorf("Error in retrieving report verification : %v", err)
+		return nil, err
+	}
+
+	return verification, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kerti/idcra-api/model"
+	"github.com/op/go-logging"
+)
+
+// RiskService assesses surveys with the enabled risk models.
+type RiskService struct {
+	db     *sqlx.DB
+	models []model.RiskModel
+	log    *logging.Logger
+}
+
+// NewRiskService enables the risk models with the given names, or every
+// registered risk model when no names are given.
+func NewRiskService(db *sqlx.DB, modelNames []string, log *logging.Logger) (*RiskService, error) {
+	if len(modelNames) == 0 {
+		modelNames = model.RiskModelNames()
+	}
+
+	models := make([]model.RiskModel, len(modelNames))
+	for i, name := range modelNames {
+		m, ok := model.FindRiskModel(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown risk model %s, expecting one of %v", name, model.RiskModelNames())
+		}
+		models[i] = m
+	}
+
+	return &RiskService{db: db, models: models, log: log}, nil
+}
+
+// Assess sets the risk assessments of the survey for every enabled model.
+func (r *RiskService) Assess(survey *model.Survey) {
+	survey.RiskAssessments = make([]*model.RiskAssessment, len(r.models))
+	for i, m := range r.models {
+		survey.RiskAssessments[i] = model.Assess(m, survey)
+	}
+}
+
+// PrimaryModel returns the name of the first enabled model, the model risk
+// categories are reported with.
+func (r *RiskService) PrimaryModel() string {
+	return r.models[0].Name()
+}
+
+// FindBySurveyIDs returns the stored risk assessments keyed by survey ID.
+func (r *RiskService) FindBySurveyIDs(ids []string) (map[string][]*model.RiskAssessment, error) {
+	assessmentsBySurvey := make(map[string][]*model.RiskAssessment)
+	if len(ids) == 0 {
+		return assessmentsBySurvey, nil
+	}
+
+	riskSQL, args, err := sqlx.In(`SELECT * FROM survey_risk_assessments WHERE survey_id IN (?) ORDER BY model ASC`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	assessments := make([]*model.RiskAssessment, 0)
+	if err := r.db.Unsafe().Select(&assessments, r.db.Rebind(riskSQL), args...); err != nil {
+		r.log.Errorf("Error in retrieving risk assessments : %v", err)
+		return nil, err
+	}
+
+	for _, assessment := range assessments {
+		assessmentsBySurvey[assessment.SurveyID] = append(assessmentsBySurvey[assessment.SurveyID], assessment)
+	}
+
+	return assessmentsBySurvey, nil
+}
+
+// saveRiskAssessments stores the risk assessments of the survey, replacing
+// earlier assessments by the same models.
+func saveRiskAssessments(tx *sqlx.Tx, survey *model.Survey) error {
+	riskSQL := `
+		INSERT INTO survey_risk_assessments
+		(survey_id, model, score, category, created_at)
+		VALUES
+		(:survey_id, :model, :score, :category, NOW())
+		ON DUPLICATE KEY UPDATE score = VALUES(score), category = VALUES(category)`
+
+	for _, assessment := range survey.RiskAssessments {
+		if _, err := tx.NamedExec(riskSQL, assessment); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3Timeout bounds every request to the storage
+const s3Timeout = 30 * time.Second
+
+// S3Storage keeps objects in a bucket of an S3 compatible storage, such as
+// Amazon S3 or MinIO. Objects are addressed path style, as endpoint/bucket/key,
+// so storages without virtual host buckets work too.
+type S3Storage struct {
+	bucket string
+	client *s3.Client
+}
+
+func NewS3Storage(endpoint, region, bucket, accessKey, secretKey string) (*S3Storage, error) {
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %s", endpoint)
+	}
+	if bucket == "" {
+		return nil, errors.New("S3 bucket is required")
+	}
+
+	client := s3.New(s3.Options{
+		Region:           region,
+		Credentials:      credentials.NewStaticCredentialsProvider(accessKey, secretKey, ""),
+		EndpointResolver: s3.EndpointResolverFromURL(u.String()),
+		UsePathStyle:     true,
+		HTTPClient:       &http.Client{Timeout: s3Timeout},
+	})
+
+	return &S3Storage{bucket: bucket, client: client}, nil
+}
+
+func (s *S3Storage) Put(key string, data []byte, contentType string) error {
+	_, err := s.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(data),
+		ContentLength: int64(len(data)),
+		ContentType:   aws.String(contentType),
+	})
+	return err
+}
+
+func (s *S3Storage) Get(key string) ([]byte, error) {
+	out, err := s.client.GetObject(context.Background(), &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	if isS3NotFound(err) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+
+	return ioutil.ReadAll(out.Body)
+}
+
+func (s *S3Storage) Delete(key string) error {
+	_, err := s.client.DeleteObject(context.Background(), &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
+
+// isS3NotFound reports whether a request failed because the object does not
+// exist. Storages answering a GET without an error body are caught by status.
+func isS3NotFound(err error) bool {
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return true
+	}
+	var statusErr interface{ HTTPStatusCode() int }
+	return errors.As(err, &statusErr) && statusErr.HTTPStatusCode() == http.StatusNotFound
+}
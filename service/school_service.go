@@ -10,12 +10,13 @@ import (
 )
 
 type SchoolService struct {
-	db  *sqlx.DB
-	log *logging.Logger
+	db          *sqlx.DB
+	roleService *RoleService
+	log         *logging.Logger
 }
 
-func NewSchoolService(db *sqlx.DB, log *logging.Logger) *SchoolService {
-	return &SchoolService{db: db, log: log}
+func NewSchoolService(db *sqlx.DB, roleService *RoleService, log *logging.Logger) *SchoolService {
+	return &SchoolService{db: db, roleService: roleService, log: log}
 }
 
 func (s *SchoolService) FindByName(name string) (*model.School, error) {
@@ -44,7 +45,7 @@ func (s *SchoolService) FindByID(id string) (*model.School, error) {
 	row := udb.QueryRowx(schoolSQL, id)
 	err := row.StructScan(school)
 	if err == sql.ErrNoRows {
-		return school, nil
+		return nil, model.NewNotFoundError("school", id)
 	}
 	if err != nil {
 		s.log.Errorf("Error in retrieving school : %v", err)
@@ -60,7 +61,7 @@ func (s *SchoolService) CreateSchool(school *model.School) (*model.School, error
 	schoolSQL := `INSERT INTO schools (id, name) VALUES (:id, :name)`
 	_, err := s.db.NamedExec(schoolSQL, school)
 	if err != nil {
-		return nil, err
+		return nil, translateDBError(err)
 	}
 	return s.FindByID(school.ID)
 }
@@ -101,3 +102,83 @@ func (s *SchoolService) Count() (int, error) {
 	}
 	return count, nil
 }
+
+// FindAccess returns the schools a user works with: the schools they are
+// assigned to and the schools of their students, or every school for admins
+// and supervisors
+func (s *SchoolService) FindAccess(userID string) (model.SchoolAccess, error) {
+	roles, err := s.roleService.FindByUserId(&userID)
+	if err != nil {
+		s.log.Errorf("Error in retrieving roles : %v", err)
+		return model.SchoolAccess{}, err
+	}
+	if model.HasRole(roles, model.RoleAdmin, model.RoleSupervisor) {
+		return model.NewSchoolAccess(roles, nil), nil
+	}
+
+	schoolIDs := make([]string, 0)
+	schoolSQL := `
+		SELECT school_id FROM rel_users_schools WHERE user_id = ?
+		UNION
+		SELECT st.school_id
+		FROM rel_users_students us
+		JOIN students st ON st.id = us.student_id
+		WHERE us.user_id = ?`
+	if err := s.db.Select(&schoolIDs, schoolSQL, userID, userID); err != nil {
+		s.log.Errorf("Error in retrieving schools of user : %v", err)
+		return model.SchoolAccess{}, err
+	}
+
+	return model.NewSchoolAccess(roles, schoolIDs), nil
+}
+
+// AssignUser lets a user work with a school. Assigning a user twice is not an
+// error.
+func (s *SchoolService) AssignUser(userID string, schoolID string) (*model.School, error) {
+	school, err := s.FindByID(schoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignSQL := `
+		INSERT INTO rel_users_schools (user_id, school_id, created_at) VALUES (?, ?, NOW())
+		ON DUPLICATE KEY UPDATE user_id = user_id`
+	if _, err := s.db.Exec(assignSQL, userID, schoolID); err != nil {
+		s.log.Errorf("Error in assigning user to school : %v", err)
+		return nil, translateDBError(err)
+	}
+	return school, nil
+}
+
+// SetTimeZone changes the time zone of a school, or resets it to the default
+// time zone when timeZone is nil
+func (s *SchoolService) SetTimeZone(schoolID string, timeZone *string) (*model.School, error) {
+	if timeZone != nil {
+		if err := model.ValidateTimeZone(*timeZone); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := s.FindByID(schoolID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.Exec(`UPDATE schools SET time_zone = ? WHERE id = ?`, timeZone, schoolID); err != nil {
+		s.log.Errorf("Error in updating school : %v", err)
+		return nil, translateDBError(err)
+	}
+	return s.FindByID(schoolID)
+}
+
+// UnassignUser stops a user from working with a school
+func (s *SchoolService) UnassignUser(userID string, schoolID string) (*model.School, error) {
+	school, err := s.FindByID(schoolID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.Exec(`DELETE FROM rel_users_schools WHERE user_id = ? AND school_id = ?`, userID, schoolID); err != nil {
+		s.log.Errorf("Error in removing user from school : %v", err)
+		return nil, err
+	}
+	return school, nil
+}
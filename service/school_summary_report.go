@@ -0,0 +1,396 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/johnfercher/maroto/pkg/consts"
+	"github.com/johnfercher/maroto/pkg/pdf"
+	"github.com/johnfercher/maroto/pkg/props"
+	"github.com/kerti/idcra-api/model"
+	"github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+)
+
+// FindSchoolSummary sums up the latest approved survey of every student of a
+// school surveyed from startDate until before endDate, both yyyy-mm-dd, with
+// the estimated treatment cost of the period.
+func (s *ReportService) FindSchoolSummary(schoolID string, startDate string, endDate string) (*model.SchoolSummary, error) {
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil, model.NewFieldError("startDate", "invalid date format, expecting yyyy-mm-dd")
+	}
+	end, err := time.Parse("2006-01-02", endDate)
+	if err != nil {
+		return nil, model.NewFieldError("endDate", "invalid date format, expecting yyyy-mm-dd")
+	}
+	if !end.After(start) {
+		return nil, model.NewFieldError("endDate", "end date must be after the start date")
+	}
+
+	var schoolName string
+	err = s.db.Get(&schoolName, `SELECT name FROM schools WHERE id = ?`, schoolID)
+	if err == sql.ErrNoRows {
+		return nil, model.NewNotFoundError("school", schoolID)
+	}
+	if err != nil {
+		s.log.Errorf("Error in retrieving school : %v", err)
+		return nil, err
+	}
+
+	var students int
+	if err := s.db.Get(&students, `SELECT COUNT(*) FROM students WHERE school_id = ?`, schoolID); err != nil {
+		s.log.Errorf("Error in counting students : %v", err)
+		return nil, err
+	}
+
+	surveys := make([]*model.SchoolSummarySurvey, 0)
+	surveySQL := `
+		select
+			s.id,
+			s.student_id,
+			st.date_of_birth,
+			st.sex,
+			s.date,
+			s.subjective_score,
+			s.lower_d,
+			s.lower_e,
+			s.lower_f,
+			s.upper_d,
+			s.upper_m,
+			s.upper_f
+		from
+			surveys s
+			join students st on s.student_id = st.id
+		where
+			st.school_id = ?
+			and s.status = ?
+			and s.date >= ?
+			and s.date < ?;`
+	if err := s.db.Select(&surveys, surveySQL, schoolID, model.SurveyStatusApproved, startDate, endDate); err != nil {
+		s.log.Errorf("Error in retrieving school surveys : %v", err)
+		return nil, err
+	}
+
+	cases := make([]*model.SchoolSummaryCase, 0)
+	caseSQL := `
+		select
+			c.survey_id,
+			d.diagnosis,
+			d.action,
+			d.unit_cost,
+			coalesce(t.performed, 0) performed
+		from
+			cases c
+			join surveys s on c.survey_id = s.id
+			join students st on s.student_id = st.id
+			join diagnosis_and_actions d on c.diagnosis_and_action_id = d.id
+			left join (
+				select case_id, count(*) performed
+				from case_treatments
+				where status in (?, ?)
+				group by case_id
+			) t on t.case_id = c.id
+		where
+			st.school_id = ?
+			and s.status = ?
+			and s.date >= ?
+			and s.date < ?;`
+	err = s.db.Select(&cases, caseSQL, model.TreatmentStatusPerformed, model.TreatmentStatusRedone, schoolID, model.SurveyStatusApproved, startDate, endDate)
+	if err != nil {
+		s.log.Errorf("Error in retrieving school cases : %v", err)
+		return nil, err
+	}
+
+	summary := model.NewSchoolSummary(students, surveys, cases)
+	summary.SchoolName = schoolName
+	summary.StartDate = start
+	summary.EndDate = end
+
+	return summary, nil
+}
+
+// GenerateSchoolSummaryPDF prints the summary of a school for a period in a
+// language
+func (s *ReportService) GenerateSchoolSummaryPDF(schoolID string, startDate string, endDate string, language string) (bytes.Buffer, error) {
+	summary, err := s.FindSchoolSummary(schoolID, startDate, endDate)
+	if err != nil {
+		return *bytes.NewBufferString(""), err
+	}
+	summary.Language = language
+
+	return getSchoolSummaryReport(summary)
+}
+
+func getSchoolSummaryReport(summary *model.SchoolSummary) (bytes.Buffer, error) {
+	l := model.NewLocalizer(summary.Language)
+
+	m := pdf.NewMaroto(consts.Portrait, consts.A4)
+	m.SetPageMargins(15, 15, 10)
+
+	heading := func(text string) {
+		m.Row(10, func() {
+			m.Col(12, func() {
+				m.Text(text, props.Text{
+					Size:  12,
+					Top:   4,
+					Style: consts.Bold,
+					Align: consts.Left,
+				})
+			})
+		})
+	}
+	field := func(label string, value string) {
+		m.Row(6, func() {
+			m.Col(4, func() {
+				m.Text(label, props.Text{
+					Top:   1,
+					Style: consts.Bold,
+					Align: consts.Left,
+				})
+			})
+			m.Col(8, func() {
+				m.Text(value, props.Text{
+					Top:   1,
+					Align: consts.Left,
+				})
+			})
+		})
+	}
+	table := func(header []string, contents [][]string, gridSizes []uint) {
+		m.TableList(header, contents, props.TableList{
+			HeaderProp: props.TableListContent{
+				Size:      9,
+				GridSizes: gridSizes,
+			},
+			ContentProp: props.TableListContent{
+				Size:      9,
+				GridSizes: gridSizes,
+			},
+			Align:              consts.Left,
+			HeaderContentSpace: 1,
+			Line:               true,
+		})
+	}
+
+	// SUMMARY TITLE
+	m.Row(9, func() {
+		m.Col(12, func() {
+			m.Text(l.T("summary.title"), props.Text{
+				Size:  16,
+				Top:   0,
+				Style: consts.Bold,
+				Align: consts.Center,
+			})
+		})
+	})
+
+	field(l.T("report.schoolName"), summary.SchoolName)
+	// the end date is not part of the period
+	field(l.T("summary.period"), fmt.Sprintf("%s - %s", l.FormatDate(summary.StartDate), l.FormatDate(summary.EndDate.AddDate(0, 0, -1))))
+	field(l.T("summary.participation"), l.T("summary.surveyed", summary.Surveyed, summary.Students, summary.ParticipationRate()))
+
+	if summary.Surveyed == 0 {
+		m.Row(10, func() {
+			m.Col(12, func() {
+				m.Text(l.T("summary.noSurveys"), props.Text{
+					Top:   4,
+					Style: consts.Italic,
+					Align: consts.Center,
+				})
+			})
+		})
+	} else {
+		field(l.T("summary.meanScore"), fmt.Sprintf("%.1f", summary.MeanSubjectiveScore))
+		field(l.T("summary.meanIndices"), fmt.Sprintf("%.2f / %.2f", summary.MeanDMFT, summary.MeanDefT))
+
+		// RISK DISTRIBUTION
+		heading(l.T("summary.riskDistribution"))
+
+		riskChart, err := getRiskDistributionChart(l, summary.RiskDistribution)
+		if err != nil {
+			return *bytes.NewBufferString(""), err
+		}
+		risks := make([][]string, len(summary.RiskDistribution))
+		for i, risk := range summary.RiskDistribution {
+			risks[i] = []string{l.T("risk." + risk.Category), fmt.Sprintf("%d", risk.Count), fmt.Sprintf("%.1f%%", float64(risk.Count)/float64(summary.Surveyed)*100)}
+		}
+		m.Row(60, func() {
+			m.Col(6, func() {
+				m.Base64Image(riskChart, consts.Png)
+			})
+			m.Col(6, func() {})
+		})
+		table([]string{l.T("summary.riskCategory"), l.T("summary.students"), "%"}, risks, []uint{6, 3, 3})
+
+		// TOOTH INDICES
+		heading(l.T("summary.toothIndices"))
+
+		dmftChart, err := getAgeBandChart("DMF-T", summary.AgeBands, func(g *model.ToothIndexGroup) float64 { return g.MeanDMFT }, chart.ColorBlue)
+		if err != nil {
+			return *bytes.NewBufferString(""), err
+		}
+		deftChart, err := getAgeBandChart("def-t", summary.AgeBands, func(g *model.ToothIndexGroup) float64 { return g.MeanDefT }, chart.ColorOrange)
+		if err != nil {
+			return *bytes.NewBufferString(""), err
+		}
+		m.Row(60, func() {
+			m.Col(6, func() {
+				m.Base64Image(dmftChart, consts.Png)
+			})
+			m.Col(6, func() {
+				m.Base64Image(deftChart, consts.Png)
+			})
+		})
+
+		indices := make([][]string, len(summary.ToothIndices))
+		for i, group := range summary.ToothIndices {
+			indices[i] = []string{
+				group.AgeBand.String(),
+				l.T("sex." + group.Sex),
+				fmt.Sprintf("%d", group.Students),
+				fmt.Sprintf("%.2f", group.MeanDMFT),
+				fmt.Sprintf("%.2f", group.MeanDefT),
+			}
+		}
+		table([]string{l.T("summary.ageBand"), l.T("summary.sex"), l.T("summary.students"), "DMF-T", "def-t"}, indices, []uint{2, 3, 3, 2, 2})
+
+		// TOP DIAGNOSES
+		if len(summary.TopDiagnoses) > 0 {
+			heading(l.T("summary.diagnoses"))
+
+			diagnoses := make([][]string, len(summary.TopDiagnoses))
+			for i, diagnosis := range summary.TopDiagnoses {
+				diagnoses[i] = []string{diagnosis.Diagnosis, fmt.Sprintf("%d", diagnosis.Count)}
+			}
+			table([]string{l.T("summary.diagnosis"), l.T("summary.cases")}, diagnoses, []uint{9, 3})
+		}
+	}
+
+	// ESTIMATED TREATMENT COST
+	heading(l.T("summary.cost"))
+
+	costs := make([][]string, len(summary.Costs))
+	for i, cost := range summary.Costs {
+		description := cost.Description
+		if i == len(summary.Costs)-1 {
+			description = l.T("summary.total")
+		}
+		costs[i] = []string{
+			description,
+			fmt.Sprintf("%d", cost.PlannedCount),
+			fmt.Sprintf("%.0f", cost.Cost),
+			fmt.Sprintf("%d", cost.PerformedCount),
+			fmt.Sprintf("%.0f", cost.PerformedCost),
+		}
+	}
+	table([]string{l.T("summary.action"), l.T("summary.planned"), l.T("summary.plannedCost"), l.T("summary.performed"), l.T("summary.performedCost")}, costs, []uint{4, 2, 2, 2, 2})
+
+	return m.Output()
+}
+
+func getRiskDistributionChart(l *model.Localizer, distribution []*model.RiskCategoryCount) (chartAsBase64 string, err error) {
+	colors := map[string]drawing.Color{
+		model.RiskCategoryLow:    chart.ColorGreen,
+		model.RiskCategoryMedium: chart.ColorYellow,
+		model.RiskCategoryHigh:   chart.ColorRed,
+	}
+
+	highestValue := 1.0
+	bars := make([]chart.Value, len(distribution))
+	for i, risk := range distribution {
+		highestValue = math.Max(highestValue, float64(risk.Count))
+		bars[i] = chart.Value{
+			Value: float64(risk.Count),
+			Label: l.T("risk." + risk.Category),
+			Style: chart.Style{
+				FillColor:   colors[risk.Category],
+				StrokeColor: colors[risk.Category],
+			},
+		}
+	}
+
+	graph := chart.BarChart{
+		Title: l.T("summary.riskDistribution"),
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top: 40,
+			},
+		},
+		YAxis: chart.YAxis{
+			Style: chart.Style{
+				StrokeColor: drawing.ColorBlack,
+				StrokeWidth: 1,
+			},
+			Range: &chart.ContinuousRange{
+				Min: 0.0,
+				Max: math.Ceil(highestValue),
+			},
+		},
+		XAxis: chart.Style{
+			StrokeColor: drawing.ColorBlack,
+			StrokeWidth: 1,
+		},
+		Height:   250,
+		Width:    350,
+		BarWidth: 60,
+		Bars:     bars,
+	}
+
+	buffer := bytes.NewBuffer([]byte{})
+	err = graph.Render(chart.PNG, buffer)
+	chartAsBase64 = base64.StdEncoding.EncodeToString(buffer.Bytes())
+	return
+}
+
+func getAgeBandChart(title string, groups []*model.ToothIndexGroup, value func(g *model.ToothIndexGroup) float64, color drawing.Color) (chartAsBase64 string, err error) {
+	highestValue := 1.0
+	bars := make([]chart.Value, len(groups))
+	for i, group := range groups {
+		highestValue = math.Max(highestValue, value(group))
+		bars[i] = chart.Value{
+			Value: value(group),
+			Label: group.AgeBand.String(),
+			Style: chart.Style{
+				FillColor:   color,
+				StrokeColor: color,
+			},
+		}
+	}
+
+	graph := chart.BarChart{
+		Title: title,
+		Background: chart.Style{
+			Padding: chart.Box{
+				Top: 40,
+			},
+		},
+		YAxis: chart.YAxis{
+			Style: chart.Style{
+				StrokeColor: drawing.ColorBlack,
+				StrokeWidth: 1,
+			},
+			Range: &chart.ContinuousRange{
+				Min: 0.0,
+				Max: math.Ceil(highestValue),
+			},
+		},
+		XAxis: chart.Style{
+			StrokeColor: drawing.ColorBlack,
+			StrokeWidth: 1,
+		},
+		Height:   250,
+		Width:    350,
+		BarWidth: 40,
+		Bars:     bars,
+	}
+
+	buffer := bytes.NewBuffer([]byte{})
+	err = graph.Render(chart.PNG, buffer)
+	chartAsBase64 = base64.StdEncoding.EncodeToString(buffer.Bytes())
+	return
+}
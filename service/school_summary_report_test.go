@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kerti/idcra-api/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSchoolSummaryReport(t *testing.T) {
+	dateOfBirth := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	surveyed := model.NewSchoolSummary(3, []*model.SchoolSummarySurvey{
+		{ID: "s1", StudentID: "a", DateOfBirth: dateOfBirth, Date: time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), SubjectiveScore: 40, LowerD: 2},
+	}, []*model.SchoolSummaryCase{
+		{SurveyID: "s1", Diagnosis: "Karies Superficial"},
+	})
+	empty := model.NewSchoolSummary(3, nil, nil)
+
+	for _, summary := range []*model.SchoolSummary{surveyed, empty} {
+		summary.SchoolName = "SD Negeri 1"
+		summary.StartDate = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		summary.EndDate = time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+		summary.Language = model.LanguageEnglish
+		summary.Costs = []*model.CostReport{
+			{Description: "ART", Cost: 16063, PlannedCount: 1},
+			{Description: "Total", Cost: 16063, PlannedCount: 1},
+		}
+
+		report, err := getSchoolSummaryReport(summary)
+		assert.NoError(t, err)
+		assert.Equal(t, "%PDF", string(report.Bytes()[:4]))
+	}
+}
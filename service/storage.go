@@ -0,0 +1,34 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kerti/idcra-api/context"
+)
+
+const (
+	StorageDriverLocal = "local"
+	StorageDriverS3    = "s3"
+)
+
+// ErrObjectNotFound is returned by a storage for a key it does not hold
+var ErrObjectNotFound = errors.New("object not found")
+
+// Storage keeps binary objects, such as clinical photos, under a key
+type Storage interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+// NewStorage returns the storage selected by the config
+func NewStorage(config *context.Config) (Storage, error) {
+	switch config.StorageDriver {
+	case StorageDriverLocal:
+		return NewLocalStorage(config.StoragePath)
+	case StorageDriverS3:
+		return NewS3Storage(config.S3Endpoint, config.S3Region, config.S3Bucket, config.S3AccessKey, config.S3SecretKey)
+	}
+	return nil, fmt.Errorf("unknown storage driver %s, expecting %s or %s", config.StorageDriver, StorageDriverLocal, StorageDriverS3)
+}
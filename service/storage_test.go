@@ -0,0 +1,115 @@
+package service
+
+import (
+	"image"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalStorage(t *testing.T) {
+	root, err := ioutil.TempDir("", "photos")
+	assert.Nil(t, err)
+	defer os.RemoveAll(root)
+
+	storage, err := NewLocalStorage(root)
+	assert.Nil(t, err)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		assert.Nil(t, storage.Put("surveys/1/photo.jpg", []byte("photo"), "image/jpeg"))
+
+		data, err := storage.Get("surveys/1/photo.jpg")
+		assert.Nil(t, err)
+		assert.Equal(t, "photo", string(data))
+
+		assert.Nil(t, storage.Delete("surveys/1/photo.jpg"))
+		_, err = storage.Get("surveys/1/photo.jpg")
+		assert.Equal(t, ErrObjectNotFound, err)
+
+		assert.Nil(t, storage.Delete("surveys/1/photo.jpg"))
+	})
+
+	t.Run("KeyOutsideRoot", func(t *testing.T) {
+		assert.NotNil(t, storage.Put("../photo.jpg", []byte("photo"), "image/jpeg"))
+		_, err := storage.Get("surveys/../../photo.jpg")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestS3Storage(t *testing.T) {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=access/") ||
+			!strings.Contains(auth, "/ap-southeast-1/s3/aws4_request, SignedHeaders=") || r.Header.Get("X-Amz-Date") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		key := r.URL.EscapedPath()
+		switch r.Method {
+		case http.MethodPut:
+			assert.Equal(t, "image/jpeg", r.Header.Get("Content-Type"))
+			objects[key], _ = ioutil.ReadAll(r.Body)
+		case http.MethodGet:
+			data, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(data)
+		case http.MethodDelete:
+			delete(objects, key)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	storage, err := NewS3Storage(server.URL, "ap-southeast-1", "photos", "access", "secret")
+	assert.Nil(t, err)
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		assert.Nil(t, storage.Put("surveys/1/photo 1.jpg", []byte("photo"), "image/jpeg"))
+		assert.Contains(t, objects, "/photos/surveys/1/photo%201.jpg")
+
+		data, err := storage.Get("surveys/1/photo 1.jpg")
+		assert.Nil(t, err)
+		assert.Equal(t, "photo", string(data))
+
+		assert.Nil(t, storage.Delete("surveys/1/photo 1.jpg"))
+		_, err = storage.Get("surveys/1/photo 1.jpg")
+		assert.Equal(t, ErrObjectNotFound, err)
+	})
+
+	t.Run("InvalidConfig", func(t *testing.T) {
+		_, err := NewS3Storage("not a url", "ap-southeast-1", "photos", "access", "secret")
+		assert.NotNil(t, err)
+		_, err = NewS3Storage(server.URL, "ap-southeast-1", "", "access", "secret")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestScalePhoto(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 1200, 900))
+
+	scaled := scalePhoto(img, 320)
+	assert.Equal(t, 320, scaled.Bounds().Dx())
+	assert.Equal(t, 240, scaled.Bounds().Dy())
+
+	small := image.NewRGBA(image.Rect(0, 0, 200, 100))
+	assert.Equal(t, small, scalePhoto(small, 320))
+
+	jpg, err := encodeJPEG(scaled)
+	assert.Nil(t, err)
+	assert.Equal(t, "image/jpeg", http.DetectContentType(jpg))
+}
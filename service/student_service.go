@@ -27,7 +27,7 @@ func (s *StudentService) FindByID(id string) (*model.Student, error) {
 	row := udb.QueryRowx(studentSQL, id)
 	err := row.StructScan(student)
 	if err == sql.ErrNoRows {
-		return student, nil
+		return nil, model.NewNotFoundError("student", id)
 	}
 	if err != nil {
 		s.log.Errorf("Error in retrieving student : %v", err)
@@ -70,10 +70,10 @@ func (s *StudentService) FindBySchoolID(schoolID *string, keyword *string) (stud
 func (s *StudentService) CreateStudent(student *model.Student) (*model.Student, error) {
 	studentID := uuid.NewV4()
 	student.ID = studentID.String()
-	studentSQL := `INSERT INTO students (id, name, date_of_birth, school_id, created_at) VALUES (:id, :name, :date_of_birth, :school_id, NOW())`
+	studentSQL := `INSERT INTO students (id, name, date_of_birth, sex, school_id, created_at) VALUES (:id, :name, :date_of_birth, :sex, :school_id, NOW())`
 	_, err := s.db.NamedExec(studentSQL, student)
 	if err != nil {
-		return nil, err
+		return nil, translateDBError(err)
 	}
 	return s.FindByID(student.ID)
 }
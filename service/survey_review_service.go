@@ -0,0 +1,314 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/kerti/idcra-api/model"
+)
+
+// SaveDraft stores the input of a survey the surveyor is still recording. A
+// new draft is created without an ID; an existing draft, or a rejected survey
+// reopened as a draft, is replaced. Only the surveyor of a draft can save it.
+func (s *SurveyService) SaveDraft(userID string, id *string, input model.SurveyInput) (*model.Survey, error) {
+	if err := input.AssignSurveyor(userID); err != nil {
+		return nil, err
+	}
+	survey, draft, err := model.NewSurveyDraftFromInput(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateStudent(&survey); err != nil {
+		return nil, err
+	}
+
+	var existing *model.Survey
+	if id != nil {
+		existing, err = s.FindByID(*id)
+		if err != nil {
+			return nil, err
+		}
+		if err := existing.CheckSurveyor(userID); err != nil {
+			return nil, err
+		}
+		if err := existing.CanSaveDraft(); err != nil {
+			return nil, err
+		}
+		survey.ID = existing.ID
+		draft.SurveyID = existing.ID
+	}
+
+	insertSQL := `
+		INSERT INTO surveys
+		(
+			id, student_id, surveyor_id, date, questionnaire_id,
+			lower_d, lower_e, lower_f, upper_d, upper_m, upper_f,
+			subjective_score, status, created_at
+		) VALUES (
+			:id, :student_id, :surveyor_id, :date, :questionnaire_id,
+			:lower_d, :lower_e, :lower_f, :upper_d, :upper_m, :upper_f,
+			0, :status, :created_at
+		)`
+	updateSQL := `
+		UPDATE surveys
+		SET student_id = :student_id, surveyor_id = :surveyor_id, date = :date,
+		questionnaire_id = :questionnaire_id,
+		lower_d = :lower_d, lower_e = :lower_e, lower_f = :lower_f,
+		upper_d = :upper_d, upper_m = :upper_m, upper_f = :upper_f,
+		status = :status
+		WHERE id = :id`
+	draftSQL := `
+		INSERT INTO survey_drafts
+		(survey_id, input)
+		VALUES
+		(:survey_id, :input)
+		ON DUPLICATE KEY UPDATE input = VALUES(input)`
+
+	err = Transact(s.db, func(tx *sqlx.Tx) error {
+		if existing == nil {
+			if _, err := tx.NamedExec(insertSQL, survey); err != nil {
+				return err
+			}
+		} else {
+			if existing.Status == model.SurveyStatusRejected {
+				if err := removeSurveyDetails(tx, existing.ID); err != nil {
+					return err
+				}
+			}
+			if _, err := tx.NamedExec(updateSQL, survey); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.NamedExec(draftSQL, draft)
+		return err
+	})
+	if err != nil {
+		s.log.Errorf("Error in saving survey draft : %v", err)
+		return nil, translateDBError(err)
+	}
+
+	return s.FindByID(survey.ID)
+}
+
+// FindDraft returns the input saved with a draft survey, or nil when the
+// survey is not a draft.
+func (s *SurveyService) FindDraft(surveyID string) (*model.SurveyDraft, error) {
+	draft := &model.SurveyDraft{}
+
+	draftSQL := `SELECT * FROM survey_drafts WHERE survey_id = ?`
+	err := s.db.Unsafe().Get(draft, draftSQL, surveyID)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		s.log.Errorf("Error in retrieving survey draft : %v", err)
+		return nil, err
+	}
+
+	return draft, nil
+}
+
+// SubmitDraft validates a draft as a complete survey, records it and submits
+// it for review. Only the surveyor of a draft can submit it.
+func (s *SurveyService) SubmitDraft(userID string, id string) (*model.Survey, error) {
+	existing, err := s.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := existing.CheckSurveyor(userID); err != nil {
+		return nil, err
+	}
+	if err := existing.CanSubmit(); err != nil {
+		return nil, err
+	}
+
+	draft, err := s.FindDraft(id)
+	if err != nil {
+		return nil, err
+	}
+	if draft == nil {
+		return nil, model.NewNotFoundError("survey draft", id)
+	}
+
+	survey, err := model.NewSurveyFromDraft(draft)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.prepareSurvey(&survey); err != nil {
+		return nil, err
+	}
+
+	surveySQL := `
+		UPDATE surveys
+		SET student_id = :student_id, surveyor_id = :surveyor_id, date = :date,
+		questionnaire_id = :questionnaire_id,
+		lower_d = :lower_d, lower_e = :lower_e, lower_f = :lower_f,
+		upper_d = :upper_d, upper_m = :upper_m, upper_f = :upper_f,
+		computed_lower_d = :computed_lower_d, computed_lower_e = :computed_lower_e,
+		computed_lower_f = :computed_lower_f, computed_upper_d = :computed_upper_d,
+		computed_upper_m = :computed_upper_m, computed_upper_f = :computed_upper_f,
+		tooth_index_mismatch = :tooth_index_mismatch, subjective_score = :subjective_score,
+		status = :status, submitted_at = NOW()
+		WHERE id = :id`
+
+	err = Transact(s.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.NamedExec(surveySQL, survey); err != nil {
+			return err
+		}
+		if err := insertSurveyDetails(tx, &survey); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`DELETE FROM survey_drafts WHERE survey_id = ?`, id)
+		return err
+	})
+	if err != nil {
+		s.log.Errorf("Error in submitting survey draft : %v", err)
+		return nil, translateDBError(err)
+	}
+
+	submittedSurvey, err := s.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishSurveyCreated(submittedSurvey)
+
+	return submittedSurvey, nil
+}
+
+// Review approves or rejects a submitted survey. Approved surveys record the
+// latest version of the recommendation rules their reports are printed with.
+func (s *SurveyService) Review(review *model.SurveyReview) (*model.Survey, error) {
+	survey, err := s.FindByID(review.SurveyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := survey.Review(review); err != nil {
+		return nil, err
+	}
+
+	surveySQL := `
+		UPDATE surveys
+		SET status = :status, reviewed_by = :reviewed_by, reviewed_at = NOW(), review_comment = :review_comment
+		WHERE id = :id AND status = '` + model.SurveyStatusSubmitted + `'`
+	reviewSQL := `
+		INSERT INTO survey_reviews
+		(id, survey_id, status, reviewer_id, comment, created_at)
+		VALUES
+		(:id, :survey_id, :status, :reviewer_id, :comment, NOW())`
+	versionSQL := `
+		UPDATE surveys
+		SET recommendation_version = (SELECT MAX(version) FROM recommendation_rule_sets)
+		WHERE id = ?`
+
+	err = Transact(s.db, func(tx *sqlx.Tx) error {
+		result, err := tx.NamedExec(surveySQL, survey)
+		if err != nil {
+			return err
+		}
+		// another reviewer may have reviewed the survey since it was read
+		if updated, err := result.RowsAffected(); err != nil {
+			return err
+		} else if updated == 0 {
+			return model.NewConflictError(fmt.Sprintf("survey %s was reviewed by someone else in the meantime", survey.ID))
+		}
+		if survey.Status == model.SurveyStatusApproved {
+			if _, err := tx.Exec(versionSQL, survey.ID); err != nil {
+				return err
+			}
+		}
+		_, err = tx.NamedExec(reviewSQL, review)
+		return err
+	})
+	if err != nil {
+		s.log.Errorf("Error in reviewing survey : %v", err)
+		return nil, translateDBError(err)
+	}
+
+	return s.FindByID(review.SurveyID)
+}
+
+// FindReviews returns the approvals and rejections of a survey, oldest first
+func (s *SurveyService) FindReviews(surveyID string) ([]*model.SurveyReview, error) {
+	reviews := make([]*model.SurveyReview, 0)
+
+	reviewSQL := `SELECT * FROM survey_reviews WHERE survey_id = ? ORDER BY created_at ASC, id ASC`
+	if err := s.db.Unsafe().Select(&reviews, reviewSQL, surveyID); err != nil {
+		s.log.Errorf("Error in retrieving survey reviews : %v", err)
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+// ListForReview returns the surveys waiting for review, optionally only those
+// of the students of a school, longest waiting first.
+func (s *SurveyService) ListForReview(first *int32, after *string, schoolID *string) ([]*model.Survey, error) {
+	surveys := make([]*model.Survey, 0)
+	var fetchSize int32
+	if first == nil {
+		fetchSize = defaultListFetchSize
+	} else {
+		fetchSize = *first
+	}
+
+	var afterID *string
+	if after != nil {
+		afterID, _ = DecodeCursor(after)
+	}
+
+	surveySQL := `
+		SELECT s.*
+		FROM surveys s
+		JOIN students st ON st.id = s.student_id
+		WHERE s.status = ?
+		AND (? IS NULL OR st.school_id = ?)
+		AND (? IS NULL OR (s.submitted_at, s.id) > (SELECT submitted_at, id FROM surveys WHERE id = ?))
+		ORDER BY s.submitted_at ASC, s.id ASC
+		LIMIT ?`
+
+	err := s.db.Unsafe().Select(&surveys, surveySQL, model.SurveyStatusSubmitted, schoolID, schoolID, afterID, afterID, fetchSize)
+	if err != nil {
+		return nil, err
+	}
+	return surveys, s.loadDetails(surveys)
+}
+
+// CountForReview returns the number of surveys waiting for review
+func (s *SurveyService) CountForReview(schoolID *string) (int, error) {
+	var count int
+
+	surveySQL := `
+		SELECT COUNT(*)
+		FROM surveys s
+		JOIN students st ON st.id = s.student_id
+		WHERE s.status = ? AND (? IS NULL OR st.school_id = ?)`
+	err := s.db.Get(&count, surveySQL, model.SurveyStatusSubmitted, schoolID, schoolID)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// removeSurveyDetails removes what was recorded when a rejected survey was
+// submitted, so it can be recorded again from its draft. Recalls closed by the
+// survey are opened again.
+func removeSurveyDetails(tx *sqlx.Tx, surveyID string) error {
+	statements := []string{
+		`DELETE FROM survey_answers WHERE survey_id = ?`,
+		`DELETE FROM survey_risk_assessments WHERE survey_id = ?`,
+		`DELETE FROM cases WHERE survey_id = ?`,
+		`DELETE FROM recalls WHERE survey_id = ?`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement, surveyID); err != nil {
+			return err
+		}
+	}
+
+	reopenSQL := `UPDATE recalls SET status = ?, completed_survey_id = NULL WHERE completed_survey_id = ?`
+	_, err := tx.Exec(reopenSQL, model.RecallStatusDue, surveyID)
+	return err
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/kerti/idcra-api/model"
@@ -10,13 +12,17 @@ import (
 )
 
 type SurveyService struct {
-	db          *sqlx.DB
-	caseService *CaseService
-	log         *logging.Logger
+	db                        *sqlx.DB
+	caseService               *CaseService
+	diagnosisAndActionService *DiagnosisAndActionService
+	questionnaireService      *QuestionnaireService
+	riskService               *RiskService
+	eventBus                  *EventBus
+	log                       *logging.Logger
 }
 
-func NewSurveyService(db *sqlx.DB, caseService *CaseService, log *logging.Logger) *SurveyService {
-	return &SurveyService{db: db, caseService: caseService, log: log}
+func NewSurveyService(db *sqlx.DB, caseService *CaseService, diagnosisAndActionService *DiagnosisAndActionService, questionnaireService *QuestionnaireService, riskService *RiskService, eventBus *EventBus, log *logging.Logger) *SurveyService {
+	return &SurveyService{db: db, caseService: caseService, diagnosisAndActionService: diagnosisAndActionService, questionnaireService: questionnaireService, riskService: riskService, eventBus: eventBus, log: log}
 }
 
 func (s *SurveyService) FindByID(id string) (*model.Survey, error) {
@@ -27,7 +33,7 @@ func (s *SurveyService) FindByID(id string) (*model.Survey, error) {
 	row := udb.QueryRowx(surveySQL, id)
 	err := row.StructScan(survey)
 	if err == sql.ErrNoRows {
-		return survey, nil
+		return nil, model.NewNotFoundError("survey", id)
 	}
 	if err != nil {
 		s.log.Errorf("Error in retrieving survey : %v", err)
@@ -41,54 +47,387 @@ func (s *SurveyService) FindByID(id string) (*model.Survey, error) {
 	}
 	survey.Cases = cases
 
+	if err := s.loadAnswers([]*model.Survey{survey}); err != nil {
+		s.log.Errorf("Error in retrieving survey answers : %v", err)
+		return nil, err
+	}
+
+	if err := s.loadRiskAssessments([]*model.Survey{survey}); err != nil {
+		return nil, err
+	}
+
 	return survey, nil
 }
 
 func (s *SurveyService) TransactionalCreateSurvey(survey *model.Survey) (*model.Survey, error) {
-	survey.CalculateScore()
+	if err := s.prepareSurvey(survey); err != nil {
+		return nil, err
+	}
+
+	err := Transact(s.db, func(tx *sqlx.Tx) error {
+		return insertSurvey(tx, survey)
+	})
+	if err != nil {
+		return nil, translateDBError(err)
+	}
+
+	createdSurvey, err := s.FindByID(survey.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishSurveyCreated(createdSurvey)
+
+	return createdSurvey, nil
+}
+
+// SubmitSurvey stores a survey uploaded by an offline client. The survey is
+// stored in its own transaction together with its idempotency key, so a retried
+// upload returns the survey stored the first time instead of failing or
+// creating a duplicate. Keys are scoped to the signed-in user, and a key reused
+// by the user for a different survey fails with a conflict.
+func (s *SurveyService) SubmitSurvey(userID string, submission *model.SurveySubmission) *model.SurveySubmissionResult {
+	if err := submission.Survey.CheckSurveyor(userID); err != nil {
+		return s.submissionResult(submission, model.SubmissionStatusFailed, nil, err)
+	}
+	if existing, err := s.findSubmittedSurvey(userID, submission); existing != nil || err != nil {
+		return s.submissionResult(submission, model.SubmissionStatusAlreadySubmitted, existing, err)
+	}
+
+	submissionSQL := `
+		INSERT INTO survey_submissions
+		(surveyor_id, idempotency_key, survey_id, payload_hash, created_at)
+		VALUES
+		(?, ?, ?, ?, NOW())`
+
+	survey := submission.Survey
+	if err := s.prepareSurvey(survey); err != nil {
+		return s.submissionResult(submission, model.SubmissionStatusFailed, nil, err)
+	}
+
+	err := Transact(s.db, func(tx *sqlx.Tx) error {
+		if err := insertSurvey(tx, survey); err != nil {
+			return err
+		}
+
+		_, err := tx.Exec(submissionSQL, userID, submission.IdempotencyKey, survey.ID, submission.PayloadHash)
+		return err
+	})
+	if err != nil {
+		// a concurrent retry of the same submission may have won the race
+		if existing, findErr := s.findSubmittedSurvey(userID, submission); existing != nil || findErr != nil {
+			return s.submissionResult(submission, model.SubmissionStatusAlreadySubmitted, existing, findErr)
+		}
+		return s.submissionResult(submission, model.SubmissionStatusFailed, nil, translateDBError(err))
+	}
+
+	createdSurvey, err := s.FindByID(survey.ID)
+	if err != nil {
+		return s.submissionResult(submission, model.SubmissionStatusFailed, nil, err)
+	}
+
+	s.publishSurveyCreated(createdSurvey)
+
+	return s.submissionResult(submission, model.SubmissionStatusCreated, createdSurvey, nil)
+}
+
+// findSubmittedSurvey returns the survey previously stored for the submission,
+// looked up by the user's idempotency key or by the client generated survey
+// ID. It fails with a conflict when the key was used for a different survey or
+// the ID belongs to a survey of another surveyor.
+func (s *SurveyService) findSubmittedSurvey(userID string, submission *model.SurveySubmission) (*model.Survey, error) {
+	stored := struct {
+		SurveyID    string  `db:"survey_id"`
+		PayloadHash *string `db:"payload_hash"`
+	}{}
+
+	submissionSQL := `
+		SELECT survey_id, payload_hash
+		FROM survey_submissions
+		WHERE surveyor_id = ? AND idempotency_key = ?`
+	err := s.db.Get(&stored, submissionSQL, userID, submission.IdempotencyKey)
+	if err == sql.ErrNoRows {
+		if !submission.ClientGeneratedID {
+			return nil, nil
+		}
+		stored.SurveyID = submission.Survey.ID
+	} else if err != nil {
+		return nil, err
+	} else if err := submission.CheckRetry(stored.PayloadHash); err != nil {
+		return nil, err
+	}
+
+	survey, err := s.FindByID(stored.SurveyID)
+	if appErr, ok := err.(*model.Error); ok && appErr.Code == model.ErrorCodeNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if survey.SurveyorID != userID {
+		return nil, model.NewConflictError(fmt.Sprintf("survey %s was submitted by another surveyor", survey.ID))
+	}
+	return survey, nil
+}
+
+func (s *SurveyService) submissionResult(submission *model.SurveySubmission, status string, survey *model.Survey, err error) *model.SurveySubmissionResult {
+	result := &model.SurveySubmissionResult{
+		IdempotencyKey: submission.IdempotencyKey,
+		Status:         status,
+		Survey:         survey,
+	}
+
+	if err != nil {
+		result = model.NewFailedSurveySubmissionResult(submission.IdempotencyKey, err)
+		if result.Error.Code == model.ErrorCodeInternal {
+			s.log.Errorf("Error in submitting survey %s : %v", submission.IdempotencyKey, err)
+		}
+	}
+
+	return result
+}
+
+// prepareSurvey validates the survey against its student and validates and
+// scores its answers against the questionnaire it was recorded with, derives
+// its def-t and DMF-T counts from its cases, then assesses its caries risk.
+func (s *SurveyService) prepareSurvey(survey *model.Survey) error {
+	studentErr := s.validateStudent(survey)
+
+	questionnaireID := model.LegacyQuestionnaireID
+	if survey.QuestionnaireID != nil {
+		questionnaireID = *survey.QuestionnaireID
+	}
+
+	var answersErr error
+	questionnaire, err := s.questionnaireService.FindByID(questionnaireID)
+	if appErr, ok := err.(*model.Error); ok && appErr.Code == model.ErrorCodeNotFound {
+		answersErr = model.NewFieldError("questionnaireId", appErr.Message)
+	} else if err != nil {
+		return err
+	} else {
+		answersErr = questionnaire.Apply(survey)
+	}
+
+	if err := model.JoinValidationErrors(studentErr, answersErr); err != nil {
+		return err
+	}
+
+	diagnosisIDs := make([]string, len(survey.Cases))
+	for i, c := range survey.Cases {
+		diagnosisIDs[i] = c.DiagnosisAndActionID
+	}
+	components, err := s.diagnosisAndActionService.FindIndexComponents(diagnosisIDs)
+	if err != nil {
+		return err
+	}
+	survey.SetComputedToothIndices(model.ComputeToothIndices(survey.Cases, components))
+
+	s.riskService.Assess(survey)
+
+	return nil
+}
+
+// validateStudent makes sure the student exists and was born by the date of
+// the survey.
+func (s *SurveyService) validateStudent(survey *model.Survey) error {
+	var dateOfBirth time.Time
+
+	studentSQL := `SELECT date_of_birth FROM students WHERE id = ?`
+	err := s.db.Get(&dateOfBirth, studentSQL, survey.StudentID)
+	if err == sql.ErrNoRows {
+		return model.NewFieldError("studentId", fmt.Sprintf("student %s not found", survey.StudentID))
+	}
+	if err != nil {
+		return err
+	}
+
+	date, err := time.Parse("2006-01-02", survey.Date)
+	if err != nil {
+		return model.NewFieldError("date", "invalid date format, expecting yyyy-mm-dd")
+	}
+	if date.Before(dateOfBirth) {
+		return model.NewFieldError("date", "date must not be before the date of birth of the student")
+	}
+
+	return nil
+}
+
+// RecomputeRiskAssessments assesses every recorded survey again with the enabled
+// risk models, returning the number of surveys assessed. Drafts are skipped.
+func (s *SurveyService) RecomputeRiskAssessments() (int, error) {
+	var (
+		count  int
+		lastID string
+	)
+
+	for {
+		ids := make([]string, 0)
+		surveySQL := `SELECT id FROM surveys WHERE id > ? AND status <> ? ORDER BY id ASC LIMIT ?`
+		if err := s.db.Select(&ids, surveySQL, lastID, model.SurveyStatusDraft, defaultListFetchSize); err != nil {
+			return count, err
+		}
+		if len(ids) == 0 {
+			return count, nil
+		}
+
+		for _, id := range ids {
+			survey, err := s.FindByID(id)
+			if err != nil {
+				return count, err
+			}
+
+			s.riskService.Assess(survey)
+			if err := Transact(s.db, func(tx *sqlx.Tx) error {
+				return saveRiskAssessments(tx, survey)
+			}); err != nil {
+				s.log.Errorf("Error in storing risk assessments of survey %s : %v", id, err)
+				return count, err
+			}
+			count++
+		}
+
+		lastID = ids[len(ids)-1]
+	}
+}
+
+// loadRiskAssessments sets the stored risk assessments of the surveys.
+func (s *SurveyService) loadRiskAssessments(surveys []*model.Survey) error {
+	ids := make([]string, len(surveys))
+	for i, survey := range surveys {
+		ids[i] = survey.ID
+	}
+
+	assessmentsBySurvey, err := s.riskService.FindBySurveyIDs(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, survey := range surveys {
+		survey.RiskAssessments = assessmentsBySurvey[survey.ID]
+	}
+
+	return nil
+}
+
+// loadAnswers sets the answers of the surveys.
+func (s *SurveyService) loadAnswers(surveys []*model.Survey) error {
+	if len(surveys) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(surveys))
+	for i, survey := range surveys {
+		ids[i] = survey.ID
+	}
+
+	answerSQL, args, err := sqlx.In(`
+		SELECT a.survey_id, a.question_id, q.code AS question_code, a.value, a.score
+		FROM survey_answers a
+		JOIN questionnaire_questions q ON q.id = a.question_id
+		JOIN questionnaire_sections sec ON sec.id = q.section_id
+		WHERE a.survey_id IN (?)
+		ORDER BY sec.position ASC, q.position ASC`, ids)
+	if err != nil {
+		return err
+	}
+
+	answers := make([]*model.SurveyAnswer, 0)
+	if err := s.db.Select(&answers, s.db.Rebind(answerSQL), args...); err != nil {
+		return err
+	}
+
+	answersBySurvey := make(map[string][]*model.SurveyAnswer)
+	for _, answer := range answers {
+		answersBySurvey[answer.SurveyID] = append(answersBySurvey[answer.SurveyID], answer)
+	}
+
+	for _, survey := range surveys {
+		survey.SetAnswers(answersBySurvey[survey.ID])
+	}
+
+	return nil
+}
+
+func insertSurvey(tx *sqlx.Tx, survey *model.Survey) error {
 	surveySQL := `
 		INSERT INTO surveys
 		(
-			id, student_id, surveyor_id, date,
-			s1q1, s1q2, s1q3, s1q4, s1q5, s1q6, s1q7,
-			s2q1, s2q2, s2q3, s2q4, s2q5, s2q6, s2q7, s2q8, s2q9,
+			id, student_id, surveyor_id, date, questionnaire_id,
 			lower_d, lower_e, lower_f, upper_d, upper_m, upper_f,
-			subjective_score, created_at
+			computed_lower_d, computed_lower_e, computed_lower_f,
+			computed_upper_d, computed_upper_m, computed_upper_f,
+			tooth_index_mismatch, subjective_score, status, submitted_at, created_at
 		) VALUES (
-			:id, :student_id, :surveyor_id, :date,
-			:s1q1, :s1q2, :s1q3, :s1q4, :s1q5, :s1q6, :s1q7, :s2q1,
-			:s2q2, :s2q3, :s2q4, :s2q5, :s2q6, :s2q7, :s2q8, :s2q9,
+			:id, :student_id, :surveyor_id, :date, :questionnaire_id,
 			:lower_d, :lower_e, :lower_f, :upper_d, :upper_m, :upper_f,
-			:subjective_score, :created_at
+			:computed_lower_d, :computed_lower_e, :computed_lower_f,
+			:computed_upper_d, :computed_upper_m, :computed_upper_f,
+			:tooth_index_mismatch, :subjective_score, :status, NOW(), :created_at
 		)`
+
+	// store survey
+	if _, err := tx.NamedExec(surveySQL, survey); err != nil {
+		return err
+	}
+
+	return insertSurveyDetails(tx, survey)
+}
+
+// insertSurveyDetails stores the cases, answers and risk assessments of a
+// submitted survey and schedules the next visit of its student.
+func insertSurveyDetails(tx *sqlx.Tx, survey *model.Survey) error {
 	caseFoundSQL := `
 		INSERT INTO cases
-		(id, survey_id, tooth_number, diagnosis_and_action_id, created_at)
+		(id, survey_id, tooth_number, diagnosis_and_action_id, treatment_status, created_at)
+		VALUES
+		(:id, :survey_id, :tooth_number, :diagnosis_and_action_id, :treatment_status, :created_at)`
+	answerSQL := `
+		INSERT INTO survey_answers
+		(survey_id, question_id, value, score)
 		VALUES
-		(:id, :survey_id, :tooth_number, :diagnosis_and_action_id, :created_at)`
+		(:survey_id, :question_id, :value, :score)`
 
-	err := Transact(s.db, func(tx *sqlx.Tx) error {
-		// store survey
-		if _, err := tx.NamedExec(surveySQL, survey); err != nil {
+	// store cases
+	for _, c := range survey.Cases {
+		if _, err := tx.NamedExec(caseFoundSQL, c); err != nil {
 			return err
 		}
+	}
 
-		// store cases
-		for _, c := range survey.Cases {
-			if _, err := tx.NamedExec(caseFoundSQL, c); err != nil {
-				return err
-			}
+	// store answers
+	for _, a := range survey.Answers {
+		if _, err := tx.NamedExec(answerSQL, a); err != nil {
+			return err
 		}
+	}
 
-		return nil
-	})
-	if err != nil {
-		return nil, err
+	if err := saveRiskAssessments(tx, survey); err != nil {
+		return err
 	}
-	return s.FindByID(survey.ID)
+
+	return saveRecall(tx, survey)
 }
 
-func (s *SurveyService) List(first *int32, after *string, studentID *string) ([]*model.Survey, error) {
+func (s *SurveyService) publishSurveyCreated(survey *model.Survey) {
+	var schoolID string
+
+	schoolSQL := `SELECT school_id FROM students WHERE id = ?`
+	if err := s.db.Get(&schoolID, schoolSQL, survey.StudentID); err != nil {
+		s.log.Errorf("Error in retrieving school of survey %s : %v", survey.ID, err)
+		return
+	}
+
+	s.eventBus.Publish(SurveyCreatedTopic, &model.SurveyCreatedEvent{
+		SchoolID: schoolID,
+		Survey:   survey,
+	})
+}
+
+// List returns surveys, optionally only those of a student, only those in a
+// status of the review workflow or only those whose entered def-t and DMF-T
+// counts do or do not match their cases. Drafts are left out unless asked for,
+// and only those of the surveyor draftsOf are listed when it is given.
+func (s *SurveyService) List(first *int32, after *string, studentID *string, status *string, toothIndexMismatch *bool, draftsOf *string) ([]*model.Survey, error) {
 	surveys := make([]*model.Survey, 0)
 	var fetchSize int32
 	if first == nil {
@@ -102,24 +441,89 @@ func (s *SurveyService) List(first *int32, after *string, studentID *string) ([]
 		strStudentID = fmt.Sprintf("%s%s%s", "%", *studentID, "%")
 	}
 
+	if err := validateSurveyStatus(status); err != nil {
+		return nil, err
+	}
+
 	if after != nil {
-		surveySQL := `SELECT * FROM surveys WHERE student_id LIKE ? AND created_at > (SELECT created_at FROM surveys WHERE id = ?) ORDER BY created_at ASC LIMIT ?;`
+		surveySQL := `SELECT * FROM surveys WHERE student_id LIKE ? AND (? IS NULL OR status = ?) AND (? IS NOT NULL OR status <> ?) AND (? IS NULL OR tooth_index_mismatch = ?) AND (? IS NULL OR status <> ? OR surveyor_id = ?) AND created_at > (SELECT created_at FROM surveys WHERE id = ?) ORDER BY created_at ASC LIMIT ?;`
 		decodedIndex, _ := DecodeCursor(after)
-		err := s.db.Select(&surveys, surveySQL, strStudentID, decodedIndex, fetchSize)
+		err := s.db.Unsafe().Select(&surveys, surveySQL, strStudentID, status, status, status, model.SurveyStatusDraft, toothIndexMismatch, toothIndexMismatch, draftsOf, model.SurveyStatusDraft, draftsOf, decodedIndex, fetchSize)
 		if err != nil {
 			return nil, err
 		}
-		return surveys, nil
+		return surveys, s.loadDetails(surveys)
 	}
-	surveySQL := `SELECT * FROM surveys WHERE student_id LIKE ? ORDER BY created_at ASC LIMIT ?;`
-	err := s.db.Select(&surveys, surveySQL, strStudentID, fetchSize)
+	surveySQL := `SELECT * FROM surveys WHERE student_id LIKE ? AND (? IS NULL OR status = ?) AND (? IS NOT NULL OR status <> ?) AND (? IS NULL OR tooth_index_mismatch = ?) AND (? IS NULL OR status <> ? OR surveyor_id = ?) ORDER BY created_at ASC LIMIT ?;`
+	err := s.db.Unsafe().Select(&surveys, surveySQL, strStudentID, status, status, status, model.SurveyStatusDraft, toothIndexMismatch, toothIndexMismatch, draftsOf, model.SurveyStatusDraft, draftsOf, fetchSize)
 	if err != nil {
 		return nil, err
 	}
-	return surveys, nil
+	return surveys, s.loadDetails(surveys)
+}
+
+// CheckAccess makes sure a user may see the clinical data of a survey, such as
+// its photos. Admins and supervisors see every survey, others only the surveys
+// they recorded, those of the schools they are assigned to and those of their
+// students.
+func (s *SurveyService) CheckAccess(userID string, roles []*model.Role, surveyID string) error {
+	if model.HasRole(roles, model.RoleAdmin, model.RoleSupervisor) {
+		return nil
+	}
+
+	var allowed bool
+	accessSQL := `
+		SELECT COUNT(*) > 0
+		FROM surveys s
+		JOIN students st ON st.id = s.student_id
+		WHERE s.id = ?
+		AND (
+			s.surveyor_id = ?
+			OR st.school_id IN (SELECT school_id FROM rel_users_schools WHERE user_id = ?)
+			OR st.id IN (SELECT student_id FROM rel_users_students WHERE user_id = ?)
+		)`
+	if err := s.db.Get(&allowed, accessSQL, surveyID, userID, userID, userID); err != nil {
+		s.log.Errorf("Error in checking access to survey : %v", err)
+		return err
+	}
+	if !allowed {
+		return model.NewForbiddenError(fmt.Sprintf("no access to survey %s", surveyID))
+	}
+	return nil
+}
+
+// FindByStudentID returns every submitted survey of a student with its cases,
+// oldest first. Drafts and rejected surveys are left out.
+func (s *SurveyService) FindByStudentID(studentID string) ([]*model.Survey, error) {
+	surveys := make([]*model.Survey, 0)
+
+	surveySQL := `SELECT * FROM surveys WHERE student_id = ? AND status NOT IN (?, ?) ORDER BY date ASC, created_at ASC`
+	if err := s.db.Unsafe().Select(&surveys, surveySQL, studentID, model.SurveyStatusDraft, model.SurveyStatusRejected); err != nil {
+		s.log.Errorf("Error in retrieving surveys of student : %v", err)
+		return nil, err
+	}
+
+	for _, survey := range surveys {
+		cases, err := s.caseService.FindBySurveyID(&survey.ID)
+		if err != nil {
+			s.log.Errorf("Error in retrieving cases : %v", err)
+			return nil, err
+		}
+		survey.Cases = cases
+	}
+
+	return surveys, s.loadDetails(surveys)
+}
+
+// loadDetails sets the answers and the risk assessments of listed surveys.
+func (s *SurveyService) loadDetails(surveys []*model.Survey) error {
+	if err := s.loadAnswers(surveys); err != nil {
+		return err
+	}
+	return s.loadRiskAssessments(surveys)
 }
 
-func (s *SurveyService) Count(studentID *string) (int, error) {
+func (s *SurveyService) Count(studentID *string, status *string, toothIndexMismatch *bool, draftsOf *string) (int, error) {
 	var count int
 
 	strStudentID := "%"
@@ -127,10 +531,23 @@ func (s *SurveyService) Count(studentID *string) (int, error) {
 		strStudentID = fmt.Sprintf("%s%s%s", "%", *studentID, "%")
 	}
 
-	surveySQL := `SELECT COUNT(*) FROM surveys WHERE student_id LIKE ?`
-	err := s.db.Get(&count, surveySQL, strStudentID)
+	if err := validateSurveyStatus(status); err != nil {
+		return 0, err
+	}
+
+	surveySQL := `SELECT COUNT(*) FROM surveys WHERE student_id LIKE ? AND (? IS NULL OR status = ?) AND (? IS NOT NULL OR status <> ?) AND (? IS NULL OR tooth_index_mismatch = ?) AND (? IS NULL OR status <> ? OR surveyor_id = ?)`
+	err := s.db.Get(&count, surveySQL, strStudentID, status, status, status, model.SurveyStatusDraft, toothIndexMismatch, toothIndexMismatch, draftsOf, model.SurveyStatusDraft, draftsOf)
 	if err != nil {
 		return 0, err
 	}
 	return count, nil
 }
+
+// validateSurveyStatus makes sure a status filter, if any, is a known survey
+// status
+func validateSurveyStatus(status *string) error {
+	if status != nil && !model.IsSurveyStatus(*status) {
+		return model.NewFieldError("status", fmt.Sprintf("invalid survey status %s, expecting one of %s", *status, strings.Join(model.SurveyStatuses, ", ")))
+	}
+	return nil
+}
@@ -34,7 +34,7 @@ func (u *UserService) FindByEmail(email string) (*model.User, error) {
 	row := udb.QueryRowx(userSQL, email)
 	err := row.StructScan(user)
 	if err == sql.ErrNoRows {
-		return user, nil
+		return nil, model.NewNotFoundError("user", email)
 	}
 	if err != nil {
 		u.log.Errorf("Error in retrieving user : %v", err)
@@ -66,7 +66,7 @@ func (u *UserService) FindUserById(userId string) (*model.User, error) {
 	row := udb.QueryRowx(userSQL, userId)
 	err := row.StructScan(user)
 	if err == sql.ErrNoRows {
-		return user, nil
+		return nil, model.NewNotFoundError("user", userId)
 	}
 	if err != nil {
 		u.log.Errorf("Error in retrieving user : %v", err)
@@ -101,7 +101,7 @@ func (u *UserService) CreateUser(user *model.User) (*model.User, error) {
 
 	if _, err := u.db.NamedExec(userSQL, user); err != nil {
 		u.log.Errorf("Error in creating user : %v", err)
-		return nil, err
+		return nil, translateDBError(err)
 	}
 
 	userResult, err := u.FindByEmail(user.Email)
@@ -134,7 +134,7 @@ func (u *UserService) CreateUserStudentRelation(relations *model.UsersStudentsRe
 
 	if _, err := u.db.Exec(userSQL, newID, relations.UserId, relations.StudentId); err != nil {
 		u.log.Errorf("Error in adding student to user : %v", err)
-		return nil, err
+		return nil, translateDBError(err)
 	}
 
 	userResult, err := u.FindUserById(relations.UserId)
@@ -234,3 +234,47 @@ func (u *UserService) ComparePassword(userCredentials *model.UserCredentials) (*
 	}
 	return user, nil
 }
+
+// SetPreferredLanguage changes the language reports are printed in for the
+// user when none is asked for
+func (u *UserService) SetPreferredLanguage(userID string, language string) (*model.User, error) {
+	if err := model.ValidateLanguage("language", language); err != nil {
+		return nil, err
+	}
+
+	if _, err := u.db.Exec(`UPDATE users SET preferred_language = ? WHERE id = ?`, language, userID); err != nil {
+		u.log.Errorf("Error in updating preferred language : %v", err)
+		return nil, err
+	}
+
+	return u.FindUserById(userID)
+}
+
+// ReportLanguage returns the language to print reports in for a user: the
+// language asked for when given, then the preferred language of the user, then
+// the default language.
+func (u *UserService) ReportLanguage(userID *string, language string) (string, error) {
+	if language != "" {
+		if err := model.ValidateLanguage("lang", language); err != nil {
+			return "", err
+		}
+		return language, nil
+	}
+	if userID == nil {
+		return model.DefaultLanguage, nil
+	}
+
+	var preferred *string
+	err := u.db.Get(&preferred, `SELECT preferred_language FROM users WHERE id = ?`, *userID)
+	if err != nil && err != sql.ErrNoRows {
+		u.log.Errorf("Error in retrieving preferred language : %v", err)
+		return "", err
+	}
+	if preferred == nil {
+		return model.DefaultLanguage, nil
+	}
+	if _, ok := model.FindCatalog(*preferred); !ok {
+		return model.DefaultLanguage, nil
+	}
+	return *preferred, nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const xlsxSheetHeader = xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+const xlsxSheetFooter = `</sheetData></worksheet>`
+
+// xlsxWriter writes a workbook row by row into a zip archive, so worksheets of
+// any size are written without being held in memory. Sheets are written one
+// after the other; adding a sheet finishes the previous one.
+type xlsxWriter struct {
+	archive *zip.Writer
+	sheets  []string
+	sheet   *bufio.Writer
+	row     int
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{archive: zip.NewWriter(w)}
+}
+
+// AddSheet starts a new worksheet, which rows are written to from then on
+func (x *xlsxWriter) AddSheet(name string) error {
+	if err := x.finishSheet(); err != nil {
+		return err
+	}
+
+	x.sheets = append(x.sheets, name)
+	entry, err := x.archive.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(x.sheets)))
+	if err != nil {
+		return err
+	}
+	x.sheet = bufio.NewWriter(entry)
+	x.row = 0
+	_, err = x.sheet.WriteString(xlsxSheetHeader)
+	return err
+}
+
+// WriteRow writes a row to the current sheet. Integers and floats are written
+// as numbers and everything else as text.
+func (x *xlsxWriter) WriteRow(cells []interface{}) error {
+	if x.sheet == nil {
+		return fmt.Errorf("no sheet to write rows to")
+	}
+
+	x.row++
+	fmt.Fprintf(x.sheet, `<row r="%d">`, x.row)
+	for i, cell := range cells {
+		ref := xlsxColumn(i) + strconv.Itoa(x.row)
+		switch value := cell.(type) {
+		case int:
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%d</v></c>`, ref, value)
+		case int32:
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%d</v></c>`, ref, value)
+		case float64:
+			fmt.Fprintf(x.sheet, `<c r="%s"><v>%s</v></c>`, ref, strconv.FormatFloat(value, 'f', -1, 64))
+		default:
+			text := fmt.Sprint(value)
+			if text == "" {
+				continue
+			}
+			fmt.Fprintf(x.sheet, `<c r="%s" t="inlineStr"><is><t xml:space="preserve">`, ref)
+			if err := xml.EscapeText(x.sheet, []byte(text)); err != nil {
+				return err
+			}
+			x.sheet.WriteString(`</t></is></c>`)
+		}
+	}
+	_, err := x.sheet.WriteString(`</row>`)
+	return err
+}
+
+// Flush sends what was written so far to the underlying writer
+func (x *xlsxWriter) Flush() error {
+	if x.sheet != nil {
+		if err := x.sheet.Flush(); err != nil {
+			return err
+		}
+	}
+	return x.archive.Flush()
+}
+
+// Close finishes the current sheet and writes the parts describing the
+// workbook
+func (x *xlsxWriter) Close() error {
+	if err := x.finishSheet(); err != nil {
+		return err
+	}
+
+	sheets, sheetRels, sheetTypes := "", "", ""
+	for i, name := range x.sheets {
+		var escaped bytes.Buffer
+		xml.EscapeText(&escaped, []byte(name))
+		sheets += fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escaped.String(), i+1, i+1)
+		sheetRels += fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+		sheetTypes += fmt.Sprintf(`<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>` + sheets + `</sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` + sheetRels + `</Relationships>`},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"[Content_Types].xml", `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` + sheetTypes + `</Types>`},
+	}
+	for _, part := range parts {
+		entry, err := x.archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(entry, xml.Header+part.content); err != nil {
+			return err
+		}
+	}
+
+	return x.archive.Close()
+}
+
+func (x *xlsxWriter) finishSheet() error {
+	if x.sheet == nil {
+		return nil
+	}
+	if _, err := x.sheet.WriteString(xlsxSheetFooter); err != nil {
+		return err
+	}
+	err := x.sheet.Flush()
+	x.sheet = nil
+	return err
+}
+
+// xlsxColumn returns the letters of a zero based column, A to Z, then AA and
+// so on
+func xlsxColumn(i int) string {
+	column := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		column = string(rune('A'+(i-1)%26)) + column
+	}
+	return column
+}